@@ -227,6 +227,39 @@ func parseResultRemediations(client runtimeclient.Client, scheme *runtime.Scheme
 	return table, nodeName, nil
 }
 
+// addPreviousResults adds the results of the rules rescanned by a targeted
+// rescan from the already processed ConfigMaps of the nodes that weren't
+// rescanned. Those results are reused as they are until the next full scan,
+// even if they became outdated in the meantime.
+func addPreviousResults(crClient aggregatorCrClient, scan *compv1alpha1.ComplianceScan, content *xmlquery.Node, configMaps []v1.ConfigMap, prCtx *utils.ParseResultContext) {
+	rescanned := make(map[string]bool, len(scan.Status.TargetedRescan.Rules))
+	for _, rule := range scan.Status.TargetedRescan.Rules {
+		rescanned[rule] = true
+	}
+
+	for i := range configMaps {
+		if _, ok := configMaps[i].Annotations[configMapRemediationsProcessed]; !ok {
+			continue
+		}
+		cm := configMaps[i].DeepCopy()
+		delete(cm.Annotations, configMapRemediationsProcessed)
+		cmdLog.Info("Processing the previous results of a node that wasn't rescanned", "ConfigMap.Name", cm.Name)
+
+		cmParsedResults, source, err := parseResultRemediations(crClient.getClient(), crClient.getScheme(), scan.Name, scan.Namespace, content, cm)
+		if err != nil {
+			cmdLog.Error(err, "Cannot parse the previous results", "ConfigMap.Name", cm.Name)
+			continue
+		}
+		previous := []*utils.ParseResult{}
+		for _, pr := range cmParsedResults {
+			if pr != nil && rescanned[pr.Id] {
+				previous = append(previous, pr)
+			}
+		}
+		prCtx.AddResults(source, previous)
+	}
+}
+
 func getScanResult(cm *v1.ConfigMap) (compv1alpha1.ComplianceScanStatusResult, string) {
 	exitcode, ok := cm.Data["exit-code"]
 	if ok {
@@ -554,6 +587,37 @@ func getCheckResultAnnotations(cr *compv1alpha1.ComplianceCheckResult, resultAnn
 	return annotations
 }
 
+// waiveCheckResult marks a failing check result as waived if an active
+// ComplianceException applies to it. The labels and annotations are only
+// set on the result when it's stored, so they're passed separately.
+func waiveCheckResult(exceptions []compv1alpha1.ComplianceException, scan *compv1alpha1.ComplianceScan,
+	cr *compv1alpha1.ComplianceCheckResult, labels, annotations map[string]string, now time.Time) {
+	check := cr.DeepCopy()
+	check.SetAnnotations(annotations)
+	e := compv1alpha1.FindWaivingException(exceptions, check, scan, now)
+	if e == nil {
+		return
+	}
+	cmdLog.Info("Waiving failed check", "ComplianceCheckResult.Name", cr.Name, "ComplianceException.Name", e.Name)
+	labels[compv1alpha1.ComplianceCheckResultWaivedLabel] = ""
+	annotations[compv1alpha1.ComplianceCheckResultWaivedByAnnotation] = e.Name
+}
+
+// attestCheckResult marks a manual check result with the outcome of the
+// active ComplianceAttestation applying to it, if any. Like for waivers, the
+// labels and annotations are passed separately.
+func attestCheckResult(attestations []compv1alpha1.ComplianceAttestation,
+	cr *compv1alpha1.ComplianceCheckResult, labels, annotations map[string]string, now time.Time) {
+	a := compv1alpha1.FindAttestation(attestations, cr, now)
+	if a == nil {
+		return
+	}
+	cmdLog.Info("Attesting manual check", "ComplianceCheckResult.Name", cr.Name, "ComplianceAttestation.Name", a.Name,
+		"outcome", a.Spec.Outcome)
+	labels[compv1alpha1.ComplianceCheckResultAttestedLabel] = string(a.Spec.Outcome)
+	annotations[compv1alpha1.ComplianceCheckResultAttestedByAnnotation] = a.Name
+}
+
 func createResults(crClient aggregatorCrClient, scan *compv1alpha1.ComplianceScan, consistentResults []*utils.ParseResultContextItem) error {
 	cmdLog.Info("Will create result objects", "objects", len(consistentResults))
 	if len(consistentResults) == 0 {
@@ -566,14 +630,19 @@ func createResults(crClient aggregatorCrClient, scan *compv1alpha1.ComplianceSca
 	// we're configured to forward results to an external system and
 	// establish that connection here.
 	//
-	f := compliancescan.NewForwarder(scan)
-	//
 	// The NewForwarder() function is a factory that returns the
 	// appropriate forwarding client based on the configuration supplied in
 	// the `ScanSetting`. This should keep forwarding implementation
-	// details (e.g., gRPC) decoupled from the aggregator (e.g., does the
-	// aggregator need to know it's using gRPC under the hood, probably
-	// not).
+	// details (e.g., HTTP) decoupled from the aggregator.
+	//
+	// Forwarding errors are not fatal, the results are still stored as
+	// CRs. The first error is surfaced as a condition on the scan instead,
+	// and nothing else is forwarded after it: every send to an unreachable
+	// endpoint would otherwise wait for its own retries and timeouts.
+	f, fwdErr := compliancescan.NewForwarder(crClient.getClient(), scan)
+	if fwdErr != nil {
+		cmdLog.Error(fwdErr, "Cannot set up result forwarding")
+	}
 
 	// Find all the existing scan results. As we iterate through the list
 	// of the most recent results below, we should remove entries from the
@@ -601,6 +670,21 @@ func createResults(crClient aggregatorCrClient, scan *compv1alpha1.ComplianceSca
 		// every new result from the latest scan.
 		staleComplianceCheckResults[r.Name] = r
 	}
+	// The existing results are also what the new ones are compared to in
+	// order to find out what changed since the previous scan.
+	drift := newResultDriftTracker(scan, complianceCheckResults.Items)
+
+	exceptions := compv1alpha1.ComplianceExceptionList{}
+	err = crClient.getClient().List(context.TODO(), &exceptions, runtimeclient.InNamespace(scan.Namespace))
+	if err != nil {
+		return fmt.Errorf("Unable to fetch ComplianceExceptionList: %w", err)
+	}
+	attestations := compv1alpha1.ComplianceAttestationList{}
+	err = crClient.getClient().List(context.TODO(), &attestations, runtimeclient.InNamespace(scan.Namespace))
+	if err != nil {
+		return fmt.Errorf("Unable to fetch ComplianceAttestationList: %w", err)
+	}
+	now := time.Now()
 
 	for _, pr := range consistentResults {
 		if pr == nil || pr.CheckResult == nil {
@@ -616,6 +700,8 @@ func createResults(crClient aggregatorCrClient, scan *compv1alpha1.ComplianceSca
 
 		checkResultLabels := getCheckResultLabels(&pr.ParseResult, pr.Labels, scan)
 		checkResultAnnotations := getCheckResultAnnotations(pr.CheckResult, pr.Annotations)
+		waiveCheckResult(exceptions.Items, scan, pr.CheckResult, checkResultLabels, checkResultAnnotations, now)
+		attestCheckResult(attestations.Items, pr.CheckResult, checkResultLabels, checkResultAnnotations, now)
 
 		crkey := getObjKey(pr.CheckResult.GetName(), pr.CheckResult.GetNamespace())
 		foundCheckResult := &compv1alpha1.ComplianceCheckResult{}
@@ -638,6 +724,8 @@ func createResults(crClient aggregatorCrClient, scan *compv1alpha1.ComplianceSca
 			return fmt.Errorf("cannot create or update checkResult %s: %v", pr.CheckResult.Name, err)
 		}
 
+		drift.add(pr.CheckResult)
+
 		// Remove the ComplianceCheckResult from the list of stale
 		// results so we don't delete it later.
 		_, ok := staleComplianceCheckResults[foundCheckResult.Name]
@@ -646,7 +734,9 @@ func createResults(crClient aggregatorCrClient, scan *compv1alpha1.ComplianceSca
 		}
 
 		// Handle forwarding.
-		f.SendComplianceCheckResult(pr.CheckResult)
+		if f != nil && fwdErr == nil {
+			fwdErr = f.SendComplianceCheckResult(pr.CheckResult)
+		}
 
 		if pr.Remediations == nil ||
 			(pr.CheckResult.Status != compv1alpha1.CheckResultFail &&
@@ -657,7 +747,9 @@ func createResults(crClient aggregatorCrClient, scan *compv1alpha1.ComplianceSca
 		}
 		for _, r := range pr.Remediations {
 			// Handle forwarding.
-			f.SendComplianceRemediation(r)
+			if f != nil && fwdErr == nil {
+				fwdErr = f.SendComplianceRemediation(r)
+			}
 		}
 
 		for idx := range pr.Remediations {
@@ -672,7 +764,11 @@ func createResults(crClient aggregatorCrClient, scan *compv1alpha1.ComplianceSca
 	// staleComplianceCheckResults, they were from previous scans and we
 	// should delete them. Otherwise, we give users the impression changes
 	// they've made to their scans, profiles, or settings haven't taken
-	// effect.
+	// effect. A targeted rescan only re-evaluates some of the checks, so
+	// its results are merged into the existing ones instead.
+	if scan.Status.TargetedRescan != nil {
+		staleComplianceCheckResults = nil
+	}
 	for _, result := range staleComplianceCheckResults {
 		err := crClient.getClient().Delete(context.TODO(), &result)
 		if err != nil {
@@ -680,9 +776,46 @@ func createResults(crClient aggregatorCrClient, scan *compv1alpha1.ComplianceSca
 		}
 	}
 
+	if f != nil && fwdErr == nil {
+		fwdErr = f.Flush()
+	}
+	if scan.Spec.ResultForwarding != nil {
+		updateScanForwardingCondition(crClient, scan, fwdErr)
+	}
+
+	recordResultDrift(crClient, drift)
+
 	return nil
 }
 
+// updateScanForwardingCondition records whether the results of the scan
+// could be forwarded in the scan's status and raises an event on failure.
+// Errors updating the status are only logged since the results themselves
+// were stored successfully.
+func updateScanForwardingCondition(crClient aggregatorCrClient, scan *compv1alpha1.ComplianceScan, fwdErr error) {
+	if fwdErr != nil {
+		cmdLog.Error(fwdErr, "Forwarding results failed", "ComplianceScan.Name", scan.Name)
+		crClient.getRecorder().Event(scan, v1.EventTypeWarning, "ResultForwardingFailed", fwdErr.Error())
+	}
+
+	key := getObjKey(scan.GetName(), scan.GetNamespace())
+	err := backoff.Retry(func() error {
+		found := &compv1alpha1.ComplianceScan{}
+		if err := crClient.getClient().Get(context.TODO(), key, found); err != nil {
+			return err
+		}
+		if fwdErr != nil {
+			found.Status.SetConditionForwardingFailed(fwdErr.Error())
+		} else {
+			found.Status.SetConditionForwarded()
+		}
+		return crClient.getClient().Status().Update(context.TODO(), found)
+	}, backoff.WithMaxRetries(backoff.NewExponentialBackOff(), maxRetries))
+	if err != nil {
+		cmdLog.Error(err, "Cannot update the result forwarding condition", "ComplianceScan.Name", scan.Name)
+	}
+}
+
 func handleRemediation(crClient aggregatorCrClient, rem *compv1alpha1.ComplianceRemediation, cr *compv1alpha1.ComplianceCheckResult, scan *compv1alpha1.ComplianceScan) error {
 	crkey := getObjKey(cr.GetName(), cr.GetNamespace())
 	remTargetObj := rem.Spec.Current.Object
@@ -885,6 +1018,14 @@ func aggregator(cmd *cobra.Command, args []string) {
 		annotateCMWithScanResult(&configMaps[i], cmParsedResults)
 	}
 
+	// A targeted rescan only re-evaluated some of the rules on some of the
+	// nodes. The previous results of the other nodes are added for the
+	// rescanned rules so that their consistency is still computed across
+	// all the nodes.
+	if scan.Status.TargetedRescan != nil {
+		addPreviousResults(crclient, scan, contentDom, configMaps, prCtx)
+	}
+
 	// Once we gathered all results, try to reconcile those that are inconsistent
 	consistentParsedResults := prCtx.GetConsistentResults()
 
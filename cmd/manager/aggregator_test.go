@@ -3,6 +3,10 @@ package manager
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
 
 	backoff "github.com/cenkalti/backoff/v4"
 	. "github.com/onsi/ginkgo"
@@ -21,6 +25,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
 )
 
 type aggregatorCrClientFake struct {
@@ -296,4 +301,363 @@ var _ = Describe("Aggregator Tests", func() {
 			})
 		})
 	})
+
+	Context("Result drift", func() {
+		var scan *compv1alpha1.ComplianceScan
+		var crClient *aggregatorCrClientFake
+		var fakerecorder *fakerec.FakeRecorder
+		var previousScan time.Time
+
+		checkResult := func(name string, status compv1alpha1.ComplianceCheckStatus) *compv1alpha1.ComplianceCheckResult {
+			return &compv1alpha1.ComplianceCheckResult{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "ComplianceCheckResult",
+					APIVersion: compv1alpha1.SchemeGroupVersion.String(),
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: "bar",
+				},
+				ID:     "xccdf_org.ssgproject.content_rule_" + name,
+				Status: status,
+			}
+		}
+		existingResult := func(name string, status compv1alpha1.ComplianceCheckStatus, scanned time.Time) *compv1alpha1.ComplianceCheckResult {
+			res := checkResult(name, status)
+			res.Labels = map[string]string{compv1alpha1.ComplianceScanLabel: scan.Name}
+			res.Annotations = map[string]string{
+				compv1alpha1.LastScannedTimestampAnnotation: scanned.Format(time.RFC3339),
+			}
+			return res
+		}
+		newResults := func(results ...*compv1alpha1.ComplianceCheckResult) []*utils.ParseResultContextItem {
+			items := []*utils.ParseResultContextItem{}
+			for _, res := range results {
+				items = append(items, &utils.ParseResultContextItem{
+					ParseResult: utils.ParseResult{Id: res.ID, CheckResult: res},
+				})
+			}
+			return items
+		}
+
+		BeforeEach(func() {
+			scheme := getScheme()
+			previousScan = time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+
+			scan = &compv1alpha1.ComplianceScan{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo",
+					Namespace: "bar",
+					UID:       "scan-uid",
+				},
+				Status: compv1alpha1.ComplianceScanStatus{
+					CurrentIndex:   1,
+					StartTimestamp: &metav1.Time{Time: time.Now()},
+				},
+			}
+			objs := []runtime.Object{
+				scan,
+				existingResult("foo-a", compv1alpha1.CheckResultPass, previousScan),
+				existingResult("foo-b", compv1alpha1.CheckResultFail, previousScan),
+				existingResult("foo-c", compv1alpha1.CheckResultPass, previousScan),
+				existingResult("foo-d", compv1alpha1.CheckResultFail, previousScan),
+				// Already updated by this run, e.g. by a previous
+				// attempt of the aggregator
+				existingResult("foo-e", compv1alpha1.CheckResultPass, scan.Status.StartTimestamp.Time),
+			}
+
+			client := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(scan).
+				WithRuntimeObjects(objs...).
+				Build()
+
+			fakerecorder = fakerec.NewFakeRecorder(10)
+			crClient = &aggregatorCrClientFake{
+				scheme:      scheme,
+				client:      client,
+				recorder:    fakerecorder,
+				fakevgetter: &fakeversionget{},
+			}
+		})
+
+		It("Records what changed since the previous scan and raises events for regressions", func() {
+			err := createResults(crClient, scan, newResults(
+				checkResult("foo-a", compv1alpha1.CheckResultFail),
+				checkResult("foo-b", compv1alpha1.CheckResultPass),
+				checkResult("foo-c", compv1alpha1.CheckResultInconsistent),
+				checkResult("foo-d", compv1alpha1.CheckResultFail),
+				checkResult("foo-e", compv1alpha1.CheckResultFail),
+				// Not part of the previous scan
+				checkResult("foo-f", compv1alpha1.CheckResultFail),
+			))
+			Expect(err).To(BeNil())
+
+			found := &compv1alpha1.ComplianceScan{}
+			Expect(crClient.client.Get(context.TODO(), getObjKey(scan.Name, scan.Namespace), found)).To(Succeed())
+			drift := found.Status.ResultDrift
+			Expect(drift).ToNot(BeNil())
+			Expect(drift.ScanIndex).To(BeEquivalentTo(1))
+			Expect(drift.PreviousScanTimestamp.Time.Equal(previousScan)).To(BeTrue())
+			Expect(drift.NewlyFailing).To(Equal([]string{"foo-a"}))
+			Expect(drift.NewlyPassing).To(Equal([]string{"foo-b"}))
+			Expect(drift.NewlyInconsistent).To(Equal([]string{"foo-c"}))
+
+			Expect(fakerecorder.Events).To(HaveLen(3))
+			Expect(<-fakerecorder.Events).To(Equal("Warning CheckResultRegressed The check is now FAIL, it was PASS in the previous scan"))
+			Expect(<-fakerecorder.Events).To(Equal("Warning CheckResultRegressed The check is now INCONSISTENT, it was PASS in the previous scan"))
+			Expect(<-fakerecorder.Events).To(ContainSubstring("Warning ResultDrift Since the previous scan, 1 checks are newly failing, 1 newly passing and 1 newly inconsistent"))
+		})
+
+		It("Doesn't record the drift twice for the same scan run", func() {
+			scan.Status.ResultDrift = &compv1alpha1.ComplianceScanResultDrift{
+				ScanIndex:    1,
+				NewlyFailing: []string{"foo-a"},
+			}
+			Expect(crClient.client.Status().Update(context.TODO(), scan)).To(Succeed())
+
+			err := createResults(crClient, scan, newResults(
+				checkResult("foo-a", compv1alpha1.CheckResultFail),
+				checkResult("foo-b", compv1alpha1.CheckResultFail),
+			))
+			Expect(err).To(BeNil())
+
+			found := &compv1alpha1.ComplianceScan{}
+			Expect(crClient.client.Get(context.TODO(), getObjKey(scan.Name, scan.Namespace), found)).To(Succeed())
+			Expect(found.Status.ResultDrift.NewlyFailing).To(Equal([]string{"foo-a"}))
+			Expect(fakerecorder.Events).To(BeEmpty())
+		})
+
+		It("Merges the results of a targeted rescan into the existing ones", func() {
+			scan.Status.TargetedRescan = &compv1alpha1.ComplianceScanTargetedRescan{
+				Rules: []string{"xccdf_org.ssgproject.content_rule_foo-b"},
+			}
+
+			err := createResults(crClient, scan, newResults(
+				checkResult("foo-b", compv1alpha1.CheckResultPass),
+			))
+			Expect(err).To(BeNil())
+
+			results := &compv1alpha1.ComplianceCheckResultList{}
+			Expect(crClient.client.List(context.TODO(), results)).To(Succeed())
+			statuses := map[string]compv1alpha1.ComplianceCheckStatus{}
+			for _, res := range results.Items {
+				statuses[res.Name] = res.Status
+			}
+			Expect(statuses).To(Equal(map[string]compv1alpha1.ComplianceCheckStatus{
+				"foo-a": compv1alpha1.CheckResultPass,
+				"foo-b": compv1alpha1.CheckResultPass,
+				"foo-c": compv1alpha1.CheckResultPass,
+				"foo-d": compv1alpha1.CheckResultFail,
+				"foo-e": compv1alpha1.CheckResultPass,
+			}))
+		})
+
+		It("Stops forwarding the results after the first failure", func() {
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&requests, 1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer server.Close()
+			scan.Spec.ResultForwarding = &compv1alpha1.ResultForwardingSettings{
+				Provider: compv1alpha1.ResultForwardingProviderHTTP,
+				HTTP: &compv1alpha1.HTTPForwardingSettings{
+					Endpoint:  server.URL,
+					BatchSize: 1,
+				},
+			}
+
+			err := createResults(crClient, scan, newResults(
+				checkResult("foo-a", compv1alpha1.CheckResultPass),
+				checkResult("foo-b", compv1alpha1.CheckResultPass),
+				checkResult("foo-c", compv1alpha1.CheckResultPass),
+			))
+			Expect(err).To(BeNil())
+			Expect(atomic.LoadInt32(&requests)).To(BeEquivalentTo(1))
+
+			found := &compv1alpha1.ComplianceScan{}
+			Expect(crClient.client.Get(context.TODO(), getObjKey(scan.Name, scan.Namespace), found)).To(Succeed())
+			cond := found.Status.Conditions.GetCondition("ResultsForwarded")
+			Expect(cond).ToNot(BeNil())
+			Expect(cond.Reason).To(BeEquivalentTo("DeliveryFailed"))
+		})
+	})
+
+	Context("Compliance exceptions", func() {
+		var scan *compv1alpha1.ComplianceScan
+		var crClient *aggregatorCrClientFake
+
+		checkResult := func(name string, status compv1alpha1.ComplianceCheckStatus) *compv1alpha1.ComplianceCheckResult {
+			return &compv1alpha1.ComplianceCheckResult{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "ComplianceCheckResult",
+					APIVersion: compv1alpha1.SchemeGroupVersion.String(),
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo-" + name,
+					Namespace: "bar",
+				},
+				ID:     "xccdf_org.ssgproject.content_rule_" + name,
+				Status: status,
+			}
+		}
+		exception := func(name string, expiration time.Time) *compv1alpha1.ComplianceException {
+			return &compv1alpha1.ComplianceException{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: "bar",
+				},
+				Spec: compv1alpha1.ComplianceExceptionSpec{
+					Justification:  "Accepted risk",
+					Approver:       "security-team",
+					ExpirationDate: metav1.Time{Time: expiration},
+				},
+				Status: compv1alpha1.ComplianceExceptionStatus{
+					Phase: compv1alpha1.ExceptionPhaseActive,
+				},
+			}
+		}
+		getCheckResult := func(name string) *compv1alpha1.ComplianceCheckResult {
+			found := &compv1alpha1.ComplianceCheckResult{}
+			Expect(crClient.client.Get(context.TODO(), getObjKey(name, "bar"), found)).To(Succeed())
+			return found
+		}
+
+		BeforeEach(func() {
+			scheme := getScheme()
+			scan = &compv1alpha1.ComplianceScan{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo",
+					Namespace: "bar",
+					UID:       "scan-uid",
+				},
+				Status: compv1alpha1.ComplianceScanStatus{
+					StartTimestamp: &metav1.Time{Time: time.Now()},
+				},
+			}
+
+			ruleException := exception("rule-a", time.Now().Add(time.Hour))
+			ruleException.Spec.Rule = "ocp4-a"
+			ruleException.Status.RuleID = "a"
+			expiredException := exception("check-b", time.Now().Add(-time.Hour))
+			expiredException.Spec.CheckResult = "foo-b"
+
+			client := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(scan).
+				WithRuntimeObjects(scan, ruleException, expiredException).
+				Build()
+			crClient = &aggregatorCrClientFake{
+				scheme:      scheme,
+				client:      client,
+				recorder:    fakerec.NewFakeRecorder(10),
+				fakevgetter: &fakeversionget{},
+			}
+		})
+
+		It("Marks the failures waived by an active exception", func() {
+			err := createResults(crClient, scan, []*utils.ParseResultContextItem{
+				{ParseResult: utils.ParseResult{Id: "a", CheckResult: checkResult("a", compv1alpha1.CheckResultFail)}},
+				{ParseResult: utils.ParseResult{Id: "b", CheckResult: checkResult("b", compv1alpha1.CheckResultFail)}},
+				{ParseResult: utils.ParseResult{Id: "c", CheckResult: checkResult("c", compv1alpha1.CheckResultPass)}},
+			})
+			Expect(err).To(BeNil())
+
+			waived := getCheckResult("foo-a")
+			Expect(waived.IsWaived()).To(BeTrue())
+			Expect(waived.Annotations).To(HaveKeyWithValue(compv1alpha1.ComplianceCheckResultWaivedByAnnotation, "rule-a"))
+			// The exception for this one expired
+			Expect(getCheckResult("foo-b").IsWaived()).To(BeFalse())
+			Expect(getCheckResult("foo-c").IsWaived()).To(BeFalse())
+		})
+	})
+
+	Context("Compliance attestations", func() {
+		var scan *compv1alpha1.ComplianceScan
+		var crClient *aggregatorCrClientFake
+
+		checkResult := func(name string, status compv1alpha1.ComplianceCheckStatus) *compv1alpha1.ComplianceCheckResult {
+			return &compv1alpha1.ComplianceCheckResult{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "ComplianceCheckResult",
+					APIVersion: compv1alpha1.SchemeGroupVersion.String(),
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo-" + name,
+					Namespace: "bar",
+				},
+				ID:     "xccdf_org.ssgproject.content_rule_" + name,
+				Status: status,
+			}
+		}
+		attestation := func(name, checkResult string, outcome compv1alpha1.ComplianceCheckStatus, expiration time.Time) *compv1alpha1.ComplianceAttestation {
+			return &compv1alpha1.ComplianceAttestation{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: "bar",
+				},
+				Spec: compv1alpha1.ComplianceAttestationSpec{
+					CheckResult:    checkResult,
+					Outcome:        outcome,
+					Attester:       "auditor",
+					ExpirationDate: metav1.Time{Time: expiration},
+				},
+				Status: compv1alpha1.ComplianceAttestationStatus{
+					Phase: compv1alpha1.AttestationPhaseActive,
+				},
+			}
+		}
+		getCheckResult := func(name string) *compv1alpha1.ComplianceCheckResult {
+			found := &compv1alpha1.ComplianceCheckResult{}
+			Expect(crClient.client.Get(context.TODO(), getObjKey(name, "bar"), found)).To(Succeed())
+			return found
+		}
+
+		BeforeEach(func() {
+			scheme := getScheme()
+			scan = &compv1alpha1.ComplianceScan{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo",
+					Namespace: "bar",
+					UID:       "scan-uid",
+				},
+				Status: compv1alpha1.ComplianceScanStatus{
+					StartTimestamp: &metav1.Time{Time: time.Now()},
+				},
+			}
+
+			client := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(scan).
+				WithRuntimeObjects(scan,
+					attestation("a-reviewed", "foo-a", compv1alpha1.CheckResultFail, time.Now().Add(time.Hour)),
+					attestation("b-reviewed", "foo-b", compv1alpha1.CheckResultPass, time.Now().Add(-time.Hour)),
+					attestation("c-reviewed", "foo-c", compv1alpha1.CheckResultPass, time.Now().Add(time.Hour))).
+				Build()
+			crClient = &aggregatorCrClientFake{
+				scheme:      scheme,
+				client:      client,
+				recorder:    fakerec.NewFakeRecorder(10),
+				fakevgetter: &fakeversionget{},
+			}
+		})
+
+		It("Marks the manual checks with the outcome of an active attestation", func() {
+			err := createResults(crClient, scan, []*utils.ParseResultContextItem{
+				{ParseResult: utils.ParseResult{Id: "a", CheckResult: checkResult("a", compv1alpha1.CheckResultManual)}},
+				{ParseResult: utils.ParseResult{Id: "b", CheckResult: checkResult("b", compv1alpha1.CheckResultManual)}},
+				{ParseResult: utils.ParseResult{Id: "c", CheckResult: checkResult("c", compv1alpha1.CheckResultFail)}},
+			})
+			Expect(err).To(BeNil())
+
+			attested := getCheckResult("foo-a")
+			Expect(attested.AttestedStatus()).To(Equal(compv1alpha1.CheckResultFail))
+			Expect(attested.Annotations).To(HaveKeyWithValue(compv1alpha1.ComplianceCheckResultAttestedByAnnotation, "a-reviewed"))
+			// The attestation for this one expired
+			Expect(getCheckResult("foo-b").Labels).ToNot(HaveKey(compv1alpha1.ComplianceCheckResultAttestedLabel))
+			// Only manual checks are attested
+			Expect(getCheckResult("foo-c").Labels).ToNot(HaveKey(compv1alpha1.ComplianceCheckResultAttestedLabel))
+		})
+	})
 })
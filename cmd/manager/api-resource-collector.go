@@ -63,6 +63,7 @@ type fetcherConfig struct {
 	Profile            string
 	ExitCodeFile       string
 	WarningsOutputFile string
+	ResourceDir        string
 }
 
 func defineAPIResourceCollectorFlags(cmd *cobra.Command) {
@@ -73,6 +74,7 @@ func defineAPIResourceCollectorFlags(cmd *cobra.Command) {
 	cmd.Flags().String("warnings-output-file", "", "A file containing the warnings output.")
 	cmd.Flags().Bool("debug", false, "Print debug messages.")
 	cmd.Flags().String("platform", "", "The platform flag used by CPE detection.")
+	cmd.Flags().String("resource-dir", "", "Read the resources from this directory, laid out like the API paths, instead of the API server.")
 
 	flags := cmd.Flags()
 
@@ -89,6 +91,7 @@ func parseAPIResourceCollectorConfig(cmd *cobra.Command) *fetcherConfig {
 	conf.WarningsOutputFile = getValidStringArg(cmd, "warnings-output-file")
 	debugLog, _ = cmd.Flags().GetBool("debug")
 	conf.Tailoring, _ = cmd.Flags().GetString("tailoring")
+	conf.ResourceDir, _ = cmd.Flags().GetString("resource-dir")
 	return &conf
 }
 
@@ -112,21 +115,14 @@ func getApiCollectorClient(config *rest.Config, scheme *runtime.Scheme) (runtime
 
 func runAPIResourceCollector(cmd *cobra.Command, args []string) {
 	fetcherConf := parseAPIResourceCollectorConfig(cmd)
-	restConfig := getConfig()
-	scheme := getScheme()
 
-	kubeClientSet, err := kubernetes.NewForConfig(restConfig)
-	if err != nil {
-		FATAL("Error building kubeClientSet: %v", err)
+	var fetcher ResourceFetcher
+	if fetcherConf.ResourceDir != "" {
+		fetcher = NewOfflineDataStreamResourceFetcher(fetcherConf.ResourceDir)
+	} else {
+		fetcher = newAPIResourceFetcher()
 	}
 
-	client, err := getApiCollectorClient(restConfig, scheme)
-	if err != nil {
-		FATAL("Error building kubeClientSet: %v", err)
-	}
-
-	fetcher := NewDataStreamResourceFetcher(scheme, client, kubeClientSet)
-
 	if err := fetcher.LoadSource(fetcherConf.Content); err != nil {
 		FATAL("Error loading source data: %v", err)
 	}
@@ -150,3 +146,20 @@ func runAPIResourceCollector(cmd *cobra.Command, args []string) {
 		FATAL("Error saving resources: %v", err)
 	}
 }
+
+func newAPIResourceFetcher() ResourceFetcher {
+	restConfig := getConfig()
+	scheme := getScheme()
+
+	kubeClientSet, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		FATAL("Error building kubeClientSet: %v", err)
+	}
+
+	client, err := getApiCollectorClient(restConfig, scheme)
+	if err != nil {
+		FATAL("Error building kubeClientSet: %v", err)
+	}
+
+	return NewDataStreamResourceFetcher(scheme, client, kubeClientSet)
+}
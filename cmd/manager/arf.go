@@ -0,0 +1,376 @@
+/*
+Copyright © 2026 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package manager
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	utils "github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+const (
+	arfOutputTable = "table"
+	arfOutputJSON  = "json"
+)
+
+var ArfCmd = &cobra.Command{
+	Use:   "arf",
+	Short: "Views and compares raw ARF results without a cluster.",
+	Long: `Views and compares the raw ARF results stored by the resultserver, plain or bzip2-compressed,
+without access to the cluster. The results of the nodes of a scan run are reconciled the way the
+aggregator does it, so the inconsistent checks show up as they would on the cluster.`,
+}
+
+var arfShowCmd = &cobra.Command{
+	Use:   "show PATH...",
+	Short: "Shows the results of a scan run, per node.",
+	Long: `Shows the results of a scan run. The paths are either ARF files or directories holding the
+ARF files of a single scan run, one per node.`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  runArfShow,
+}
+
+var arfDiffCmd = &cobra.Command{
+	Use:   "diff OLD NEW",
+	Short: "Shows the checks whose result changed between two scan runs.",
+	Long: `Shows the checks whose result changed between two scan runs. Each run is either an ARF file
+or a directory holding the ARF files of a single scan run, one per node.`,
+	Args: cobra.ExactArgs(2),
+	Run:  runArfDiff,
+}
+
+func init() {
+	defineArfFlags(ArfCmd)
+	ArfCmd.AddCommand(arfShowCmd)
+	ArfCmd.AddCommand(arfDiffCmd)
+}
+
+func defineArfFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().String("content", "", "The path to the data stream the scans were run with")
+	cmd.PersistentFlags().String("scan", "", "The name of the scan the results belong to")
+	cmd.PersistentFlags().String("namespace", "openshift-compliance", "The namespace of the scan")
+	cmd.PersistentFlags().StringP("output", "o", arfOutputTable, "The output format, table or json")
+
+	flags := cmd.PersistentFlags()
+
+	// Add flags registered by imported packages (e.g. glog and
+	// controller-runtime)
+	flags.AddGoFlagSet(flag.CommandLine)
+}
+
+type arfConfig struct {
+	reportConfig
+	Output string
+}
+
+func parseArfConfig(cmd *cobra.Command) *arfConfig {
+	conf := &arfConfig{
+		reportConfig: reportConfig{
+			Content:   getValidStringArg(cmd, "content"),
+			ScanName:  getValidStringArg(cmd, "scan"),
+			Namespace: getValidStringArg(cmd, "namespace"),
+		},
+		Output: getValidStringArg(cmd, "output"),
+	}
+	if conf.Output != arfOutputTable && conf.Output != arfOutputJSON {
+		fmt.Fprintf(os.Stderr, "Unknown output format '%s'.\n", conf.Output)
+		os.Exit(1)
+	}
+
+	logf.SetLogger(zap.New())
+
+	return conf
+}
+
+func loadArfContent(conf *arfConfig) *xmlquery.Node {
+	contentFile, err := readContent(conf.Content)
+	if err != nil {
+		cmdLog.Error(err, "Cannot read the content")
+		os.Exit(1)
+	}
+	// #nosec
+	defer contentFile.Close()
+	contentDom, err := utils.ParseContent(bufio.NewReader(contentFile))
+	if err != nil {
+		cmdLog.Error(err, "Cannot parse the content")
+		os.Exit(1)
+	}
+	return contentDom
+}
+
+func runArfShow(cmd *cobra.Command, args []string) {
+	conf := parseArfConfig(cmd)
+	content := loadArfContent(conf)
+
+	run, err := loadArfRun(getScheme(), &conf.reportConfig, content, args...)
+	if err != nil {
+		cmdLog.Error(err, "Cannot load the results")
+		os.Exit(1)
+	}
+	if err := printArfRun(os.Stdout, conf.Output, run); err != nil {
+		cmdLog.Error(err, "Cannot print the results")
+		os.Exit(1)
+	}
+}
+
+func runArfDiff(cmd *cobra.Command, args []string) {
+	conf := parseArfConfig(cmd)
+	content := loadArfContent(conf)
+
+	oldRun, err := loadArfRun(getScheme(), &conf.reportConfig, content, args[0])
+	if err != nil {
+		cmdLog.Error(err, "Cannot load the old results")
+		os.Exit(1)
+	}
+	newRun, err := loadArfRun(getScheme(), &conf.reportConfig, content, args[1])
+	if err != nil {
+		cmdLog.Error(err, "Cannot load the new results")
+		os.Exit(1)
+	}
+	if err := printArfDiff(os.Stdout, conf.Output, diffArfRuns(oldRun, newRun)); err != nil {
+		cmdLog.Error(err, "Cannot print the differences")
+		os.Exit(1)
+	}
+}
+
+// arfRun holds the results of a scan run, per node and reconciled
+type arfRun struct {
+	// The nodes or platform the results come from
+	sources []string
+	// Check ID -> source -> status
+	statuses map[string]map[string]compv1alpha1.ComplianceCheckStatus
+	// Check ID -> result reconciled across the sources
+	results map[string]*utils.ParseResultContextItem
+}
+
+// loadArfRun parses the given ARF files, or the ARF files found in the given
+// directories, and reconciles their results like the aggregator does
+func loadArfRun(scheme *runtime.Scheme, conf *reportConfig, content *xmlquery.Node, paths ...string) (*arfRun, error) {
+	arfFiles := map[string]string{}
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			source, ok := arfSourceName(filepath.Base(p))
+			if !ok {
+				source = filepath.Base(p)
+			}
+			arfFiles[source] = p
+			continue
+		}
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if source, ok := arfSourceName(entry.Name()); ok && !entry.IsDir() {
+				arfFiles[source] = filepath.Join(p, entry.Name())
+			}
+		}
+	}
+	if len(arfFiles) == 0 {
+		return nil, fmt.Errorf("no ARF files found in %s", strings.Join(paths, ", "))
+	}
+
+	run := &arfRun{
+		statuses: map[string]map[string]compv1alpha1.ComplianceCheckStatus{},
+		results:  map[string]*utils.ParseResultContextItem{},
+	}
+	for source := range arfFiles {
+		run.sources = append(run.sources, source)
+	}
+	sort.Strings(run.sources)
+
+	prCtx := utils.NewParseResultContext()
+	for _, source := range run.sources {
+		results, err := parseARFFile(scheme, conf, content, arfFiles[source])
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse %s: %w", arfFiles[source], err)
+		}
+		for _, pr := range results {
+			if pr == nil || pr.CheckResult == nil {
+				continue
+			}
+			if run.statuses[pr.Id] == nil {
+				run.statuses[pr.Id] = map[string]compv1alpha1.ComplianceCheckStatus{}
+			}
+			run.statuses[pr.Id][source] = pr.CheckResult.Status
+		}
+		prCtx.AddResults(source, results)
+	}
+	for _, item := range prCtx.GetConsistentResults() {
+		run.results[item.Id] = item
+	}
+	return run, nil
+}
+
+// arfCheckResult is how a check result is shown
+type arfCheckResult struct {
+	Name     string                                        `json:"name"`
+	ID       string                                        `json:"id"`
+	Severity compv1alpha1.ComplianceCheckResultSeverity    `json:"severity"`
+	Status   compv1alpha1.ComplianceCheckStatus            `json:"status"`
+	Sources  map[string]compv1alpha1.ComplianceCheckStatus `json:"sources"`
+	// Only set for inconsistent checks
+	MostCommonStatus    string `json:"mostCommonStatus,omitempty"`
+	InconsistentSources string `json:"inconsistentSources,omitempty"`
+	Error               string `json:"error,omitempty"`
+}
+
+// checkResults returns the results of the run sorted by name
+func (r *arfRun) checkResults() []arfCheckResult {
+	checks := make([]arfCheckResult, 0, len(r.results))
+	for id, item := range r.results {
+		checks = append(checks, arfCheckResult{
+			Name:                item.CheckResult.Name,
+			ID:                  id,
+			Severity:            item.CheckResult.Severity,
+			Status:              item.CheckResult.Status,
+			Sources:             r.statuses[id],
+			MostCommonStatus:    item.Annotations[compv1alpha1.ComplianceCheckResultMostCommonAnnotation],
+			InconsistentSources: item.Annotations[compv1alpha1.ComplianceCheckResultInconsistentSourceAnnotation],
+			Error:               item.Annotations[compv1alpha1.ComplianceCheckResultErrorAnnotation],
+		})
+	}
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].Name < checks[j].Name
+	})
+	return checks
+}
+
+func printArfRun(w io.Writer, output string, run *arfRun) error {
+	checks := run.checkResults()
+	if output == arfOutputJSON {
+		return printJSON(w, struct {
+			Sources []string         `json:"sources"`
+			Results []arfCheckResult `json:"results"`
+		}{run.sources, checks})
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "CHECK\tSEVERITY\tRESULT\t%s\n", strings.Join(run.sources, "\t"))
+	for _, check := range checks {
+		statuses := make([]string, 0, len(run.sources))
+		for _, source := range run.sources {
+			status, ok := check.Sources[source]
+			if !ok {
+				status = "-"
+			}
+			statuses = append(statuses, string(status))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", check.Name, check.Severity, check.Status, strings.Join(statuses, "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, check := range checks {
+		switch {
+		case check.Error != "":
+			fmt.Fprintf(w, "\n%s is %s: %s\n", check.Name, check.Status, check.Error)
+		case check.Status == compv1alpha1.CheckResultInconsistent:
+			fmt.Fprintf(w, "\n%s is %s", check.Name, check.Status)
+			if check.MostCommonStatus != "" {
+				fmt.Fprintf(w, ", most commonly %s", check.MostCommonStatus)
+			}
+			fmt.Fprintf(w, ", differing sources: %s\n", check.InconsistentSources)
+		}
+	}
+	return nil
+}
+
+// arfCheckDiff is a check whose result changed between two scan runs. The
+// status is empty on the side the check wasn't found.
+type arfCheckDiff struct {
+	Name string                             `json:"name"`
+	ID   string                             `json:"id"`
+	Old  compv1alpha1.ComplianceCheckStatus `json:"old,omitempty"`
+	New  compv1alpha1.ComplianceCheckStatus `json:"new,omitempty"`
+}
+
+// diffArfRuns returns the checks whose reconciled result changed, sorted by
+// name
+func diffArfRuns(oldRun, newRun *arfRun) []arfCheckDiff {
+	diffs := []arfCheckDiff{}
+	for id, oldItem := range oldRun.results {
+		newItem, ok := newRun.results[id]
+		if !ok {
+			diffs = append(diffs, arfCheckDiff{Name: oldItem.CheckResult.Name, ID: id, Old: oldItem.CheckResult.Status})
+		} else if newItem.CheckResult.Status != oldItem.CheckResult.Status {
+			diffs = append(diffs, arfCheckDiff{Name: newItem.CheckResult.Name, ID: id, Old: oldItem.CheckResult.Status, New: newItem.CheckResult.Status})
+		}
+	}
+	for id, newItem := range newRun.results {
+		if _, ok := oldRun.results[id]; !ok {
+			diffs = append(diffs, arfCheckDiff{Name: newItem.CheckResult.Name, ID: id, New: newItem.CheckResult.Status})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Name < diffs[j].Name
+	})
+	return diffs
+}
+
+func printArfDiff(w io.Writer, output string, diffs []arfCheckDiff) error {
+	if output == arfOutputJSON {
+		return printJSON(w, diffs)
+	}
+
+	counts := map[compv1alpha1.ComplianceCheckStatus]int{}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tOLD\tNEW")
+	for _, diff := range diffs {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", diff.Name, statusOrDash(diff.Old), statusOrDash(diff.New))
+		counts[diff.New]++
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%d checks changed: %d newly failing, %d newly passing, %d newly inconsistent\n", len(diffs),
+		counts[compv1alpha1.CheckResultFail], counts[compv1alpha1.CheckResultPass], counts[compv1alpha1.CheckResultInconsistent])
+	return err
+}
+
+func statusOrDash(status compv1alpha1.ComplianceCheckStatus) string {
+	if status == "" {
+		return "-"
+	}
+	return string(status)
+}
+
+func printJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
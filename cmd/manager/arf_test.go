@@ -0,0 +1,169 @@
+package manager
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/dsnet/compress/bzip2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	utils "github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+var _ = Describe("Viewing and diffing ARF results", func() {
+	const changedRule = "xccdf_org.ssgproject.content_rule_selinux_policytype"
+
+	var (
+		resultDir string
+		content   *xmlquery.Node
+		conf      *reportConfig
+	)
+
+	writeResult := func(name string, data []byte, compress bool) {
+		if compress {
+			var buf bytes.Buffer
+			bz, err := bzip2.NewWriter(&buf, &bzip2.WriterConfig{})
+			Expect(err).To(BeNil())
+			_, err = bz.Write(data)
+			Expect(err).To(BeNil())
+			Expect(bz.Close()).To(Succeed())
+			data = buf.Bytes()
+		}
+		p := filepath.Join(resultDir, name)
+		Expect(os.MkdirAll(filepath.Dir(p), 0700)).To(Succeed())
+		Expect(os.WriteFile(p, data, 0600)).To(Succeed())
+	}
+
+	loadRun := func(paths ...string) *arfRun {
+		for i := range paths {
+			paths[i] = filepath.Join(resultDir, paths[i])
+		}
+		run, err := loadArfRun(getScheme(), conf, content, paths...)
+		Expect(err).To(BeNil())
+		return run
+	}
+
+	findCheck := func(checks []arfCheckResult, id string) arfCheckResult {
+		for _, check := range checks {
+			if check.ID == id {
+				return check
+			}
+		}
+		Fail("check " + id + " not found")
+		return arfCheckResult{}
+	}
+
+	BeforeEach(func() {
+		var err error
+		resultDir, err = os.MkdirTemp("", "arf")
+		Expect(err).To(BeNil())
+
+		ds, err := os.Open("../../tests/data/ds-input.xml")
+		Expect(err).To(BeNil())
+		defer ds.Close()
+		content, err = utils.ParseContent(ds)
+		Expect(err).To(BeNil())
+		conf = &reportConfig{ScanName: "test-scan", Namespace: "openshift-compliance"}
+
+		passing, err := os.ReadFile("../../tests/data/xccdf-result.xml")
+		Expect(err).To(BeNil())
+		failing := []byte(strings.Replace(string(passing),
+			`<rule-result idref="`+changedRule+`" time="2020-02-17T12:28:00" severity="high" weight="1.000000">
+            <result>pass</result>`,
+			`<rule-result idref="`+changedRule+`" time="2020-02-17T12:28:00" severity="high" weight="1.000000">
+            <result>fail</result>`, 1))
+		Expect(failing).ToNot(Equal(passing))
+
+		writeResult("0/test-scan-node-1-pod.xml", passing, false)
+		writeResult("0/test-scan-node-2-pod.xml.bzip2", passing, true)
+		writeResult("0/test-scan-node-1-pod.sarif", []byte("{}"), false)
+		writeResult("1/test-scan-node-1-pod.xml", failing, false)
+		// Compressed, but without the extension
+		writeResult("1/test-scan-node-2-pod.xml", passing, true)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(resultDir)
+	})
+
+	It("shows the results of every node", func() {
+		run := loadRun("0")
+		Expect(run.sources).To(Equal([]string{"test-scan-node-1-pod", "test-scan-node-2-pod"}))
+
+		var out bytes.Buffer
+		Expect(printArfRun(&out, arfOutputJSON, run)).To(Succeed())
+		shown := struct {
+			Sources []string         `json:"sources"`
+			Results []arfCheckResult `json:"results"`
+		}{}
+		Expect(json.Unmarshal(out.Bytes(), &shown)).To(Succeed())
+		Expect(shown.Sources).To(Equal(run.sources))
+		Expect(shown.Results).ToNot(BeEmpty())
+
+		check := findCheck(shown.Results, changedRule)
+		Expect(check.Name).To(Equal("test-scan-selinux-policytype"))
+		Expect(check.Status).To(Equal(compv1alpha1.CheckResultPass))
+		Expect(check.Sources).To(Equal(map[string]compv1alpha1.ComplianceCheckStatus{
+			"test-scan-node-1-pod": compv1alpha1.CheckResultPass,
+			"test-scan-node-2-pod": compv1alpha1.CheckResultPass,
+		}))
+	})
+
+	It("shows the inconsistencies between the nodes", func() {
+		run := loadRun("1")
+		check := findCheck(run.checkResults(), changedRule)
+		Expect(check.Status).To(Equal(compv1alpha1.CheckResultInconsistent))
+		Expect(check.Sources).To(Equal(map[string]compv1alpha1.ComplianceCheckStatus{
+			"test-scan-node-1-pod": compv1alpha1.CheckResultFail,
+			"test-scan-node-2-pod": compv1alpha1.CheckResultPass,
+		}))
+		Expect(check.InconsistentSources).To(ContainSubstring("test-scan-node-1-pod:FAIL"))
+
+		var out bytes.Buffer
+		Expect(printArfRun(&out, arfOutputTable, run)).To(Succeed())
+		Expect(out.String()).To(HavePrefix("CHECK"))
+		Expect(out.String()).To(MatchRegexp(`test-scan-selinux-policytype\s+medium\s+INCONSISTENT\s+FAIL\s+PASS`))
+		Expect(out.String()).To(ContainSubstring("test-scan-selinux-policytype is INCONSISTENT, differing sources:"))
+	})
+
+	It("diffs two scan runs", func() {
+		diffs := diffArfRuns(loadRun("0"), loadRun("1"))
+		Expect(diffs).To(Equal([]arfCheckDiff{{
+			Name: "test-scan-selinux-policytype",
+			ID:   changedRule,
+			Old:  compv1alpha1.CheckResultPass,
+			New:  compv1alpha1.CheckResultInconsistent,
+		}}))
+
+		diffs = diffArfRuns(loadRun("0/test-scan-node-1-pod.xml"), loadRun("1/test-scan-node-1-pod.xml"))
+		var out bytes.Buffer
+		Expect(printArfDiff(&out, arfOutputTable, diffs)).To(Succeed())
+		Expect(out.String()).To(MatchRegexp(`test-scan-selinux-policytype\s+PASS\s+FAIL`))
+		Expect(out.String()).To(ContainSubstring("1 checks changed: 1 newly failing"))
+	})
+
+	It("diffs runs with different checks", func() {
+		oldRun := loadRun("0")
+		newRun := loadRun("0")
+		delete(newRun.results, changedRule)
+		diffs := diffArfRuns(oldRun, newRun)
+		Expect(diffs).To(HaveLen(1))
+		Expect(diffs[0].New).To(BeEmpty())
+
+		var out bytes.Buffer
+		Expect(printArfDiff(&out, arfOutputTable, diffs)).To(Succeed())
+		Expect(out.String()).To(MatchRegexp(`test-scan-selinux-policytype\s+PASS\s+-`))
+	})
+
+	It("refuses directories without results", func() {
+		Expect(os.MkdirAll(filepath.Join(resultDir, "empty"), 0700)).To(Succeed())
+		_, err := loadArfRun(getScheme(), conf, content, filepath.Join(resultDir, "empty"))
+		Expect(err).ToNot(BeNil())
+	})
+})
@@ -0,0 +1,581 @@
+/*
+Copyright © 2026 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package manager
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+// The agent keeps what it applied for every remediation, along with the
+// backups of the files it overwrote, under this directory of the host
+const nodeRemediationStateDir = "/var/lib/compliance-operator/node-remediations"
+
+var NodeRemediationAgentCmd = &cobra.Command{
+	Use:   "node-remediation-agent",
+	Short: "Applies node remediations to the node it runs on.",
+	Long: `Applies the files and systemd units of the node remediations to the node it runs on,
+and restores the node once the remediations are removed.`,
+	Run: runNodeRemediationAgent,
+}
+
+func init() {
+	defineNodeRemediationAgentFlags(NodeRemediationAgentCmd)
+}
+
+func defineNodeRemediationAgentFlags(cmd *cobra.Command) {
+	cmd.Flags().String("namespace", "", "The namespace of the node remediation ConfigMaps")
+	cmd.Flags().String("node-name", os.Getenv("NODE_NAME"), "The name of the node the agent runs on")
+	cmd.Flags().String("host-root", "/host", "Where the root filesystem of the node is mounted")
+	cmd.Flags().Duration("interval", 30*time.Second, "How often the remediations are synced")
+
+	flags := cmd.Flags()
+
+	// Add flags registered by imported packages (e.g. glog and
+	// controller-runtime)
+	flags.AddGoFlagSet(flag.CommandLine)
+}
+
+type nodeRemediationAgentConfig struct {
+	Namespace string
+	NodeName  string
+	HostRoot  string
+	Interval  time.Duration
+}
+
+func parseNodeRemediationAgentConfig(cmd *cobra.Command) *nodeRemediationAgentConfig {
+	var conf nodeRemediationAgentConfig
+	conf.Namespace = getValidStringArg(cmd, "namespace")
+	conf.NodeName = getValidStringArg(cmd, "node-name")
+	conf.HostRoot = getValidStringArg(cmd, "host-root")
+	conf.Interval, _ = cmd.Flags().GetDuration("interval")
+	return &conf
+}
+
+func runNodeRemediationAgent(cmd *cobra.Command, args []string) {
+	conf := parseNodeRemediationAgentConfig(cmd)
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		FATAL("Error getting the kubeconfig: %v", err)
+	}
+	crclient, err := createCrClient(cfg)
+	if err != nil {
+		FATAL("Cannot create client for our types: %v", err)
+	}
+
+	agent := newNodeRemediationAgent(conf.HostRoot)
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	ticker := time.NewTicker(conf.Interval)
+	defer ticker.Stop()
+	for {
+		if err := syncNodeRemediations(ctx, crclient.getClient(), agent, conf); err != nil {
+			LOG("Error syncing the node remediations: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func syncNodeRemediations(ctx context.Context, c client.Client, agent *nodeRemediationAgent, conf *nodeRemediationAgentConfig) error {
+	node := &corev1.Node{}
+	if err := c.Get(ctx, types.NamespacedName{Name: conf.NodeName}, node); err != nil {
+		return fmt.Errorf("cannot get node %s: %w", conf.NodeName, err)
+	}
+	cms := &corev1.ConfigMapList{}
+	err := c.List(ctx, cms, client.InNamespace(conf.Namespace), client.HasLabels{compv1alpha1.NodeRemediationLabel})
+	if err != nil {
+		return fmt.Errorf("cannot list the node remediations: %w", err)
+	}
+
+	// Anyone able to create ConfigMaps in the namespace could otherwise
+	// configure the nodes, so the ConfigMaps that weren't rendered from an
+	// applied remediation are left out, and the nodes restored from them
+	var errs []error
+	verified := []corev1.ConfigMap{}
+	for i := range cms.Items {
+		if err := verifyNodeRemediation(ctx, c, &cms.Items[i]); err != nil {
+			errs = append(errs, fmt.Errorf("ignoring node remediation %s: %w", cms.Items[i].Name, err))
+			continue
+		}
+		verified = append(verified, cms.Items[i])
+	}
+	if err := agent.sync(labels.Set(node.Labels), verified); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// verifyNodeRemediation makes sure that a node remediation ConfigMap is
+// controlled by an applied ComplianceRemediation, and holds what the
+// remediation renders to
+func verifyNodeRemediation(ctx context.Context, c client.Client, cm *corev1.ConfigMap) error {
+	owner := metav1.GetControllerOf(cm)
+	if owner == nil || owner.Kind != "ComplianceRemediation" || owner.APIVersion != compv1alpha1.SchemeGroupVersion.String() {
+		return errors.New("it isn't controlled by a ComplianceRemediation")
+	}
+	rem := &compv1alpha1.ComplianceRemediation{}
+	if err := c.Get(ctx, types.NamespacedName{Name: owner.Name, Namespace: cm.Namespace}, rem); err != nil {
+		return fmt.Errorf("cannot get remediation %s: %w", owner.Name, err)
+	}
+	if rem.UID != owner.UID {
+		return fmt.Errorf("remediation %s was replaced", owner.Name)
+	}
+	if !rem.Spec.Apply {
+		return fmt.Errorf("remediation %s isn't applied", owner.Name)
+	}
+
+	obj := rem.Spec.Current.Object
+	if rem.Spec.Outdated.Object != nil {
+		obj = rem.Spec.Outdated.Object
+	}
+	if obj == nil || !utils.IsMachineConfig(obj) {
+		return fmt.Errorf("remediation %s doesn't configure the nodes", owner.Name)
+	}
+	scan := &compv1alpha1.ComplianceScan{}
+	scanKey := types.NamespacedName{Name: rem.Labels[compv1alpha1.ComplianceScanLabel], Namespace: rem.Namespace}
+	if err := c.Get(ctx, scanKey, scan); err != nil {
+		return fmt.Errorf("cannot get the scan of remediation %s: %w", owner.Name, err)
+	}
+	selector, err := scan.GetNodeLabelSelector()
+	if err != nil {
+		return err
+	}
+	mc, err := utils.ParseMachineConfig(rem, obj)
+	if err != nil {
+		return err
+	}
+	nodeRem, err := utils.NodeRemediationFromMachineConfig(mc, selector.String())
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(nodeRem)
+	if err != nil {
+		return err
+	}
+	if string(data) != cm.Data[utils.NodeRemediationDataKey] {
+		return fmt.Errorf("it doesn't match remediation %s", owner.Name)
+	}
+	return nil
+}
+
+type nodeRemediationAgent struct {
+	hostRoot string
+	// runs systemctl on the host with the given arguments
+	systemctl func(args ...string) (string, error)
+}
+
+func newNodeRemediationAgent(hostRoot string) *nodeRemediationAgent {
+	return &nodeRemediationAgent{
+		hostRoot: hostRoot,
+		systemctl: func(args ...string) (string, error) {
+			out, err := exec.Command("chroot", append([]string{hostRoot, "systemctl"}, args...)...).CombinedOutput()
+			return string(out), err
+		},
+	}
+}
+
+// appliedNodeRemediation is what the agent applied for a remediation
+type appliedNodeRemediation struct {
+	// Checksum of the applied remediation
+	Checksum string                       `json:"checksum"`
+	Files    []appliedNodeRemediationFile `json:"files,omitempty"`
+	Units    []appliedNodeRemediationUnit `json:"units,omitempty"`
+}
+
+type appliedNodeRemediationFile struct {
+	Path string `json:"path"`
+	// Whether the file existed before, in which case it was backed up
+	Existed bool `json:"existed"`
+}
+
+type appliedNodeRemediationUnit struct {
+	utils.NodeRemediationUnit
+	// The state of the unit before, as given by systemctl is-enabled
+	PreviousState string `json:"previousState"`
+}
+
+// sync applies the remediations that select the node and restores the node
+// from the remediations that were removed or no longer select it
+func (a *nodeRemediationAgent) sync(nodeLabels labels.Set, cms []corev1.ConfigMap) error {
+	wanted := map[string]bool{}
+	var errs []error
+	for i := range cms {
+		cm := &cms[i]
+		nodeRem := &utils.NodeRemediation{}
+		if err := json.Unmarshal([]byte(cm.Data[utils.NodeRemediationDataKey]), nodeRem); err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse node remediation %s: %w", cm.Name, err))
+			continue
+		}
+		selector, err := labels.Parse(nodeRem.NodeSelector)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid node selector of node remediation %s: %w", cm.Name, err))
+			continue
+		}
+		if !selector.Matches(nodeLabels) {
+			continue
+		}
+		wanted[cm.Name] = true
+		checksum := sha256.Sum256([]byte(cm.Data[utils.NodeRemediationDataKey]))
+		if err := a.apply(cm.Name, nodeRem, hex.EncodeToString(checksum[:])); err != nil {
+			errs = append(errs, fmt.Errorf("cannot apply node remediation %s: %w", cm.Name, err))
+		}
+	}
+
+	applied, err := a.appliedRemediations()
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+	for _, name := range applied {
+		if wanted[name] {
+			continue
+		}
+		if err := a.restore(name); err != nil {
+			errs = append(errs, fmt.Errorf("cannot restore node remediation %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// apply writes the files and units of a remediation and sets the state of
+// the units. A remediation that changed since it was applied is reapplied,
+// restoring the files and units it no longer configures.
+func (a *nodeRemediationAgent) apply(name string, nodeRem *utils.NodeRemediation, checksum string) error {
+	state, err := a.loadState(name)
+	if err != nil {
+		return err
+	}
+	if state.Checksum == checksum {
+		return nil
+	}
+	if err := nodeRem.Validate(); err != nil {
+		return err
+	}
+	LOG("Applying node remediation %s", name)
+
+	trackedFiles := map[string]appliedNodeRemediationFile{}
+	for _, f := range state.Files {
+		trackedFiles[f.Path] = f
+	}
+	trackedUnits := map[string]appliedNodeRemediationUnit{}
+	for _, u := range state.Units {
+		trackedUnits[u.Name] = u
+	}
+
+	files := nodeRem.Files
+	for _, unit := range nodeRem.Units {
+		if unit.Contents != "" {
+			files = append(files, utils.NodeRemediationFile{
+				Path:     filepath.Join(utils.NodeRemediationUnitDir, unit.Name),
+				Mode:     0644,
+				Contents: []byte(unit.Contents),
+			})
+		}
+	}
+
+	// Until the remediation is fully applied, the state also records what
+	// it no longer configures, so that it's restored if applying fails
+	newState := &appliedNodeRemediation{}
+	for _, f := range files {
+		applied, ok := trackedFiles[f.Path]
+		if !ok {
+			if applied, err = a.backupFile(name, f.Path); err != nil {
+				return err
+			}
+		}
+		delete(trackedFiles, f.Path)
+		newState.Files = append(newState.Files, applied)
+	}
+	for _, unit := range nodeRem.Units {
+		applied, ok := trackedUnits[unit.Name]
+		if !ok {
+			applied.PreviousState = a.unitState(unit.Name)
+		}
+		applied.NodeRemediationUnit = unit
+		delete(trackedUnits, unit.Name)
+		newState.Units = append(newState.Units, applied)
+	}
+	removed := &appliedNodeRemediation{}
+	for _, f := range trackedFiles {
+		removed.Files = append(removed.Files, f)
+	}
+	for _, unit := range trackedUnits {
+		removed.Units = append(removed.Units, unit)
+	}
+	newState.Files = append(newState.Files, removed.Files...)
+	newState.Units = append(newState.Units, removed.Units...)
+	if err := a.saveState(name, newState); err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if err := writeHostFile(a.hostPath(f.Path), f.Contents, os.FileMode(f.Mode)); err != nil {
+			return err
+		}
+	}
+	// What the remediation no longer configures is restored
+	if err := a.restoreUnits(removed.Units, false); err != nil {
+		return err
+	}
+	for _, f := range removed.Files {
+		if err := a.restoreFile(name, f); err != nil {
+			return err
+		}
+	}
+	if _, err := a.systemctl("daemon-reload"); err != nil {
+		return fmt.Errorf("cannot reload systemd: %w", err)
+	}
+	if err := a.restoreUnits(removed.Units, true); err != nil {
+		return err
+	}
+
+	for _, unit := range nodeRem.Units {
+		if err := a.setUnitState(unit); err != nil {
+			return err
+		}
+	}
+	newState.Files = newState.Files[:len(files)]
+	newState.Units = newState.Units[:len(nodeRem.Units)]
+	newState.Checksum = checksum
+	return a.saveState(name, newState)
+}
+
+// restore puts back the files a remediation overwrote, removes the ones it
+// created and reverts the state of its units
+func (a *nodeRemediationAgent) restore(name string) error {
+	state, err := a.loadState(name)
+	if err != nil {
+		return err
+	}
+	LOG("Restoring the node from node remediation %s", name)
+
+	if err := a.restoreUnits(state.Units, false); err != nil {
+		return err
+	}
+	for _, f := range state.Files {
+		if err := a.restoreFile(name, f); err != nil {
+			return err
+		}
+	}
+	if _, err := a.systemctl("daemon-reload"); err != nil {
+		return fmt.Errorf("cannot reload systemd: %w", err)
+	}
+	if err := a.restoreUnits(state.Units, true); err != nil {
+		return err
+	}
+	return os.RemoveAll(a.stateDir(name))
+}
+
+// unitState returns the state of a unit as given by systemctl is-enabled,
+// which exits with an error for the units that aren't enabled
+func (a *nodeRemediationAgent) unitState(unit string) string {
+	out, _ := a.systemctl("is-enabled", unit)
+	return strings.TrimSpace(out)
+}
+
+func (a *nodeRemediationAgent) setUnitState(unit utils.NodeRemediationUnit) error {
+	var args []string
+	switch {
+	case unit.Mask:
+		args = []string{"mask", "--now", unit.Name}
+	case unit.Enabled == nil:
+		return nil
+	case *unit.Enabled:
+		args = []string{"enable", "--now", unit.Name}
+	default:
+		args = []string{"disable", "--now", unit.Name}
+	}
+	if out, err := a.systemctl(args...); err != nil {
+		return fmt.Errorf("cannot %s unit %s: %w: %s", args[0], unit.Name, err, out)
+	}
+	return nil
+}
+
+// restoreUnits reverts the state of the units in two passes. Before the
+// files are restored, the units that had no state are disabled. Once the
+// files are restored, the other units get their previous state back.
+func (a *nodeRemediationAgent) restoreUnits(units []appliedNodeRemediationUnit, filesRestored bool) error {
+	for _, unit := range units {
+		if !unit.Mask && unit.Enabled == nil {
+			continue
+		}
+		action := ""
+		switch unit.PreviousState {
+		case "enabled":
+			action = "enable"
+		case "disabled":
+			action = "disable"
+		case "masked":
+			action = "mask"
+		}
+		if (action == "") == filesRestored {
+			continue
+		}
+		if action == "" {
+			action = "disable"
+		}
+		if unit.Mask && action != "mask" {
+			if out, err := a.systemctl("unmask", unit.Name); err != nil {
+				return fmt.Errorf("cannot unmask unit %s: %w: %s", unit.Name, err, out)
+			}
+		}
+		if out, err := a.systemctl(action, "--now", unit.Name); err != nil {
+			return fmt.Errorf("cannot %s unit %s: %w: %s", action, unit.Name, err, out)
+		}
+	}
+	return nil
+}
+
+func (a *nodeRemediationAgent) backupFile(name, path string) (appliedNodeRemediationFile, error) {
+	applied := appliedNodeRemediationFile{Path: path}
+	info, err := os.Stat(a.hostPath(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return applied, nil
+	} else if err != nil {
+		return applied, err
+	}
+	contents, err := os.ReadFile(a.hostPath(path))
+	if err != nil {
+		return applied, err
+	}
+	if err := writeHostFile(a.backupPath(name, path), contents, info.Mode().Perm()); err != nil {
+		return applied, fmt.Errorf("cannot back up file %s: %w", path, err)
+	}
+	applied.Existed = true
+	return applied, nil
+}
+
+func (a *nodeRemediationAgent) restoreFile(name string, f appliedNodeRemediationFile) error {
+	if !f.Existed {
+		if err := os.Remove(a.hostPath(f.Path)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	info, err := os.Stat(a.backupPath(name, f.Path))
+	if err != nil {
+		return fmt.Errorf("cannot find the backup of file %s: %w", f.Path, err)
+	}
+	contents, err := os.ReadFile(a.backupPath(name, f.Path))
+	if err != nil {
+		return err
+	}
+	return writeHostFile(a.hostPath(f.Path), contents, info.Mode().Perm())
+}
+
+// appliedRemediations returns the names of the remediations applied to the
+// node
+func (a *nodeRemediationAgent) appliedRemediations() ([]string, error) {
+	entries, err := os.ReadDir(a.hostPath(nodeRemediationStateDir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+func (a *nodeRemediationAgent) loadState(name string) (*appliedNodeRemediation, error) {
+	state := &appliedNodeRemediation{}
+	data, err := os.ReadFile(filepath.Join(a.stateDir(name), "state.json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("cannot parse the state of node remediation %s: %w", name, err)
+	}
+	return state, nil
+}
+
+func (a *nodeRemediationAgent) saveState(name string, state *appliedNodeRemediation) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return writeHostFile(filepath.Join(a.stateDir(name), "state.json"), data, 0600)
+}
+
+func (a *nodeRemediationAgent) hostPath(path string) string {
+	return filepath.Join(a.hostRoot, path)
+}
+
+func (a *nodeRemediationAgent) stateDir(name string) string {
+	return a.hostPath(filepath.Join(nodeRemediationStateDir, name))
+}
+
+func (a *nodeRemediationAgent) backupPath(name, path string) string {
+	return filepath.Join(a.stateDir(name), "backup", path)
+}
+
+// writeHostFile atomically replaces a file
+func writeHostFile(path string, contents []byte, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(contents); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,287 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+	igntypes "github.com/coreos/ignition/v2/config/v3_2/types"
+)
+
+var _ = Describe("Testing the node remediation agent", func() {
+	var (
+		hostRoot   string
+		agent      *nodeRemediationAgent
+		systemctl  []string
+		unitStates map[string]string
+		nodeLabels = labels.Set{"node-role.kubernetes.io/worker": ""}
+	)
+
+	newRemediationCM := func(name string, nodeRem *utils.NodeRemediation) corev1.ConfigMap {
+		data, err := json.Marshal(nodeRem)
+		Expect(err).To(BeNil())
+		return corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Data:       map[string]string{utils.NodeRemediationDataKey: string(data)},
+		}
+	}
+	readHostFile := func(path string) string {
+		contents, err := os.ReadFile(filepath.Join(hostRoot, path))
+		Expect(err).To(BeNil())
+		return string(contents)
+	}
+	writeHostFile := func(path, contents string) {
+		Expect(os.MkdirAll(filepath.Dir(filepath.Join(hostRoot, path)), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(hostRoot, path), []byte(contents), 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		hostRoot, err = os.MkdirTemp("", "host")
+		Expect(err).To(BeNil())
+		systemctl = nil
+		unitStates = map[string]string{}
+		agent = &nodeRemediationAgent{
+			hostRoot: hostRoot,
+			systemctl: func(args ...string) (string, error) {
+				systemctl = append(systemctl, strings.Join(args, " "))
+				if args[0] == "is-enabled" {
+					return unitStates[args[1]] + "\n", nil
+				}
+				return "", nil
+			},
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(hostRoot)
+	})
+
+	It("applies the remediations selecting the node and restores the node once they're removed", func() {
+		writeHostFile("/etc/sysctl.d/existing.conf", "original")
+		unitStates["chronyd.service"] = "disabled"
+		enabled := true
+		cms := []corev1.ConfigMap{
+			newRemediationCM("75-worker-rem", &utils.NodeRemediation{
+				NodeSelector: "node-role.kubernetes.io/worker",
+				Files: []utils.NodeRemediationFile{
+					{Path: "/etc/sysctl.d/existing.conf", Mode: 0600, Contents: []byte("hardened")},
+					{Path: "/etc/sysctl.d/new.conf", Mode: 0644, Contents: []byte("new")},
+				},
+				Units: []utils.NodeRemediationUnit{
+					{Name: "chronyd.service", Enabled: &enabled},
+				},
+			}),
+			newRemediationCM("75-master-rem", &utils.NodeRemediation{
+				NodeSelector: "node-role.kubernetes.io/master",
+				Files: []utils.NodeRemediationFile{
+					{Path: "/etc/sysctl.d/master.conf", Mode: 0644, Contents: []byte("master")},
+				},
+			}),
+		}
+
+		Expect(agent.sync(nodeLabels, cms)).To(Succeed())
+		Expect(readHostFile("/etc/sysctl.d/existing.conf")).To(Equal("hardened"))
+		Expect(readHostFile("/etc/sysctl.d/new.conf")).To(Equal("new"))
+		info, err := os.Stat(filepath.Join(hostRoot, "/etc/sysctl.d/existing.conf"))
+		Expect(err).To(BeNil())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+		_, err = os.Stat(filepath.Join(hostRoot, "/etc/sysctl.d/master.conf"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+		Expect(systemctl).To(ContainElement("enable --now chronyd.service"))
+
+		By("not applying the remediations again")
+		systemctl = nil
+		Expect(agent.sync(nodeLabels, cms)).To(Succeed())
+		Expect(systemctl).To(BeEmpty())
+
+		By("restoring the node once the remediation is removed")
+		Expect(agent.sync(nodeLabels, cms[1:])).To(Succeed())
+		Expect(readHostFile("/etc/sysctl.d/existing.conf")).To(Equal("original"))
+		_, err = os.Stat(filepath.Join(hostRoot, "/etc/sysctl.d/new.conf"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+		Expect(systemctl).To(ContainElement("disable --now chronyd.service"))
+		applied, err := agent.appliedRemediations()
+		Expect(err).To(BeNil())
+		Expect(applied).To(BeEmpty())
+	})
+
+	It("restores what an updated remediation no longer configures", func() {
+		writeHostFile("/etc/sysctl.d/kept.conf", "original")
+		cm := newRemediationCM("75-rem", &utils.NodeRemediation{
+			Files: []utils.NodeRemediationFile{
+				{Path: "/etc/sysctl.d/kept.conf", Mode: 0644, Contents: []byte("v1")},
+				{Path: "/etc/sysctl.d/dropped.conf", Mode: 0644, Contents: []byte("v1")},
+			},
+			Units: []utils.NodeRemediationUnit{
+				{Name: "hardening.service", Contents: "[Service]\n", Mask: true},
+			},
+		})
+		Expect(agent.sync(nodeLabels, []corev1.ConfigMap{cm})).To(Succeed())
+		Expect(readHostFile("/etc/systemd/system/hardening.service")).To(Equal("[Service]\n"))
+		Expect(systemctl).To(ContainElement("mask --now hardening.service"))
+
+		systemctl = nil
+		cm = newRemediationCM("75-rem", &utils.NodeRemediation{
+			Files: []utils.NodeRemediationFile{
+				{Path: "/etc/sysctl.d/kept.conf", Mode: 0644, Contents: []byte("v2")},
+			},
+		})
+		Expect(agent.sync(nodeLabels, []corev1.ConfigMap{cm})).To(Succeed())
+		Expect(readHostFile("/etc/sysctl.d/kept.conf")).To(Equal("v2"))
+		_, err := os.Stat(filepath.Join(hostRoot, "/etc/sysctl.d/dropped.conf"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+		_, err = os.Stat(filepath.Join(hostRoot, "/etc/systemd/system/hardening.service"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+		Expect(systemctl).To(Equal([]string{
+			"unmask hardening.service",
+			"disable --now hardening.service",
+			"daemon-reload",
+		}))
+
+		By("restoring the original file once the remediation is removed")
+		Expect(agent.sync(nodeLabels, nil)).To(Succeed())
+		Expect(readHostFile("/etc/sysctl.d/kept.conf")).To(Equal("original"))
+	})
+
+	It("refuses to write files outside of the allowed paths", func() {
+		writeHostFile("/etc/shadow", "original")
+		cm := newRemediationCM("75-rem", &utils.NodeRemediation{
+			Files: []utils.NodeRemediationFile{
+				{Path: "/etc/sysctl.d/rem.conf", Mode: 0644, Contents: []byte("rem")},
+				{Path: "/etc/sysctl.d/../shadow", Mode: 0644, Contents: []byte("forged")},
+			},
+		})
+		Expect(agent.sync(nodeLabels, []corev1.ConfigMap{cm})).ToNot(Succeed())
+		Expect(readHostFile("/etc/shadow")).To(Equal("original"))
+		_, err := os.Stat(filepath.Join(hostRoot, "/etc/sysctl.d/rem.conf"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	Context("syncing the node remediations of the cluster", func() {
+		const namespace = "test-ns"
+		var (
+			c    client.Client
+			rem  *compv1alpha1.ComplianceRemediation
+			conf *nodeRemediationAgentConfig
+		)
+
+		newOwnedCM := func(name string, data []byte) *corev1.ConfigMap {
+			return &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+					Labels:    map[string]string{compv1alpha1.NodeRemediationLabel: ""},
+					OwnerReferences: []metav1.OwnerReference{
+						*metav1.NewControllerRef(rem, compv1alpha1.SchemeGroupVersion.WithKind("ComplianceRemediation")),
+					},
+				},
+				Data: map[string]string{utils.NodeRemediationDataKey: string(data)},
+			}
+		}
+
+		BeforeEach(func() {
+			source := "data:,hardened%0A"
+			rawIgn, err := json.Marshal(igntypes.Config{
+				Ignition: igntypes.Ignition{Version: "3.2.0"},
+				Storage: igntypes.Storage{
+					Files: []igntypes.File{
+						{
+							Node:          igntypes.Node{Path: "/etc/sysctl.d/hardened.conf"},
+							FileEmbedded1: igntypes.FileEmbedded1{Contents: igntypes.Resource{Source: &source}},
+						},
+					},
+				},
+			})
+			Expect(err).To(BeNil())
+			mc, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&mcfgv1.MachineConfig{
+				TypeMeta: metav1.TypeMeta{Kind: "MachineConfig", APIVersion: mcfgv1.SchemeGroupVersion.String()},
+				Spec:     mcfgv1.MachineConfigSpec{Config: runtime.RawExtension{Raw: rawIgn}},
+			})
+			Expect(err).To(BeNil())
+
+			rem = &compv1alpha1.ComplianceRemediation{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "workers-scan-sysctl",
+					Namespace: namespace,
+					UID:       "rem-uid",
+					Labels:    map[string]string{compv1alpha1.ComplianceScanLabel: "workers-scan"},
+				},
+				Spec: compv1alpha1.ComplianceRemediationSpec{
+					ComplianceRemediationSpecMeta: compv1alpha1.ComplianceRemediationSpecMeta{Apply: true},
+					Current: compv1alpha1.ComplianceRemediationPayload{
+						Object: &unstructured.Unstructured{Object: mc},
+					},
+				},
+			}
+			scan := &compv1alpha1.ComplianceScan{
+				ObjectMeta: metav1.ObjectMeta{Name: "workers-scan", Namespace: namespace},
+				Spec: compv1alpha1.ComplianceScanSpec{
+					NodeSelector: map[string]string{"node-role.kubernetes.io/worker": ""},
+				},
+			}
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-0", Labels: nodeLabels},
+			}
+			c = fake.NewClientBuilder().WithScheme(getScheme()).WithObjects(rem, scan, node).Build()
+			conf = &nodeRemediationAgentConfig{Namespace: namespace, NodeName: "worker-0"}
+		})
+
+		It("only applies the ConfigMaps rendered from applied remediations", func() {
+			nodeRem := &utils.NodeRemediation{
+				NodeSelector: "node-role.kubernetes.io/worker=",
+				Files: []utils.NodeRemediationFile{
+					{Path: "/etc/sysctl.d/hardened.conf", Mode: 0644, Contents: []byte("hardened\n")},
+				},
+			}
+			data, err := json.Marshal(nodeRem)
+			Expect(err).To(BeNil())
+			Expect(c.Create(context.TODO(), newOwnedCM("75-workers-scan-sysctl", data))).To(Succeed())
+
+			By("ignoring ConfigMaps no remediation controls")
+			forged := newRemediationCM("75-forged", &utils.NodeRemediation{
+				Files: []utils.NodeRemediationFile{
+					{Path: "/etc/sysctl.d/forged.conf", Mode: 0644, Contents: []byte("forged")},
+				},
+			})
+			forged.Namespace = namespace
+			forged.Labels = map[string]string{compv1alpha1.NodeRemediationLabel: ""}
+			Expect(c.Create(context.TODO(), &forged)).To(Succeed())
+
+			By("ignoring ConfigMaps that don't match their remediation")
+			nodeRem.Files[0].Path = "/etc/sysctl.d/tampered.conf"
+			data, err = json.Marshal(nodeRem)
+			Expect(err).To(BeNil())
+			Expect(c.Create(context.TODO(), newOwnedCM("75-tampered", data))).To(Succeed())
+
+			Expect(syncNodeRemediations(context.TODO(), c, agent, conf)).ToNot(Succeed())
+			Expect(readHostFile("/etc/sysctl.d/hardened.conf")).To(Equal("hardened\n"))
+			for _, path := range []string{"/etc/sysctl.d/forged.conf", "/etc/sysctl.d/tampered.conf"} {
+				_, err := os.Stat(filepath.Join(hostRoot, path))
+				Expect(os.IsNotExist(err)).To(BeTrue())
+			}
+
+			By("restoring the node once the remediation is no longer applied")
+			rem.Spec.Apply = false
+			Expect(c.Update(context.TODO(), rem)).To(Succeed())
+			Expect(syncNodeRemediations(context.TODO(), c, agent, conf)).ToNot(Succeed())
+			_, err = os.Stat(filepath.Join(hostRoot, "/etc/sysctl.d/hardened.conf"))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,263 @@
+/*
+Copyright © 2026 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package manager
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	utils "github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/xccdf"
+)
+
+const (
+	offlineScanResourcesDir = "api-resources"
+	offlineScanTailoring    = "tailoring.xml"
+	offlineScanARF          = "report-arf.xml"
+	offlineScanWarnings     = "warning_output"
+
+	// oscap exits with 2 when some of the rules failed
+	oscapFailedRulesExitCode = 2
+)
+
+var OfflineScanCmd = &cobra.Command{
+	Use:   "offline-scan",
+	Short: "Runs a platform scan against a dump of the cluster resources.",
+	Long: `Runs a platform scan against the YAML or JSON dumps of the API resources of a cluster,
+such as an extracted must-gather, instead of a live API server. The resources are laid out the
+way the api-resource-collector saves them, evaluated with oscap, and the results are rendered
+as SARIF and JUnit reports in the output directory. The command exits with 2 if some of the
+checks failed.`,
+	Run: runOfflineScan,
+}
+
+func init() {
+	defineOfflineScanFlags(OfflineScanCmd)
+}
+
+func defineOfflineScanFlags(cmd *cobra.Command) {
+	cmd.Flags().String("content", "", "The path to the OpenSCAP content file.")
+	cmd.Flags().String("tailoring", "", "The path to the OpenSCAP tailoring file.")
+	cmd.Flags().String("profile", "", "The scan profile.")
+	cmd.Flags().String("dump-dir", "", "The directory containing the YAML or JSON dumps of the API resources.")
+	cmd.Flags().String("output-dir", "", "The directory to write the resources, the raw results and the reports to.")
+	cmd.Flags().String("scan", "offline-scan", "The name of the scan the results are reported under.")
+	cmd.Flags().String("namespace", "openshift-compliance", "The namespace of the scan the results are reported under.")
+	cmd.Flags().String("oscap", "oscap", "The oscap binary to run.")
+	cmd.Flags().Bool("debug", false, "Print debug messages.")
+
+	flags := cmd.Flags()
+
+	// Add flags registered by imported packages (e.g. glog and
+	// controller-runtime)
+	flags.AddGoFlagSet(flag.CommandLine)
+}
+
+type offlineScanConfig struct {
+	Content   string
+	Tailoring string
+	Profile   string
+	DumpDir   string
+	OutputDir string
+	ScanName  string
+	Namespace string
+	Oscap     string
+}
+
+func parseOfflineScanConfig(cmd *cobra.Command) *offlineScanConfig {
+	conf := &offlineScanConfig{
+		Content:   getValidStringArg(cmd, "content"),
+		Profile:   getValidStringArg(cmd, "profile"),
+		DumpDir:   getValidStringArg(cmd, "dump-dir"),
+		OutputDir: getValidStringArg(cmd, "output-dir"),
+		ScanName:  getValidStringArg(cmd, "scan"),
+		Namespace: getValidStringArg(cmd, "namespace"),
+		Oscap:     getValidStringArg(cmd, "oscap"),
+	}
+	conf.Tailoring, _ = cmd.Flags().GetString("tailoring")
+	debugLog, _ = cmd.Flags().GetBool("debug")
+
+	logf.SetLogger(zap.New())
+
+	return conf
+}
+
+func runOfflineScan(cmd *cobra.Command, args []string) {
+	conf := parseOfflineScanConfig(cmd)
+
+	results, err := offlineScan(getScheme(), conf)
+	if err != nil {
+		cmdLog.Error(err, "Offline scan failed")
+		os.Exit(1)
+	}
+
+	if printOfflineScanResults(os.Stdout, results) {
+		os.Exit(oscapFailedRulesExitCode)
+	}
+}
+
+// offlineScan fetches the resources needed by the profile from the dump,
+// evaluates the profile against them and renders the reports
+func offlineScan(scheme *runtime.Scheme, conf *offlineScanConfig) ([]*utils.ParseResult, error) {
+	dump, err := loadResourceDump(conf.DumpDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load the resource dump: %w", err)
+	}
+	if err := os.MkdirAll(conf.OutputDir, 0700); err != nil {
+		return nil, err
+	}
+	dataRoot, err := filepath.Abs(filepath.Join(conf.OutputDir, offlineScanResourcesDir))
+	if err != nil {
+		return nil, err
+	}
+	contentPath, err := filepath.Abs(conf.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	fetcher := newDumpResourceFetcher(dump)
+	if err := fetcher.LoadSource(contentPath); err != nil {
+		return nil, fmt.Errorf("cannot load the content: %w", err)
+	}
+	var tailoring []byte
+	if conf.Tailoring != "" {
+		if err := fetcher.LoadTailoring(conf.Tailoring); err != nil {
+			return nil, fmt.Errorf("cannot load the tailoring: %w", err)
+		}
+		if tailoring, err = os.ReadFile(filepath.Clean(conf.Tailoring)); err != nil {
+			return nil, err
+		}
+	}
+	if err := fetcher.FigureResources(conf.Profile); err != nil {
+		return nil, fmt.Errorf("cannot find the resources of the profile: %w", err)
+	}
+	warnings, err := fetcher.FetchResources()
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch the resources: %w", err)
+	}
+	for _, warning := range warnings {
+		LOG("Warning: %s", warning)
+	}
+	if err := fetcher.SaveWarningsIfAny(warnings, filepath.Join(conf.OutputDir, offlineScanWarnings)); err != nil {
+		return nil, err
+	}
+	if err := fetcher.SaveResources(dataRoot); err != nil {
+		return nil, fmt.Errorf("cannot save the resources: %w", err)
+	}
+
+	// Point the checks to the saved resources
+	offlineTailoring, err := xccdf.OfflineScanToXML(conf.ScanName, contentPath, conf.Profile, string(tailoring), dataRoot)
+	if err != nil {
+		return nil, err
+	}
+	tailoringPath := filepath.Join(conf.OutputDir, offlineScanTailoring)
+	if err := os.WriteFile(tailoringPath, []byte(offlineTailoring), 0600); err != nil {
+		return nil, err
+	}
+
+	arfPath := filepath.Join(conf.OutputDir, offlineScanARF)
+	if err := runOscap(conf.Oscap, contentPath, tailoringPath, xccdf.GetOfflineScanProfileID(conf.ScanName), arfPath); err != nil {
+		return nil, err
+	}
+
+	contentFile, err := readContent(contentPath)
+	if err != nil {
+		return nil, err
+	}
+	// #nosec
+	defer contentFile.Close()
+	contentDom, err := utils.ParseContent(bufio.NewReader(contentFile))
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse the content: %w", err)
+	}
+
+	reportConf := &reportConfig{
+		Path:      conf.OutputDir,
+		ScanName:  conf.ScanName,
+		Namespace: conf.Namespace,
+	}
+	results, err := parseARFFile(scheme, reportConf, contentDom, arfPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %w", arfPath, err)
+	}
+	if err := writeReports(reportConf, conf.ScanName, results); err != nil {
+		return nil, fmt.Errorf("cannot render the reports: %w", err)
+	}
+	return results, nil
+}
+
+// runOscap evaluates the tailored profile and writes the ARF results
+func runOscap(oscap, content, tailoring, profileID, arfPath string) error {
+	// #nosec G204
+	cmd := exec.Command(oscap, "xccdf", "eval",
+		"--tailoring-file", tailoring,
+		"--profile", profileID,
+		"--results-arf", arfPath,
+		content)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	LOG("Running %s", cmd.String())
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == oscapFailedRulesExitCode {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("oscap failed: %w", err)
+	}
+	return nil
+}
+
+// printOfflineScanResults prints the status of every check and tells whether
+// some of them failed
+func printOfflineScanResults(w io.Writer, results []*utils.ParseResult) bool {
+	checks := []*compv1alpha1.ComplianceCheckResult{}
+	for _, pr := range results {
+		if pr != nil && pr.CheckResult != nil {
+			checks = append(checks, pr.CheckResult)
+		}
+	}
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].Name < checks[j].Name
+	})
+
+	failed := false
+	counts := map[compv1alpha1.ComplianceCheckStatus]int{}
+	for _, check := range checks {
+		fmt.Fprintf(w, "%-14s %s\n", check.Status, check.Name)
+		counts[check.Status]++
+		if check.Status == compv1alpha1.CheckResultFail {
+			failed = true
+		}
+	}
+	fmt.Fprintf(w, "\n%d checks: %d passed, %d failed, %d manual, %d not applicable, %d errors\n", len(checks),
+		counts[compv1alpha1.CheckResultPass], counts[compv1alpha1.CheckResultFail], counts[compv1alpha1.CheckResultManual],
+		counts[compv1alpha1.CheckResultNotApplicable], counts[compv1alpha1.CheckResultError])
+	return failed
+}
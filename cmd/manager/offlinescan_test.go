@@ -0,0 +1,241 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	utils "github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/xccdf"
+)
+
+var _ = Describe("Offline scans", func() {
+	var dumpDir, outputDir string
+
+	writeDumpFile := func(name, contents string) {
+		p := filepath.Join(dumpDir, filepath.FromSlash(name))
+		Expect(os.MkdirAll(filepath.Dir(p), 0700)).To(Succeed())
+		Expect(os.WriteFile(p, []byte(contents), 0600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		dumpDir, err = os.MkdirTemp("", "dump")
+		Expect(err).To(BeNil())
+		outputDir, err = os.MkdirTemp("", "offline-scan")
+		Expect(err).To(BeNil())
+
+		// Laid out like a must-gather
+		writeDumpFile("cluster-scoped-resources/config.openshift.io/oauths.yaml", `
+apiVersion: config.openshift.io/v1
+kind: OAuthList
+items:
+- apiVersion: config.openshift.io/v1
+  kind: OAuth
+  metadata:
+    name: cluster
+  spec:
+    tokenConfig:
+      accessTokenMaxAgeSeconds: 600
+`)
+		writeDumpFile("namespaces/openshift-kube-apiserver/openshift-kube-apiserver.yaml", `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: openshift-kube-apiserver
+`)
+		writeDumpFile("namespaces/openshift-kube-apiserver/core/configmaps.yaml", `
+apiVersion: v1
+kind: ConfigMapList
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: config
+    namespace: openshift-kube-apiserver
+  data:
+    config.yaml: '{"apiServerArguments":{}}'
+`)
+		writeDumpFile("namespaces/openshift-etcd/pods/etcd.json", `{"apiVersion":"v1","kind":"Pod","metadata":{"name":"etcd","namespace":"openshift-etcd","labels":{"app":"etcd"}}}`)
+		writeDumpFile("namespaces/openshift-etcd/pods/guard.yaml", `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: guard
+  namespace: openshift-etcd
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: installer
+  namespace: openshift-etcd
+`)
+		writeDumpFile("cluster-scoped-resources/security.openshift.io/securitycontextconstraints/restricted.yaml", `
+apiVersion: security.openshift.io/v1
+kind: SecurityContextConstraints
+metadata:
+  name: restricted
+allowPrivilegedContainer: false
+`)
+		writeDumpFile("extra/endpoints.yaml", `
+apiVersion: v1
+kind: Endpoints
+metadata:
+  name: kubernetes
+  namespace: default
+`)
+		writeDumpFile("event-filter.yaml", "not: an object\n")
+		writeDumpFile("timestamp.json", "{broken")
+		writeDumpFile("namespaces/openshift-etcd/pods/etcd/etcd/logs/current.log", "log line\n")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dumpDir)
+		os.RemoveAll(outputDir)
+	})
+
+	Context("Loading the dump", func() {
+		var dump *resourceDump
+
+		BeforeEach(func() {
+			var err error
+			dump, err = loadResourceDump(dumpDir)
+			Expect(err).To(BeNil())
+		})
+
+		fetchOne := func(rpath utils.ResourcePath) ([]byte, []string) {
+			rpath.DumpPath = "dump"
+			files, warnings, err := fetch(context.TODO(), newDumpStreamerRegistry(dump).streamerFor, resourceFetcherClients{}, []utils.ResourcePath{rpath})
+			Expect(err).To(BeNil())
+			return files["dump"], warnings
+		}
+
+		It("serves the objects from their API paths", func() {
+			data, warnings := fetchOne(utils.ResourcePath{
+				ObjPath: "/apis/config.openshift.io/v1/oauths/cluster",
+				Filter:  ".spec.tokenConfig.accessTokenMaxAgeSeconds",
+			})
+			Expect(warnings).To(BeEmpty())
+			Expect(string(data)).To(Equal("600"))
+
+			obj, err := dump.get("/api/v1/namespaces/openshift-kube-apiserver")
+			Expect(err).To(BeNil())
+			Expect(obj["kind"]).To(Equal("Namespace"))
+		})
+
+		It("serves the resources whose plural isn't guessable", func() {
+			obj, err := dump.get("/apis/security.openshift.io/v1/securitycontextconstraints/restricted")
+			Expect(err).To(BeNil())
+			Expect(obj["allowPrivilegedContainer"]).To(Equal(false))
+
+			list, err := dump.get("/apis/security.openshift.io/v1/securitycontextconstraints")
+			Expect(err).To(BeNil())
+			Expect(itemNames(list)).To(Equal([]string{"restricted"}))
+
+			obj, err = dump.get("/api/v1/namespaces/default/endpoints/kubernetes")
+			Expect(err).To(BeNil())
+			Expect(obj["kind"]).To(Equal("Endpoints"))
+		})
+
+		It("serves the lists of a namespace and of all the namespaces", func() {
+			list, err := dump.get("/api/v1/namespaces/openshift-etcd/pods")
+			Expect(err).To(BeNil())
+			Expect(itemNames(list)).To(Equal([]string{"etcd", "guard", "installer"}))
+
+			list, err = dump.get("/api/v1/pods")
+			Expect(err).To(BeNil())
+			Expect(itemNames(list)).To(Equal([]string{"etcd", "guard", "installer"}))
+
+			list, err = dump.get("/api/v1/namespaces/openshift-kube-apiserver/pods")
+			Expect(err).To(BeNil())
+			Expect(itemNames(list)).To(BeEmpty())
+
+			data, _ := fetchOne(utils.ResourcePath{ObjPath: "/api/v1/pods?limit=500", LabelSelector: "app=etcd"})
+			Expect(itemNames(decodeFetched(data))).To(Equal([]string{"etcd"}))
+		})
+
+		It("reports the resources that weren't dumped like the API server", func() {
+			_, err := dump.get("/apis/config.openshift.io/v1/apiservers/cluster")
+			Expect(kerrors.IsNotFound(err)).To(BeTrue())
+			_, err = dump.get("/apis/apps/v1/namespaces/openshift-etcd/deployments")
+			Expect(kerrors.IsNotFound(err)).To(BeTrue())
+
+			data, warnings := fetchOne(utils.ResourcePath{ObjPath: "/apis/apps/v1/deployments"})
+			Expect(string(data)).To(Equal("# kube-api-error=NotFound"))
+			Expect(warnings).To(HaveLen(1))
+		})
+
+		It("refuses dumps without resources", func() {
+			emptyDir, err := os.MkdirTemp("", "empty-dump")
+			Expect(err).To(BeNil())
+			defer os.RemoveAll(emptyDir)
+			_, err = loadResourceDump(emptyDir)
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
+	It("evaluates the profile against the dump and renders the reports", func() {
+		// Stands in for oscap, checking it was given what it needs
+		arfSource, err := filepath.Abs("../../tests/data/xccdf-result.xml")
+		Expect(err).To(BeNil())
+		oscap := filepath.Join(outputDir, "oscap")
+		Expect(os.WriteFile(oscap, []byte(fmt.Sprintf(`#!/bin/sh
+while [ $# -gt 0 ]; do
+	case "$1" in
+	--tailoring-file) grep -q "%s" "$2" || exit 1 ;;
+	--profile) [ "$2" = "%s" ] || exit 1 ;;
+	--results-arf) cp "%s" "$2" ;;
+	esac
+	shift
+done
+exit 2
+`, xccdf.OCPDataRootValueID, xccdf.GetOfflineScanProfileID("dump-scan"), arfSource)), 0700)).To(Succeed())
+
+		conf := &offlineScanConfig{
+			Content:   "../../tests/data/ssg-ocp4-ds-new.xml",
+			Profile:   "xccdf_org.ssgproject.content_profile_platform-moderate",
+			DumpDir:   dumpDir,
+			OutputDir: outputDir,
+			ScanName:  "dump-scan",
+			Namespace: "openshift-compliance",
+			Oscap:     oscap,
+		}
+		results, err := offlineScan(getScheme(), conf)
+		Expect(err).To(BeNil())
+		Expect(results).ToNot(BeEmpty())
+
+		By("laying out the resources like the api-resource-collector")
+		saved, err := os.ReadFile(filepath.Join(outputDir, offlineScanResourcesDir, "apis/config.openshift.io/v1/oauths/cluster"))
+		Expect(err).To(BeNil())
+		Expect(string(saved)).To(ContainSubstring(`"accessTokenMaxAgeSeconds":600`))
+		Expect(filepath.Join(outputDir, offlineScanResourcesDir, "api/v1/namespaces/openshift-kube-apiserver/configmaps/config")).To(BeAnExistingFile())
+
+		By("recording the resources missing from the dump")
+		warnings, err := os.ReadFile(filepath.Join(outputDir, offlineScanWarnings))
+		Expect(err).To(BeNil())
+		Expect(string(warnings)).To(ContainSubstring("could not fetch /version"))
+		Expect(string(warnings)).ToNot(ContainSubstring("oauths"))
+
+		By("rendering the reports")
+		Expect(filepath.Join(outputDir, "dump-scan.sarif")).To(BeAnExistingFile())
+		Expect(filepath.Join(outputDir, "dump-scan.junit.xml")).To(BeAnExistingFile())
+
+		var out strings.Builder
+		failed := printOfflineScanResults(&out, results)
+		failing := 0
+		for _, pr := range results {
+			if pr.CheckResult != nil && pr.CheckResult.Status == compv1alpha1.CheckResultFail {
+				failing++
+			}
+		}
+		Expect(failed).To(Equal(failing > 0))
+		Expect(out.String()).To(ContainSubstring(fmt.Sprintf("%d failed", failing)))
+	})
+})
@@ -7,6 +7,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
 	goruntime "runtime"
 	"strings"
@@ -165,6 +166,9 @@ const (
 func defineOperatorFlags(cmd *cobra.Command) {
 	cmd.Flags().Bool("skip-metrics", false,
 		"Skips adding metrics.")
+	cmd.Flags().Bool("enable-webhooks", true,
+		"Serves the admission webhooks, which requires their serving certificate. "+
+			"Only disable them when their configurations aren't installed.")
 	cmd.Flags().String("platform", "OpenShift",
 		"Specifies the Platform the Compliance Operator is running on. "+
 			"This will affect the defaults created.")
@@ -265,8 +269,10 @@ func RunOperator(cmd *cobra.Command, args []string) {
 		c.NextProtos = []string{"http/1.1"}
 	}
 	webhookServerOptions := webhook.Options{
-		Port:    9443,
-		TLSOpts: []func(config *tls.Config){disableHTTP2},
+		Port:     9443,
+		CertDir:  filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs"),
+		CertName: "tls.crt",
+		TLSOpts:  []func(config *tls.Config){disableHTTP2},
 	}
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
@@ -339,6 +345,25 @@ func RunOperator(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	// The webhook configurations reject the requests they get while the
+	// webhooks aren't served, so the operator can't run without them when
+	// they're installed. The serving certificate is provided by OLM when
+	// deploying the bundle, and by the cert source of the deploy variants.
+	if enableWebhooks, _ := flags.GetBool("enable-webhooks"); enableWebhooks {
+		certFile := filepath.Join(webhookServerOptions.CertDir, webhookServerOptions.CertName)
+		if _, err := os.Stat(certFile); err != nil {
+			setupLog.Error(err, "No webhook serving certificate found, pass --enable-webhooks=false if the webhook configurations aren't installed",
+				"CertDir", webhookServerOptions.CertDir)
+			os.Exit(1)
+		}
+		if err := controller.AddWebhooksToManager(mgr); err != nil {
+			setupLog.Error(err, "unable to set up the admission webhooks")
+			os.Exit(1)
+		}
+	} else {
+		setupLog.Info("The admission webhooks are disabled")
+	}
+
 	infra := &configv1.Infrastructure{}
 	if err := kubeClient.RESTClient().Get().RequestURI("/apis/config.openshift.io/v1/infrastructures/cluster").Do(ctx).Into(infra); err != nil {
 		setupLog.Info("Couldn't get Infrastructure. This is not fatal though.")
@@ -34,6 +34,9 @@ func defineProfileParserFlags(cmd *cobra.Command) {
 	cmd.Flags().String("ds-path", "/content/ssg-ocp4-ds.xml", "Path to the datastream xml file")
 	cmd.Flags().String("name", "", "Name of the ProfileBundle object")
 	cmd.Flags().String("namespace", "", "Namespace of the ProfileBundle object")
+	cmd.Flags().String("content-sha256", "", "Expected sha256 checksum of the datastream xml file")
+	cmd.Flags().String("signature-path", "", "Path to the detached signature of the datastream xml file")
+	cmd.Flags().String("public-key-path", "", "Path to the public key to verify the signature with")
 
 	flags := cmd.Flags()
 
@@ -51,6 +54,9 @@ func newParserConfig(cmd *cobra.Command) *profileparser.ParserConfig {
 	pcfg.DataStreamPath = getValidStringArg(cmd, "ds-path")
 	pcfg.ProfileBundleKey.Name = getValidStringArg(cmd, "name")
 	pcfg.ProfileBundleKey.Namespace = getValidStringArg(cmd, "namespace")
+	pcfg.ContentSHA256, _ = flags.GetString("content-sha256")
+	pcfg.SignaturePath, _ = flags.GetString("signature-path")
+	pcfg.PublicKeyPath, _ = flags.GetString("public-key-path")
 
 	logf.SetLogger(zap.New())
 
@@ -123,6 +129,12 @@ func runProfileParser(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	if err := profileparser.VerifyContent(pcfg); err != nil {
+		cmdLog.Error(err, "Couldn't verify the content")
+		updateProfileBundleStatus(pcfg, pb, fmt.Errorf("Couldn't verify the content file: %s", err))
+		os.Exit(1)
+	}
+
 	contentFile, err := readContent(pcfg.DataStreamPath)
 	if err != nil {
 		cmdLog.Error(err, "Couldn't read the content")
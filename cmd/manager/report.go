@@ -0,0 +1,190 @@
+package manager
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/dsnet/compress/bzip2"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	utils "github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+const (
+	arfExtension           = ".xml"
+	arfCompressedExtension = ".xml.bzip2"
+	sarifReportExtension   = ".sarif"
+	junitReportExtension   = ".junit.xml"
+	bzip2Magic             = "BZh"
+)
+
+var ReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Renders raw scan results as SARIF and JUnit reports.",
+	Long: `Renders the raw ARF results stored by the resultserver as SARIF 2.1.0 and JUnit XML reports.
+The reports are written next to the ARF files they were generated from.`,
+	Run: runReport,
+}
+
+func init() {
+	defineReportFlags(ReportCmd)
+}
+
+func defineReportFlags(cmd *cobra.Command) {
+	cmd.Flags().String("content", "", "The path to the data stream the scan was run with")
+	cmd.Flags().String("path", "", "The directory containing the raw results of a single scan run")
+	cmd.Flags().String("scan", "", "The name of the scan the results belong to")
+	cmd.Flags().String("namespace", "openshift-compliance", "The namespace of the scan")
+
+	flags := cmd.Flags()
+
+	// Add flags registered by imported packages (e.g. glog and
+	// controller-runtime)
+	flags.AddGoFlagSet(flag.CommandLine)
+}
+
+type reportConfig struct {
+	Content   string
+	Path      string
+	ScanName  string
+	Namespace string
+}
+
+func parseReportConfig(cmd *cobra.Command) *reportConfig {
+	conf := &reportConfig{
+		Content:   getValidStringArg(cmd, "content"),
+		Path:      getValidStringArg(cmd, "path"),
+		ScanName:  getValidStringArg(cmd, "scan"),
+		Namespace: getValidStringArg(cmd, "namespace"),
+	}
+
+	logf.SetLogger(zap.New())
+
+	return conf
+}
+
+func runReport(cmd *cobra.Command, args []string) {
+	conf := parseReportConfig(cmd)
+
+	contentFile, err := readContent(conf.Content)
+	if err != nil {
+		cmdLog.Error(err, "Cannot read the content")
+		os.Exit(1)
+	}
+	// #nosec
+	defer contentFile.Close()
+	contentDom, err := utils.ParseContent(bufio.NewReader(contentFile))
+	if err != nil {
+		cmdLog.Error(err, "Cannot parse the content")
+		os.Exit(1)
+	}
+
+	if err := generateReports(getScheme(), conf, contentDom); err != nil {
+		cmdLog.Error(err, "Cannot generate the reports")
+		os.Exit(1)
+	}
+}
+
+// generateReports renders a SARIF and a JUnit report for every ARF file found
+// in the configured path
+func generateReports(scheme *runtime.Scheme, conf *reportConfig, content *xmlquery.Node) error {
+	entries, err := os.ReadDir(conf.Path)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		source, ok := arfSourceName(entry.Name())
+		if !ok {
+			continue
+		}
+
+		arfPath := filepath.Join(conf.Path, entry.Name())
+		results, err := parseARFFile(scheme, conf, content, arfPath)
+		if err != nil {
+			return fmt.Errorf("cannot parse %s: %w", arfPath, err)
+		}
+
+		if err := writeReports(conf, source, results); err != nil {
+			return fmt.Errorf("cannot render the reports for %s: %w", arfPath, err)
+		}
+		cmdLog.Info("Generated reports", "ARF", arfPath, "results", len(results))
+	}
+	return nil
+}
+
+// writeReports renders the results of a source as SARIF and JUnit reports in
+// the configured path
+func writeReports(conf *reportConfig, source string, results []*utils.ParseResult) error {
+	sarif, err := utils.ParseResultsToSARIF(conf.ScanName, source, results)
+	if err != nil {
+		return fmt.Errorf("cannot render SARIF report: %w", err)
+	}
+	if err := writeReport(filepath.Join(conf.Path, source+sarifReportExtension), sarif); err != nil {
+		return err
+	}
+
+	junit, err := utils.ParseResultsToJUnit(conf.ScanName, source, results)
+	if err != nil {
+		return fmt.Errorf("cannot render JUnit report: %w", err)
+	}
+	return writeReport(filepath.Join(conf.Path, source+junitReportExtension), junit)
+}
+
+// arfSourceName returns the name the resultserver stored the ARF file under,
+// which identifies the node or platform the results come from. Files that
+// aren't ARF results, including the reports themselves, are skipped.
+func arfSourceName(fileName string) (string, bool) {
+	if strings.HasSuffix(fileName, junitReportExtension) {
+		return "", false
+	}
+	if strings.HasSuffix(fileName, arfCompressedExtension) {
+		return strings.TrimSuffix(fileName, arfCompressedExtension), true
+	}
+	if strings.HasSuffix(fileName, arfExtension) {
+		return strings.TrimSuffix(fileName, arfExtension), true
+	}
+	return "", false
+}
+
+func parseARFFile(scheme *runtime.Scheme, conf *reportConfig, content *xmlquery.Node, arfPath string) ([]*utils.ParseResult, error) {
+	f, err := readContent(arfPath)
+	if err != nil {
+		return nil, err
+	}
+	// #nosec
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	var arfReader io.Reader = br
+	// Tell compressed results by their magic, not all copies keep the extension
+	if magic, _ := br.Peek(len(bzip2Magic)); string(magic) == bzip2Magic {
+		bz, err := bzip2.NewReader(arfReader, &bzip2.ReaderConfig{})
+		if err != nil {
+			return nil, err
+		}
+		defer bz.Close()
+		arfReader = bz
+	}
+
+	return utils.ParseResultsFromContentAndXccdf(scheme, conf.ScanName, conf.Namespace, content, arfReader, []string{})
+}
+
+func writeReport(reportPath string, data []byte) error {
+	if err := os.WriteFile(filepath.Clean(reportPath), data, 0600); err != nil {
+		return fmt.Errorf("cannot write report %s: %w", reportPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package manager
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	utils "github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+var _ = Describe("Report generation", func() {
+	var resultDir string
+
+	BeforeEach(func() {
+		var err error
+		resultDir, err = os.MkdirTemp("", "report")
+		Expect(err).To(BeNil())
+
+		src, err := os.Open("../../tests/data/xccdf-result.xml")
+		Expect(err).To(BeNil())
+		defer src.Close()
+		dst, err := os.Create(filepath.Join(resultDir, "test-scan-node-1-pod.xml"))
+		Expect(err).To(BeNil())
+		defer dst.Close()
+		_, err = io.Copy(dst, src)
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(resultDir)
+	})
+
+	It("Writes the reports next to the raw results", func() {
+		ds, err := os.Open("../../tests/data/ds-input.xml")
+		Expect(err).To(BeNil())
+		defer ds.Close()
+		content, err := utils.ParseContent(ds)
+		Expect(err).To(BeNil())
+
+		conf := &reportConfig{
+			Path:      resultDir,
+			ScanName:  "test-scan",
+			Namespace: "openshift-compliance",
+		}
+		Expect(generateReports(getScheme(), conf, content)).To(Succeed())
+		Expect(filepath.Join(resultDir, "test-scan-node-1-pod.sarif")).To(BeAnExistingFile())
+		Expect(filepath.Join(resultDir, "test-scan-node-1-pod.junit.xml")).To(BeAnExistingFile())
+
+		// Running again must not pick up the generated reports as results
+		Expect(generateReports(getScheme(), conf, content)).To(Succeed())
+		entries, err := os.ReadDir(resultDir)
+		Expect(err).To(BeNil())
+		Expect(entries).To(HaveLen(3))
+	})
+
+	It("Recognizes the raw result file names", func() {
+		source, ok := arfSourceName("scan-node-pod.xml.bzip2")
+		Expect(ok).To(BeTrue())
+		Expect(source).To(Equal("scan-node-pod"))
+		_, ok = arfSourceName("scan-node-pod.junit.xml")
+		Expect(ok).To(BeFalse())
+		_, ok = arfSourceName("scan-node-pod.sarif")
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -0,0 +1,251 @@
+/*
+Copyright © 2026 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package manager
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+// resourceDump indexes the API resources found in a cluster dump, such as an
+// extracted must-gather, by the API paths they would be served from
+type resourceDump struct {
+	// API path of an object -> the object
+	objects map[string]map[string]interface{}
+	// API path of a collection -> the API paths of its objects
+	lists map[string][]string
+}
+
+// loadResourceDump reads all the YAML and JSON files under root. The files
+// may hold several objects or lists of objects, however they are laid out.
+// Files that don't hold API objects, such as the other files of a
+// must-gather, are skipped.
+func loadResourceDump(root string) (*resourceDump, error) {
+	dump := &resourceDump{
+		objects: map[string]map[string]interface{}{},
+		lists:   map[string][]string{},
+	}
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(p)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+		if err := dump.addFile(p); err != nil {
+			LOG("Skipping %s: %v", p, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(dump.objects) == 0 {
+		return nil, fmt.Errorf("no API resources found in %s", root)
+	}
+	return dump, nil
+}
+
+func (d *resourceDump) addFile(p string) error {
+	f, err := readContent(p)
+	if err != nil {
+		return err
+	}
+	// #nosec
+	defer f.Close()
+
+	dec := k8syaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		obj := map[string]interface{}{}
+		if err := dec.Decode(&obj); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if items, ok := obj["items"].([]interface{}); ok && strings.HasSuffix(fmt.Sprint(obj["kind"]), "List") {
+			for _, item := range items {
+				if itemObj, ok := item.(map[string]interface{}); ok {
+					d.add(itemObj, p)
+				}
+			}
+			continue
+		}
+		d.add(obj, p)
+	}
+}
+
+// add indexes an object read from the given file
+func (d *resourceDump) add(obj map[string]interface{}, file string) {
+	u := &unstructured.Unstructured{Object: obj}
+	gvk := u.GroupVersionKind()
+	if gvk.Kind == "" || gvk.Version == "" || u.GetName() == "" {
+		return
+	}
+	resource := resourceName(gvk, u.GetName(), file)
+
+	prefix := "/api/" + gvk.Version
+	if gvk.Group != "" {
+		prefix = "/apis/" + gvk.Group + "/" + gvk.Version
+	}
+	listPaths := []string{prefix + "/" + resource}
+	if ns := u.GetNamespace(); ns != "" {
+		listPaths = append(listPaths, prefix+"/namespaces/"+ns+"/"+resource)
+	}
+
+	objPath := listPaths[len(listPaths)-1] + "/" + u.GetName()
+	if _, seen := d.objects[objPath]; !seen {
+		for _, listPath := range listPaths {
+			d.lists[listPath] = append(d.lists[listPath], objPath)
+		}
+	}
+	d.objects[objPath] = obj
+}
+
+// irregularResources maps the kinds whose resource can't be guessed from the
+// kind to their resource
+var irregularResources = map[string]string{
+	"Endpoints":                  "endpoints",
+	"SecurityContextConstraints": "securitycontextconstraints",
+}
+
+// resourceName returns the resource an object is served as. Must-gathers
+// lay out the objects by group and resource, either as
+// <group>/<resource>/<name>.yaml or as <group>/<resource>.yaml for lists,
+// with "core" standing for the core group. The resource is taken from the
+// path of the file when it follows that layout and guessed from the kind
+// otherwise.
+func resourceName(gvk schema.GroupVersionKind, name, file string) string {
+	dir := filepath.Dir(file)
+	base := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	resource, groupDir := base, filepath.Base(dir)
+	if base == name {
+		resource, groupDir = filepath.Base(dir), filepath.Base(filepath.Dir(dir))
+	}
+	group := gvk.Group
+	if group == "" {
+		group = "core"
+	}
+	if groupDir == group {
+		return resource
+	}
+
+	if resource, ok := irregularResources[gvk.Kind]; ok {
+		return resource
+	}
+	plural, _ := meta.UnsafeGuessKindToResource(gvk)
+	return plural.Resource
+}
+
+// get returns the object or the list served from an API path
+func (d *resourceDump) get(apiPath string) (map[string]interface{}, error) {
+	if obj, ok := d.objects[apiPath]; ok {
+		return obj, nil
+	}
+	if !isListPath(apiPath) {
+		return nil, kerrors.NewNotFound(schema.GroupResource{Resource: "dump"}, apiPath)
+	}
+
+	objPaths, ok := d.lists[apiPath]
+	// A namespace without objects of a resource that was dumped
+	if !ok && len(d.lists[clusterWideListPath(apiPath)]) == 0 {
+		return nil, kerrors.NewNotFound(schema.GroupResource{Resource: "dump"}, apiPath)
+	}
+	sorted := append([]string{}, objPaths...)
+	sort.Strings(sorted)
+	items := make([]interface{}, 0, len(sorted))
+	for _, objPath := range sorted {
+		items = append(items, d.objects[objPath])
+	}
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"metadata":   map[string]interface{}{},
+		"items":      items,
+	}, nil
+}
+
+// clusterWideListPath returns the path listing a resource in all the
+// namespaces, e.g. /api/v1/pods for /api/v1/namespaces/default/pods
+func clusterWideListPath(p string) string {
+	segments := strings.Split(strings.Trim(p, "/"), "/")
+	prefixLen := 2
+	if segments[0] == "apis" {
+		prefixLen = 3
+	}
+	if len(segments) != prefixLen+3 || segments[prefixLen] != "namespaces" {
+		return p
+	}
+	return "/" + strings.Join(append(segments[:prefixLen:prefixLen], segments[prefixLen+2]), "/")
+}
+
+// dumpStreamer implements resourceStreamer for serving the resources from a
+// cluster dump. The selectors of the resource path are evaluated against the
+// listed objects.
+type dumpStreamer struct {
+	dump  *resourceDump
+	rpath utils.ResourcePath
+}
+
+func (ds *dumpStreamer) Stream(_ context.Context, _ resourceFetcherClients) (io.ReadCloser, error) {
+	u, err := url.Parse(ds.rpath.ObjPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse URI %s: %w", ds.rpath.ObjPath, err)
+	}
+	obj, err := ds.dump.get(path.Clean("/" + u.Path))
+	if err != nil {
+		return nil, err
+	}
+	if obj, err = selectResources(obj, ds.rpath); err != nil {
+		return nil, err
+	}
+	return encodeJSONObject(obj)
+}
+
+// newDumpStreamerRegistry returns the registry serving the resources from a
+// cluster dump
+func newDumpStreamerRegistry(dump *resourceDump) *streamerRegistry {
+	return &streamerRegistry{fallback: func(rpath utils.ResourcePath) resourceStreamer {
+		return &dumpStreamer{dump: dump, rpath: rpath}
+	}}
+}
+
+// newDumpResourceFetcher returns a ResourceFetcher fetching the resources
+// from a cluster dump
+func newDumpResourceFetcher(dump *resourceDump) ResourceFetcher {
+	return &scapContentDataStream{
+		streamers: newDumpStreamerRegistry(dump).streamerFor,
+	}
+}
@@ -0,0 +1,129 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	backoff "github.com/cenkalti/backoff/v4"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+// resultDriftTracker compares the results of the current scan run with the
+// ComplianceCheckResults left over from the previous run, before they get
+// overwritten
+type resultDriftTracker struct {
+	scan     *compv1alpha1.ComplianceScan
+	previous map[string]compv1alpha1.ComplianceCheckStatus
+	drift    *compv1alpha1.ComplianceScanResultDrift
+	// The checks that regressed, so that an event can be raised for each
+	regressions []checkRegression
+}
+
+type checkRegression struct {
+	result   *compv1alpha1.ComplianceCheckResult
+	previous compv1alpha1.ComplianceCheckStatus
+}
+
+// newResultDriftTracker records the status of the existing check results.
+// Results that were already updated by this scan run, e.g. by an aggregator
+// that got restarted, can't be compared and are left out.
+func newResultDriftTracker(scan *compv1alpha1.ComplianceScan, existing []compv1alpha1.ComplianceCheckResult) *resultDriftTracker {
+	t := &resultDriftTracker{
+		scan:     scan,
+		previous: map[string]compv1alpha1.ComplianceCheckStatus{},
+		drift: &compv1alpha1.ComplianceScanResultDrift{
+			ScanIndex: scan.Status.CurrentIndex,
+		},
+	}
+	current := ""
+	if scan.Status.StartTimestamp != nil {
+		current = scan.Status.StartTimestamp.Format(time.RFC3339)
+	}
+	for i := range existing {
+		res := &existing[i]
+		scanned := res.Annotations[compv1alpha1.LastScannedTimestampAnnotation]
+		if scanned == current {
+			continue
+		}
+		t.previous[res.Name] = res.Status
+		if ts, err := time.Parse(time.RFC3339, scanned); err == nil {
+			if t.drift.PreviousScanTimestamp == nil || ts.After(t.drift.PreviousScanTimestamp.Time) {
+				t.drift.PreviousScanTimestamp = &metav1.Time{Time: ts}
+			}
+		}
+	}
+	return t
+}
+
+// add compares the new result of a check with its previous one
+func (t *resultDriftTracker) add(res *compv1alpha1.ComplianceCheckResult) {
+	prev, ok := t.previous[res.Name]
+	if !ok || prev == res.Status {
+		return
+	}
+	switch res.Status {
+	case compv1alpha1.CheckResultFail:
+		t.drift.NewlyFailing = append(t.drift.NewlyFailing, res.Name)
+	case compv1alpha1.CheckResultPass:
+		t.drift.NewlyPassing = append(t.drift.NewlyPassing, res.Name)
+		return
+	case compv1alpha1.CheckResultInconsistent:
+		t.drift.NewlyInconsistent = append(t.drift.NewlyInconsistent, res.Name)
+	default:
+		return
+	}
+	t.regressions = append(t.regressions, checkRegression{result: res, previous: prev})
+}
+
+// getDrift returns the summary of the changes, sorted for a stable status
+func (t *resultDriftTracker) getDrift() *compv1alpha1.ComplianceScanResultDrift {
+	sort.Strings(t.drift.NewlyFailing)
+	sort.Strings(t.drift.NewlyPassing)
+	sort.Strings(t.drift.NewlyInconsistent)
+	return t.drift
+}
+
+// recordResultDrift stores the drift in the scan's status and raises a
+// warning event for every check that regressed, as well as a summary event
+// on the scan. Errors are only logged since the results themselves were
+// stored successfully.
+func recordResultDrift(crClient aggregatorCrClient, t *resultDriftTracker) {
+	scan := t.scan
+	if scan.Status.ResultDrift != nil && scan.Status.ResultDrift.ScanIndex == scan.Status.CurrentIndex {
+		cmdLog.Info("Result drift was already recorded for this scan run", "ComplianceScan.Name", scan.Name)
+		return
+	}
+	drift := t.getDrift()
+
+	for _, r := range t.regressions {
+		crClient.getRecorder().Eventf(r.result, v1.EventTypeWarning, "CheckResultRegressed",
+			"The check is now %s, it was %s in the previous scan", r.result.Status, r.previous)
+	}
+	if drift.PreviousScanTimestamp != nil {
+		eventType := v1.EventTypeNormal
+		if drift.HasRegressions() {
+			eventType = v1.EventTypeWarning
+		}
+		crClient.getRecorder().Event(scan, eventType, "ResultDrift", fmt.Sprintf(
+			"Since the previous scan, %d checks are newly failing, %d newly passing and %d newly inconsistent",
+			len(drift.NewlyFailing), len(drift.NewlyPassing), len(drift.NewlyInconsistent)))
+	}
+
+	key := getObjKey(scan.GetName(), scan.GetNamespace())
+	err := backoff.Retry(func() error {
+		found := &compv1alpha1.ComplianceScan{}
+		if err := crClient.getClient().Get(context.TODO(), key, found); err != nil {
+			return err
+		}
+		found.Status.ResultDrift = drift
+		return crClient.getClient().Status().Update(context.TODO(), found)
+	}, backoff.WithMaxRetries(backoff.NewExponentialBackOff(), maxRetries))
+	if err != nil {
+		cmdLog.Error(err, "Cannot record the result drift", "ComplianceScan.Name", scan.Name)
+	}
+}
@@ -19,24 +19,29 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
 	"flag"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"os/signal"
-	"path"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/dsnet/compress/bzip2"
 	"github.com/spf13/cobra"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	libgocrypto "github.com/openshift/library-go/pkg/crypto"
 
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
 	utils "github.com/ComplianceAsCode/compliance-operator/pkg/utils"
 )
 
@@ -63,6 +68,12 @@ func defineResultServerFlags(cmd *cobra.Command) {
 	cmd.Flags().String("tls-server-key", "", "Path to the server key")
 	cmd.Flags().String("tls-ca", "", "Path to the CA certificate")
 	cmd.Flags().Uint16("rotation", 3, "Amount of raw result directories to keep")
+	cmd.Flags().String("storage-backend", string(compv1alpha1.RawResultStorageBackendPVC), "Where to store the results, either pvc or s3")
+	cmd.Flags().String("s3-endpoint", "", "URL of the S3-compatible endpoint")
+	cmd.Flags().String("s3-bucket", "", "The bucket to upload the results to")
+	cmd.Flags().String("s3-region", "us-east-1", "The region used to sign the S3 requests")
+	cmd.Flags().String("s3-prefix", "", "The prefix of the keys of the uploaded results")
+	cmd.Flags().String("s3-ca", "", "Path to the CA certificate used to verify the S3 endpoint")
 
 	flags := cmd.Flags()
 
@@ -80,6 +91,13 @@ type resultServerConfig struct {
 	Key      string
 	CA       string
 	Rotation uint16
+	Backend  string
+	// Only used by the s3 backend
+	S3Endpoint string
+	S3Bucket   string
+	S3Region   string
+	S3Prefix   string
+	S3CA       string
 }
 
 func parseResultServerConfig(cmd *cobra.Command) *resultServerConfig {
@@ -95,6 +113,14 @@ func parseResultServerConfig(cmd *cobra.Command) *resultServerConfig {
 		Key:      getValidStringArg(cmd, "tls-server-key"),
 		CA:       getValidStringArg(cmd, "tls-ca"),
 		Rotation: rotation,
+		Backend:  getValidStringArg(cmd, "storage-backend"),
+	}
+	if conf.Backend == string(compv1alpha1.RawResultStorageBackendS3) {
+		conf.S3Endpoint = getValidStringArg(cmd, "s3-endpoint")
+		conf.S3Bucket = getValidStringArg(cmd, "s3-bucket")
+		conf.S3Region = getValidStringArg(cmd, "s3-region")
+		conf.S3Prefix = getValidStringArg(cmd, "s3-prefix")
+		conf.S3CA, _ = cmd.Flags().GetString("s3-ca")
 	}
 
 	logf.SetLogger(zap.New())
@@ -112,12 +138,9 @@ func ensureDir(path string) error {
 	return nil
 }
 
-func rotateResultDirectories(rootPath string, rotation uint16) error {
-	// If rotation is a negative number, we don't rotate
-	if rotation == 0 {
-		cmdLog.Info("Rotation policy set to '0'. No need to rotate.")
-		return nil
-	}
+// listResultDirectories returns the directories holding the results of the
+// individual scan runs, newest first
+func listResultDirectories(rootPath string) ([]utils.Directory, error) {
 	dirs := []utils.Directory{}
 	err := filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -130,7 +153,7 @@ func rotateResultDirectories(rootPath string, rotation uint16) error {
 		}
 		if strings.Contains(path, "lost+found") {
 			// Do nothing on base directory
-			cmdLog.Info("Rotation: Skipping 'lost+found' directory")
+			cmdLog.Info("Skipping 'lost+found' directory")
 			return filepath.SkipDir
 		}
 		if info.IsDir() {
@@ -139,11 +162,24 @@ func rotateResultDirectories(rootPath string, rotation uint16) error {
 		}
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].CreationTime.After(dirs[j].CreationTime) })
+	return dirs, nil
+}
+
+func rotateResultDirectories(rootPath string, rotation uint16) error {
+	// If rotation is a negative number, we don't rotate
+	if rotation == 0 {
+		cmdLog.Info("Rotation policy set to '0'. No need to rotate.")
+		return nil
+	}
+	dirs, err := listResultDirectories(rootPath)
 	if err != nil {
 		cmdLog.Error(err, "Couldn't rotate directories")
 		return err
 	}
-	sort.Slice(dirs, func(i, j int) bool { return dirs[i].CreationTime.After(dirs[j].CreationTime) })
 	var lastError error
 	// No need to rotate, we're whithin the policy
 	if len(dirs) <= int(rotation) {
@@ -159,25 +195,95 @@ func rotateResultDirectories(rootPath string, rotation uint16) error {
 	return lastError
 }
 
+// certReloader serves the certificate of the result server and the CAs its
+// clients are verified with. The operator renews the certificates before
+// they expire, so they're read again whenever the mounted files change.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+
+	mu      sync.Mutex
+	modTime time.Time
+	cert    *tls.Certificate
+	pool    *x509.CertPool
+}
+
+// reload reads the certificates again if any of the files changed since
+// they were last read
+func (l *certReloader) reload() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var modTime time.Time
+	for _, f := range []string{l.certFile, l.keyFile, l.caFile} {
+		info, err := os.Stat(f)
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+	}
+	if l.cert != nil && !modTime.After(l.modTime) {
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(l.certFile, l.keyFile)
+	if err != nil {
+		return err
+	}
+	ca, err := os.ReadFile(l.caFile)
+	if err != nil {
+		return err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return fmt.Errorf("no CA certificate found in %s", l.caFile)
+	}
+	if l.cert != nil {
+		cmdLog.Info("Reloaded the renewed certificates")
+	}
+	l.cert, l.pool, l.modTime = &cert, pool, modTime
+	return nil
+}
+
+// configForClient returns a GetConfigForClient callback serving the current
+// certificates with the given base configuration. If they can't be read
+// again, e.g. while the files are being replaced, the previous ones are
+// served.
+func (l *certReloader) configForClient(base *tls.Config) func(*tls.ClientHelloInfo) (*tls.Config, error) {
+	return func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		if err := l.reload(); err != nil {
+			cmdLog.Error(err, "Cannot reload the certificates, using the previous ones")
+		}
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		config := base.Clone()
+		config.GetConfigForClient = nil
+		config.Certificates = []tls.Certificate{*l.cert}
+		config.ClientCAs = l.pool
+		return config, nil
+	}
+}
+
 func server(c *resultServerConfig) {
 	exit := make(chan os.Signal, 1)
 	signal.Notify(exit, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 
-	err := ensureDir(c.Path)
+	store, err := newResultStore(c)
 	if err != nil {
-		cmdLog.Error(err, "Error ensuring result path: %s", c.Path)
+		cmdLog.Error(err, "Error setting up the result storage", "backend", c.Backend)
 		os.Exit(1)
 	}
 
-	rotateResultDirectories(c.BasePath, c.Rotation)
+	store.Rotate(c.Rotation)
 
-	caCert, err := os.ReadFile(c.CA)
-	if err != nil {
-		cmdLog.Error(err, "Error reading CA file")
+	certs := &certReloader{certFile: c.Cert, keyFile: c.Key, caFile: c.CA}
+	if err := certs.reload(); err != nil {
+		cmdLog.Error(err, "Error reading the certificates")
 		os.Exit(1)
 	}
-	caCertPool := x509.NewCertPool()
-	caCertPool.AppendCertsFromPEM(caCert)
 
 	tlsConfig := &tls.Config{
 		MinVersion: tls.VersionTLS12,
@@ -185,15 +291,41 @@ func server(c *resultServerConfig) {
 	}
 	// Configures TLS 1.2
 	tlsConfig = libgocrypto.SecureTLSConfig(tlsConfig)
-	tlsConfig.ClientCAs = caCertPool
 	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
-	tlsConfig.BuildNameToCertificate()
+	tlsConfig.GetConfigForClient = certs.configForClient(tlsConfig)
 	server := &http.Server{
 		Addr:      c.Address + ":" + c.Port,
 		TLSConfig: tlsConfig,
+		Handler:   newResultServerMux(c, store),
+	}
+
+	cmdLog.Info("Listening...")
+
+	go func() {
+		// The certificates come from the TLS config
+		err := server.ListenAndServeTLS("", "")
+		if err != nil && err != http.ErrServerClosed {
+			cmdLog.Error(err, "Error in result server")
+		}
+	}()
+
+	<-exit
+	cmdLog.Info("Server stopped.")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		cmdLog.Error(err, "Server shutdown failed")
 	}
 
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+	cmdLog.Info("Server exited gracefully")
+}
+
+// uploadHandler stores the results uploaded by the scan pods for the current
+// scan run
+func uploadHandler(store resultStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
 		filename := r.Header.Get("X-Report-Name")
 		if filename == "" {
 			cmdLog.Info("Rejecting. No \"X-Report-Name\" header given.")
@@ -210,44 +342,199 @@ func server(c *resultServerConfig) {
 			extraExtension = "." + extraExtension
 		}
 		// TODO(jaosorior): Check that content-type is application/xml
-		filePath := path.Join(c.Path, filename+".xml"+extraExtension)
-		cleanPath := filepath.Clean(filePath)
-		f, err := os.Create(cleanPath)
-		if err != nil {
-			cmdLog.Info("Error creating file", "file-path", cleanPath)
-			http.Error(w, "Error creating file", 500)
+		if err := store.Store(filename+".xml"+extraExtension, r.Body); err != nil {
+			http.Error(w, "Error storing file", 500)
 			return
 		}
-		// #nosec
-		defer f.Close()
+	}
+}
+
+type resultIndex struct {
+	Index             string    `json:"index"`
+	CreationTimestamp time.Time `json:"creationTimestamp"`
+	Results           []string  `json:"results"`
+}
+
+type resultFile struct {
+	Name       string `json:"name"`
+	Compressed bool   `json:"compressed"`
+	Size       int64  `json:"size"`
+}
+
+// newResultServerMux routes the uploads of the scan pods as well as the read
+// only endpoints serving the results of the stored scan runs. Both share the
+// mTLS client certificate authentication of the server. Results kept in
+// object storage are meant to be fetched from the bucket directly.
+func newResultServerMux(c *resultServerConfig, store resultStore) *http.ServeMux {
+	mux := http.NewServeMux()
+	if _, ok := store.(*fsResultStore); ok {
+		mux.HandleFunc("GET /results", listIndicesHandler(c))
+		mux.HandleFunc("GET /results/{index}", listResultsHandler(c))
+		mux.HandleFunc("GET /results/{index}/{name}", getResultHandler(c))
+	} else {
+		notServed := func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Results are stored in object storage", http.StatusNotImplemented)
+		}
+		mux.HandleFunc("GET /results", notServed)
+		mux.HandleFunc("GET /results/", notServed)
+	}
+	mux.HandleFunc("/", uploadHandler(store))
+	return mux
+}
 
-		_, err = io.Copy(f, r.Body)
+// listIndicesHandler lists the scan runs whose results are still stored, as
+// kept by the rotation policy, newest first
+func listIndicesHandler(c *resultServerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dirs, err := listResultDirectories(c.BasePath)
 		if err != nil {
-			cmdLog.Info("Error writing file", "file-path", cleanPath)
-			http.Error(w, "Error writing file", 500)
+			http.Error(w, "Error listing result directories", 500)
 			return
 		}
-		cmdLog.Info("Received file", "file-path", cleanPath)
-	})
+		indices := make([]resultIndex, 0, len(dirs))
+		for _, dir := range dirs {
+			files, err := listResultFiles(dir.Path)
+			if err != nil {
+				http.Error(w, "Error listing results", 500)
+				return
+			}
+			idx := resultIndex{
+				Index:             filepath.Base(dir.Path),
+				CreationTimestamp: dir.CreationTime.UTC(),
+				Results:           make([]string, 0, len(files)),
+			}
+			for _, f := range files {
+				idx.Results = append(idx.Results, f.Name)
+			}
+			indices = append(indices, idx)
+		}
+		writeJSON(w, indices)
+	}
+}
 
-	cmdLog.Info("Listening...")
+// listResultsHandler lists the results stored for a single scan run
+func listResultsHandler(c *resultServerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dir, ok := resultIndexPath(c, r.PathValue("index"))
+		if !ok {
+			http.Error(w, "Invalid scan index", 400)
+			return
+		}
+		files, err := listResultFiles(dir)
+		if os.IsNotExist(err) {
+			http.NotFound(w, r)
+			return
+		} else if err != nil {
+			http.Error(w, "Error listing results", 500)
+			return
+		}
+		writeJSON(w, files)
+	}
+}
 
-	go func() {
-		err := server.ListenAndServeTLS(c.Cert, c.Key)
-		if err != nil && err != http.ErrServerClosed {
-			cmdLog.Error(err, "Error in result server")
+// getResultHandler streams the ARF result of a node or platform for a scan
+// run. Compressed results are sent as stored unless the "decompress" query
+// parameter is set.
+func getResultHandler(c *resultServerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dir, ok := resultIndexPath(c, r.PathValue("index"))
+		name := r.PathValue("name")
+		if !ok || !isValidPathSegment(name) {
+			http.Error(w, "Invalid result path", 400)
+			return
+		}
+		decompress := false
+		if v := r.URL.Query().Get("decompress"); v != "" {
+			var err error
+			if decompress, err = strconv.ParseBool(v); err != nil {
+				http.Error(w, "Invalid \"decompress\" parameter", 400)
+				return
+			}
 		}
-	}()
 
-	<-exit
-	cmdLog.Info("Server stopped.")
+		compressed := true
+		f, err := os.Open(filepath.Join(dir, name+arfCompressedExtension))
+		if os.IsNotExist(err) {
+			compressed = false
+			f, err = os.Open(filepath.Join(dir, name+arfExtension))
+		}
+		if os.IsNotExist(err) {
+			http.NotFound(w, r)
+			return
+		} else if err != nil {
+			cmdLog.Info("Error opening result", "index", filepath.Base(dir), "name", name)
+			http.Error(w, "Error opening result", 500)
+			return
+		}
+		// #nosec
+		defer f.Close()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+		var body io.Reader = f
+		switch {
+		case compressed && decompress:
+			bz, err := bzip2.NewReader(f, &bzip2.ReaderConfig{})
+			if err != nil {
+				http.Error(w, "Error decompressing result", 500)
+				return
+			}
+			defer bz.Close()
+			body = bz
+			w.Header().Set("Content-Type", "application/xml")
+		case compressed:
+			w.Header().Set("Content-Type", "application/x-bzip2")
+		default:
+			w.Header().Set("Content-Type", "application/xml")
+		}
+		if _, err := io.Copy(w, body); err != nil {
+			cmdLog.Info("Error sending result", "index", filepath.Base(dir), "name", name, "error", err.Error())
+		}
+	}
+}
 
-	if err := server.Shutdown(ctx); err != nil {
-		cmdLog.Error(err, "Server shutdown failed")
+// listResultFiles returns the ARF results stored in a scan run directory
+func listResultFiles(dir string) ([]resultFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := []resultFile{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name, ok := arfSourceName(entry.Name())
+		if !ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, resultFile{
+			Name:       name,
+			Compressed: strings.HasSuffix(entry.Name(), arfCompressedExtension),
+			Size:       info.Size(),
+		})
 	}
+	return files, nil
+}
 
-	cmdLog.Info("Server exited gracefully")
+// resultIndexPath returns the directory of a scan run, making sure the index
+// can't be used to reach outside of the result directories
+func resultIndexPath(c *resultServerConfig, index string) (string, bool) {
+	if !isValidPathSegment(index) || strings.Contains(index, "lost+found") {
+		return "", false
+	}
+	return filepath.Join(c.BasePath, index), true
+}
+
+func isValidPathSegment(s string) bool {
+	return s != "" && s != "." && s != ".." && filepath.Base(s) == s
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		cmdLog.Info("Error encoding response", "error", err.Error())
+	}
 }
@@ -16,15 +16,23 @@ limitations under the License.
 package manager
 
 import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	goruntime "runtime"
 	"strings"
 	"time"
 
+	"github.com/dsnet/compress/bzip2"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
 )
 
 func _readDirNames(path string) []string {
@@ -176,4 +184,181 @@ var _ = Describe("Resultserver testing", func() {
 			})
 		}
 	})
+
+	Context("Serving stored results", func() {
+		const arf = "<arf:asset-report-collection/>"
+		var rootDir string
+		var mux *http.ServeMux
+
+		get := func(url string) *httptest.ResponseRecorder {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+			return rec
+		}
+
+		BeforeEach(func() {
+			var err error
+			rootDir, err = os.MkdirTemp("", "results-root")
+			Expect(err).To(BeNil())
+			Expect(os.Mkdir(path.Join(rootDir, "lost+found"), 0750)).To(Succeed())
+
+			Expect(os.Mkdir(path.Join(rootDir, "0"), 0750)).To(Succeed())
+			Expect(os.WriteFile(path.Join(rootDir, "0", "node-a-pod.xml"), []byte(arf), 0600)).To(Succeed())
+			Expect(os.WriteFile(path.Join(rootDir, "0", "node-a-pod.sarif"), []byte("{}"), 0600)).To(Succeed())
+
+			// Ensure next directory will have significant time difference
+			time.Sleep(100 * time.Millisecond)
+			Expect(os.Mkdir(path.Join(rootDir, "1"), 0750)).To(Succeed())
+			var buf bytes.Buffer
+			w, err := bzip2.NewWriter(&buf, &bzip2.WriterConfig{})
+			Expect(err).To(BeNil())
+			_, err = w.Write([]byte(arf))
+			Expect(err).To(BeNil())
+			Expect(w.Close()).To(Succeed())
+			Expect(os.WriteFile(path.Join(rootDir, "1", "node-a-pod.xml.bzip2"), buf.Bytes(), 0600)).To(Succeed())
+
+			c := &resultServerConfig{
+				BasePath: rootDir,
+				Path:     path.Join(rootDir, "1"),
+				Backend:  "pvc",
+			}
+			store, err := newResultStore(c)
+			Expect(err).To(BeNil())
+			mux = newResultServerMux(c, store)
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(rootDir)
+		})
+
+		It("Lists the stored scan indices newest first", func() {
+			rec := get("/results")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Header().Get("Content-Type")).To(Equal("application/json"))
+
+			var indices []resultIndex
+			Expect(json.Unmarshal(rec.Body.Bytes(), &indices)).To(Succeed())
+			Expect(indices).To(HaveLen(2))
+			Expect(indices[0].Index).To(Equal("1"))
+			Expect(indices[0].Results).To(Equal([]string{"node-a-pod"}))
+			Expect(indices[1].Index).To(Equal("0"))
+			Expect(indices[1].Results).To(Equal([]string{"node-a-pod"}))
+		})
+
+		It("Lists the results of a scan index", func() {
+			rec := get("/results/1")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+
+			var files []resultFile
+			Expect(json.Unmarshal(rec.Body.Bytes(), &files)).To(Succeed())
+			Expect(files).To(HaveLen(1))
+			Expect(files[0].Name).To(Equal("node-a-pod"))
+			Expect(files[0].Compressed).To(BeTrue())
+
+			Expect(get("/results/5").Code).To(Equal(http.StatusNotFound))
+			Expect(get("/results/lost+found").Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("Streams uncompressed results as they're stored", func() {
+			rec := get("/results/0/node-a-pod")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Header().Get("Content-Type")).To(Equal("application/xml"))
+			Expect(rec.Body.String()).To(Equal(arf))
+		})
+
+		It("Streams compressed results as stored unless asked to decompress them", func() {
+			rec := get("/results/1/node-a-pod")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Header().Get("Content-Type")).To(Equal("application/x-bzip2"))
+			Expect(rec.Body.String()).ToNot(Equal(arf))
+
+			rec = get("/results/1/node-a-pod?decompress=true")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Header().Get("Content-Type")).To(Equal("application/xml"))
+			Expect(rec.Body.String()).To(Equal(arf))
+
+			Expect(get("/results/1/node-a-pod?decompress=maybe").Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("Rejects paths outside of the result directories", func() {
+			Expect(get("/results/1/missing").Code).To(Equal(http.StatusNotFound))
+			Expect(get("/results/..%2F..%2Fetc/passwd").Code).To(Equal(http.StatusBadRequest))
+			Expect(get("/results/1/..%2F0%2Fnode-a-pod").Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("Keeps accepting uploads", func() {
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(arf))
+			req.Header.Set("X-Report-Name", "node-b-pod")
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(path.Join(rootDir, "1", "node-b-pod.xml")).To(BeARegularFile())
+		})
+	})
+
+	Context("Certificate reloading", func() {
+		var certDir string
+		var reloader *certReloader
+
+		writeCerts := func(modTime time.Time) []byte {
+			opts := utils.CertOptions{Validity: time.Hour}
+			ca, caKey, err := utils.ComplianceOperatorRootCA("root-ca-test", opts)
+			Expect(err).To(BeNil())
+			cert, key, err := utils.NewServerCert(ca, caKey, "test-rs", opts)
+			Expect(err).To(BeNil())
+			for name, data := range map[string][]byte{"tls.crt": cert, "tls.key": key, "ca.crt": ca} {
+				f := path.Join(certDir, name)
+				Expect(os.WriteFile(f, data, 0600)).To(Succeed())
+				Expect(os.Chtimes(f, modTime, modTime)).To(Succeed())
+			}
+			return cert
+		}
+		servedCert := func() []byte {
+			base := &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert}
+			config, err := reloader.configForClient(base)(&tls.ClientHelloInfo{})
+			Expect(err).To(BeNil())
+			Expect(config.ClientAuth).To(Equal(tls.RequireAndVerifyClientCert))
+			Expect(config.ClientCAs).ToNot(BeNil())
+			Expect(config.Certificates).To(HaveLen(1))
+			return config.Certificates[0].Certificate[0]
+		}
+		leafDER := func(certPEM []byte) []byte {
+			cert, err := utils.ParseCertificatePEM(certPEM)
+			Expect(err).To(BeNil())
+			return cert.Raw
+		}
+
+		BeforeEach(func() {
+			var err error
+			certDir, err = os.MkdirTemp("", "resultserver-certs")
+			Expect(err).To(BeNil())
+			reloader = &certReloader{
+				certFile: path.Join(certDir, "tls.crt"),
+				keyFile:  path.Join(certDir, "tls.key"),
+				caFile:   path.Join(certDir, "ca.crt"),
+			}
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(certDir)
+		})
+
+		It("Serves the renewed certificates", func() {
+			first := writeCerts(time.Now().Add(-time.Hour))
+			Expect(reloader.reload()).To(Succeed())
+			Expect(servedCert()).To(Equal(leafDER(first)))
+
+			renewed := writeCerts(time.Now())
+			Expect(servedCert()).To(Equal(leafDER(renewed)))
+		})
+
+		It("Keeps serving the previous certificates if the new ones can't be read", func() {
+			first := writeCerts(time.Now().Add(-time.Hour))
+			Expect(reloader.reload()).To(Succeed())
+
+			Expect(os.WriteFile(reloader.keyFile, []byte("garbage"), 0600)).To(Succeed())
+			Expect(reloader.reload()).ToNot(Succeed())
+			Expect(servedCert()).To(Equal(leafDER(first)))
+		})
+	})
 })
@@ -0,0 +1,81 @@
+/*
+Copyright © 2026 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package manager
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+// resultStore persists the results uploaded during the current scan run
+// and rotates the results of older runs
+type resultStore interface {
+	// Store saves an uploaded result under the given file name
+	Store(fileName string, body io.Reader) error
+	// Rotate removes the results of the oldest scan runs so that at most
+	// rotation runs, including the current one, are kept. A rotation of 0
+	// keeps everything.
+	Rotate(rotation uint16) error
+}
+
+func newResultStore(c *resultServerConfig) (resultStore, error) {
+	switch compv1alpha1.RawResultStorageBackend(c.Backend) {
+	case compv1alpha1.RawResultStorageBackendPVC:
+		if err := ensureDir(c.Path); err != nil {
+			return nil, err
+		}
+		return &fsResultStore{basePath: c.BasePath, path: c.Path}, nil
+	case compv1alpha1.RawResultStorageBackendS3:
+		return newS3ResultStore(c)
+	}
+	return nil, fmt.Errorf("unknown storage backend %s", c.Backend)
+}
+
+// fsResultStore stores the results in a directory per scan run of a
+// mounted volume
+type fsResultStore struct {
+	basePath string
+	path     string
+}
+
+func (s *fsResultStore) Store(fileName string, body io.Reader) error {
+	filePath := path.Join(s.path, fileName)
+	cleanPath := filepath.Clean(filePath)
+	f, err := os.Create(cleanPath)
+	if err != nil {
+		cmdLog.Info("Error creating file", "file-path", cleanPath)
+		return err
+	}
+	// #nosec
+	defer f.Close()
+
+	_, err = io.Copy(f, body)
+	if err != nil {
+		cmdLog.Info("Error writing file", "file-path", cleanPath)
+		return err
+	}
+	cmdLog.Info("Received file", "file-path", cleanPath)
+	return nil
+}
+
+func (s *fsResultStore) Rotate(rotation uint16) error {
+	return rotateResultDirectories(s.basePath, rotation)
+}
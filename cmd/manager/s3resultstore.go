@@ -0,0 +1,308 @@
+/*
+Copyright © 2026 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package manager
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+
+	utils "github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+const (
+	s3RequestTimeout = 60 * time.Second
+	// s3UnsignedPayload lets the uploads be streamed instead of hashing
+	// the whole body before sending it
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3EmptyPayload    = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// s3ResultStore uploads the results to an S3-compatible bucket. The results
+// of a scan run are stored under <prefix>/<index>/, which plays the role of
+// the per-run directory of the filesystem store.
+type s3ResultStore struct {
+	client *s3Client
+	prefix string
+	index  string
+}
+
+func newS3ResultStore(c *resultServerConfig) (*s3ResultStore, error) {
+	creds := aws.Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return nil, fmt.Errorf("the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables must be set")
+	}
+	client, err := newS3Client(c.S3Endpoint, c.S3Bucket, c.S3Region, c.S3CA, creds)
+	if err != nil {
+		return nil, err
+	}
+	return &s3ResultStore{
+		client: client,
+		prefix: c.S3Prefix,
+		index:  filepath.Base(c.Path),
+	}, nil
+}
+
+func (s *s3ResultStore) Store(fileName string, body io.Reader) error {
+	// S3 needs to know the length of the object upfront, which the scan
+	// pods don't always send, so the upload is spooled to a temporary file
+	// rather than kept in memory. The result server mounts an emptyDir on
+	// the temporary directory for it.
+	f, err := os.CreateTemp("", "upload-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	size, err := io.Copy(f, body)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	contentType := "application/xml"
+	if strings.HasSuffix(fileName, arfCompressedExtension) {
+		contentType = "application/x-bzip2"
+	}
+	key := path.Join(s.prefix, s.index, fileName)
+	if err := s.client.putObject(key, contentType, f, size); err != nil {
+		cmdLog.Info("Error uploading object", "bucket", s.client.bucket, "key", key)
+		return err
+	}
+	cmdLog.Info("Uploaded file", "bucket", s.client.bucket, "key", key)
+	return nil
+}
+
+func (s *s3ResultStore) Rotate(rotation uint16) error {
+	// If rotation is a negative number, we don't rotate
+	if rotation == 0 {
+		cmdLog.Info("Rotation policy set to '0'. No need to rotate.")
+		return nil
+	}
+	objects, err := s.client.listObjects(s.prefix + "/")
+	if err != nil {
+		cmdLog.Error(err, "Couldn't rotate results")
+		return err
+	}
+
+	// Group the objects by scan run, which is considered as recent as its
+	// newest object. The current run may not have any results yet but is
+	// always the newest.
+	keysByIndex := map[string][]string{}
+	newest := map[string]time.Time{s.index: time.Now()}
+	for _, obj := range objects {
+		rel := strings.TrimPrefix(obj.Key, s.prefix+"/")
+		index, _, found := strings.Cut(rel, "/")
+		if !found {
+			continue
+		}
+		keysByIndex[index] = append(keysByIndex[index], obj.Key)
+		if index != s.index && obj.LastModified.After(newest[index]) {
+			newest[index] = obj.LastModified
+		}
+	}
+	runs := make([]utils.Directory, 0, len(newest))
+	for index, t := range newest {
+		runs = append(runs, utils.Directory{CreationTime: t, Path: index})
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].CreationTime.After(runs[j].CreationTime) })
+
+	// No need to rotate, we're whithin the policy
+	if len(runs) <= int(rotation) {
+		return nil
+	}
+	var lastError error
+	for _, run := range runs[rotation:] {
+		cmdLog.Info("Removing results because of rotation policy", "bucket", s.client.bucket, "index", run.Path)
+		for _, key := range keysByIndex[run.Path] {
+			if err := s.client.deleteObject(key); err != nil {
+				lastError = err
+			}
+		}
+	}
+	return lastError
+}
+
+// s3Client implements the few S3 API calls the result server needs,
+// addressing the bucket path-style so that it works with most S3-compatible
+// object stores
+type s3Client struct {
+	endpoint   *url.URL
+	bucket     string
+	region     string
+	creds      aws.Credentials
+	signer     *v4.Signer
+	httpClient *http.Client
+}
+
+type s3Object struct {
+	Key          string    `xml:"Key"`
+	LastModified time.Time `xml:"LastModified"`
+	Size         int64     `xml:"Size"`
+}
+
+type s3ListBucketResult struct {
+	Contents              []s3Object `xml:"Contents"`
+	IsTruncated           bool       `xml:"IsTruncated"`
+	NextContinuationToken string     `xml:"NextContinuationToken"`
+}
+
+func newS3Client(endpoint, bucket, region, caPath string, creds aws.Credentials) (*s3Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint %s: %w", endpoint, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid S3 endpoint %s: the scheme must be http or https", endpoint)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if caPath != "" {
+		ca, err := os.ReadFile(filepath.Clean(caPath))
+		if err != nil {
+			return nil, fmt.Errorf("cannot read the S3 endpoint CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in %s", caPath)
+		}
+		transport.TLSClientConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			RootCAs:    pool,
+		}
+	}
+
+	return &s3Client{
+		endpoint: u,
+		bucket:   bucket,
+		region:   region,
+		creds:    creds,
+		signer: v4.NewSigner(func(o *v4.SignerOptions) {
+			// S3 expects the path to be escaped only once
+			o.DisableURIPathEscaping = true
+		}),
+		httpClient: &http.Client{
+			Transport: transport,
+			Timeout:   s3RequestTimeout,
+		},
+	}, nil
+}
+
+func (c *s3Client) putObject(key, contentType string, body io.Reader, size int64) error {
+	resp, err := c.do(http.MethodPut, key, nil, body, size, contentType)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (c *s3Client) deleteObject(key string) error {
+	resp, err := c.do(http.MethodDelete, key, nil, nil, 0, "")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// listObjects returns all the objects whose key starts with prefix
+func (c *s3Client) listObjects(prefix string) ([]s3Object, error) {
+	objects := []s3Object{}
+	token := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := c.do(http.MethodGet, "", query, nil, 0, "")
+		if err != nil {
+			return nil, err
+		}
+		result := s3ListBucketResult{}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse the object list of bucket %s: %w", c.bucket, err)
+		}
+		objects = append(objects, result.Contents...)
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// do sends a signed request for the given key of the bucket. The body, if
+// any, is streamed and left out of the signature. Responses other than 2xx
+// are returned as errors.
+func (c *s3Client) do(method, key string, query url.Values, body io.Reader, size int64, contentType string) (*http.Response, error) {
+	u := *c.endpoint
+	u.Path = path.Join("/", c.endpoint.Path, c.bucket, key)
+	if key == "" {
+		u.Path += "/"
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	payloadHash := s3EmptyPayload
+	if body != nil {
+		payloadHash = s3UnsignedPayload
+		req.Header.Set("Content-Type", contentType)
+		// S3 doesn't accept chunked uploads
+		req.ContentLength = size
+	}
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	err = c.signer.SignHTTP(context.Background(), c.creds, req, payloadHash, "s3", c.region, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("cannot sign the S3 request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s %s returned %s: %s", method, u.Path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
@@ -0,0 +1,191 @@
+package manager
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fakeBucket is a minimal stand-in for an S3-compatible object store
+// serving a single path-style addressed bucket
+type fakeBucket struct {
+	sync.Mutex
+	name     string
+	pageSize int
+	objects  map[string][]byte
+	modified map[string]time.Time
+}
+
+func newFakeBucket(name string) *fakeBucket {
+	return &fakeBucket{
+		name:     name,
+		pageSize: 2,
+		objects:  map[string][]byte{},
+		modified: map[string]time.Time{},
+	}
+}
+
+func (b *fakeBucket) put(key string, data []byte, modified time.Time) {
+	b.Lock()
+	defer b.Unlock()
+	b.objects[key] = data
+	b.modified[key] = modified
+}
+
+func (b *fakeBucket) keys() []string {
+	b.Lock()
+	defer b.Unlock()
+	keys := []string{}
+	for k := range b.objects {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (b *fakeBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ") {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+		return
+	}
+	key, found := strings.CutPrefix(r.URL.Path, "/"+b.name+"/")
+	if !found {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		if r.Header.Get("X-Amz-Content-Sha256") != "UNSIGNED-PAYLOAD" || len(r.TransferEncoding) > 0 || r.ContentLength != int64(len(data)) {
+			http.Error(w, "BadDigest", http.StatusBadRequest)
+			return
+		}
+		b.put(key, data, time.Now())
+	case r.Method == http.MethodDelete:
+		b.Lock()
+		delete(b.objects, key)
+		delete(b.modified, key)
+		b.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && key == "" && r.URL.Query().Get("list-type") == "2":
+		b.list(w, r.URL.Query().Get("prefix"), r.URL.Query().Get("continuation-token"))
+	default:
+		http.Error(w, "NotImplemented", http.StatusNotImplemented)
+	}
+}
+
+func (b *fakeBucket) list(w http.ResponseWriter, prefix, token string) {
+	result := s3ListBucketResult{}
+	b.Lock()
+	keys := []string{}
+	for k := range b.objects {
+		if strings.HasPrefix(k, prefix) && k > token {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for i, k := range keys {
+		if i == b.pageSize {
+			result.IsTruncated = true
+			result.NextContinuationToken = keys[i-1]
+			break
+		}
+		result.Contents = append(result.Contents, s3Object{Key: k, LastModified: b.modified[k], Size: int64(len(b.objects[k]))})
+	}
+	b.Unlock()
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"ListBucketResult"`
+		s3ListBucketResult
+	}{s3ListBucketResult: result})
+}
+
+var testS3Credentials = aws.Credentials{AccessKeyID: "id", SecretAccessKey: "secret"}
+
+var _ = Describe("S3 result storage", func() {
+	var bucket *fakeBucket
+	var srv *httptest.Server
+	var store *s3ResultStore
+
+	BeforeEach(func() {
+		bucket = newFakeBucket("results")
+		srv = httptest.NewServer(bucket)
+		client, err := newS3Client(srv.URL, "results", "us-east-1", "", testS3Credentials)
+		Expect(err).To(BeNil())
+		store = &s3ResultStore{client: client, prefix: "cluster-a/workers-scan", index: "3"}
+	})
+
+	AfterEach(func() {
+		srv.Close()
+	})
+
+	It("Uploads the results of the current scan run", func() {
+		c := &resultServerConfig{Backend: "s3"}
+		mux := newResultServerMux(c, store)
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString("compressed"))
+		req.Header.Set("X-Report-Name", "workers-scan-node-a-pod")
+		req.Header.Set("Content-Encoding", "bzip2")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+
+		Expect(bucket.keys()).To(Equal([]string{"cluster-a/workers-scan/3/workers-scan-node-a-pod.xml.bzip2"}))
+		Expect(string(bucket.objects["cluster-a/workers-scan/3/workers-scan-node-a-pod.xml.bzip2"])).To(Equal("compressed"))
+
+		By("Not serving the results itself")
+		rec = httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/results", nil))
+		Expect(rec.Code).To(Equal(http.StatusNotImplemented))
+	})
+
+	It("Surfaces errors returned by the object store", func() {
+		client, err := newS3Client(srv.URL, "missing", "us-east-1", "", testS3Credentials)
+		Expect(err).To(BeNil())
+		store.client = client
+		err = store.Store("node-a-pod.xml", bytes.NewBufferString("<arf/>"))
+		Expect(err).To(MatchError(ContainSubstring("404")))
+	})
+
+	It("Rotates scan runs according to the rotation policy", func() {
+		now := time.Now()
+		for i, index := range []string{"0", "1", "2"} {
+			modified := now.Add(time.Duration(i-3) * time.Hour)
+			bucket.put("cluster-a/workers-scan/"+index+"/node-a-pod.xml.bzip2", []byte("a"), modified)
+			bucket.put("cluster-a/workers-scan/"+index+"/node-b-pod.xml.bzip2", []byte("b"), modified)
+		}
+		// Another scan sharing the bucket is left alone
+		bucket.put("cluster-a/masters-scan/0/node-c-pod.xml.bzip2", []byte("c"), now.Add(-5*time.Hour))
+
+		Expect(store.Rotate(0)).To(Succeed())
+		Expect(bucket.keys()).To(HaveLen(7))
+
+		Expect(store.Rotate(4)).To(Succeed())
+		Expect(bucket.keys()).To(HaveLen(7))
+
+		// The current run counts as the newest one even before it has
+		// any results
+		Expect(store.Rotate(2)).To(Succeed())
+		Expect(bucket.keys()).To(Equal([]string{
+			"cluster-a/masters-scan/0/node-c-pod.xml.bzip2",
+			"cluster-a/workers-scan/2/node-a-pod.xml.bzip2",
+			"cluster-a/workers-scan/2/node-b-pod.xml.bzip2",
+		}))
+	})
+
+	It("Rejects endpoints that aren't HTTP URLs", func() {
+		_, err := newS3Client("s3.example.com", "results", "us-east-1", "", testS3Credentials)
+		Expect(err).ToNot(BeNil())
+	})
+})
@@ -42,6 +42,8 @@ import (
 	"github.com/itchyny/gojq"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	meta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -73,6 +75,8 @@ type scapContentDataStream struct {
 	tailoring  *xmlquery.Node
 	resources  []utils.ResourcePath
 	found      map[string][]byte
+	// Picks how each resource is fetched
+	streamers streamerDispatcherFn
 }
 
 func NewDataStreamResourceFetcher(scheme *runtime.Scheme, client runtimeclient.Client, clientSet *kubernetes.Clientset) ResourceFetcher {
@@ -82,6 +86,15 @@ func NewDataStreamResourceFetcher(scheme *runtime.Scheme, client runtimeclient.C
 			client:    client,
 			scheme:    scheme,
 		},
+		streamers: getStreamerFn,
+	}
+}
+
+// NewOfflineDataStreamResourceFetcher returns a ResourceFetcher reading the
+// resources from a directory instead of the API server
+func NewOfflineDataStreamResourceFetcher(resourceDir string) ResourceFetcher {
+	return &scapContentDataStream{
+		streamers: newFileStreamerRegistry(resourceDir).streamerFor,
 	}
 }
 
@@ -203,8 +216,7 @@ func (c *scapContentDataStream) FigureResources(profile string) error {
 		effectiveProfile = c.getExtendedProfileFromTailoring(c.tailoring, profile)
 		// No profile is being extended
 		if effectiveProfile == "" {
-			c.resources = found
-			return nil
+			return c.setResources(found)
 		}
 	}
 
@@ -213,11 +225,44 @@ func (c *scapContentDataStream) FigureResources(profile string) error {
 		fmt.Printf("no valid checks found in profile\n")
 	}
 	found = append(found, selected...)
-	c.resources = found
+	return c.setResources(found)
+}
+
+func (c *scapContentDataStream) setResources(found []utils.ResourcePath) error {
+	resources, err := dedupResourcePaths(found)
+	if err != nil {
+		return err
+	}
+	c.resources = resources
 	DBG("c.resources: %v\n", c.resources)
 	return nil
 }
 
+// dedupResourcePaths removes the resource paths saved to the same dump path
+// more than once. As each fetch overwrites the previous one saved to the
+// same path, fetching something else to a dump path in use is an error.
+func dedupResourcePaths(paths []utils.ResourcePath) ([]utils.ResourcePath, error) {
+	out := []utils.ResourcePath{}
+	seen := map[string]int{}
+	for _, rpath := range paths {
+		i, ok := seen[rpath.DumpPath]
+		if !ok {
+			seen[rpath.DumpPath] = len(out)
+			out = append(out, rpath)
+			continue
+		}
+		prev := out[i]
+		if prev.ObjPath != rpath.ObjPath || prev.Filter != rpath.Filter || prev.LabelSelector != rpath.LabelSelector ||
+			prev.FieldSelector != rpath.FieldSelector || prev.MetadataOnly != rpath.MetadataOnly {
+			return nil, fmt.Errorf("%s and %s are both saved to %s with different filters or selectors",
+				prev.ObjPath, rpath.ObjPath, rpath.DumpPath)
+		}
+		// The warnings are kept unless all the rules suppress them
+		out[i].SuppressWarning = prev.SuppressWarning && rpath.SuppressWarning
+	}
+	return out, nil
+}
+
 // getPathsFromRuleWarning finds the API endpoint from in. The expected structure is:
 //
 //	<warning category="general" lang="en-US"><code class="ocp-api-endpoint">/apis/config.openshift.io/v1/oauths/cluster
@@ -364,7 +409,7 @@ func (c *scapContentDataStream) getExtendedProfileFromTailoring(ds *xmlquery.Nod
 }
 
 func (c *scapContentDataStream) FetchResources() ([]string, error) {
-	found, warnings, err := fetch(context.Background(), getStreamerFn, c.resourceFetcherClients, c.resources)
+	found, warnings, err := fetch(context.Background(), c.streamers, c.resourceFetcherClients, c.resources)
 	if err != nil {
 		return warnings, err
 	}
@@ -377,31 +422,13 @@ type resourceStreamer interface {
 	Stream(ctx context.Context, rfClients resourceFetcherClients) (io.ReadCloser, error)
 }
 
-type streamerDispatcherFn func(string) resourceStreamer
-
-// getStreamerFn returns a structure implementing resourceStreamer interface based on the
-// uri passed to it
-func getStreamerFn(uri string) resourceStreamer {
-	if uri == "/apis/machineconfiguration.openshift.io/v1/machineconfigs" {
-		return &mcStreamer{}
-	}
-
-	return &uriStreamer{
-		uri: uri,
-	}
-}
-
-// uriStreamer implements resourceStreamer for fetching a generic URI
-type uriStreamer struct {
-	uri string
-}
-
-func (us *uriStreamer) Stream(ctx context.Context, rfClients resourceFetcherClients) (io.ReadCloser, error) {
-	return rfClients.clientset.RESTClient().Get().RequestURI(us.uri).Stream(ctx)
-}
+type streamerDispatcherFn func(utils.ResourcePath) resourceStreamer
 
 // mcStreamer implements resourceStreamer for fetching a list of MachineConfigs
-type mcStreamer struct{}
+type mcStreamer struct {
+	labelSelector string
+	fieldSelector string
+}
 
 // bufCloser is a kludge so that mcStreamer's Stream() method can return an io.ReadCloser
 type bufCloser struct {
@@ -420,11 +447,29 @@ func (ms *mcStreamer) Stream(ctx context.Context, rfClients resourceFetcherClien
 	mcfgListNoFiles := mcfgv1.MachineConfigList{}
 	const pageSize = 5
 
+	selectorOpts := runtimeclient.ListOptions{}
+	if ms.labelSelector != "" {
+		labelSelector, err := labels.Parse(ms.labelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid label selector %s: %w", ms.labelSelector, err)
+		}
+		selectorOpts.LabelSelector = labelSelector
+	}
+	if ms.fieldSelector != "" {
+		fieldSelector, err := fields.ParseSelector(ms.fieldSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field selector %s: %w", ms.fieldSelector, err)
+		}
+		selectorOpts.FieldSelector = fieldSelector
+	}
+
 	continueToken := ""
 	for {
 		mcfgList := mcfgv1.MachineConfigList{}
 		listOpts := runtimeclient.ListOptions{
-			Limit: int64(pageSize),
+			Limit:         int64(pageSize),
+			LabelSelector: selectorOpts.LabelSelector,
+			FieldSelector: selectorOpts.FieldSelector,
 		}
 		if continueToken != "" {
 			listOpts.Continue = continueToken
@@ -491,7 +536,7 @@ func fetch(ctx context.Context, streamDispatcher streamerDispatcherFn, rfClients
 		err := func() error {
 			uri := rpath.ObjPath
 			LOG("Fetching URI: '%s'", uri)
-			streamer := streamDispatcher(uri)
+			streamer := streamDispatcher(rpath)
 			stream, err := streamer.Stream(ctx, rfClients)
 			if meta.IsNoMatchError(err) || kerrors.IsForbidden(err) || kerrors.IsNotFound(err) {
 				DBG("Encountered non-fatal error to be persisted in the scan: %s", err)
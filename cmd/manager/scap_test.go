@@ -199,6 +199,30 @@ var _ = Describe("Testing SCAP parsing and storage", func() {
 		})
 	})
 
+	Context("Deduplicating the resource paths", func() {
+		It("fetches each dump path once", func() {
+			paths, err := dedupResourcePaths([]utils.ResourcePath{
+				{ObjPath: "/api/v1/nodes", DumpPath: "/api/v1/nodes"},
+				{ObjPath: "/api/v1/pods", DumpPath: "/api/v1/pods#a1b2", LabelSelector: "app=router", SuppressWarning: true},
+				{ObjPath: "/api/v1/nodes", DumpPath: "/api/v1/nodes", SuppressWarning: true},
+				{ObjPath: "/api/v1/pods", DumpPath: "/api/v1/pods#a1b2", LabelSelector: "app=router", SuppressWarning: true},
+			})
+			Expect(err).To(BeNil())
+			Expect(paths).To(Equal([]utils.ResourcePath{
+				{ObjPath: "/api/v1/nodes", DumpPath: "/api/v1/nodes"},
+				{ObjPath: "/api/v1/pods", DumpPath: "/api/v1/pods#a1b2", LabelSelector: "app=router", SuppressWarning: true},
+			}))
+		})
+
+		It("fails if a dump path is fetched differently", func() {
+			_, err := dedupResourcePaths([]utils.ResourcePath{
+				{ObjPath: "/api/v1/pods", DumpPath: "/api/v1/pods#a1b2", LabelSelector: "app=router"},
+				{ObjPath: "/api/v1/pods", DumpPath: "/api/v1/pods#a1b2", LabelSelector: "app=console"},
+			})
+			Expect(err).To(MatchError(ContainSubstring("/api/v1/pods#a1b2")))
+		})
+	})
+
 	Context("Parses the save path appropriately", func() {
 		It("Parses correctly with the root being '/tmp'", func() {
 			root := "/tmp"
@@ -354,7 +378,7 @@ var _ = Describe("Testing fetching", func() {
 
 	Context("handle fetch failures", func() {
 		It("fetches and stores 404s", func() {
-			fakeDispatcher := func(uri string) resourceStreamer {
+			fakeDispatcher := func(rpath utils.ResourcePath) resourceStreamer {
 				return &notFoundFetcher{}
 			}
 
@@ -373,7 +397,7 @@ var _ = Describe("Testing fetching", func() {
 
 	Context("handle fetch failures with suppressed warning", func() {
 		It("fetches and discard 404s", func() {
-			fakeDispatcher := func(uri string) resourceStreamer {
+			fakeDispatcher := func(rpath utils.ResourcePath) resourceStreamer {
 				return &notFoundFetcher{}
 			}
 
@@ -0,0 +1,428 @@
+/*
+Copyright © 2026 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package manager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+const (
+	// listPageSize is how many objects are fetched at once when listing
+	// a collection
+	listPageSize = 500
+	// listRestarts is how many times listing a collection is started over
+	// when the continue token expires before all the pages are fetched
+	listRestarts = 3
+	// machineConfigsURI is streamed by mcStreamer
+	machineConfigsURI = "/apis/machineconfiguration.openshift.io/v1/machineconfigs"
+)
+
+// streamerFactory returns the resourceStreamer fetching a resource path, or
+// nil if it doesn't handle the path
+type streamerFactory func(rpath utils.ResourcePath) resourceStreamer
+
+// streamerRegistry dispatches the resource paths to the first registered
+// factory that handles them, and to its fallback otherwise
+type streamerRegistry struct {
+	factories []streamerFactory
+	fallback  streamerFactory
+}
+
+func (r *streamerRegistry) register(f streamerFactory) {
+	r.factories = append(r.factories, f)
+}
+
+// streamerFor implements streamerDispatcherFn
+func (r *streamerRegistry) streamerFor(rpath utils.ResourcePath) resourceStreamer {
+	for _, f := range r.factories {
+		if s := f(rpath); s != nil {
+			return s
+		}
+	}
+	return r.fallback(rpath)
+}
+
+// newAPIStreamerRegistry returns the registry fetching the resources from
+// the API server
+func newAPIStreamerRegistry() *streamerRegistry {
+	r := &streamerRegistry{fallback: newURIStreamer}
+	r.register(newMCStreamer)
+	return r
+}
+
+// newFileStreamerRegistry returns the registry reading the resources from
+// the given directory, for offline scanning
+func newFileStreamerRegistry(root string) *streamerRegistry {
+	return &streamerRegistry{fallback: func(rpath utils.ResourcePath) resourceStreamer {
+		return &fileStreamer{root: root, rpath: rpath}
+	}}
+}
+
+var apiStreamers = newAPIStreamerRegistry()
+
+// getStreamerFn returns a structure implementing resourceStreamer interface based on the
+// resource path passed to it
+func getStreamerFn(rpath utils.ResourcePath) resourceStreamer {
+	return apiStreamers.streamerFor(rpath)
+}
+
+func newMCStreamer(rpath utils.ResourcePath) resourceStreamer {
+	// Without their spec, the MachineConfigs don't need to be trimmed
+	if rpath.ObjPath != machineConfigsURI || rpath.MetadataOnly {
+		return nil
+	}
+	return &mcStreamer{labelSelector: rpath.LabelSelector, fieldSelector: rpath.FieldSelector}
+}
+
+// uriStreamer implements resourceStreamer for fetching a generic URI.
+// Collections are listed page by page.
+type uriStreamer struct {
+	uri           string
+	labelSelector string
+	fieldSelector string
+	metadataOnly  bool
+}
+
+func newURIStreamer(rpath utils.ResourcePath) resourceStreamer {
+	return &uriStreamer{
+		uri:           rpath.ObjPath,
+		labelSelector: rpath.LabelSelector,
+		fieldSelector: rpath.FieldSelector,
+		metadataOnly:  rpath.MetadataOnly,
+	}
+}
+
+func (us *uriStreamer) Stream(ctx context.Context, rfClients resourceFetcherClients) (io.ReadCloser, error) {
+	u, err := url.Parse(us.uri)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse URI %s: %w", us.uri, err)
+	}
+	if !isListPath(u.Path) {
+		req := rfClients.clientset.RESTClient().Get().RequestURI(us.uri)
+		if us.metadataOnly {
+			req = req.SetHeader("Accept", metadataAcceptHeader("PartialObjectMetadata"))
+		}
+		return req.Stream(ctx)
+	}
+
+	query := u.Query()
+	if us.labelSelector != "" {
+		query.Set("labelSelector", us.labelSelector)
+	}
+	if us.fieldSelector != "" {
+		query.Set("fieldSelector", us.fieldSelector)
+	}
+	query.Set("limit", strconv.Itoa(listPageSize))
+
+	for attempt := 0; ; attempt++ {
+		list, err := us.list(ctx, rfClients, u.Path, query)
+		// Expired continue tokens are reported with 410 Gone
+		expired := kerrors.IsResourceExpired(err) || kerrors.IsGone(err)
+		if expired && attempt < listRestarts {
+			DBG("The continue token of %s expired, listing it again", u.Path)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		return list, nil
+	}
+}
+
+// list fetches all the pages of a collection and returns them as a single
+// list. The items are kept as they were received rather than decoded, as
+// they are only passed through.
+func (us *uriStreamer) list(ctx context.Context, rfClients resourceFetcherClients, listPath string, query url.Values) (io.ReadCloser, error) {
+	query.Del("continue")
+	var list map[string]json.RawMessage
+	var metadata map[string]interface{}
+	items := []json.RawMessage{}
+	for {
+		req := rfClients.clientset.RESTClient().Get().RequestURI(listPath + "?" + query.Encode())
+		if us.metadataOnly {
+			req = req.SetHeader("Accept", metadataAcceptHeader("PartialObjectMetadataList"))
+		}
+		body, err := req.Do(ctx).Raw()
+		if err != nil {
+			return nil, err
+		}
+		page := map[string]json.RawMessage{}
+		pageItems := []json.RawMessage{}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("cannot decode the list %s: %w", listPath, err)
+		}
+		if raw, ok := page["items"]; ok && string(raw) != "null" {
+			if err := json.Unmarshal(raw, &pageItems); err != nil {
+				return nil, fmt.Errorf("cannot decode the items of the list %s: %w", listPath, err)
+			}
+		}
+		items = append(items, pageItems...)
+
+		pageMetadata := map[string]interface{}{}
+		if raw, ok := page["metadata"]; ok {
+			if pageMetadata, err = decodeJSONObject(raw); err != nil {
+				return nil, fmt.Errorf("cannot decode the metadata of the list %s: %w", listPath, err)
+			}
+		}
+		if list == nil {
+			list, metadata = page, pageMetadata
+		}
+
+		cont, _ := pageMetadata["continue"].(string)
+		if cont == "" {
+			break
+		}
+		DBG("Fetching the next page of %s", listPath)
+		query.Set("continue", cont)
+	}
+
+	delete(metadata, "continue")
+	delete(metadata, "remainingItemCount")
+	var err error
+	if list["metadata"], err = json.Marshal(metadata); err != nil {
+		return nil, err
+	}
+	if list["items"], err = json.Marshal(items); err != nil {
+		return nil, err
+	}
+	out, err := json.Marshal(list)
+	if err != nil {
+		return nil, err
+	}
+	return &bufCloser{bytes.NewBuffer(out)}, nil
+}
+
+// isListPath tells whether an API path refers to a collection, such as
+// /api/v1/namespaces or /apis/apps/v1/namespaces/default/deployments
+func isListPath(p string) bool {
+	segments := strings.Split(strings.Trim(p, "/"), "/")
+	var rest []string
+	switch {
+	case len(segments) >= 2 && segments[0] == "api":
+		rest = segments[2:]
+	case len(segments) >= 3 && segments[0] == "apis":
+		rest = segments[3:]
+	default:
+		return false
+	}
+	if len(rest) == 1 {
+		return true
+	}
+	// Namespaces have subresources too
+	return len(rest) == 3 && rest[0] == "namespaces" && rest[2] != "status" && rest[2] != "finalize"
+}
+
+// metadataAcceptHeader asks the API server for the metadata of the objects
+// only, falling back to the full objects for APIs that don't support it
+func metadataAcceptHeader(kind string) string {
+	return fmt.Sprintf("application/json;as=%s;g=meta.k8s.io;v=v1,application/json", kind)
+}
+
+// fileStreamer implements resourceStreamer for reading the resources from a
+// directory laid out like the API paths, for offline scanning. A path is
+// either a file holding the object or the list as returned by the API
+// server, in JSON or YAML, or a directory holding a file per object of the
+// list. The selectors of the resource path are evaluated against the listed
+// objects.
+type fileStreamer struct {
+	root  string
+	rpath utils.ResourcePath
+}
+
+func (fs *fileStreamer) Stream(_ context.Context, _ resourceFetcherClients) (io.ReadCloser, error) {
+	u, err := url.Parse(fs.rpath.ObjPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse URI %s: %w", fs.rpath.ObjPath, err)
+	}
+	p := filepath.Join(fs.root, filepath.FromSlash(path.Clean("/"+u.Path)))
+	info, err := os.Stat(p)
+	if os.IsNotExist(err) {
+		return nil, kerrors.NewNotFound(schema.GroupResource{Resource: "file"}, u.Path)
+	} else if err != nil {
+		return nil, err
+	}
+
+	var obj map[string]interface{}
+	if info.IsDir() {
+		obj, err = readListDir(p)
+	} else {
+		obj, err = readObjectFile(p)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if obj, err = selectResources(obj, fs.rpath); err != nil {
+		return nil, err
+	}
+	return encodeJSONObject(obj)
+}
+
+// selectResources does what the API server does with the selectors and the
+// metadataOnly setting of the resource path when returning obj, which is
+// either a single object or a list
+func selectResources(obj map[string]interface{}, rpath utils.ResourcePath) (map[string]interface{}, error) {
+	items, ok := obj["items"].([]interface{})
+	if !ok {
+		if rpath.MetadataOnly {
+			return metadataOnly(obj, "PartialObjectMetadata"), nil
+		}
+		return obj, nil
+	}
+
+	selected, err := selectItems(items, rpath)
+	if err != nil {
+		return nil, err
+	}
+	obj["items"] = selected
+	if rpath.MetadataOnly {
+		return metadataOnly(obj, "PartialObjectMetadataList"), nil
+	}
+	return obj, nil
+}
+
+// selectItems returns the items matching the selectors of the resource path
+func selectItems(items []interface{}, rpath utils.ResourcePath) ([]interface{}, error) {
+	labelSelector, err := labels.Parse(rpath.LabelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector %s: %w", rpath.LabelSelector, err)
+	}
+	fieldSelector, err := fields.ParseSelector(rpath.FieldSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid field selector %s: %w", rpath.FieldSelector, err)
+	}
+
+	selected := []interface{}{}
+	for _, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		u := &unstructured.Unstructured{Object: obj}
+		if !labelSelector.Matches(labels.Set(u.GetLabels())) {
+			continue
+		}
+		objFields := fields.Set{}
+		for _, req := range fieldSelector.Requirements() {
+			if v, found, _ := unstructured.NestedFieldNoCopy(obj, strings.Split(req.Field, ".")...); found {
+				objFields[req.Field] = fmt.Sprint(v)
+			}
+		}
+		if !fieldSelector.Matches(objFields) {
+			continue
+		}
+		if rpath.MetadataOnly {
+			obj = metadataOnly(obj, "PartialObjectMetadata")
+		}
+		selected = append(selected, obj)
+	}
+	return selected, nil
+}
+
+// metadataOnly returns what the API server returns when only the metadata
+// of obj is asked for
+func metadataOnly(obj map[string]interface{}, kind string) map[string]interface{} {
+	out := map[string]interface{}{
+		"apiVersion": "meta.k8s.io/v1",
+		"kind":       kind,
+	}
+	if md, ok := obj["metadata"]; ok {
+		out["metadata"] = md
+	}
+	if items, ok := obj["items"]; ok {
+		out["items"] = items
+	}
+	return out
+}
+
+func readObjectFile(p string) (map[string]interface{}, error) {
+	// #nosec
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	data, err = yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", p, err)
+	}
+	obj, err := decodeJSONObject(data)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", p, err)
+	}
+	return obj, nil
+}
+
+// readListDir reads a list from a directory holding a file per object
+func readListDir(p string) (map[string]interface{}, error) {
+	entries, err := os.ReadDir(p)
+	if err != nil {
+		return nil, err
+	}
+	items := []interface{}{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		obj, err := readObjectFile(filepath.Join(p, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, obj)
+	}
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"metadata":   map[string]interface{}{},
+		"items":      items,
+	}, nil
+}
+
+// decodeJSONObject decodes a JSON object, keeping its numbers as they are
+func decodeJSONObject(data []byte) (map[string]interface{}, error) {
+	obj := map[string]interface{}{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func encodeJSONObject(obj map[string]interface{}) (io.ReadCloser, error) {
+	out, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &bufCloser{bytes.NewBuffer(out)}, nil
+}
@@ -0,0 +1,285 @@
+/*
+Copyright © 2026 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+func decodeFetched(data []byte) map[string]interface{} {
+	obj := map[string]interface{}{}
+	Expect(json.Unmarshal(data, &obj)).To(Succeed())
+	return obj
+}
+
+func itemNames(obj map[string]interface{}) []string {
+	names := []string{}
+	for _, item := range obj["items"].([]interface{}) {
+		names = append(names, item.(map[string]interface{})["metadata"].(map[string]interface{})["name"].(string))
+	}
+	return names
+}
+
+var _ = Describe("Testing the streamer registry", func() {
+	It("tells collections apart from single objects", func() {
+		Expect(isListPath("/api/v1/nodes")).To(BeTrue())
+		Expect(isListPath("/api/v1/namespaces")).To(BeTrue())
+		Expect(isListPath("/api/v1/namespaces/openshift-etcd/pods")).To(BeTrue())
+		Expect(isListPath("/apis/apps/v1/deployments")).To(BeTrue())
+		Expect(isListPath("/apis/apps/v1/namespaces/default/deployments")).To(BeTrue())
+
+		Expect(isListPath("/version")).To(BeFalse())
+		Expect(isListPath("/api/v1/namespaces/default")).To(BeFalse())
+		Expect(isListPath("/api/v1/namespaces/default/status")).To(BeFalse())
+		Expect(isListPath("/api/v1/nodes/worker-0/proxy/configz")).To(BeFalse())
+		Expect(isListPath("/apis/config.openshift.io/v1/oauths/cluster")).To(BeFalse())
+		Expect(isListPath("/apis/apps/v1/namespaces/default/deployments/web")).To(BeFalse())
+	})
+
+	It("dispatches the MachineConfigs to their streamer unless only their metadata is needed", func() {
+		Expect(getStreamerFn(utils.ResourcePath{ObjPath: machineConfigsURI})).To(BeAssignableToTypeOf(&mcStreamer{}))
+		Expect(getStreamerFn(utils.ResourcePath{ObjPath: machineConfigsURI, MetadataOnly: true})).To(BeAssignableToTypeOf(&uriStreamer{}))
+		Expect(getStreamerFn(utils.ResourcePath{ObjPath: "/api/v1/pods"})).To(BeAssignableToTypeOf(&uriStreamer{}))
+	})
+
+	It("lets registered factories take precedence", func() {
+		r := newAPIStreamerRegistry()
+		r.register(func(rpath utils.ResourcePath) resourceStreamer {
+			if rpath.ObjPath == "/api/v1/secrets" {
+				return &notFoundFetcher{}
+			}
+			return nil
+		})
+		Expect(r.streamerFor(utils.ResourcePath{ObjPath: "/api/v1/secrets"})).To(BeAssignableToTypeOf(&notFoundFetcher{}))
+		Expect(r.streamerFor(utils.ResourcePath{ObjPath: "/api/v1/pods"})).To(BeAssignableToTypeOf(&uriStreamer{}))
+	})
+})
+
+var _ = Describe("Testing fetching from the API server", func() {
+	var (
+		server    *httptest.Server
+		rfClients resourceFetcherClients
+		mu        sync.Mutex
+		requests  []*http.Request
+		listings  int
+	)
+
+	BeforeEach(func() {
+		requests = nil
+		listings = 0
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			mu.Lock()
+			requests = append(requests, req)
+			mu.Unlock()
+
+			w.Header().Set("Content-Type", "application/json")
+			switch req.URL.Path {
+			case "/api/v1/pods":
+				if req.URL.Query().Get("continue") == "" {
+					fmt.Fprint(w, `{"kind":"PodList","apiVersion":"v1","metadata":{"resourceVersion":"42","continue":"page-2","remainingItemCount":1},"items":[{"metadata":{"name":"pod-a","generation":9007199254740993}}]}`)
+				} else {
+					fmt.Fprint(w, `{"kind":"PodList","apiVersion":"v1","metadata":{"resourceVersion":"42"},"items":[{"metadata":{"name":"pod-b"}}]}`)
+				}
+			case "/api/v1/configmaps":
+				// The continue token of the first listing expires
+				switch {
+				case req.URL.Query().Get("continue") != "":
+					w.WriteHeader(http.StatusGone)
+					fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Expired","code":410}`)
+				case listings == 0:
+					listings++
+					fmt.Fprint(w, `{"kind":"ConfigMapList","apiVersion":"v1","metadata":{"continue":"expired"},"items":[{"metadata":{"name":"stale"}}]}`)
+				default:
+					fmt.Fprint(w, `{"kind":"ConfigMapList","apiVersion":"v1","metadata":{"resourceVersion":"43"},"items":[{"metadata":{"name":"cm-a"}},{"metadata":{"name":"cm-b"}}]}`)
+				}
+			case "/apis/config.openshift.io/v1/oauths/cluster":
+				fmt.Fprint(w, `{"kind":"OAuth","metadata":{"name":"cluster"}}`)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+			}
+		}))
+		clientset, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+		Expect(err).To(BeNil())
+		rfClients = resourceFetcherClients{clientset: clientset}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("lists collections page by page with their selectors", func() {
+		files, warnings, err := fetch(context.TODO(), getStreamerFn, rfClients, []utils.ResourcePath{{
+			ObjPath:       "/api/v1/pods",
+			DumpPath:      "/api/v1/pods",
+			LabelSelector: "app=router",
+			FieldSelector: "status.phase=Running",
+			MetadataOnly:  true,
+		}})
+		Expect(err).To(BeNil())
+		Expect(warnings).To(BeEmpty())
+
+		Expect(requests).To(HaveLen(2))
+		for _, req := range requests {
+			Expect(req.URL.Query().Get("limit")).To(Equal("500"))
+			Expect(req.URL.Query().Get("labelSelector")).To(Equal("app=router"))
+			Expect(req.URL.Query().Get("fieldSelector")).To(Equal("status.phase=Running"))
+			Expect(req.Header.Get("Accept")).To(HavePrefix("application/json;as=PartialObjectMetadataList;g=meta.k8s.io;v=v1"))
+		}
+		Expect(requests[1].URL.Query().Get("continue")).To(Equal("page-2"))
+
+		list := decodeFetched(files["/api/v1/pods"])
+		Expect(itemNames(list)).To(Equal([]string{"pod-a", "pod-b"}))
+		Expect(list["metadata"]).To(Equal(map[string]interface{}{"resourceVersion": "42"}))
+		// Numbers are passed through untouched
+		Expect(string(files["/api/v1/pods"])).To(ContainSubstring(`"generation":9007199254740993`))
+	})
+
+	It("lists collections again when the continue token expires", func() {
+		files, _, err := fetch(context.TODO(), getStreamerFn, rfClients, []utils.ResourcePath{{
+			ObjPath:  "/api/v1/configmaps",
+			DumpPath: "/api/v1/configmaps",
+		}})
+		Expect(err).To(BeNil())
+		Expect(requests).To(HaveLen(3))
+		Expect(requests[2].URL.Query().Get("continue")).To(BeEmpty())
+		Expect(itemNames(decodeFetched(files["/api/v1/configmaps"]))).To(Equal([]string{"cm-a", "cm-b"}))
+	})
+
+	It("gets single objects in one go and keeps filtering them", func() {
+		files, _, err := fetch(context.TODO(), getStreamerFn, rfClients, []utils.ResourcePath{{
+			ObjPath:  "/apis/config.openshift.io/v1/oauths/cluster",
+			DumpPath: "/oauth-name",
+			Filter:   ".metadata.name",
+		}})
+		Expect(err).To(BeNil())
+		Expect(string(files["/oauth-name"])).To(Equal("cluster"))
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].URL.RawQuery).To(BeEmpty())
+	})
+
+	It("stores the collections that aren't found", func() {
+		files, warnings, err := fetch(context.TODO(), getStreamerFn, rfClients, []utils.ResourcePath{{
+			ObjPath:  "/apis/example.com/v1/widgets",
+			DumpPath: "/apis/example.com/v1/widgets",
+		}})
+		Expect(err).To(BeNil())
+		Expect(warnings).To(HaveLen(1))
+		Expect(string(files["/apis/example.com/v1/widgets"])).To(Equal("# kube-api-error=NotFound"))
+	})
+})
+
+var _ = Describe("Testing fetching from local files", func() {
+	var (
+		rootDir   string
+		streamers streamerDispatcherFn
+	)
+
+	writeFile := func(name, contents string) {
+		p := filepath.Join(rootDir, filepath.FromSlash(name))
+		Expect(os.MkdirAll(filepath.Dir(p), 0700)).To(Succeed())
+		Expect(os.WriteFile(p, []byte(contents), 0600)).To(Succeed())
+	}
+	fetchOne := func(rpath utils.ResourcePath) ([]byte, []string) {
+		rpath.DumpPath = "dump"
+		files, warnings, err := fetch(context.TODO(), streamers, resourceFetcherClients{}, []utils.ResourcePath{rpath})
+		Expect(err).To(BeNil())
+		return files["dump"], warnings
+	}
+
+	BeforeEach(func() {
+		var err error
+		rootDir, err = os.MkdirTemp("", "offline-resources")
+		Expect(err).To(BeNil())
+		streamers = newFileStreamerRegistry(rootDir).streamerFor
+
+		writeFile("/apis/config.openshift.io/v1/oauths/cluster", `
+apiVersion: config.openshift.io/v1
+kind: OAuth
+metadata:
+  name: cluster
+spec:
+  tokenConfig:
+    accessTokenMaxAgeSeconds: 600
+`)
+		writeFile("/api/v1/namespaces/openshift-ingress/pods", `{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[
+{"metadata":{"name":"router-a","labels":{"app":"router"}},"status":{"phase":"Running"}},
+{"metadata":{"name":"router-b","labels":{"app":"router"}},"status":{"phase":"Pending"}},
+{"metadata":{"name":"canary","labels":{"app":"canary"}},"status":{"phase":"Running"}}]}`)
+		writeFile("/api/v1/namespaces/a.yaml", "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: a\n")
+		writeFile("/api/v1/namespaces/b.json", `{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"b"},"spec":{"finalizers":["kubernetes"]}}`)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(rootDir)
+	})
+
+	It("reads single objects and filters them", func() {
+		data, warnings := fetchOne(utils.ResourcePath{
+			ObjPath: "/apis/config.openshift.io/v1/oauths/cluster",
+			Filter:  ".spec.tokenConfig.accessTokenMaxAgeSeconds",
+		})
+		Expect(warnings).To(BeEmpty())
+		Expect(string(data)).To(Equal("600"))
+	})
+
+	It("applies the selectors to lists", func() {
+		data, _ := fetchOne(utils.ResourcePath{
+			ObjPath:       "/api/v1/namespaces/openshift-ingress/pods",
+			LabelSelector: "app=router",
+			FieldSelector: "status.phase=Running",
+		})
+		Expect(itemNames(decodeFetched(data))).To(Equal([]string{"router-a"}))
+	})
+
+	It("reads lists from directories", func() {
+		data, _ := fetchOne(utils.ResourcePath{ObjPath: "/api/v1/namespaces"})
+		list := decodeFetched(data)
+		Expect(list["kind"]).To(Equal("List"))
+		Expect(itemNames(list)).To(Equal([]string{"a", "b"}))
+	})
+
+	It("only keeps the metadata if asked to", func() {
+		data, _ := fetchOne(utils.ResourcePath{ObjPath: "/api/v1/namespaces", MetadataOnly: true})
+		list := decodeFetched(data)
+		Expect(list["kind"]).To(Equal("PartialObjectMetadataList"))
+		Expect(itemNames(list)).To(Equal([]string{"a", "b"}))
+		for _, item := range list["items"].([]interface{}) {
+			Expect(item).ToNot(HaveKey("spec"))
+			Expect(item).To(HaveKeyWithValue("kind", "PartialObjectMetadata"))
+		}
+	})
+
+	It("reports the missing resources like the API server", func() {
+		data, warnings := fetchOne(utils.ResourcePath{ObjPath: "/apis/config.openshift.io/v1/apiservers/cluster"})
+		Expect(string(data)).To(Equal("# kube-api-error=NotFound"))
+		Expect(warnings).To(HaveLen(1))
+	})
+})
@@ -40,6 +40,10 @@ func init() {
 	rootCmd.AddCommand(manager.ResultcollectorCmd)
 	rootCmd.AddCommand(manager.ResultServerCmd)
 	rootCmd.AddCommand(manager.RerunnerCmd)
+	rootCmd.AddCommand(manager.ReportCmd)
+	rootCmd.AddCommand(manager.OfflineScanCmd)
+	rootCmd.AddCommand(manager.ArfCmd)
+	rootCmd.AddCommand(manager.NodeRemediationAgentCmd)
 }
 
 func main() {
@@ -0,0 +1,136 @@
+package v1alpha1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ComplianceAttestationFinalizer is a finalizer for ComplianceAttestations.
+// It makes sure the check result an attestation applies to is MANUAL again
+// once the attestation is deleted.
+const ComplianceAttestationFinalizer = "attestation.finalizers.compliance.openshift.io"
+
+// ComplianceCheckResultAttestedLabel marks the manual ComplianceCheckResults
+// that are attested by a ComplianceAttestation. Its value is the attested
+// outcome, PASS or FAIL.
+const ComplianceCheckResultAttestedLabel = "compliance.openshift.io/check-attested"
+
+// ComplianceCheckResultAttestedByAnnotation records the name of the
+// ComplianceAttestation attesting a ComplianceCheckResult
+const ComplianceCheckResultAttestedByAnnotation = "compliance.openshift.io/attested-by"
+
+type ComplianceAttestationPhase string
+
+const (
+	AttestationPhasePending ComplianceAttestationPhase = "PENDING"
+	AttestationPhaseActive  ComplianceAttestationPhase = "ACTIVE"
+	AttestationPhaseExpired ComplianceAttestationPhase = "EXPIRED"
+	AttestationPhaseInvalid ComplianceAttestationPhase = "INVALID"
+)
+
+// ComplianceAttestationSpec records the outcome of a manual check
+type ComplianceAttestationSpec struct {
+	// The name of the ComplianceCheckResult of the manual check
+	// +kubebuilder:validation:MinLength=1
+	CheckResult string `json:"checkResult"`
+	// The outcome of the manual check
+	// +kubebuilder:validation:Enum=PASS;FAIL
+	Outcome ComplianceCheckStatus `json:"outcome"`
+	// Describes how the check was performed and what was found
+	// +optional
+	Evidence string `json:"evidence,omitempty"`
+	// Links to documents or tickets backing the outcome
+	// +optional
+	EvidenceLinks []string `json:"evidenceLinks,omitempty"`
+	// Who performed the manual check. The admission webhook sets it to the
+	// user creating the attestation and keeps it from being changed.
+	// +optional
+	Attester string `json:"attester,omitempty"`
+	// When the attestation expires. From then on, the check is MANUAL
+	// again until it's attested anew.
+	ExpirationDate metav1.Time `json:"expirationDate"`
+}
+
+// ComplianceAttestationStatus defines the observed state of a
+// ComplianceAttestation
+type ComplianceAttestationStatus struct {
+	Phase ComplianceAttestationPhase `json:"phase,omitempty"`
+	// +optional
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ComplianceAttestation records the outcome of a manual check until it
+// expires. An attested check counts as passing or failing when the result of
+// its scan is computed.
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=complianceattestations,scope=Namespaced,shortName=catt
+// +kubebuilder:printcolumn:name="CheckResult",type="string",JSONPath=`.spec.checkResult`
+// +kubebuilder:printcolumn:name="Outcome",type="string",JSONPath=`.spec.outcome`
+// +kubebuilder:printcolumn:name="Attester",type="string",JSONPath=`.spec.attester`
+// +kubebuilder:printcolumn:name="Expires",type="date",JSONPath=`.spec.expirationDate`
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=`.status.phase`
+type ComplianceAttestation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ComplianceAttestationSpec `json:"spec,omitempty"`
+	// +optional
+	Status ComplianceAttestationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ComplianceAttestationList contains a list of ComplianceAttestation
+type ComplianceAttestationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ComplianceAttestation `json:"items"`
+}
+
+// IsActive tells whether the attestation applies at the given time
+func (a *ComplianceAttestation) IsActive(now time.Time) bool {
+	return a.DeletionTimestamp == nil &&
+		a.Status.Phase == AttestationPhaseActive &&
+		now.Before(a.Spec.ExpirationDate.Time)
+}
+
+// Attests tells whether the attestation applies to a check result. Only
+// manual checks can be attested.
+func (a *ComplianceAttestation) Attests(check *ComplianceCheckResult) bool {
+	return check.Status == CheckResultManual && a.Spec.CheckResult == check.Name
+}
+
+// FindAttestation returns the active attestation of a check result, if any.
+// When a check was attested several times, the most recent attestation wins,
+// with ties broken by name so that the outcome doesn't depend on the order
+// they're listed in.
+func FindAttestation(attestations []ComplianceAttestation, check *ComplianceCheckResult, now time.Time) *ComplianceAttestation {
+	var found *ComplianceAttestation
+	for i := range attestations {
+		a := &attestations[i]
+		if !a.IsActive(now) || !a.Attests(check) {
+			continue
+		}
+		if found == nil || found.CreationTimestamp.Before(&a.CreationTimestamp) ||
+			(found.CreationTimestamp.Equal(&a.CreationTimestamp) && a.Name < found.Name) {
+			found = a
+		}
+	}
+	return found
+}
+
+// AttestedStatus returns the outcome a ComplianceAttestation recorded for a
+// manual check result, or an empty status if the check isn't attested
+func (r *ComplianceCheckResult) AttestedStatus() ComplianceCheckStatus {
+	if r.Status != CheckResultManual {
+		return ""
+	}
+	return ComplianceCheckStatus(r.Labels[ComplianceCheckResultAttestedLabel])
+}
+
+func init() {
+	SchemeBuilder.Register(&ComplianceAttestation{}, &ComplianceAttestationList{})
+}
@@ -0,0 +1,84 @@
+package v1alpha1
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Testing ComplianceAttestation API", func() {
+	var now time.Time
+
+	newAttestation := func(name string, created time.Time, outcome ComplianceCheckStatus) ComplianceAttestation {
+		return ComplianceAttestation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				CreationTimestamp: metav1.Time{Time: created},
+			},
+			Spec: ComplianceAttestationSpec{
+				CheckResult:    "ocp4-cis-audit-log-forwarding-enabled",
+				Outcome:        outcome,
+				Attester:       "auditor@example.com",
+				ExpirationDate: metav1.Time{Time: now.Add(time.Hour)},
+			},
+			Status: ComplianceAttestationStatus{
+				Phase: AttestationPhaseActive,
+			},
+		}
+	}
+	newCheck := func(name string, status ComplianceCheckStatus) ComplianceCheckResult {
+		return ComplianceCheckResult{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     status,
+		}
+	}
+	attest := func(check ComplianceCheckResult, outcome ComplianceCheckStatus) ComplianceCheckResult {
+		check.Labels = map[string]string{ComplianceCheckResultAttestedLabel: string(outcome)}
+		return check
+	}
+
+	BeforeEach(func() {
+		now = time.Now().Truncate(time.Second)
+	})
+
+	Context("Matching check results", func() {
+		It("only attests the given manual check", func() {
+			a := newAttestation("a", now, CheckResultPass)
+			check := newCheck("ocp4-cis-audit-log-forwarding-enabled", CheckResultManual)
+			Expect(a.Attests(&check)).To(BeTrue())
+
+			check.Status = CheckResultFail
+			Expect(a.Attests(&check)).To(BeFalse())
+
+			check = newCheck("ocp4-cis-idp-is-configured", CheckResultManual)
+			Expect(a.Attests(&check)).To(BeFalse())
+		})
+
+		It("picks the most recent active attestation", func() {
+			older := newAttestation("older", now.Add(-2*time.Hour), CheckResultFail)
+			newer := newAttestation("newer", now.Add(-time.Hour), CheckResultPass)
+			expired := newAttestation("expired", now, CheckResultFail)
+			expired.Spec.ExpirationDate = metav1.Time{Time: now.Add(-time.Minute)}
+			check := newCheck("ocp4-cis-audit-log-forwarding-enabled", CheckResultManual)
+
+			found := FindAttestation([]ComplianceAttestation{newer, expired, older}, &check, now)
+			Expect(found).ToNot(BeNil())
+			Expect(found.Name).To(Equal("newer"))
+
+			found = FindAttestation([]ComplianceAttestation{expired}, &check, now)
+			Expect(found).To(BeNil())
+		})
+
+		It("only reports the attested outcome of manual checks", func() {
+			check := attest(newCheck("a", CheckResultManual), CheckResultFail)
+			Expect(check.AttestedStatus()).To(Equal(CheckResultFail))
+
+			check.Status = CheckResultPass
+			Expect(check.AttestedStatus()).To(BeEmpty())
+			unattested := newCheck("b", CheckResultManual)
+			Expect(unattested.AttestedStatus()).To(BeEmpty())
+		})
+	})
+})
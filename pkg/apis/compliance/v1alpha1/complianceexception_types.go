@@ -0,0 +1,151 @@
+package v1alpha1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ComplianceExceptionFinalizer is a finalizer for ComplianceExceptions. It
+// makes sure the check results waived by an exception fail again once the
+// exception is deleted.
+const ComplianceExceptionFinalizer = "exception.finalizers.compliance.openshift.io"
+
+// ComplianceCheckResultWaivedLabel marks the failing ComplianceCheckResults
+// that are waived by a ComplianceException
+const ComplianceCheckResultWaivedLabel = "compliance.openshift.io/check-waived"
+
+// ComplianceCheckResultWaivedByAnnotation records the name of the
+// ComplianceException waiving a ComplianceCheckResult
+const ComplianceCheckResultWaivedByAnnotation = "compliance.openshift.io/waived-by"
+
+type ComplianceExceptionPhase string
+
+const (
+	ExceptionPhasePending ComplianceExceptionPhase = "PENDING"
+	ExceptionPhaseActive  ComplianceExceptionPhase = "ACTIVE"
+	ExceptionPhaseExpired ComplianceExceptionPhase = "EXPIRED"
+	ExceptionPhaseInvalid ComplianceExceptionPhase = "INVALID"
+)
+
+// ComplianceExceptionSpec defines which failures are accepted and why
+type ComplianceExceptionSpec struct {
+	// The name of the Rule whose failures are waived in every scan. Exactly
+	// one of rule and checkResult must be set.
+	// +optional
+	Rule string `json:"rule,omitempty"`
+	// The name of a single ComplianceCheckResult to waive. Exactly one of
+	// rule and checkResult must be set.
+	// +optional
+	CheckResult string `json:"checkResult,omitempty"`
+	// Why the failure is accepted
+	// +kubebuilder:validation:MinLength=1
+	Justification string `json:"justification"`
+	// Who approved the exception
+	// +kubebuilder:validation:MinLength=1
+	Approver string `json:"approver"`
+	// When the exception expires. From then on, the waived checks fail
+	// again.
+	ExpirationDate metav1.Time `json:"expirationDate"`
+	// Restricts the exception to the results of the node scans whose node
+	// selector matches this one, e.g. only the scan of the worker nodes.
+	// Results of platform scans are never waived by an exception with a
+	// node selector.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+}
+
+// ComplianceExceptionStatus defines the observed state of a ComplianceException
+type ComplianceExceptionStatus struct {
+	Phase ComplianceExceptionPhase `json:"phase,omitempty"`
+	// The DNS-friendly name of the waived rule, as found in the
+	// compliance.openshift.io/rule annotation of the check results
+	// +optional
+	RuleID string `json:"ruleID,omitempty"`
+	// +optional
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ComplianceException waives the failures of a rule, or of a single check
+// result, until it expires. Waived failures don't make a scan NON-COMPLIANT.
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=complianceexceptions,scope=Namespaced,shortName=cex
+// +kubebuilder:printcolumn:name="Rule",type="string",JSONPath=`.spec.rule`
+// +kubebuilder:printcolumn:name="CheckResult",type="string",JSONPath=`.spec.checkResult`
+// +kubebuilder:printcolumn:name="Expires",type="date",JSONPath=`.spec.expirationDate`
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=`.status.phase`
+type ComplianceException struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ComplianceExceptionSpec `json:"spec,omitempty"`
+	// +optional
+	Status ComplianceExceptionStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ComplianceExceptionList contains a list of ComplianceException
+type ComplianceExceptionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ComplianceException `json:"items"`
+}
+
+// IsActive tells whether the exception waives failures at the given time
+func (e *ComplianceException) IsActive(now time.Time) bool {
+	return e.DeletionTimestamp == nil &&
+		e.Status.Phase == ExceptionPhaseActive &&
+		now.Before(e.Spec.ExpirationDate.Time)
+}
+
+// Waives tells whether the exception applies to a check result of the given
+// scan. Only failures can be waived.
+func (e *ComplianceException) Waives(check *ComplianceCheckResult, scan *ComplianceScan) bool {
+	if check.Status != CheckResultFail {
+		return false
+	}
+	if e.Spec.CheckResult != "" && e.Spec.CheckResult != check.Name {
+		return false
+	}
+	if e.Spec.Rule != "" && e.Status.RuleID != check.Annotations[ComplianceCheckResultRuleAnnotation] {
+		return false
+	}
+	if len(e.Spec.NodeSelector) == 0 {
+		return true
+	}
+	if scan == nil || scan.GetScanType() != ScanTypeNode {
+		return false
+	}
+	return labels.SelectorFromSet(e.Spec.NodeSelector).Matches(labels.Set(scan.GetNodeSelectorLabels()))
+}
+
+// FindWaivingException returns the active exception waiving a check result,
+// if any. When several exceptions apply, the first one by name is returned
+// so that the outcome doesn't depend on the order they're listed in.
+func FindWaivingException(exceptions []ComplianceException, check *ComplianceCheckResult, scan *ComplianceScan, now time.Time) *ComplianceException {
+	var found *ComplianceException
+	for i := range exceptions {
+		e := &exceptions[i]
+		if !e.IsActive(now) || !e.Waives(check, scan) {
+			continue
+		}
+		if found == nil || e.Name < found.Name {
+			found = e
+		}
+	}
+	return found
+}
+
+// IsWaived tells whether a check result is marked as waived by an exception
+func (r *ComplianceCheckResult) IsWaived() bool {
+	_, ok := r.Labels[ComplianceCheckResultWaivedLabel]
+	return ok
+}
+
+func init() {
+	SchemeBuilder.Register(&ComplianceException{}, &ComplianceExceptionList{})
+}
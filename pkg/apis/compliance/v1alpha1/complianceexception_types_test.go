@@ -0,0 +1,122 @@
+package v1alpha1
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Testing ComplianceException API", func() {
+	var now time.Time
+
+	newException := func(name string) ComplianceException {
+		return ComplianceException{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: ComplianceExceptionSpec{
+				Rule:           "ocp4-kubelet-enable-protect-kernel-defaults",
+				ExpirationDate: metav1.Time{Time: now.Add(time.Hour)},
+			},
+			Status: ComplianceExceptionStatus{
+				Phase:  ExceptionPhaseActive,
+				RuleID: "kubelet-enable-protect-kernel-defaults",
+			},
+		}
+	}
+	newCheck := func(name string, status ComplianceCheckStatus) ComplianceCheckResult {
+		return ComplianceCheckResult{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Annotations: map[string]string{
+					ComplianceCheckResultRuleAnnotation: "kubelet-enable-protect-kernel-defaults",
+				},
+			},
+			Status: status,
+		}
+	}
+	newScan := func(scanType ComplianceScanType, nodeSelector map[string]string) *ComplianceScan {
+		return &ComplianceScan{
+			Spec: ComplianceScanSpec{
+				ScanType:     scanType,
+				NodeSelector: nodeSelector,
+			},
+		}
+	}
+	workers := map[string]string{"node-role.kubernetes.io/worker": ""}
+	masters := map[string]string{"node-role.kubernetes.io/master": ""}
+
+	BeforeEach(func() {
+		now = time.Now()
+	})
+
+	Context("Matching check results", func() {
+		It("only waives failures of the rule", func() {
+			e := newException("e")
+			check := newCheck("ocp4-cis-node-worker-kubelet-enable-protect-kernel-defaults", CheckResultFail)
+			Expect(e.Waives(&check, newScan(ScanTypeNode, workers))).To(BeTrue())
+
+			check.Status = CheckResultPass
+			Expect(e.Waives(&check, newScan(ScanTypeNode, workers))).To(BeFalse())
+
+			check = newCheck("ocp4-cis-api-server-anonymous-auth", CheckResultFail)
+			check.Annotations[ComplianceCheckResultRuleAnnotation] = "api-server-anonymous-auth"
+			Expect(e.Waives(&check, newScan(ScanTypePlatform, nil))).To(BeFalse())
+		})
+
+		It("matches the labels of the node label selector of a scan", func() {
+			e := newException("e")
+			e.Spec.NodeSelector = workers
+			check := newCheck("ocp4-cis-node-worker-kubelet-enable-protect-kernel-defaults", CheckResultFail)
+			scan := newScan(ScanTypeNode, nil)
+			scan.Spec.NodeLabelSelector = &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "node-role.kubernetes.io/worker", Operator: metav1.LabelSelectorOpIn, Values: []string{""}},
+					{Key: "hardware", Operator: metav1.LabelSelectorOpIn, Values: []string{"gpu"}},
+				},
+			}
+			Expect(e.Waives(&check, scan)).To(BeTrue())
+
+			By("not matching selectors that can't be expressed as labels")
+			scan.Spec.NodeLabelSelector.MatchExpressions[1].Operator = metav1.LabelSelectorOpNotIn
+			Expect(e.Waives(&check, scan)).To(BeFalse())
+		})
+
+		It("only waives the given check result", func() {
+			e := newException("e")
+			e.Spec.Rule = ""
+			e.Status.RuleID = ""
+			e.Spec.CheckResult = "ocp4-cis-node-worker-kubelet-enable-protect-kernel-defaults"
+			check := newCheck("ocp4-cis-node-worker-kubelet-enable-protect-kernel-defaults", CheckResultFail)
+			Expect(e.Waives(&check, newScan(ScanTypeNode, workers))).To(BeTrue())
+
+			check.Name = "ocp4-cis-node-master-kubelet-enable-protect-kernel-defaults"
+			Expect(e.Waives(&check, newScan(ScanTypeNode, masters))).To(BeFalse())
+		})
+
+		It("only waives the results of node scans matching the node selector", func() {
+			e := newException("e")
+			e.Spec.NodeSelector = workers
+			check := newCheck("ocp4-cis-node-worker-kubelet-enable-protect-kernel-defaults", CheckResultFail)
+			Expect(e.Waives(&check, newScan(ScanTypeNode, workers))).To(BeTrue())
+			Expect(e.Waives(&check, newScan(ScanTypeNode, masters))).To(BeFalse())
+			Expect(e.Waives(&check, newScan(ScanTypePlatform, nil))).To(BeFalse())
+		})
+
+		It("picks the first active exception by name", func() {
+			expired := newException("a")
+			expired.Spec.ExpirationDate = metav1.Time{Time: now.Add(-time.Hour)}
+			invalid := newException("b")
+			invalid.Status.Phase = ExceptionPhaseInvalid
+			check := newCheck("ocp4-cis-node-worker-kubelet-enable-protect-kernel-defaults", CheckResultFail)
+
+			found := FindWaivingException([]ComplianceException{newException("d"), expired, newException("c"), invalid},
+				&check, newScan(ScanTypeNode, workers), now)
+			Expect(found).ToNot(BeNil())
+			Expect(found.Name).To(Equal("c"))
+
+			found = FindWaivingException([]ComplianceException{expired, invalid}, &check, newScan(ScanTypeNode, workers), now)
+			Expect(found).To(BeNil())
+		})
+	})
+})
@@ -21,6 +21,8 @@ const (
 	RemediationError               RemediationApplicationState = "Error"
 	RemediationMissingDependencies RemediationApplicationState = "MissingDependencies"
 	RemediationNeedsReview         RemediationApplicationState = "NeedsReview"
+	RemediationPendingApproval     RemediationApplicationState = "PendingApproval"
+	RemediationReverted            RemediationApplicationState = "Reverted"
 )
 
 // +kubebuilder:validation:Enum=Configuration;Enforcement
@@ -54,6 +56,12 @@ const (
 	// RemediationValueRequiredProcessedLabel specifies that a remediation's needed value
 	// has been processed.
 	RemediationValueRequiredProcessedLabel = "compliance.openshift.io/value-required-processed"
+	// NodeRemediationLabel marks the ConfigMaps holding the node configuration
+	// that the node remediation agent applies to the nodes.
+	NodeRemediationLabel = "compliance.openshift.io/node-remediation"
+	// RemediationRevisionLabel marks the Secrets keeping the revisions of a
+	// remediation object with the name of the remediation.
+	RemediationRevisionLabel = "compliance.openshift.io/remediation-revision"
 	// RemediationCreatedByOperatorAnnotation specifies that a remediation was
 	// created by the Compliance Operator; this is used for the Compliance Operator to
 	// know whether it can delete the object or not when un-applying a remediation.
@@ -90,6 +98,15 @@ const (
 	// K8SVersionDependencyAnnotation specifies that the k8s cluster needs to fall
 	// into a range in order to be applied
 	K8SVersionDependencyAnnotation = "compliance.openshift.io/k8s-version"
+	// RemediationDisruptionAnnotation specifies how disruptive applying the
+	// remediation is, as given by the disruption of the fix in the content
+	RemediationDisruptionAnnotation = "compliance.openshift.io/disruption"
+	// RemediationSeverityAnnotation specifies the severity of the rule the
+	// remediation fixes
+	RemediationSeverityAnnotation = "compliance.openshift.io/severity"
+	// RemediationRevertToRevisionAnnotation requests the remediation object
+	// to be restored to one of the revisions kept in the remediation's status
+	RemediationRevertToRevisionAnnotation = "compliance.openshift.io/revert-to-revision"
 )
 
 var (
@@ -112,6 +129,18 @@ type ComplianceRemediationSpecMeta struct {
 	// stays in compliance via means of authorization.
 	// +kubebuilder:default="Configuration"
 	Type RemediationType `json:"type,omitempty"`
+	// When set, the operator neither applies nor unapplies the remediation.
+	// Instead, it performs a server-side dry-run apply of the remediation
+	// object and stores how it would change the object in the cluster in
+	// the status, so that the remediation can be reviewed first.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+	// The number of revisions of the remediation object to keep, each
+	// holding the object as it was before the remediation patched it.
+	// Defaults to 3, setting it to 0 disables the revision history.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
 }
 
 type ComplianceRemediationPayload struct {
@@ -154,6 +183,93 @@ type ComplianceRemediationStatus struct {
 	// +kubebuilder:default="NotApplied"
 	ApplicationState RemediationApplicationState `json:"applicationState,omitempty"`
 	ErrorMessage     string                      `json:"errorMessage,omitempty"`
+	// The outcome of the dry-run apply of the remediation, only set while
+	// the remediation is in dry-run mode
+	// +optional
+	DryRun *ComplianceRemediationDryRun `json:"dryRun,omitempty"`
+	// The approvals the remediation needs before it's applied, only set if
+	// the suite requires approvals for it
+	// +optional
+	Approvals *ComplianceRemediationApprovals `json:"approvals,omitempty"`
+	// The revisions of the objects as they were before the remediation
+	// patched them, oldest first
+	// +listType=atomic
+	// +optional
+	Revisions []ComplianceRemediationRevision `json:"revisions,omitempty"`
+	// The last time the remediation object was reverted to a revision
+	// +optional
+	LastRevert *ComplianceRemediationRevert `json:"lastRevert,omitempty"`
+}
+
+// ComplianceRemediationRevision points to the Secret that keeps the
+// remediation object as it was before the remediation patched it
+type ComplianceRemediationRevision struct {
+	// The number of the revision, increasing with every patch
+	Revision int64 `json:"revision"`
+	// When the remediation patched the object
+	Timestamp metav1.Time `json:"timestamp"`
+	// The name of the Secret, in the namespace of the remediation, that
+	// keeps the object before it was patched
+	SecretName string `json:"secretName"`
+}
+
+// ComplianceRemediationRevert records the revert of a remediation object
+type ComplianceRemediationRevert struct {
+	// The revision the object was reverted to
+	Revision int64 `json:"revision"`
+	// When the object was reverted
+	Timestamp metav1.Time `json:"timestamp"`
+}
+
+// GetRevision returns the revision with the given number, or nil if it's not
+// kept anymore
+func (s *ComplianceRemediationStatus) GetRevision(revision int64) *ComplianceRemediationRevision {
+	for i := range s.Revisions {
+		if s.Revisions[i].Revision == revision {
+			return &s.Revisions[i]
+		}
+	}
+	return nil
+}
+
+// ComplianceRemediationApprovals tracks the sign-offs of a remediation
+// against the number of approvals it needs
+type ComplianceRemediationApprovals struct {
+	// The number of distinct users that need to sign off the remediation
+	Required int `json:"required"`
+	// The users that signed off the remediation in its RemediationApproval
+	// +listType=atomic
+	// +optional
+	SignedOffBy []string `json:"signedOffBy,omitempty"`
+}
+
+// IsMet tells whether enough users signed off the remediation
+func (a *ComplianceRemediationApprovals) IsMet() bool {
+	return len(a.SignedOffBy) >= a.Required
+}
+
+type RemediationDryRunOperation string
+
+const (
+	// The remediation object doesn't exist and would be created
+	RemediationDryRunCreate RemediationDryRunOperation = "Create"
+	// The remediation object exists and would be changed
+	RemediationDryRunUpdate RemediationDryRunOperation = "Update"
+	// The remediation object exists and applying wouldn't change it
+	RemediationDryRunNoChange RemediationDryRunOperation = "NoChange"
+)
+
+// ComplianceRemediationDryRun describes what applying a remediation would do
+type ComplianceRemediationDryRun struct {
+	// When the dry-run apply was performed
+	Timestamp metav1.Time `json:"timestamp"`
+	// Whether the object would be created or updated
+	Operation RemediationDryRunOperation `json:"operation"`
+	// A unified diff between the object in the cluster and the object as
+	// the API server would store it once the remediation is applied, both in
+	// YAML. Long diffs are truncated.
+	// +optional
+	Diff string `json:"diff,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -224,6 +340,19 @@ func (r *ComplianceRemediation) IsApplied() bool {
 	return applied || outDatedButApplied || appliedButUnmet
 }
 
+// IsPendingApproval tells whether the remediation is to be applied but still
+// lacks the approvals it needs. Remediations that are already applied stay
+// applied even if sign-offs are withdrawn.
+func (r *ComplianceRemediation) IsPendingApproval() bool {
+	if !r.Spec.Apply || r.Spec.DryRun || r.Status.Approvals == nil {
+		return false
+	}
+	if r.Status.ApplicationState == RemediationApplied || r.Status.ApplicationState == RemediationOutdated {
+		return false
+	}
+	return !r.Status.Approvals.IsMet()
+}
+
 func (r *ComplianceRemediation) HasUnmetDependencies() bool {
 	a := r.GetAnnotations()
 	if len(a) == 0 {
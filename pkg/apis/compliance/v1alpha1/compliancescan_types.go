@@ -8,6 +8,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 // +genclient
@@ -16,6 +17,16 @@ import (
 // should be re-run
 const ComplianceScanRescanAnnotation = "compliance.openshift.io/rescan"
 
+// ComplianceScanRescanModeAnnotation selects what a rescan requested with
+// ComplianceScanRescanAnnotation evaluates. The whole profile is evaluated
+// unless it's set to RescanModeFailed.
+const ComplianceScanRescanModeAnnotation = "compliance.openshift.io/rescan-mode"
+
+// RescanModeFailed rescans only the rules whose checks failed or were
+// inconsistent, on the nodes they failed on, and merges the outcome into the
+// existing results
+const RescanModeFailed = "failed"
+
 // ComplianceScanTimeoutAnnotation indicates that a ComplianceScan
 // got a timeout, we will put the timeout node name in the annotation
 // if the scan is a node scan. If it's a platform scan, we will put
@@ -48,6 +59,10 @@ const ScanFinalizer = "scan.finalizers.compliance.openshift.io"
 const DefaultRawStorageSize = "1Gi"
 const DefaultStorageRotation = 3
 
+// DefaultS3Region is the region used to sign the requests to the raw result
+// bucket when none is set
+const DefaultS3Region = "us-east-1"
+
 var ErrUnkownScanType = errors.New("Unknown scan type")
 
 // Represents the status of the compliance scan run.
@@ -165,6 +180,176 @@ type RawResultStorageSettings struct {
 	// in case the target set of nodes have custom taints that don't allow certain
 	// workloads to run. Defaults to allowing scheduling on master nodes.
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// Specifies where the raw results are stored. With the default, pvc, a
+	// PersistentVolumeClaim is created for the scan and mounted by the result
+	// server. With s3, the result server uploads the results to an
+	// S3-compatible bucket instead and no PersistentVolumeClaim is created.
+	// +kubebuilder:validation:Enum=pvc;s3
+	// +kubebuilder:default=pvc
+	// +optional
+	Backend RawResultStorageBackend `json:"backend,omitempty"`
+	// Settings for the s3 backend.
+	// +optional
+	S3 *S3StorageSettings `json:"s3,omitempty"`
+	// Configures how the certificates authenticating the result collectors
+	// and the result server to each other are issued. By default, the
+	// operator creates a self-signed CA for every scan.
+	// +optional
+	Certificates *ResultCertificateSettings `json:"certificates,omitempty"`
+}
+
+// RawResultStorageBackend is where the raw results of a scan are stored
+type RawResultStorageBackend string
+
+const (
+	// RawResultStorageBackendPVC stores the raw results in a
+	// PersistentVolume
+	RawResultStorageBackendPVC RawResultStorageBackend = "pvc"
+	// RawResultStorageBackendS3 stores the raw results in an S3-compatible
+	// bucket
+	RawResultStorageBackendS3 RawResultStorageBackend = "s3"
+)
+
+// S3StorageSettings configures the bucket the raw results are uploaded to.
+// The results of a scan run are stored under
+// <prefix>/<scan name>/<scan index>/, and runs are rotated according to the
+// rotation policy just like with a PersistentVolume.
+type S3StorageSettings struct {
+	// URL of the S3-compatible endpoint, e.g. https://s3.us-east-1.amazonaws.com.
+	// The bucket is addressed path-style.
+	Endpoint string `json:"endpoint"`
+	// Name of the bucket, which must already exist.
+	Bucket string `json:"bucket"`
+	// Region used to sign the requests. Defaults to us-east-1.
+	// +kubebuilder:default=us-east-1
+	Region string `json:"region,omitempty"`
+	// Prefix prepended to the keys of the stored results.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+	// Name of a Secret in the operator's namespace holding the credentials
+	// in the `AWS_ACCESS_KEY_ID` and `AWS_SECRET_ACCESS_KEY` keys, as
+	// created for ObjectBucketClaims.
+	CredentialsSecretName string `json:"credentialsSecretName"`
+	// Name of a Secret in the operator's namespace whose `ca.crt` key is
+	// used to verify the endpoint instead of the system's trust store.
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+}
+
+// GetBackend returns the raw result storage backend, defaulting to pvc
+func (s *RawResultStorageSettings) GetBackend() RawResultStorageBackend {
+	if s.Backend == "" {
+		return RawResultStorageBackendPVC
+	}
+	return s.Backend
+}
+
+// ResultCertificateIssuer is what issues the certificates of the result
+// server and of the result collectors
+type ResultCertificateIssuer string
+
+const (
+	// ResultCertificateIssuerSelfSigned creates a self-signed CA for every
+	// scan
+	ResultCertificateIssuerSelfSigned ResultCertificateIssuer = "selfSigned"
+	// ResultCertificateIssuerCA signs the certificates with a CA stored in
+	// a Secret
+	ResultCertificateIssuerCA ResultCertificateIssuer = "ca"
+	// ResultCertificateIssuerCertManager requests the certificates from a
+	// cert-manager issuer
+	ResultCertificateIssuerCertManager ResultCertificateIssuer = "certManager"
+)
+
+// CertificateKeyAlgorithm is the algorithm of the private keys of the
+// certificates
+type CertificateKeyAlgorithm string
+
+const (
+	CertificateKeyAlgorithmRSA   CertificateKeyAlgorithm = "RSA"
+	CertificateKeyAlgorithmECDSA CertificateKeyAlgorithm = "ECDSA"
+)
+
+// ResultCertificateSettings configures the certificates of the result server
+// and of the result collectors. The certificates are renewed before they
+// expire, and the result server picks up the renewed ones without
+// restarting.
+type ResultCertificateSettings struct {
+	// What issues the certificates. With selfSigned, the operator creates
+	// a CA for every scan. With ca, the certificates are signed by the CA
+	// in caSecretName. With certManager, they're requested from the
+	// cert-manager issuer in issuerRef.
+	// +kubebuilder:validation:Enum=selfSigned;ca;certManager
+	// +kubebuilder:default=selfSigned
+	// +optional
+	Issuer ResultCertificateIssuer `json:"issuer,omitempty"`
+	// Name of a Secret in the operator's namespace holding the certificate
+	// and the private key of the CA in the `tls.crt` and `tls.key` keys.
+	// Required by the ca issuer. The certificates of intermediate CAs, if
+	// any, are read from the `ca.crt` key.
+	// +optional
+	CASecretName string `json:"caSecretName,omitempty"`
+	// The cert-manager Issuer or ClusterIssuer to request the certificates
+	// from. Required by the certManager issuer, which must put the
+	// certificate of the CA in the `ca.crt` key of the Secrets it creates.
+	// +optional
+	IssuerRef *CertManagerIssuerReference `json:"issuerRef,omitempty"`
+	// How long the certificates are valid for. Defaults to 24h.
+	// +optional
+	Duration string `json:"duration,omitempty"`
+	// How long before they expire the certificates are renewed. Defaults
+	// to a third of their duration.
+	// +optional
+	RenewBefore string `json:"renewBefore,omitempty"`
+	// The algorithm of the private keys.
+	// +kubebuilder:validation:Enum=RSA;ECDSA
+	// +kubebuilder:default=RSA
+	// +optional
+	KeyAlgorithm CertificateKeyAlgorithm `json:"keyAlgorithm,omitempty"`
+	// The size of the private keys in bits. Defaults to 2048 for RSA keys,
+	// which must be at least that long, and to 256 for ECDSA keys, which
+	// can be 256, 384 or 521 bits long.
+	// +optional
+	KeySize int `json:"keySize,omitempty"`
+}
+
+// CertManagerIssuerReference refers to a cert-manager issuer
+type CertManagerIssuerReference struct {
+	// The name of the issuer. An Issuer must be in the operator's
+	// namespace.
+	Name string `json:"name"`
+	// The kind of the issuer, Issuer or ClusterIssuer.
+	// +kubebuilder:default=Issuer
+	// +optional
+	Kind string `json:"kind,omitempty"`
+	// The API group of the issuer, for external issuers.
+	// +kubebuilder:default=cert-manager.io
+	// +optional
+	Group string `json:"group,omitempty"`
+}
+
+// GetCertificates returns the certificate settings of the result server,
+// with their defaults if none are set
+func (s *RawResultStorageSettings) GetCertificates() *ResultCertificateSettings {
+	if s.Certificates == nil {
+		return &ResultCertificateSettings{}
+	}
+	return s.Certificates
+}
+
+// GetIssuer returns what issues the certificates
+func (s *ResultCertificateSettings) GetIssuer() ResultCertificateIssuer {
+	if s.Issuer == "" {
+		return ResultCertificateIssuerSelfSigned
+	}
+	return s.Issuer
+}
+
+// GetKeyAlgorithm returns the algorithm of the private keys
+func (s *ResultCertificateSettings) GetKeyAlgorithm() CertificateKeyAlgorithm {
+	if s.KeyAlgorithm == "" {
+		return CertificateKeyAlgorithmRSA
+	}
+	return s.KeyAlgorithm
 }
 
 // ComplianceScanSettings groups together settings of a ComplianceScan
@@ -227,6 +412,131 @@ type ComplianceScanSettings struct {
 	// MaxRetryOnTimeout is the maximum number of times the scan will be retried if it times out.
 	// +kubebuilder:default=3
 	MaxRetryOnTimeout int `json:"maxRetryOnTimeout,omitempty"`
+
+	// ResultForwarding configures sending the ComplianceCheckResults and
+	// ComplianceRemediations of a scan to a system outside of the cluster.
+	// Results are still stored as CRs regardless of this setting.
+	// +optional
+	ResultForwarding *ResultForwardingSettings `json:"resultForwarding,omitempty"`
+	// Specifies how many failing checks of a scan are exposed as
+	// compliance_operator_compliance_scan_failing_check metrics, with a
+	// series per control of their rule. If there are more failing checks,
+	// the most severe ones are exposed. Defaults to 0, which disables these
+	// metrics.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1000
+	// +optional
+	FailingCheckMetricsLimit int `json:"failingCheckMetricsLimit,omitempty"`
+	// Limits how many nodes a node scan runs on at the same time. The scan
+	// rolls through the nodes, starting the scan of another node whenever
+	// the scan of one finishes, and tracks the progress of every node in
+	// its status. Defaults to 0, which scans all the nodes at once.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxConcurrentNodes int `json:"maxConcurrentNodes,omitempty"`
+}
+
+// ResultForwardingProvider is the implementation used to forward results
+type ResultForwardingProvider string
+
+const (
+	// ResultForwardingProviderHTTP POSTs batches of results as JSON
+	// to an HTTP(S) endpoint
+	ResultForwardingProviderHTTP ResultForwardingProvider = "http"
+	// ResultForwardingProviderSyslog sends every result as a syslog
+	// message
+	ResultForwardingProviderSyslog ResultForwardingProvider = "syslog"
+)
+
+// SyslogProtocol is the transport used to deliver syslog messages
+type SyslogProtocol string
+
+const (
+	SyslogProtocolTCP SyslogProtocol = "tcp"
+	SyslogProtocolTLS SyslogProtocol = "tls"
+	SyslogProtocolUDP SyslogProtocol = "udp"
+)
+
+// SyslogFormat is the format of the syslog messages
+type SyslogFormat string
+
+const (
+	// SyslogFormatRFC5424 sends the result as structured data of an
+	// RFC 5424 message
+	SyslogFormatRFC5424 SyslogFormat = "rfc5424"
+	// SyslogFormatCEF sends the result as an ArcSight Common Event Format
+	// event in the message part of an RFC 5424 message
+	SyslogFormatCEF SyslogFormat = "cef"
+)
+
+// ResultForwardingSettings defines where and how the results of a scan
+// are forwarded
+type ResultForwardingSettings struct {
+	// The implementation to use for forwarding.
+	// +kubebuilder:validation:Enum=http;syslog
+	Provider ResultForwardingProvider `json:"provider"`
+	// Settings for the http provider.
+	// +optional
+	HTTP *HTTPForwardingSettings `json:"http,omitempty"`
+	// Settings for the syslog provider.
+	// +optional
+	Syslog *SyslogForwardingSettings `json:"syslog,omitempty"`
+	// Additional data that will be sent along with every forwarded
+	// result, e.g. the name of the cluster.
+	// +optional
+	ExtraMetadata map[string]string `json:"extraMetadata,omitempty"`
+}
+
+// HTTPForwardingSettings configures the http result forwarding provider
+type HTTPForwardingSettings struct {
+	// Endpoint URL that the results will be POSTed to.
+	Endpoint string `json:"endpoint"`
+	// Specifies how many results and remediations are sent in a
+	// single request. Defaults to 50.
+	// +kubebuilder:default=50
+	// +kubebuilder:validation:Minimum=1
+	BatchSize int `json:"batchSize,omitempty"`
+	// Specifies how many times a failed request is retried, with an
+	// exponential backoff, before giving up. Defaults to 5.
+	// +kubebuilder:default=5
+	// +kubebuilder:validation:Minimum=0
+	MaxRetries int `json:"maxRetries,omitempty"`
+	// Timeout for a single request. Defaults to 30s.
+	// +kubebuilder:default="30s"
+	Timeout string `json:"timeout,omitempty"`
+	// Name of a Secret in the operator's namespace used for mutual TLS.
+	// The client certificate and key are read from the `tls.crt` and
+	// `tls.key` keys. If `ca.crt` is present, it's used to verify the
+	// endpoint instead of the system's trust store.
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+}
+
+// SyslogForwardingSettings configures the syslog result forwarding provider
+type SyslogForwardingSettings struct {
+	// Address of the syslog server in the host:port form.
+	Address string `json:"address"`
+	// The transport used to send the messages. TCP and TLS use octet
+	// counting framing as described in RFC 6587 and RFC 5425.
+	// Defaults to tcp.
+	// +kubebuilder:default=tcp
+	// +kubebuilder:validation:Enum=tcp;tls;udp
+	Protocol SyslogProtocol `json:"protocol,omitempty"`
+	// The format of the messages. Defaults to rfc5424.
+	// +kubebuilder:default=rfc5424
+	// +kubebuilder:validation:Enum=rfc5424;cef
+	Format SyslogFormat `json:"format,omitempty"`
+	// Timeout for connecting to and writing all the results of a scan to
+	// the syslog server. Forwarding stops once it elapses or connecting to
+	// the server failed. Defaults to 30s.
+	// +kubebuilder:default="30s"
+	Timeout string `json:"timeout,omitempty"`
+	// Name of a Secret in the operator's namespace used with the tls
+	// protocol. The client certificate and key are read from the
+	// `tls.crt` and `tls.key` keys. If `ca.crt` is present, it's used to
+	// verify the server instead of the system's trust store.
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
 }
 
 // ComplianceScanSpec defines the desired state of ComplianceScan
@@ -253,6 +563,13 @@ type ComplianceScanSpec struct {
 	// scan, this should match the selector of the MachineConfigPool you want
 	// to apply the remediations to.
 	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Selects the nodes to run the scan on with a label selector, which
+	// unlike the nodeSelector supports set-based requirements. If both are
+	// set, the nodes must match both. Remediations can only be applied if
+	// the selector can be expressed as a set of labels matching the
+	// selector of a MachineConfigPool.
+	// +optional
+	NodeLabelSelector *metav1.LabelSelector `json:"nodeLabelSelector,omitempty"`
 	// Is a reference to a ConfigMap that contains the
 	// tailoring file. It assumes a key called `tailoring.xml` which will
 	// have the tailoring contents.
@@ -290,6 +607,78 @@ type ComplianceScanStatus struct {
 	StartTimestamp *metav1.Time `json:"startTimestamp,omitempty"`
 	// Is the time when the scan was finished
 	EndTimestamp *metav1.Time `json:"endTimestamp,omitempty"`
+	// Summarizes how the results changed compared to the previous scan
+	// run.
+	// +optional
+	ResultDrift *ComplianceScanResultDrift `json:"resultDrift,omitempty"`
+	// The number of failing checks waived by a ComplianceException. Waived
+	// failures don't make the scan NON-COMPLIANT.
+	// +optional
+	WaivedChecks int `json:"waivedChecks,omitempty"`
+	// The number of manual checks attested by a ComplianceAttestation.
+	// Checks attested as failing make the scan NON-COMPLIANT.
+	// +optional
+	AttestedChecks int `json:"attestedChecks,omitempty"`
+	// The progress of the scan on every node, tracked for node scans
+	// limiting the number of nodes scanned concurrently with
+	// maxConcurrentNodes.
+	// +optional
+	Nodes []ComplianceScanNodeStatus `json:"nodes,omitempty"`
+	// The rules and nodes re-evaluated by a rescan of the failed and
+	// inconsistent checks. Not set when the whole profile is scanned.
+	// +optional
+	TargetedRescan *ComplianceScanTargetedRescan `json:"targetedRescan,omitempty"`
+}
+
+// ComplianceScanTargetedRescan describes a rescan limited to some of the
+// rules of the profile. Its results are merged into the existing ones
+// instead of replacing them.
+type ComplianceScanTargetedRescan struct {
+	// The XCCDF IDs of the rules that are rescanned
+	Rules []string `json:"rules"`
+	// The nodes that are rescanned. All the nodes of the scan are rescanned
+	// if empty.
+	// +optional
+	Nodes []string `json:"nodes,omitempty"`
+}
+
+// ComplianceScanNodeStatus is the progress of a node scan on a node
+type ComplianceScanNodeStatus struct {
+	// The name of the node
+	Name string `json:"name"`
+	// The phase of the scan on the node. Nodes are PENDING until their
+	// scan pod is launched, RUNNING until it finishes and DONE afterwards.
+	Phase ComplianceScanStatusPhase `json:"phase"`
+}
+
+// ComplianceScanResultDrift lists the checks whose status changed between
+// two consecutive runs of a scan. Checks that weren't part of the previous
+// run aren't considered.
+type ComplianceScanResultDrift struct {
+	// The index of the scan run the results were compared for.
+	ScanIndex int64 `json:"scanIndex"`
+	// The start time of the scan run the results were compared against.
+	// Not set for the first run of a scan.
+	// +optional
+	PreviousScanTimestamp *metav1.Time `json:"previousScanTimestamp,omitempty"`
+	// The ComplianceCheckResults that are now FAIL but weren't in the
+	// previous run.
+	// +optional
+	NewlyFailing []string `json:"newlyFailing,omitempty"`
+	// The ComplianceCheckResults that are now PASS but weren't in the
+	// previous run.
+	// +optional
+	NewlyPassing []string `json:"newlyPassing,omitempty"`
+	// The ComplianceCheckResults that are now INCONSISTENT but weren't in
+	// the previous run.
+	// +optional
+	NewlyInconsistent []string `json:"newlyInconsistent,omitempty"`
+}
+
+// HasRegressions tells whether any check started failing or became
+// inconsistent
+func (d *ComplianceScanResultDrift) HasRegressions() bool {
+	return len(d.NewlyFailing) > 0 || len(d.NewlyInconsistent) > 0
 }
 
 // StorageReference stores a reference to where certain objects are being stored
@@ -343,6 +732,12 @@ func (cs *ComplianceScan) NeedsRescan() bool {
 	return needsRescan
 }
 
+// NeedsFailedRescan indicates whether a ComplianceScan needs to
+// rescan only the rules that failed or were inconsistent
+func (cs *ComplianceScan) NeedsFailedRescan() bool {
+	return cs.NeedsRescan() && cs.GetAnnotations()[ComplianceScanRescanModeAnnotation] == RescanModeFailed
+}
+
 // NeedsTimeoutRescan indicates whether a ComplianceScan needs to
 // rescan due to timeout
 func (cs *ComplianceScan) NeedsTimeoutRescan() bool {
@@ -398,6 +793,38 @@ func (cs *ComplianceScan) IsStrictNodeScan() bool {
 	return *cs.Spec.StrictNodeScan
 }
 
+// GetNodeSelectorLabels returns the labels selecting the nodes the scan runs
+// on, which is what the MachineConfigPools are matched with. Returns nil if
+// the nodeLabelSelector can't be expressed as a set of labels, e.g. because
+// it uses the NotIn operator.
+func (cs *ComplianceScan) GetNodeSelectorLabels() map[string]string {
+	if cs.Spec.NodeLabelSelector == nil {
+		return cs.Spec.NodeSelector
+	}
+	selectorLabels, err := metav1.LabelSelectorAsMap(cs.Spec.NodeLabelSelector)
+	if err != nil {
+		return nil
+	}
+	for k, v := range cs.Spec.NodeSelector {
+		selectorLabels[k] = v
+	}
+	return selectorLabels
+}
+
+// GetNodeLabelSelector returns the selector of the nodes the scan runs on
+func (cs *ComplianceScan) GetNodeLabelSelector() (labels.Selector, error) {
+	selector := labels.SelectorFromSet(cs.Spec.NodeSelector)
+	if cs.Spec.NodeLabelSelector == nil {
+		return selector, nil
+	}
+	labelSelector, err := metav1.LabelSelectorAsSelector(cs.Spec.NodeLabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	requirements, _ := labelSelector.Requirements()
+	return selector.Add(requirements...), nil
+}
+
 // +kubebuilder:object:root=true
 
 // ComplianceScanList contains a list of ComplianceScan
@@ -430,3 +857,52 @@ func (s *ComplianceScanStatus) SetConditionReady() {
 func (s *ComplianceScanStatus) SetConditionTimeout() {
 	s.Conditions.SetConditionTimeout("scan")
 }
+
+func (s *ComplianceScanStatus) SetConditionForwarded() {
+	s.Conditions.SetConditionForwarded("scan")
+}
+
+func (s *ComplianceScanStatus) SetConditionForwardingFailed(msg string) {
+	s.Conditions.SetConditionForwardingFailed("scan", msg)
+}
+
+// UpdateResultWithChecks recomputes the result of a finished scan from its
+// check results, along with the number of waived and attested checks, and
+// tells whether anything changed. Failures waived by a ComplianceException
+// don't count, while manual checks attested as failing by a
+// ComplianceAttestation do and can't be waived. Since the result OpenSCAP
+// reported doesn't tell why a scan is non-compliant, it's only made
+// compliant when nothing fails and checks are, or were last time, waived or
+// attested.
+func (s *ComplianceScanStatus) UpdateResultWithChecks(checks []ComplianceCheckResult) bool {
+	waived, attested, failing := 0, 0, 0
+	for i := range checks {
+		switch {
+		case checks[i].AttestedStatus() != "":
+			attested++
+			if checks[i].AttestedStatus() == CheckResultFail {
+				failing++
+			}
+		case checks[i].Status != CheckResultFail:
+		case checks[i].IsWaived():
+			waived++
+		default:
+			failing++
+		}
+	}
+
+	result := s.Result
+	overridden := waived+attested+s.WaivedChecks+s.AttestedChecks > 0
+	switch {
+	case result == ResultCompliant && failing > 0:
+		result = ResultNonCompliant
+	case result == ResultNonCompliant && failing == 0 && overridden:
+		result = ResultCompliant
+	}
+
+	if result == s.Result && waived == s.WaivedChecks && attested == s.AttestedChecks {
+		return false
+	}
+	s.Result, s.WaivedChecks, s.AttestedChecks = result, waived, attested
+	return true
+}
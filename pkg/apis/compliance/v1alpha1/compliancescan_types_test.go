@@ -0,0 +1,110 @@
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Testing ComplianceScan API", func() {
+	Context("Computing the scan result from the check results", func() {
+		newCheck := func(name string, status ComplianceCheckStatus) ComplianceCheckResult {
+			return ComplianceCheckResult{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{}},
+				Status:     status,
+			}
+		}
+		waive := func(check ComplianceCheckResult) ComplianceCheckResult {
+			check.Labels[ComplianceCheckResultWaivedLabel] = ""
+			return check
+		}
+		attest := func(check ComplianceCheckResult, outcome ComplianceCheckStatus) ComplianceCheckResult {
+			check.Labels[ComplianceCheckResultAttestedLabel] = string(outcome)
+			return check
+		}
+
+		It("is compliant when all the failures are waived", func() {
+			status := ComplianceScanStatus{Result: ResultNonCompliant}
+			Expect(status.UpdateResultWithChecks([]ComplianceCheckResult{
+				waive(newCheck("a", CheckResultFail)),
+				newCheck("b", CheckResultPass),
+			})).To(BeTrue())
+			Expect(status.Result).To(Equal(ResultCompliant))
+			Expect(status.WaivedChecks).To(Equal(1))
+		})
+
+		It("stays non-compliant when some failures aren't waived", func() {
+			status := ComplianceScanStatus{Result: ResultNonCompliant}
+			status.UpdateResultWithChecks([]ComplianceCheckResult{
+				waive(newCheck("a", CheckResultFail)),
+				newCheck("b", CheckResultFail),
+			})
+			Expect(status.Result).To(Equal(ResultNonCompliant))
+			Expect(status.WaivedChecks).To(Equal(1))
+		})
+
+		It("becomes non-compliant again when a waiver is lifted", func() {
+			status := ComplianceScanStatus{Result: ResultCompliant, WaivedChecks: 1}
+			Expect(status.UpdateResultWithChecks([]ComplianceCheckResult{
+				newCheck("a", CheckResultFail),
+			})).To(BeTrue())
+			Expect(status.Result).To(Equal(ResultNonCompliant))
+			Expect(status.WaivedChecks).To(BeZero())
+		})
+
+		It("is non-compliant when a manual check is attested as failing", func() {
+			status := ComplianceScanStatus{Result: ResultCompliant}
+			status.UpdateResultWithChecks([]ComplianceCheckResult{
+				attest(newCheck("a", CheckResultManual), CheckResultFail),
+				attest(newCheck("b", CheckResultManual), CheckResultPass),
+				newCheck("c", CheckResultPass),
+			})
+			Expect(status.Result).To(Equal(ResultNonCompliant))
+			Expect(status.AttestedChecks).To(Equal(2))
+		})
+
+		It("is compliant again once a failing attestation is gone", func() {
+			status := ComplianceScanStatus{Result: ResultNonCompliant, AttestedChecks: 1}
+			status.UpdateResultWithChecks([]ComplianceCheckResult{
+				newCheck("a", CheckResultManual),
+				newCheck("b", CheckResultPass),
+			})
+			Expect(status.Result).To(Equal(ResultCompliant))
+			Expect(status.AttestedChecks).To(BeZero())
+
+			By("not changing the result of scans without waivers or attestations")
+			status = ComplianceScanStatus{Result: ResultNonCompliant}
+			Expect(status.UpdateResultWithChecks([]ComplianceCheckResult{
+				newCheck("a", CheckResultManual),
+			})).To(BeFalse())
+			Expect(status.Result).To(Equal(ResultNonCompliant))
+		})
+
+		It("accounts for both the waived and the attested checks", func() {
+			status := ComplianceScanStatus{Result: ResultNonCompliant}
+			status.UpdateResultWithChecks([]ComplianceCheckResult{
+				waive(newCheck("a", CheckResultFail)),
+				attest(newCheck("b", CheckResultManual), CheckResultPass),
+			})
+			Expect(status.Result).To(Equal(ResultCompliant))
+			Expect(status.WaivedChecks).To(Equal(1))
+			Expect(status.AttestedChecks).To(Equal(1))
+
+			By("not letting exceptions waive failing attestations")
+			status.UpdateResultWithChecks([]ComplianceCheckResult{
+				waive(newCheck("a", CheckResultFail)),
+				waive(attest(newCheck("b", CheckResultManual), CheckResultFail)),
+			})
+			Expect(status.Result).To(Equal(ResultNonCompliant))
+			Expect(status.WaivedChecks).To(Equal(1))
+		})
+
+		It("doesn't change other results", func() {
+			status := ComplianceScanStatus{Result: ResultInconsistent}
+			status.UpdateResultWithChecks([]ComplianceCheckResult{
+				waive(newCheck("a", CheckResultFail)),
+			})
+			Expect(status.Result).To(Equal(ResultInconsistent))
+		})
+	})
+})
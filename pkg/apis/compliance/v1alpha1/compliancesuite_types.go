@@ -1,8 +1,11 @@
 package v1alpha1
 
 import (
+	"fmt"
 	"reflect"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -86,6 +89,87 @@ type ComplianceSuiteSettings struct {
 	// defaulting to False.
 	// +kubebuilder:default=false
 	Suspend bool `json:"suspend,omitempty"`
+	// Defines whether the results of the suite should be exported as NIST
+	// OSCAL Assessment Results and a Component Definition. The OSCAL
+	// documents are stored in a ConfigMap named after the suite.
+	GenerateOSCAL bool `json:"generateOSCAL,omitempty"`
+	// Defines how many users need to sign off the remediations, in their
+	// RemediationApproval, before they're applied. A remediation needs the
+	// highest number of approvals among the rules matching it.
+	// +listType=atomic
+	// +optional
+	RemediationApprovals []RemediationApprovalRule `json:"remediationApprovals,omitempty"`
+	// Restricts when remediations are applied automatically, and when the
+	// MachineConfigPools paused while applying them are unpaused, to the
+	// given recurring windows. Outside of them, that work is deferred and
+	// shown in the suite status. Remediations are applied at any time if
+	// unset.
+	// +listType=atomic
+	// +optional
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=Sunday;Monday;Tuesday;Wednesday;Thursday;Friday;Saturday
+type Weekday string
+
+// MaintenanceWindow is a recurring period of time during which remediations
+// can be applied and nodes rebooted
+type MaintenanceWindow struct {
+	// When the window opens, in cronjob format. Exactly one of schedule and
+	// days must be set. The schedule can't set a time zone, timeZone is used
+	// instead.
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+	// The days of the week the window opens on, at startTime. Exactly one
+	// of schedule and days must be set.
+	// +listType=atomic
+	// +optional
+	Days []Weekday `json:"days,omitempty"`
+	// The time of the day the window opens at on the given days, as HH:MM.
+	// Defaults to midnight.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	// +optional
+	StartTime string `json:"startTime,omitempty"`
+	// How long the window stays open once it opens, e.g. 4h
+	Duration metav1.Duration `json:"duration"`
+	// The IANA time zone the window is given in, e.g. Europe/Prague.
+	// Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// RemediationApprovalRule defines the approvals needed by the remediations
+// matching a disruption and a severity
+type RemediationApprovalRule struct {
+	// The disruption of the remediations the rule applies to, as given by
+	// the content, e.g. "high". Any disruption matches if unset.
+	// +optional
+	Disruption string `json:"disruption,omitempty"`
+	// The severity of the rules whose remediations the rule applies to.
+	// Any severity matches if unset.
+	// +optional
+	Severity ComplianceCheckResultSeverity `json:"severity,omitempty"`
+	// The number of distinct users that need to sign off the remediations
+	// +kubebuilder:validation:Minimum=1
+	Approvals int `json:"approvals"`
+}
+
+// RequiredRemediationApprovals returns the number of approvals needed by a
+// remediation with the given disruption and severity, zero if none
+func (s *ComplianceSuiteSettings) RequiredRemediationApprovals(disruption string, severity ComplianceCheckResultSeverity) int {
+	required := 0
+	for _, rule := range s.RemediationApprovals {
+		if rule.Disruption != "" && rule.Disruption != disruption {
+			continue
+		}
+		if rule.Severity != "" && rule.Severity != severity {
+			continue
+		}
+		if rule.Approvals > required {
+			required = rule.Approvals
+		}
+	}
+	return required
 }
 
 // ComplianceSuiteSpec defines the desired state of ComplianceSuite
@@ -107,6 +191,24 @@ type ComplianceSuiteStatus struct {
 	ErrorMessage string                        `json:"errorMessage,omitempty"`
 	// +optional
 	Conditions Conditions `json:"conditions,omitempty"`
+	// The remediation work waiting for the next maintenance window
+	// +optional
+	DeferredRemediations *DeferredRemediations `json:"deferredRemediations,omitempty"`
+}
+
+// DeferredRemediations describes what the suite will do once the next
+// maintenance window opens
+type DeferredRemediations struct {
+	// When the next maintenance window opens
+	NextWindow metav1.Time `json:"nextWindow"`
+	// The remediations that will be applied
+	// +listType=atomic
+	// +optional
+	Remediations []string `json:"remediations,omitempty"`
+	// The MachineConfigPools that will be unpaused
+	// +listType=atomic
+	// +optional
+	MachineConfigPools []string `json:"machineConfigPools,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -247,3 +349,20 @@ func (s *ComplianceSuiteStatus) SetConditionsProcessing() {
 func (s *ComplianceSuiteStatus) SetConditionReady() {
 	s.Conditions.SetConditionReady("suite")
 }
+
+// SetDeferredRemediations records the remediation work deferred until the
+// next maintenance window, or that nothing is deferred if nil
+func (s *ComplianceSuiteStatus) SetDeferredRemediations(deferred *DeferredRemediations) {
+	s.DeferredRemediations = deferred
+	if deferred == nil {
+		s.Conditions.RemoveCondition("RemediationsDeferred")
+		return
+	}
+	s.Conditions.SetCondition(Condition{
+		Type:   "RemediationsDeferred",
+		Status: corev1.ConditionTrue,
+		Reason: "OutsideMaintenanceWindow",
+		Message: fmt.Sprintf("Applying %d remediations and unpausing %d pools is deferred until the maintenance window opens at %s",
+			len(deferred.Remediations), len(deferred.MachineConfigPools), deferred.NextWindow.UTC().Format(time.RFC3339)),
+	})
+}
@@ -222,6 +222,24 @@ func (conditions *Conditions) SetConditionReady(what string) {
 	})
 }
 
+func (conditions *Conditions) SetConditionForwarded(what string) {
+	conditions.SetCondition(Condition{
+		Type:    "ResultsForwarded",
+		Status:  corev1.ConditionTrue,
+		Reason:  "Delivered",
+		Message: fmt.Sprintf("The results of the compliance %s were forwarded", what),
+	})
+}
+
+func (conditions *Conditions) SetConditionForwardingFailed(what, msg string) {
+	conditions.SetCondition(Condition{
+		Type:    "ResultsForwarded",
+		Status:  corev1.ConditionFalse,
+		Reason:  "DeliveryFailed",
+		Message: fmt.Sprintf("Forwarding the results of the compliance %s failed: %s", what, msg),
+	})
+}
+
 func (conditions *Conditions) SetConditionTimeout(what string) {
 	conditions.SetCondition(Condition{
 		Type:    "Ready",
@@ -16,6 +16,10 @@ const ProfileBundleOwnerLabel = "compliance.openshift.io/profile-bundle"
 // ProfileImageDigestAnnotation is the parsed out digest of the content image
 const ProfileImageDigestAnnotation = "compliance.openshift.io/image-digest"
 
+// ContentPublicKeySecretKey is the key of the Secret referenced by a
+// ProfileBundle that holds the public key to verify the content with
+const ContentPublicKeySecretKey = "public.pem"
+
 // DataStreamStatusType is the type for the data stream status
 type DataStreamStatusType string
 
@@ -35,6 +39,40 @@ type ProfileBundleSpec struct {
 	ContentImage string `json:"contentImage"`
 	// Is the path for the file in the image that contains the content for this bundle.
 	ContentFile string `json:"contentFile"`
+	// Verifies the integrity of the content file before it's parsed. If the
+	// verification fails, the data stream is marked as INVALID.
+	// +optional
+	ContentVerification *ContentVerification `json:"contentVerification,omitempty"`
+}
+
+// ContentVerification defines how to verify the content file of a
+// ProfileBundle. Both the checksum and the signature are verified if set.
+type ContentVerification struct {
+	// The expected sha256 checksum of the content file, hex-encoded
+	// +kubebuilder:validation:Pattern=`^[a-fA-F0-9]{64}$`
+	// +optional
+	SHA256 string `json:"sha256,omitempty"`
+	// The name of a Secret in the operator's namespace holding the
+	// PEM-encoded public key that the detached signature of the content
+	// file is verified with, under the public.pem key. RSA and ECDSA keys
+	// are supported, the signature being made over the sha256 digest of the
+	// content file.
+	// +optional
+	PublicKeySecret string `json:"publicKeySecret,omitempty"`
+	// Is the path for the file in the image that contains the detached
+	// signature of the content file, either raw or base64-encoded.
+	// Defaults to the content file with a ".sig" suffix.
+	// +optional
+	SignatureFile string `json:"signatureFile,omitempty"`
+}
+
+// GetSignatureFile returns the path of the detached signature of the
+// content file in the content image
+func (v *ContentVerification) GetSignatureFile(contentFile string) string {
+	if v.SignatureFile != "" {
+		return v.SignatureFile
+	}
+	return contentFile + ".sig"
 }
 
 // Defines the observed state of ProfileBundle
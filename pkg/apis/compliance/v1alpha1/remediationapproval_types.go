@@ -0,0 +1,68 @@
+package v1alpha1
+
+import (
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RemediationSignOff records a user approving a remediation
+type RemediationSignOff struct {
+	// The user approving the remediation. The admission webhook only lets
+	// users add, change or remove their own sign-off.
+	// +kubebuilder:validation:MinLength=1
+	SignedOffBy string `json:"signedOffBy"`
+	// Why the remediation is approved, or a reference to the change request
+	// +optional
+	Comment string `json:"comment,omitempty"`
+}
+
+// RemediationApprovalSpec holds the sign-offs of a remediation
+type RemediationApprovalSpec struct {
+	// The users approving the remediation, at most one entry per user
+	// +listType=atomic
+	// +optional
+	SignOffs []RemediationSignOff `json:"signOffs,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RemediationApproval approves applying the ComplianceRemediation with the
+// same name. Remediations that the suite requires approvals for are only
+// applied once enough users have signed them off.
+// +kubebuilder:resource:path=remediationapprovals,scope=Namespaced,shortName=rap
+type RemediationApproval struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec RemediationApprovalSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RemediationApprovalList contains a list of RemediationApproval
+type RemediationApprovalList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RemediationApproval `json:"items"`
+}
+
+// GetApprovers returns the distinct users that signed off the remediation,
+// sorted by name
+func (a *RemediationApproval) GetApprovers() []string {
+	seen := make(map[string]bool)
+	approvers := []string{}
+	for _, signOff := range a.Spec.SignOffs {
+		if signOff.SignedOffBy == "" || seen[signOff.SignedOffBy] {
+			continue
+		}
+		seen[signOff.SignedOffBy] = true
+		approvers = append(approvers, signOff.SignedOffBy)
+	}
+	sort.Strings(approvers)
+	return approvers
+}
+
+func init() {
+	SchemeBuilder.Register(&RemediationApproval{}, &RemediationApprovalList{})
+}
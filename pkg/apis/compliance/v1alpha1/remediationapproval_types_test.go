@@ -0,0 +1,31 @@
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Testing RemediationApproval API", func() {
+	newApproval := func(signOffs ...RemediationSignOff) *RemediationApproval {
+		return &RemediationApproval{Spec: RemediationApprovalSpec{SignOffs: signOffs}}
+	}
+
+	It("returns the distinct approvers", func() {
+		approval := newApproval(RemediationSignOff{SignedOffBy: "bob"}, RemediationSignOff{SignedOffBy: "alice"}, RemediationSignOff{SignedOffBy: "bob"})
+		Expect(approval.GetApprovers()).To(Equal([]string{"alice", "bob"}))
+	})
+
+	It("requires the highest number of approvals among the matching rules", func() {
+		settings := &ComplianceSuiteSettings{
+			RemediationApprovals: []RemediationApprovalRule{
+				{Disruption: "high", Approvals: 2},
+				{Severity: CheckResultSeverityHigh, Approvals: 1},
+				{Disruption: "high", Severity: CheckResultSeverityHigh, Approvals: 3},
+			},
+		}
+		Expect(settings.RequiredRemediationApprovals("high", CheckResultSeverityHigh)).To(Equal(3))
+		Expect(settings.RequiredRemediationApprovals("high", CheckResultSeverityLow)).To(Equal(2))
+		Expect(settings.RequiredRemediationApprovals("low", CheckResultSeverityHigh)).To(Equal(1))
+		Expect(settings.RequiredRemediationApprovals("", CheckResultSeverityMedium)).To(Equal(0))
+	})
+})
@@ -21,6 +21,11 @@ const RuleVariableAnnotationKey = "compliance.openshift.io/rule-variable"
 // RuleProfileAnnotationKey is the annotation used to store which profiles are using a particular rule
 const RuleProfileAnnotationKey = "compliance.openshift.io/profiles"
 
+// RuleControlAnnotationPrefix is the prefix of the annotations listing the
+// controls of a compliance standard that a rule maps to. The name of the
+// standard follows the prefix and the controls are separated by semicolons.
+const RuleControlAnnotationPrefix = "control.compliance.openshift.io/"
+
 const (
 	CheckTypePlatform = "Platform"
 	CheckTypeNode     = "Node"
@@ -1,6 +1,8 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -33,6 +35,88 @@ type ScanSetting struct {
 	// Note that tolerations must still be configured for
 	// the opeartor to appropriately schedule scans.
 	Roles []string `json:"roles,omitempty"`
+	// Overrides some of the scan settings for the node scans of a role,
+	// e.g. to give the scans of the master nodes a longer timeout than the
+	// ones of the worker nodes. The keys must be listed in roles.
+	// +optional
+	RoleOverrides map[string]ScanSettingRoleOverride `json:"roleOverrides,omitempty"`
+	// The list of label selectors to apply node-specific checks to, in
+	// addition to the roles. Unlike the roles, these allow targeting nodes
+	// by any label, e.g. by zone or hardware class, using set-based
+	// requirements. A separate scan is created for every selector.
+	//
+	// Remediations of these scans can only be applied if the selector
+	// matches the node selector of a MachineConfigPool.
+	// +optional
+	NodeSelectors []metav1.LabelSelector `json:"nodeSelectors,omitempty"`
+}
+
+// ScanSettingRoleOverride holds the scan settings that differ for the node
+// scans of a role. The settings that aren't set are inherited from the
+// ScanSetting.
+type ScanSettingRoleOverride struct {
+	// Enable debug logging of workloads and OpenSCAP
+	// +optional
+	Debug *bool `json:"debug,omitempty"`
+	// Specifies tolerations needed for the scan to run on the nodes of the
+	// role. Replaces the tolerations of the ScanSetting.
+	// +optional
+	ScanTolerations []corev1.Toleration `json:"scanTolerations,omitempty"`
+	// Defines whether the scan should proceed if we're not able to
+	// scan all the nodes of the role or not.
+	// +optional
+	StrictNodeScan *bool `json:"strictNodeScan,omitempty"`
+	// Defines the PriorityClass to use for launching scan related pods
+	// +optional
+	PriorityClass string `json:"priorityClass,omitempty"`
+	// The resource limits of the scan pods. The limits are merged with the
+	// ones of the ScanSetting, by resource.
+	// +optional
+	ScanLimits map[corev1.ResourceName]resource.Quantity `json:"scanLimits,omitempty"`
+	// Timeout is the maximum amount of time the scan can run
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
+	// MaxRetryOnTimeout is the maximum number of times the scan will be
+	// retried if it times out
+	// +optional
+	MaxRetryOnTimeout *int `json:"maxRetryOnTimeout,omitempty"`
+}
+
+// ApplyTo overrides the given scan settings with the ones set in the
+// override
+func (o *ScanSettingRoleOverride) ApplyTo(settings *ComplianceScanSettings) {
+	if o.Debug != nil {
+		settings.Debug = *o.Debug
+	}
+	if o.ScanTolerations != nil {
+		settings.ScanTolerations = make([]corev1.Toleration, len(o.ScanTolerations))
+		for i := range o.ScanTolerations {
+			o.ScanTolerations[i].DeepCopyInto(&settings.ScanTolerations[i])
+		}
+	}
+	if o.StrictNodeScan != nil {
+		strict := *o.StrictNodeScan
+		settings.StrictNodeScan = &strict
+	}
+	if o.PriorityClass != "" {
+		settings.PriorityClass = o.PriorityClass
+	}
+	if len(o.ScanLimits) > 0 {
+		limits := make(map[corev1.ResourceName]resource.Quantity, len(settings.ScanLimits)+len(o.ScanLimits))
+		for name, quantity := range settings.ScanLimits {
+			limits[name] = quantity.DeepCopy()
+		}
+		for name, quantity := range o.ScanLimits {
+			limits[name] = quantity.DeepCopy()
+		}
+		settings.ScanLimits = limits
+	}
+	if o.Timeout != "" {
+		settings.Timeout = o.Timeout
+	}
+	if o.MaxRetryOnTimeout != nil {
+		settings.MaxRetryOnTimeout = *o.MaxRetryOnTimeout
+	}
 }
 
 // +kubebuilder:object:root=true
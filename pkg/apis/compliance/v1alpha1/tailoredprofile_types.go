@@ -63,6 +63,11 @@ type TailoredProfileSpec struct {
 	// +optional
 	// +nullable
 	SetValues []VariableValueSpec `json:"setValues,omitempty"`
+	// The name of a kubernetes.io/tls Secret in the same namespace whose
+	// private key is used to sign the generated tailoring with an XML
+	// signature. The certificate, if any, is embedded in the signature.
+	// +optional
+	SigningKeySecret string `json:"signingKeySecret,omitempty"`
 }
 
 // TailoredProfileState defines the state fo the tailored profile
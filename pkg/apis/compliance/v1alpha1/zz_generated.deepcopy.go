@@ -23,9 +23,120 @@ package v1alpha1
 import (
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertManagerIssuerReference) DeepCopyInto(out *CertManagerIssuerReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerIssuerReference.
+func (in *CertManagerIssuerReference) DeepCopy() *CertManagerIssuerReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CertManagerIssuerReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceAttestation) DeepCopyInto(out *ComplianceAttestation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceAttestation.
+func (in *ComplianceAttestation) DeepCopy() *ComplianceAttestation {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceAttestation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ComplianceAttestation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceAttestationList) DeepCopyInto(out *ComplianceAttestationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ComplianceAttestation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceAttestationList.
+func (in *ComplianceAttestationList) DeepCopy() *ComplianceAttestationList {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceAttestationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ComplianceAttestationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceAttestationSpec) DeepCopyInto(out *ComplianceAttestationSpec) {
+	*out = *in
+	if in.EvidenceLinks != nil {
+		in, out := &in.EvidenceLinks, &out.EvidenceLinks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.ExpirationDate.DeepCopyInto(&out.ExpirationDate)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceAttestationSpec.
+func (in *ComplianceAttestationSpec) DeepCopy() *ComplianceAttestationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceAttestationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceAttestationStatus) DeepCopyInto(out *ComplianceAttestationStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceAttestationStatus.
+func (in *ComplianceAttestationStatus) DeepCopy() *ComplianceAttestationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceAttestationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComplianceCheckResult) DeepCopyInto(out *ComplianceCheckResult) {
 	*out = *in
@@ -94,7 +205,7 @@ func (in *ComplianceCheckResultList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ComplianceRemediation) DeepCopyInto(out *ComplianceRemediation) {
+func (in *ComplianceException) DeepCopyInto(out *ComplianceException) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -102,6 +213,103 @@ func (in *ComplianceRemediation) DeepCopyInto(out *ComplianceRemediation) {
 	out.Status = in.Status
 }
 
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceException.
+func (in *ComplianceException) DeepCopy() *ComplianceException {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceException)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ComplianceException) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceExceptionList) DeepCopyInto(out *ComplianceExceptionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ComplianceException, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceExceptionList.
+func (in *ComplianceExceptionList) DeepCopy() *ComplianceExceptionList {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceExceptionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ComplianceExceptionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceExceptionSpec) DeepCopyInto(out *ComplianceExceptionSpec) {
+	*out = *in
+	in.ExpirationDate.DeepCopyInto(&out.ExpirationDate)
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceExceptionSpec.
+func (in *ComplianceExceptionSpec) DeepCopy() *ComplianceExceptionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceExceptionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceExceptionStatus) DeepCopyInto(out *ComplianceExceptionStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceExceptionStatus.
+func (in *ComplianceExceptionStatus) DeepCopy() *ComplianceExceptionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceExceptionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceRemediation) DeepCopyInto(out *ComplianceRemediation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceRemediation.
 func (in *ComplianceRemediation) DeepCopy() *ComplianceRemediation {
 	if in == nil {
@@ -120,6 +328,42 @@ func (in *ComplianceRemediation) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceRemediationApprovals) DeepCopyInto(out *ComplianceRemediationApprovals) {
+	*out = *in
+	if in.SignedOffBy != nil {
+		in, out := &in.SignedOffBy, &out.SignedOffBy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceRemediationApprovals.
+func (in *ComplianceRemediationApprovals) DeepCopy() *ComplianceRemediationApprovals {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceRemediationApprovals)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceRemediationDryRun) DeepCopyInto(out *ComplianceRemediationDryRun) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceRemediationDryRun.
+func (in *ComplianceRemediationDryRun) DeepCopy() *ComplianceRemediationDryRun {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceRemediationDryRun)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComplianceRemediationList) DeepCopyInto(out *ComplianceRemediationList) {
 	*out = *in
@@ -171,10 +415,42 @@ func (in *ComplianceRemediationPayload) DeepCopy() *ComplianceRemediationPayload
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceRemediationRevert) DeepCopyInto(out *ComplianceRemediationRevert) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceRemediationRevert.
+func (in *ComplianceRemediationRevert) DeepCopy() *ComplianceRemediationRevert {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceRemediationRevert)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceRemediationRevision) DeepCopyInto(out *ComplianceRemediationRevision) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceRemediationRevision.
+func (in *ComplianceRemediationRevision) DeepCopy() *ComplianceRemediationRevision {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceRemediationRevision)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComplianceRemediationSpec) DeepCopyInto(out *ComplianceRemediationSpec) {
 	*out = *in
-	out.ComplianceRemediationSpecMeta = in.ComplianceRemediationSpecMeta
+	in.ComplianceRemediationSpecMeta.DeepCopyInto(&out.ComplianceRemediationSpecMeta)
 	in.Current.DeepCopyInto(&out.Current)
 	in.Outdated.DeepCopyInto(&out.Outdated)
 }
@@ -192,6 +468,11 @@ func (in *ComplianceRemediationSpec) DeepCopy() *ComplianceRemediationSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComplianceRemediationSpecMeta) DeepCopyInto(out *ComplianceRemediationSpecMeta) {
 	*out = *in
+	if in.RevisionHistoryLimit != nil {
+		in, out := &in.RevisionHistoryLimit, &out.RevisionHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceRemediationSpecMeta.
@@ -207,6 +488,28 @@ func (in *ComplianceRemediationSpecMeta) DeepCopy() *ComplianceRemediationSpecMe
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComplianceRemediationStatus) DeepCopyInto(out *ComplianceRemediationStatus) {
 	*out = *in
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(ComplianceRemediationDryRun)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Approvals != nil {
+		in, out := &in.Approvals, &out.Approvals
+		*out = new(ComplianceRemediationApprovals)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Revisions != nil {
+		in, out := &in.Revisions, &out.Revisions
+		*out = make([]ComplianceRemediationRevision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastRevert != nil {
+		in, out := &in.LastRevert, &out.LastRevert
+		*out = new(ComplianceRemediationRevert)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceRemediationStatus.
@@ -278,6 +581,55 @@ func (in *ComplianceScanList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceScanNodeStatus) DeepCopyInto(out *ComplianceScanNodeStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceScanNodeStatus.
+func (in *ComplianceScanNodeStatus) DeepCopy() *ComplianceScanNodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceScanNodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceScanResultDrift) DeepCopyInto(out *ComplianceScanResultDrift) {
+	*out = *in
+	if in.PreviousScanTimestamp != nil {
+		in, out := &in.PreviousScanTimestamp, &out.PreviousScanTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.NewlyFailing != nil {
+		in, out := &in.NewlyFailing, &out.NewlyFailing
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NewlyPassing != nil {
+		in, out := &in.NewlyPassing, &out.NewlyPassing
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NewlyInconsistent != nil {
+		in, out := &in.NewlyInconsistent, &out.NewlyInconsistent
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceScanResultDrift.
+func (in *ComplianceScanResultDrift) DeepCopy() *ComplianceScanResultDrift {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceScanResultDrift)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComplianceScanSettings) DeepCopyInto(out *ComplianceScanSettings) {
 	*out = *in
@@ -301,6 +653,11 @@ func (in *ComplianceScanSettings) DeepCopyInto(out *ComplianceScanSettings) {
 			(*out)[key] = val.DeepCopy()
 		}
 	}
+	if in.ResultForwarding != nil {
+		in, out := &in.ResultForwarding, &out.ResultForwarding
+		*out = new(ResultForwardingSettings)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceScanSettings.
@@ -323,6 +680,11 @@ func (in *ComplianceScanSpec) DeepCopyInto(out *ComplianceScanSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.NodeLabelSelector != nil {
+		in, out := &in.NodeLabelSelector, &out.NodeLabelSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.TailoringConfigMap != nil {
 		in, out := &in.TailoringConfigMap, &out.TailoringConfigMap
 		*out = new(TailoringConfigMapRef)
@@ -376,6 +738,21 @@ func (in *ComplianceScanStatus) DeepCopyInto(out *ComplianceScanStatus) {
 		in, out := &in.EndTimestamp, &out.EndTimestamp
 		*out = (*in).DeepCopy()
 	}
+	if in.ResultDrift != nil {
+		in, out := &in.ResultDrift, &out.ResultDrift
+		*out = new(ComplianceScanResultDrift)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]ComplianceScanNodeStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.TargetedRescan != nil {
+		in, out := &in.TargetedRescan, &out.TargetedRescan
+		*out = new(ComplianceScanTargetedRescan)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceScanStatus.
@@ -394,12 +771,37 @@ func (in *ComplianceScanStatusWrapper) DeepCopyInto(out *ComplianceScanStatusWra
 	in.ComplianceScanStatus.DeepCopyInto(&out.ComplianceScanStatus)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceScanStatusWrapper.
-func (in *ComplianceScanStatusWrapper) DeepCopy() *ComplianceScanStatusWrapper {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceScanStatusWrapper.
+func (in *ComplianceScanStatusWrapper) DeepCopy() *ComplianceScanStatusWrapper {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceScanStatusWrapper)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceScanTargetedRescan) DeepCopyInto(out *ComplianceScanTargetedRescan) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceScanTargetedRescan.
+func (in *ComplianceScanTargetedRescan) DeepCopy() *ComplianceScanTargetedRescan {
 	if in == nil {
 		return nil
 	}
-	out := new(ComplianceScanStatusWrapper)
+	out := new(ComplianceScanTargetedRescan)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -466,6 +868,18 @@ func (in *ComplianceSuiteList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComplianceSuiteSettings) DeepCopyInto(out *ComplianceSuiteSettings) {
 	*out = *in
+	if in.RemediationApprovals != nil {
+		in, out := &in.RemediationApprovals, &out.RemediationApprovals
+		*out = make([]RemediationApprovalRule, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MaintenanceWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceSuiteSettings.
@@ -481,7 +895,7 @@ func (in *ComplianceSuiteSettings) DeepCopy() *ComplianceSuiteSettings {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComplianceSuiteSpec) DeepCopyInto(out *ComplianceSuiteSpec) {
 	*out = *in
-	out.ComplianceSuiteSettings = in.ComplianceSuiteSettings
+	in.ComplianceSuiteSettings.DeepCopyInto(&out.ComplianceSuiteSettings)
 	if in.Scans != nil {
 		in, out := &in.Scans, &out.Scans
 		*out = make([]ComplianceScanSpecWrapper, len(*in))
@@ -518,6 +932,11 @@ func (in *ComplianceSuiteStatus) DeepCopyInto(out *ComplianceSuiteStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.DeferredRemediations != nil {
+		in, out := &in.DeferredRemediations, &out.DeferredRemediations
+		*out = new(DeferredRemediations)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceSuiteStatus.
@@ -561,6 +980,47 @@ func (in Conditions) DeepCopy() Conditions {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContentVerification) DeepCopyInto(out *ContentVerification) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContentVerification.
+func (in *ContentVerification) DeepCopy() *ContentVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(ContentVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeferredRemediations) DeepCopyInto(out *DeferredRemediations) {
+	*out = *in
+	in.NextWindow.DeepCopyInto(&out.NextWindow)
+	if in.Remediations != nil {
+		in, out := &in.Remediations, &out.Remediations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MachineConfigPools != nil {
+		in, out := &in.MachineConfigPools, &out.MachineConfigPools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeferredRemediations.
+func (in *DeferredRemediations) DeepCopy() *DeferredRemediations {
+	if in == nil {
+		return nil
+	}
+	out := new(DeferredRemediations)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FixDefinition) DeepCopyInto(out *FixDefinition) {
 	*out = *in
@@ -580,6 +1040,42 @@ func (in *FixDefinition) DeepCopy() *FixDefinition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPForwardingSettings) DeepCopyInto(out *HTTPForwardingSettings) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPForwardingSettings.
+func (in *HTTPForwardingSettings) DeepCopy() *HTTPForwardingSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPForwardingSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]Weekday, len(*in))
+		copy(*out, *in)
+	}
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NamedObjectReference) DeepCopyInto(out *NamedObjectReference) {
 	*out = *in
@@ -641,7 +1137,7 @@ func (in *ProfileBundle) DeepCopyInto(out *ProfileBundle) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -698,6 +1194,11 @@ func (in *ProfileBundleList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProfileBundleSpec) DeepCopyInto(out *ProfileBundleSpec) {
 	*out = *in
+	if in.ContentVerification != nil {
+		in, out := &in.ContentVerification, &out.ContentVerification
+		*out = new(ContentVerification)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileBundleSpec.
@@ -816,6 +1317,16 @@ func (in *RawResultStorageSettings) DeepCopyInto(out *RawResultStorageSettings)
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3StorageSettings)
+		**out = **in
+	}
+	if in.Certificates != nil {
+		in, out := &in.Certificates, &out.Certificates
+		*out = new(ResultCertificateSettings)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RawResultStorageSettings.
@@ -828,6 +1339,99 @@ func (in *RawResultStorageSettings) DeepCopy() *RawResultStorageSettings {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationApproval) DeepCopyInto(out *RemediationApproval) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationApproval.
+func (in *RemediationApproval) DeepCopy() *RemediationApproval {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationApproval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RemediationApproval) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationApprovalList) DeepCopyInto(out *RemediationApprovalList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RemediationApproval, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationApprovalList.
+func (in *RemediationApprovalList) DeepCopy() *RemediationApprovalList {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationApprovalList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RemediationApprovalList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationApprovalRule) DeepCopyInto(out *RemediationApprovalRule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationApprovalRule.
+func (in *RemediationApprovalRule) DeepCopy() *RemediationApprovalRule {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationApprovalRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationApprovalSpec) DeepCopyInto(out *RemediationApprovalSpec) {
+	*out = *in
+	if in.SignOffs != nil {
+		in, out := &in.SignOffs, &out.SignOffs
+		*out = make([]RemediationSignOff, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationApprovalSpec.
+func (in *RemediationApprovalSpec) DeepCopy() *RemediationApprovalSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationApprovalSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RemediationObjectDependencyReference) DeepCopyInto(out *RemediationObjectDependencyReference) {
 	*out = *in
@@ -844,6 +1448,73 @@ func (in *RemediationObjectDependencyReference) DeepCopy() *RemediationObjectDep
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationSignOff) DeepCopyInto(out *RemediationSignOff) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationSignOff.
+func (in *RemediationSignOff) DeepCopy() *RemediationSignOff {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationSignOff)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResultCertificateSettings) DeepCopyInto(out *ResultCertificateSettings) {
+	*out = *in
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(CertManagerIssuerReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResultCertificateSettings.
+func (in *ResultCertificateSettings) DeepCopy() *ResultCertificateSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(ResultCertificateSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResultForwardingSettings) DeepCopyInto(out *ResultForwardingSettings) {
+	*out = *in
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(HTTPForwardingSettings)
+		**out = **in
+	}
+	if in.Syslog != nil {
+		in, out := &in.Syslog, &out.Syslog
+		*out = new(SyslogForwardingSettings)
+		**out = **in
+	}
+	if in.ExtraMetadata != nil {
+		in, out := &in.ExtraMetadata, &out.ExtraMetadata
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResultForwardingSettings.
+func (in *ResultForwardingSettings) DeepCopy() *ResultForwardingSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(ResultForwardingSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Rule) DeepCopyInto(out *Rule) {
 	*out = *in
@@ -939,18 +1610,47 @@ func (in *RuleReferenceSpec) DeepCopy() *RuleReferenceSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3StorageSettings) DeepCopyInto(out *S3StorageSettings) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3StorageSettings.
+func (in *S3StorageSettings) DeepCopy() *S3StorageSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(S3StorageSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScanSetting) DeepCopyInto(out *ScanSetting) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.ComplianceSuiteSettings = in.ComplianceSuiteSettings
+	in.ComplianceSuiteSettings.DeepCopyInto(&out.ComplianceSuiteSettings)
 	in.ComplianceScanSettings.DeepCopyInto(&out.ComplianceScanSettings)
 	if in.Roles != nil {
 		in, out := &in.Roles, &out.Roles
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.RoleOverrides != nil {
+		in, out := &in.RoleOverrides, &out.RoleOverrides
+		*out = make(map[string]ScanSettingRoleOverride, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.NodeSelectors != nil {
+		in, out := &in.NodeSelectors, &out.NodeSelectors
+		*out = make([]metav1.LabelSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanSetting.
@@ -1114,6 +1814,50 @@ func (in *ScanSettingList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScanSettingRoleOverride) DeepCopyInto(out *ScanSettingRoleOverride) {
+	*out = *in
+	if in.Debug != nil {
+		in, out := &in.Debug, &out.Debug
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ScanTolerations != nil {
+		in, out := &in.ScanTolerations, &out.ScanTolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StrictNodeScan != nil {
+		in, out := &in.StrictNodeScan, &out.StrictNodeScan
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ScanLimits != nil {
+		in, out := &in.ScanLimits, &out.ScanLimits
+		*out = make(map[v1.ResourceName]resource.Quantity, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.MaxRetryOnTimeout != nil {
+		in, out := &in.MaxRetryOnTimeout, &out.MaxRetryOnTimeout
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanSettingRoleOverride.
+func (in *ScanSettingRoleOverride) DeepCopy() *ScanSettingRoleOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(ScanSettingRoleOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageReference) DeepCopyInto(out *StorageReference) {
 	*out = *in
@@ -1129,6 +1873,21 @@ func (in *StorageReference) DeepCopy() *StorageReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyslogForwardingSettings) DeepCopyInto(out *SyslogForwardingSettings) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyslogForwardingSettings.
+func (in *SyslogForwardingSettings) DeepCopy() *SyslogForwardingSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(SyslogForwardingSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TailoredProfile) DeepCopyInto(out *TailoredProfile) {
 	*out = *in
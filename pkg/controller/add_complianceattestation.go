@@ -0,0 +1,12 @@
+package controller
+
+import (
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/complianceattestation"
+)
+
+func init() {
+	// AddToManagerFuncs is a list of functions to create controllers and add them to a manager.
+	AddToManagerFuncs = append(AddToManagerFuncs, complianceattestation.Add)
+	// AddWebhooksToManagerFuncs is a list of functions to add admission webhooks to a manager.
+	AddWebhooksToManagerFuncs = append(AddWebhooksToManagerFuncs, complianceattestation.AddWebhooks)
+}
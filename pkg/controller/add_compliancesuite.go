@@ -7,4 +7,6 @@ import (
 func init() {
 	// AddToManagerFuncs is a list of functions to create controllers and add them to a manager.
 	AddToManagerFuncs = append(AddToManagerFuncs, compliancesuite.Add)
+	// AddWebhooksToManagerFuncs is a list of functions to add admission webhooks to a manager.
+	AddWebhooksToManagerFuncs = append(AddWebhooksToManagerFuncs, compliancesuite.AddWebhooks)
 }
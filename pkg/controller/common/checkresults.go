@@ -0,0 +1,73 @@
+package common
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+// CheckResultMarker returns a copy of the check result with its marks, such
+// as the waiver of a ComplianceException or the outcome of a
+// ComplianceAttestation, brought up to date, or nil if they already are. The
+// scan is nil if it doesn't exist anymore.
+type CheckResultMarker func(check *compv1alpha1.ComplianceCheckResult, scan *compv1alpha1.ComplianceScan) *compv1alpha1.ComplianceCheckResult
+
+// MarkCheckResults updates the marks of the check results of a namespace and
+// recomputes the result of the finished scans whose check results changed.
+// The ComplianceException and ComplianceAttestation controllers both go
+// through it, so that the result accounts for the waived and the attested
+// checks no matter which of them updates it.
+func MarkCheckResults(c client.Client, namespace string, mark CheckResultMarker, logger logr.Logger) error {
+	scanList := &compv1alpha1.ComplianceScanList{}
+	if err := c.List(context.TODO(), scanList, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+	scans := map[string]*compv1alpha1.ComplianceScan{}
+	for i := range scanList.Items {
+		scans[scanList.Items[i].Name] = &scanList.Items[i]
+	}
+
+	checkList := &compv1alpha1.ComplianceCheckResultList{}
+	if err := c.List(context.TODO(), checkList, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+
+	changedScans := map[string]bool{}
+	checksByScan := map[string][]compv1alpha1.ComplianceCheckResult{}
+	for i := range checkList.Items {
+		check := &checkList.Items[i]
+		scanName := check.Labels[compv1alpha1.ComplianceScanLabel]
+
+		if updated := mark(check, scans[scanName]); updated != nil {
+			if err := c.Update(context.TODO(), updated); err != nil {
+				return err
+			}
+			check = updated
+			changedScans[scanName] = true
+		}
+		checksByScan[scanName] = append(checksByScan[scanName], *check)
+	}
+
+	for scanName := range changedScans {
+		scan := scans[scanName]
+		if scan == nil || scan.Status.Phase != compv1alpha1.PhaseDone {
+			// Scans that are still running account for the marked checks
+			// once they're done
+			continue
+		}
+		scanCopy := scan.DeepCopy()
+		if !scanCopy.Status.UpdateResultWithChecks(checksByScan[scanName]) {
+			continue
+		}
+		logger.Info("Updating the scan result for the waived and attested checks", "ComplianceScan.Name", scanName,
+			"result", scanCopy.Status.Result, "waivedChecks", scanCopy.Status.WaivedChecks,
+			"attestedChecks", scanCopy.Status.AttestedChecks)
+		if err := c.Status().Update(context.TODO(), scanCopy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
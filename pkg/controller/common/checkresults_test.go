@@ -0,0 +1,90 @@
+package common
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/zapr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common/checkresultstest"
+)
+
+var _ = Describe("Marking check results", func() {
+	var (
+		logger logr.Logger
+		c      client.Client
+		scan   *compv1alpha1.ComplianceScan
+	)
+
+	getScan := func() *compv1alpha1.ComplianceScan {
+		found := &compv1alpha1.ComplianceScan{}
+		key := types.NamespacedName{Name: scan.Name, Namespace: scan.Namespace}
+		Expect(c.Get(context.TODO(), key, found)).To(Succeed())
+		return found
+	}
+	// mark sets or removes a label on the named check result
+	mark := func(name, label string, set bool) CheckResultMarker {
+		return func(check *compv1alpha1.ComplianceCheckResult, _ *compv1alpha1.ComplianceScan) *compv1alpha1.ComplianceCheckResult {
+			if _, ok := check.Labels[label]; check.Name != name || ok == set {
+				return nil
+			}
+			checkCopy := check.DeepCopy()
+			if set {
+				checkCopy.Labels[label] = string(compv1alpha1.CheckResultPass)
+			} else {
+				delete(checkCopy.Labels, label)
+			}
+			return checkCopy
+		}
+	}
+
+	BeforeEach(func() {
+		logger = zapr.NewLogger(zap.NewNop())
+		scan = checkresultstest.NewScan("platform-scan", compv1alpha1.ScanTypePlatform, compv1alpha1.ResultNonCompliant)
+		var err error
+		c, err = checkresultstest.NewClient(scan,
+			checkresultstest.NewCheck(scan, "platform-scan-failing", compv1alpha1.CheckResultFail),
+			checkresultstest.NewCheck(scan, "platform-scan-manual", compv1alpha1.CheckResultManual))
+		Expect(err).To(BeNil())
+	})
+
+	It("accounts for the checks marked by the other controller", func() {
+		By("waiving the failing check")
+		Expect(MarkCheckResults(c, scan.Namespace,
+			mark("platform-scan-failing", compv1alpha1.ComplianceCheckResultWaivedLabel, true), logger)).To(Succeed())
+		status := getScan().Status
+		Expect(status.Result).To(Equal(compv1alpha1.ResultCompliant))
+		Expect(status.WaivedChecks).To(Equal(1))
+
+		By("attesting the manual check")
+		Expect(MarkCheckResults(c, scan.Namespace,
+			mark("platform-scan-manual", compv1alpha1.ComplianceCheckResultAttestedLabel, true), logger)).To(Succeed())
+		status = getScan().Status
+		Expect(status.Result).To(Equal(compv1alpha1.ResultCompliant))
+		Expect(status.WaivedChecks).To(Equal(1))
+		Expect(status.AttestedChecks).To(Equal(1))
+
+		By("lifting the waiver")
+		Expect(MarkCheckResults(c, scan.Namespace,
+			mark("platform-scan-failing", compv1alpha1.ComplianceCheckResultWaivedLabel, false), logger)).To(Succeed())
+		status = getScan().Status
+		Expect(status.Result).To(Equal(compv1alpha1.ResultNonCompliant))
+		Expect(status.WaivedChecks).To(BeZero())
+		Expect(status.AttestedChecks).To(Equal(1))
+	})
+
+	It("doesn't update the result of scans that are still running", func() {
+		scan.Status.Phase = compv1alpha1.PhaseAggregating
+		Expect(c.Status().Update(context.TODO(), scan)).To(Succeed())
+
+		Expect(MarkCheckResults(c, scan.Namespace,
+			mark("platform-scan-failing", compv1alpha1.ComplianceCheckResultWaivedLabel, true), logger)).To(Succeed())
+		Expect(getScan().Status.Result).To(Equal(compv1alpha1.ResultNonCompliant))
+	})
+})
@@ -0,0 +1,65 @@
+// Package checkresultstest provides the finished scans, check results and
+// fake client the tests of the controllers marking check results run
+// against.
+package checkresultstest
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/ComplianceAsCode/compliance-operator/pkg/apis"
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+// Namespace is the namespace of the objects
+const Namespace = "test-ns"
+
+// NewScan returns a finished scan with the given result
+func NewScan(name string, scanType compv1alpha1.ComplianceScanType, result compv1alpha1.ComplianceScanStatusResult) *compv1alpha1.ComplianceScan {
+	return &compv1alpha1.ComplianceScan{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: Namespace,
+		},
+		Spec: compv1alpha1.ComplianceScanSpec{
+			ScanType: scanType,
+		},
+		Status: compv1alpha1.ComplianceScanStatus{
+			Phase:  compv1alpha1.PhaseDone,
+			Result: result,
+		},
+	}
+}
+
+// NewCheck returns a check result of the given scan
+func NewCheck(scan *compv1alpha1.ComplianceScan, name string, status compv1alpha1.ComplianceCheckStatus) *compv1alpha1.ComplianceCheckResult {
+	return &compv1alpha1.ComplianceCheckResult{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: Namespace,
+			Labels: map[string]string{
+				compv1alpha1.ComplianceScanLabel: scan.Name,
+			},
+			Annotations: map[string]string{},
+		},
+		Status: status,
+	}
+}
+
+// NewClient returns a fake client serving the given objects, along with
+// the status subresource of the scans, exceptions and attestations
+func NewClient(objs ...runtime.Object) (client.Client, error) {
+	cscheme := scheme.Scheme
+	if err := apis.AddToScheme(cscheme); err != nil {
+		return nil, err
+	}
+	return fake.NewClientBuilder().
+		WithScheme(cscheme).
+		WithRuntimeObjects(objs...).
+		WithStatusSubresource(&compv1alpha1.ComplianceScan{}, &compv1alpha1.ComplianceException{},
+			&compv1alpha1.ComplianceAttestation{}).
+		Build(), nil
+}
@@ -0,0 +1,39 @@
+package complianceattestation
+
+import (
+	"context"
+
+	"github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type checkResultMapper struct {
+	client.Client
+}
+
+// Map enqueues the attestations referring to a check result, so that
+// attestations created before their check result get validated again
+func (t *checkResultMapper) Map(ctx context.Context, obj client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+
+	attList := v1alpha1.ComplianceAttestationList{}
+	err := t.List(ctx, &attList, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		return requests
+	}
+
+	for _, att := range attList.Items {
+		if att.Spec.CheckResult != obj.GetName() {
+			continue
+		}
+		objKey := types.NamespacedName{
+			Name:      att.GetName(),
+			Namespace: att.GetNamespace(),
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: objKey})
+	}
+
+	return requests
+}
@@ -0,0 +1,210 @@
+package complianceattestation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+var log = logf.Log.WithName("complianceattestationctrl")
+
+// Add creates a new ComplianceAttestation Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager, met *metrics.Metrics, _ utils.CtlplaneSchedulingInfo, _ *kubernetes.Clientset) error {
+	return add(mgr, newReconciler(mgr, met))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager, met *metrics.Metrics) reconcile.Reconciler {
+	return &ReconcileComplianceAttestation{Client: mgr.GetClient(), Scheme: mgr.GetScheme(), Metrics: met,
+		Recorder: common.NewSafeRecorder("complianceattestation-controller", mgr)}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	checkMapper := &checkResultMapper{mgr.GetClient()}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("complianceattestation-controller").
+		For(&compv1alpha1.ComplianceAttestation{}).
+		Watches(&compv1alpha1.ComplianceCheckResult{}, handler.EnqueueRequestsFromMapFunc(checkMapper.Map)).
+		Complete(r)
+}
+
+// blank assignment to verify that ReconcileComplianceAttestation implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileComplianceAttestation{}
+
+// ReconcileComplianceAttestation reconciles a ComplianceAttestation object
+type ReconcileComplianceAttestation struct {
+	// This Client, initialized using mgr.Client() above, is a split Client
+	// that reads objects from the cache and writes to the apiserver
+	Client   client.Client
+	Scheme   *runtime.Scheme
+	Metrics  *metrics.Metrics
+	Recorder *common.SafeRecorder
+}
+
+// Reconcile validates a ComplianceAttestation and marks the manual check
+// result it attests. Since a check may be attested several times, the
+// attestations of the whole namespace are recomputed every time. The
+// attestation is requeued for the time it expires, so that the check is
+// MANUAL again.
+func (r *ReconcileComplianceAttestation) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling ComplianceAttestation")
+
+	instance := &compv1alpha1.ComplianceAttestation{}
+	err := r.Client.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Return and don't requeue
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+
+	if instance.GetDeletionTimestamp() == nil {
+		if !common.ContainsFinalizer(instance.GetFinalizers(), compv1alpha1.ComplianceAttestationFinalizer) {
+			attCopy := instance.DeepCopy()
+			attCopy.SetFinalizers(append(attCopy.GetFinalizers(), compv1alpha1.ComplianceAttestationFinalizer))
+			return reconcile.Result{}, r.Client.Update(context.TODO(), attCopy)
+		}
+		if err := r.updateAttestationStatus(instance, reqLogger); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	if err := r.applyAttestations(instance, reqLogger); err != nil {
+		reqLogger.Error(err, "Cannot apply the attestations to the check results")
+		return reconcile.Result{}, err
+	}
+
+	if instance.GetDeletionTimestamp() != nil {
+		if !common.ContainsFinalizer(instance.GetFinalizers(), compv1alpha1.ComplianceAttestationFinalizer) {
+			return reconcile.Result{}, nil
+		}
+		reqLogger.Info("The attestation is being deleted")
+		attCopy := instance.DeepCopy()
+		attCopy.SetFinalizers(common.RemoveFinalizer(attCopy.GetFinalizers(), compv1alpha1.ComplianceAttestationFinalizer))
+		return reconcile.Result{}, r.Client.Update(context.TODO(), attCopy)
+	}
+
+	if instance.Status.Phase == compv1alpha1.AttestationPhaseActive {
+		// Come back when the attestation expires
+		return reconcile.Result{RequeueAfter: time.Until(instance.Spec.ExpirationDate.Time) + time.Second}, nil
+	}
+	return reconcile.Result{}, nil
+}
+
+// updateAttestationStatus makes sure that the attestation refers to a manual
+// check result. Attestations may be created before the scan that creates the
+// check result ran, in which case they're pending.
+func (r *ReconcileComplianceAttestation) updateAttestationStatus(instance *compv1alpha1.ComplianceAttestation, logger logr.Logger) error {
+	status := compv1alpha1.ComplianceAttestationStatus{}
+
+	check := &compv1alpha1.ComplianceCheckResult{}
+	key := types.NamespacedName{Name: instance.Spec.CheckResult, Namespace: instance.Namespace}
+	if err := r.Client.Get(context.TODO(), key, check); kerrors.IsNotFound(err) {
+		status.Phase = compv1alpha1.AttestationPhasePending
+		status.ErrorMessage = fmt.Sprintf("ComplianceCheckResult %s not found", instance.Spec.CheckResult)
+	} else if err != nil {
+		return err
+	} else if check.Status != compv1alpha1.CheckResultManual {
+		status.Phase = compv1alpha1.AttestationPhaseInvalid
+		status.ErrorMessage = fmt.Sprintf("ComplianceCheckResult %s is %s, only MANUAL checks can be attested",
+			check.Name, check.Status)
+	} else {
+		status.Phase = compv1alpha1.AttestationPhaseActive
+		if !time.Now().Before(instance.Spec.ExpirationDate.Time) {
+			status.Phase = compv1alpha1.AttestationPhaseExpired
+		}
+	}
+
+	if status == instance.Status {
+		return nil
+	}
+	logger.Info("Updating the attestation status", "phase", status.Phase)
+	if instance.Status.Phase == compv1alpha1.AttestationPhaseActive && status.Phase == compv1alpha1.AttestationPhaseExpired {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, "AttestationExpired",
+			"The attestation expired, the check it attested is MANUAL again")
+	}
+	instance.Status = status
+	return r.Client.Status().Update(context.TODO(), instance)
+}
+
+// applyAttestations marks the manual check results of the namespace with the
+// outcome of their active attestation and unmarks the ones that aren't
+// attested anymore, updating the result of their scans accordingly.
+func (r *ReconcileComplianceAttestation) applyAttestations(instance *compv1alpha1.ComplianceAttestation, logger logr.Logger) error {
+	attestations := &compv1alpha1.ComplianceAttestationList{}
+	if err := r.Client.List(context.TODO(), attestations, client.InNamespace(instance.Namespace)); err != nil {
+		return err
+	}
+	// The cache might not have caught up with the status we just updated
+	for i := range attestations.Items {
+		if attestations.Items[i].Name == instance.Name {
+			attestations.Items[i] = *instance
+		}
+	}
+
+	now := time.Now()
+	return common.MarkCheckResults(r.Client, instance.Namespace, func(check *compv1alpha1.ComplianceCheckResult, _ *compv1alpha1.ComplianceScan) *compv1alpha1.ComplianceCheckResult {
+		attestation := compv1alpha1.FindAttestation(attestations.Items, check, now)
+		currentlyAttestedBy := ""
+		if check.AttestedStatus() != "" {
+			currentlyAttestedBy = check.Annotations[compv1alpha1.ComplianceCheckResultAttestedByAnnotation]
+		}
+
+		attestedBy, outcome := "", compv1alpha1.ComplianceCheckStatus("")
+		if attestation != nil {
+			attestedBy, outcome = attestation.Name, attestation.Spec.Outcome
+		}
+		if attestedBy == currentlyAttestedBy && outcome == check.AttestedStatus() {
+			return nil
+		}
+		return setCheckResultAttestation(check, attestation, logger)
+	}, logger)
+}
+
+// setCheckResultAttestation returns a copy of the check result marked with
+// the outcome of the given attestation, or without the mark if the
+// attestation is nil
+func setCheckResultAttestation(check *compv1alpha1.ComplianceCheckResult, attestation *compv1alpha1.ComplianceAttestation, logger logr.Logger) *compv1alpha1.ComplianceCheckResult {
+	checkCopy := check.DeepCopy()
+	if attestation == nil {
+		logger.Info("The check result is no longer attested", "ComplianceCheckResult.Name", check.Name)
+		delete(checkCopy.Labels, compv1alpha1.ComplianceCheckResultAttestedLabel)
+		delete(checkCopy.Annotations, compv1alpha1.ComplianceCheckResultAttestedByAnnotation)
+	} else {
+		logger.Info("Attesting the check result", "ComplianceCheckResult.Name", check.Name,
+			"ComplianceAttestation.Name", attestation.Name, "outcome", attestation.Spec.Outcome)
+		if checkCopy.Labels == nil {
+			checkCopy.Labels = map[string]string{}
+		}
+		if checkCopy.Annotations == nil {
+			checkCopy.Annotations = map[string]string{}
+		}
+		checkCopy.Labels[compv1alpha1.ComplianceCheckResultAttestedLabel] = string(attestation.Spec.Outcome)
+		checkCopy.Annotations[compv1alpha1.ComplianceCheckResultAttestedByAnnotation] = attestation.Name
+	}
+	return checkCopy
+}
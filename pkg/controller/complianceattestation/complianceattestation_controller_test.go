@@ -0,0 +1,224 @@
+package complianceattestation
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common/checkresultstest"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics/metricsfakes"
+)
+
+var _ = Describe("ComplianceAttestationController", func() {
+	const manualCheck = "platform-scan-audit-log-forwarding-enabled"
+
+	var (
+		ctx         = context.Background()
+		namespace   = checkresultstest.Namespace
+		r           *ReconcileComplianceAttestation
+		attestation *compv1alpha1.ComplianceAttestation
+		scan        *compv1alpha1.ComplianceScan
+		objs        []runtime.Object
+	)
+
+	newCheck := func(name string, status compv1alpha1.ComplianceCheckStatus) *compv1alpha1.ComplianceCheckResult {
+		return checkresultstest.NewCheck(scan, name, status)
+	}
+	getCheck := func(name string) *compv1alpha1.ComplianceCheckResult {
+		check := &compv1alpha1.ComplianceCheckResult{}
+		Expect(r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, check)).To(Succeed())
+		return check
+	}
+	getScan := func() *compv1alpha1.ComplianceScan {
+		scan := &compv1alpha1.ComplianceScan{}
+		Expect(r.Client.Get(ctx, types.NamespacedName{Name: "platform-scan", Namespace: namespace}, scan)).To(Succeed())
+		return scan
+	}
+	getAttestation := func() *compv1alpha1.ComplianceAttestation {
+		found := &compv1alpha1.ComplianceAttestation{}
+		Expect(r.Client.Get(ctx, types.NamespacedName{Name: attestation.Name, Namespace: namespace}, found)).To(Succeed())
+		return found
+	}
+	doReconcile := func() (reconcile.Result, error) {
+		return r.Reconcile(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: attestation.Name, Namespace: namespace},
+		})
+	}
+	attested := func(outcome compv1alpha1.ComplianceCheckStatus) *compv1alpha1.ComplianceCheckResult {
+		check := newCheck(manualCheck, compv1alpha1.CheckResultManual)
+		check.Labels[compv1alpha1.ComplianceCheckResultAttestedLabel] = string(outcome)
+		check.Annotations[compv1alpha1.ComplianceCheckResultAttestedByAnnotation] = attestation.Name
+		return check
+	}
+
+	BeforeEach(func() {
+		attestation = &compv1alpha1.ComplianceAttestation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "log-forwarding-review",
+				Namespace:  namespace,
+				Finalizers: []string{compv1alpha1.ComplianceAttestationFinalizer},
+			},
+			Spec: compv1alpha1.ComplianceAttestationSpec{
+				CheckResult:    manualCheck,
+				Outcome:        compv1alpha1.CheckResultFail,
+				Evidence:       "The audit logs aren't forwarded to the SIEM",
+				EvidenceLinks:  []string{"https://tickets.example.com/SEC-42"},
+				Attester:       "auditor@example.com",
+				ExpirationDate: metav1.Time{Time: time.Now().Add(time.Hour)},
+			},
+		}
+		scan = checkresultstest.NewScan("platform-scan", compv1alpha1.ScanTypePlatform, compv1alpha1.ResultCompliant)
+		objs = []runtime.Object{
+			scan,
+			newCheck(manualCheck, compv1alpha1.CheckResultManual),
+			newCheck("platform-scan-passing", compv1alpha1.CheckResultPass),
+		}
+	})
+
+	JustBeforeEach(func() {
+		client, err := checkresultstest.NewClient(append(objs, attestation)...)
+		Expect(err).To(BeNil())
+		r = &ReconcileComplianceAttestation{
+			Client:   client,
+			Scheme:   client.Scheme(),
+			Metrics:  metrics.NewMetrics(&metricsfakes.FakeImpl{}),
+			Recorder: &common.SafeRecorder{},
+		}
+	})
+
+	Context("with a new attestation", func() {
+		BeforeEach(func() {
+			attestation.Finalizers = nil
+		})
+
+		It("adds the finalizer", func() {
+			_, err := doReconcile()
+			Expect(err).To(BeNil())
+			Expect(getAttestation().Finalizers).To(ContainElement(compv1alpha1.ComplianceAttestationFinalizer))
+		})
+	})
+
+	Context("with an active attestation of a failing manual check", func() {
+		It("attests the check and makes the scan non-compliant", func() {
+			result, err := doReconcile()
+			Expect(err).To(BeNil())
+			Expect(result.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+
+			Expect(getAttestation().Status.Phase).To(Equal(compv1alpha1.AttestationPhaseActive))
+
+			check := getCheck(manualCheck)
+			Expect(check.AttestedStatus()).To(Equal(compv1alpha1.CheckResultFail))
+			Expect(check.Annotations).To(HaveKeyWithValue(compv1alpha1.ComplianceCheckResultAttestedByAnnotation, attestation.Name))
+			Expect(getCheck("platform-scan-passing").Labels).ToNot(HaveKey(compv1alpha1.ComplianceCheckResultAttestedLabel))
+
+			scan := getScan()
+			Expect(scan.Status.Result).To(Equal(compv1alpha1.ResultNonCompliant))
+			Expect(scan.Status.AttestedChecks).To(Equal(1))
+		})
+	})
+
+	Context("with an attestation whose outcome changed", func() {
+		BeforeEach(func() {
+			attestation.Spec.Outcome = compv1alpha1.CheckResultPass
+			attestation.Status.Phase = compv1alpha1.AttestationPhaseActive
+			objs[1] = attested(compv1alpha1.CheckResultFail)
+
+			scan.Status.Result = compv1alpha1.ResultNonCompliant
+			scan.Status.AttestedChecks = 1
+		})
+
+		It("updates the check and makes the scan compliant again", func() {
+			_, err := doReconcile()
+			Expect(err).To(BeNil())
+			Expect(getCheck(manualCheck).AttestedStatus()).To(Equal(compv1alpha1.CheckResultPass))
+
+			scan := getScan()
+			Expect(scan.Status.Result).To(Equal(compv1alpha1.ResultCompliant))
+			Expect(scan.Status.AttestedChecks).To(Equal(1))
+		})
+	})
+
+	Context("with an attestation that expired", func() {
+		BeforeEach(func() {
+			attestation.Spec.ExpirationDate = metav1.Time{Time: time.Now().Add(-time.Minute)}
+			attestation.Status.Phase = compv1alpha1.AttestationPhaseActive
+			objs[1] = attested(compv1alpha1.CheckResultFail)
+
+			scan.Status.Result = compv1alpha1.ResultNonCompliant
+			scan.Status.AttestedChecks = 1
+		})
+
+		It("makes the check manual again", func() {
+			result, err := doReconcile()
+			Expect(err).To(BeNil())
+			Expect(result.RequeueAfter).To(BeZero())
+
+			Expect(getAttestation().Status.Phase).To(Equal(compv1alpha1.AttestationPhaseExpired))
+			Expect(getCheck(manualCheck).AttestedStatus()).To(BeEmpty())
+
+			scan := getScan()
+			Expect(scan.Status.Result).To(Equal(compv1alpha1.ResultCompliant))
+			Expect(scan.Status.AttestedChecks).To(BeZero())
+		})
+	})
+
+	Context("with an attestation of a check that doesn't exist yet", func() {
+		BeforeEach(func() {
+			attestation.Spec.CheckResult = "platform-scan-not-scanned-yet"
+		})
+
+		It("marks the attestation as pending", func() {
+			_, err := doReconcile()
+			Expect(err).To(BeNil())
+
+			found := getAttestation()
+			Expect(found.Status.Phase).To(Equal(compv1alpha1.AttestationPhasePending))
+			Expect(found.Status.ErrorMessage).To(ContainSubstring("platform-scan-not-scanned-yet"))
+			Expect(getScan().Status.Result).To(Equal(compv1alpha1.ResultCompliant))
+		})
+	})
+
+	Context("with an attestation of a check that isn't manual", func() {
+		BeforeEach(func() {
+			attestation.Spec.CheckResult = "platform-scan-passing"
+		})
+
+		It("marks the attestation as invalid", func() {
+			_, err := doReconcile()
+			Expect(err).To(BeNil())
+
+			found := getAttestation()
+			Expect(found.Status.Phase).To(Equal(compv1alpha1.AttestationPhaseInvalid))
+			Expect(found.Status.ErrorMessage).To(ContainSubstring("only MANUAL checks can be attested"))
+			Expect(getCheck("platform-scan-passing").Labels).ToNot(HaveKey(compv1alpha1.ComplianceCheckResultAttestedLabel))
+		})
+	})
+
+	Context("with an attestation being deleted", func() {
+		BeforeEach(func() {
+			attestation.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+			attestation.Status.Phase = compv1alpha1.AttestationPhaseActive
+			objs[1] = attested(compv1alpha1.CheckResultFail)
+		})
+
+		It("makes the check manual again and removes the finalizer", func() {
+			_, err := doReconcile()
+			Expect(err).To(BeNil())
+			Expect(getCheck(manualCheck).AttestedStatus()).To(BeEmpty())
+
+			// Without its finalizer, the attestation is gone
+			err = r.Client.Get(ctx, types.NamespacedName{Name: attestation.Name, Namespace: namespace}, &compv1alpha1.ComplianceAttestation{})
+			Expect(kerrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
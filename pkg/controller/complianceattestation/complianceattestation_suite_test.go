@@ -0,0 +1,13 @@
+package complianceattestation
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestComplianceattestation(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Complianceattestation Suite")
+}
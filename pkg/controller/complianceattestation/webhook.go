@@ -0,0 +1,76 @@
+package complianceattestation
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+// AddWebhooks registers the webhooks recording who attested a manual check
+// and keeping it from being changed afterwards
+func AddWebhooks(mgr manager.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&compv1alpha1.ComplianceAttestation{}).
+		WithDefaulter(&attesterDefaulter{}).
+		WithValidator(&attesterValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-compliance-openshift-io-v1alpha1-complianceattestation,mutating=true,failurePolicy=fail,sideEffects=None,groups=compliance.openshift.io,resources=complianceattestations,verbs=create,versions=v1alpha1,name=mcomplianceattestation.compliance.openshift.io,admissionReviewVersions=v1
+
+// attesterDefaulter sets the attester of new attestations to the user
+// creating them, so that it can be trusted
+type attesterDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &attesterDefaulter{}
+
+func (d *attesterDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	attestation, ok := obj.(*compv1alpha1.ComplianceAttestation)
+	if !ok {
+		return fmt.Errorf("expected a ComplianceAttestation but got a %T", obj)
+	}
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	attestation.Spec.Attester = req.UserInfo.Username
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-compliance-openshift-io-v1alpha1-complianceattestation,mutating=false,failurePolicy=fail,sideEffects=None,groups=compliance.openshift.io,resources=complianceattestations,verbs=update,versions=v1alpha1,name=vcomplianceattestation.compliance.openshift.io,admissionReviewVersions=v1
+
+// attesterValidator keeps the attester of an attestation from being changed
+// once it was recorded
+type attesterValidator struct{}
+
+var _ webhook.CustomValidator = &attesterValidator{}
+
+func (v *attesterValidator) ValidateCreate(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *attesterValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldAttestation, ok := oldObj.(*compv1alpha1.ComplianceAttestation)
+	if !ok {
+		return nil, fmt.Errorf("expected a ComplianceAttestation but got a %T", oldObj)
+	}
+	attestation, ok := newObj.(*compv1alpha1.ComplianceAttestation)
+	if !ok {
+		return nil, fmt.Errorf("expected a ComplianceAttestation but got a %T", newObj)
+	}
+	if attestation.Spec.Attester != oldAttestation.Spec.Attester {
+		return nil, fmt.Errorf("the attester %s can't be changed", oldAttestation.Spec.Attester)
+	}
+	return nil, nil
+}
+
+func (v *attesterValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
@@ -0,0 +1,47 @@
+package complianceattestation
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+var _ = Describe("Recording the attester in the admission webhooks", func() {
+	newAttestation := func(attester string) *compv1alpha1.ComplianceAttestation {
+		return &compv1alpha1.ComplianceAttestation{
+			Spec: compv1alpha1.ComplianceAttestationSpec{
+				CheckResult: "platform-scan-audit-log-forwarding-enabled",
+				Outcome:     compv1alpha1.CheckResultPass,
+				Attester:    attester,
+			},
+		}
+	}
+
+	It("sets the attester to the user creating the attestation", func() {
+		ctx := admission.NewContextWithRequest(context.TODO(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				UserInfo: authenticationv1.UserInfo{Username: "alice"},
+			},
+		})
+		attestation := newAttestation("somebody-else")
+		Expect((&attesterDefaulter{}).Default(ctx, attestation)).To(Succeed())
+		Expect(attestation.Spec.Attester).To(Equal("alice"))
+	})
+
+	It("doesn't let the attester change", func() {
+		validator := &attesterValidator{}
+		_, err := validator.ValidateUpdate(context.TODO(), newAttestation("alice"), newAttestation("bob"))
+		Expect(err).To(MatchError(ContainSubstring("the attester alice can't be changed")))
+
+		updated := newAttestation("alice")
+		updated.Spec.Evidence = "Checked the SIEM again"
+		_, err = validator.ValidateUpdate(context.TODO(), newAttestation("alice"), updated)
+		Expect(err).To(BeNil())
+	})
+})
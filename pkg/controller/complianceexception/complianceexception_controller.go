@@ -0,0 +1,216 @@
+package complianceexception
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+var log = logf.Log.WithName("complianceexceptionctrl")
+
+// Add creates a new ComplianceException Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager, met *metrics.Metrics, _ utils.CtlplaneSchedulingInfo, _ *kubernetes.Clientset) error {
+	return add(mgr, newReconciler(mgr, met))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager, met *metrics.Metrics) reconcile.Reconciler {
+	return &ReconcileComplianceException{Client: mgr.GetClient(), Scheme: mgr.GetScheme(), Metrics: met,
+		Recorder: common.NewSafeRecorder("complianceexception-controller", mgr)}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	ruleMapper := &ruleMapper{mgr.GetClient()}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("complianceexception-controller").
+		For(&compv1alpha1.ComplianceException{}).
+		Watches(&compv1alpha1.Rule{}, handler.EnqueueRequestsFromMapFunc(ruleMapper.Map)).
+		Complete(r)
+}
+
+// blank assignment to verify that ReconcileComplianceException implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileComplianceException{}
+
+// ReconcileComplianceException reconciles a ComplianceException object
+type ReconcileComplianceException struct {
+	// This Client, initialized using mgr.Client() above, is a split Client
+	// that reads objects from the cache and writes to the apiserver
+	Client   client.Client
+	Scheme   *runtime.Scheme
+	Metrics  *metrics.Metrics
+	Recorder *common.SafeRecorder
+}
+
+// Reconcile validates a ComplianceException and marks the check results it
+// waives. Since several exceptions may apply to the same check result, the
+// waivers of the whole namespace are recomputed every time. The exception is
+// requeued for the time it expires, so that the checks it waives fail again.
+func (r *ReconcileComplianceException) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling ComplianceException")
+
+	instance := &compv1alpha1.ComplianceException{}
+	err := r.Client.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Return and don't requeue
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+
+	if instance.GetDeletionTimestamp() == nil {
+		if !common.ContainsFinalizer(instance.GetFinalizers(), compv1alpha1.ComplianceExceptionFinalizer) {
+			exCopy := instance.DeepCopy()
+			exCopy.SetFinalizers(append(exCopy.GetFinalizers(), compv1alpha1.ComplianceExceptionFinalizer))
+			return reconcile.Result{}, r.Client.Update(context.TODO(), exCopy)
+		}
+		if err := r.updateExceptionStatus(instance, reqLogger); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	if err := r.applyExceptions(instance, reqLogger); err != nil {
+		reqLogger.Error(err, "Cannot apply the exceptions to the check results")
+		return reconcile.Result{}, err
+	}
+
+	if instance.GetDeletionTimestamp() != nil {
+		if !common.ContainsFinalizer(instance.GetFinalizers(), compv1alpha1.ComplianceExceptionFinalizer) {
+			return reconcile.Result{}, nil
+		}
+		reqLogger.Info("The exception is being deleted")
+		exCopy := instance.DeepCopy()
+		exCopy.SetFinalizers(common.RemoveFinalizer(exCopy.GetFinalizers(), compv1alpha1.ComplianceExceptionFinalizer))
+		return reconcile.Result{}, r.Client.Update(context.TODO(), exCopy)
+	}
+
+	if instance.Status.Phase == compv1alpha1.ExceptionPhaseActive {
+		// Come back when the exception expires
+		return reconcile.Result{RequeueAfter: time.Until(instance.Spec.ExpirationDate.Time) + time.Second}, nil
+	}
+	return reconcile.Result{}, nil
+}
+
+// updateExceptionStatus validates the exception and resolves the rule it
+// refers to
+func (r *ReconcileComplianceException) updateExceptionStatus(instance *compv1alpha1.ComplianceException, logger logr.Logger) error {
+	status := compv1alpha1.ComplianceExceptionStatus{}
+	spec := &instance.Spec
+
+	switch {
+	case spec.Rule == "" && spec.CheckResult == "":
+		status.Phase = compv1alpha1.ExceptionPhaseInvalid
+		status.ErrorMessage = "Either rule or checkResult must be set"
+	case spec.Rule != "" && spec.CheckResult != "":
+		status.Phase = compv1alpha1.ExceptionPhaseInvalid
+		status.ErrorMessage = "Only one of rule and checkResult can be set"
+	case spec.Rule != "":
+		rule := &compv1alpha1.Rule{}
+		key := types.NamespacedName{Name: spec.Rule, Namespace: instance.Namespace}
+		if err := r.Client.Get(context.TODO(), key, rule); kerrors.IsNotFound(err) {
+			status.Phase = compv1alpha1.ExceptionPhaseInvalid
+			status.ErrorMessage = fmt.Sprintf("Rule %s not found", spec.Rule)
+			break
+		} else if err != nil {
+			return err
+		}
+		status.RuleID = rule.Annotations[compv1alpha1.RuleIDAnnotationKey]
+		if status.RuleID == "" {
+			status.RuleID = utils.IDToDNSFriendlyName(rule.ID)
+		}
+	}
+	if status.Phase == "" {
+		status.Phase = compv1alpha1.ExceptionPhaseActive
+		if !time.Now().Before(spec.ExpirationDate.Time) {
+			status.Phase = compv1alpha1.ExceptionPhaseExpired
+		}
+	}
+
+	if status == instance.Status {
+		return nil
+	}
+	logger.Info("Updating the exception status", "phase", status.Phase)
+	if instance.Status.Phase == compv1alpha1.ExceptionPhaseActive && status.Phase == compv1alpha1.ExceptionPhaseExpired {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, "ExceptionExpired",
+			"The exception expired, the checks it waived are failing again")
+	}
+	instance.Status = status
+	return r.Client.Status().Update(context.TODO(), instance)
+}
+
+// applyExceptions marks the failing check results of the namespace that are
+// waived by an active exception and unmarks the ones that aren't anymore,
+// updating the result of their scans accordingly.
+func (r *ReconcileComplianceException) applyExceptions(instance *compv1alpha1.ComplianceException, logger logr.Logger) error {
+	exceptions := &compv1alpha1.ComplianceExceptionList{}
+	if err := r.Client.List(context.TODO(), exceptions, client.InNamespace(instance.Namespace)); err != nil {
+		return err
+	}
+	// The cache might not have caught up with the status we just updated
+	for i := range exceptions.Items {
+		if exceptions.Items[i].Name == instance.Name {
+			exceptions.Items[i] = *instance
+		}
+	}
+
+	now := time.Now()
+	return common.MarkCheckResults(r.Client, instance.Namespace, func(check *compv1alpha1.ComplianceCheckResult, scan *compv1alpha1.ComplianceScan) *compv1alpha1.ComplianceCheckResult {
+		waivedBy := ""
+		if e := compv1alpha1.FindWaivingException(exceptions.Items, check, scan, now); e != nil {
+			waivedBy = e.Name
+		}
+		currentlyWaivedBy := ""
+		if check.IsWaived() {
+			currentlyWaivedBy = check.Annotations[compv1alpha1.ComplianceCheckResultWaivedByAnnotation]
+		}
+		if waivedBy == currentlyWaivedBy {
+			return nil
+		}
+		return setCheckResultWaiver(check, waivedBy, logger)
+	}, logger)
+}
+
+// setCheckResultWaiver returns a copy of the check result marked as waived by
+// the given exception, or without the mark if exceptionName is empty
+func setCheckResultWaiver(check *compv1alpha1.ComplianceCheckResult, exceptionName string, logger logr.Logger) *compv1alpha1.ComplianceCheckResult {
+	checkCopy := check.DeepCopy()
+	if exceptionName == "" {
+		logger.Info("The check result is no longer waived", "ComplianceCheckResult.Name", check.Name)
+		delete(checkCopy.Labels, compv1alpha1.ComplianceCheckResultWaivedLabel)
+		delete(checkCopy.Annotations, compv1alpha1.ComplianceCheckResultWaivedByAnnotation)
+	} else {
+		logger.Info("Waiving the check result", "ComplianceCheckResult.Name", check.Name, "ComplianceException.Name", exceptionName)
+		if checkCopy.Labels == nil {
+			checkCopy.Labels = map[string]string{}
+		}
+		if checkCopy.Annotations == nil {
+			checkCopy.Annotations = map[string]string{}
+		}
+		checkCopy.Labels[compv1alpha1.ComplianceCheckResultWaivedLabel] = ""
+		checkCopy.Annotations[compv1alpha1.ComplianceCheckResultWaivedByAnnotation] = exceptionName
+	}
+	return checkCopy
+}
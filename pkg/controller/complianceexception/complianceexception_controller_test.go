@@ -0,0 +1,217 @@
+package complianceexception
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common/checkresultstest"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics/metricsfakes"
+)
+
+var _ = Describe("ComplianceExceptionController", func() {
+	var (
+		ctx       = context.Background()
+		namespace = checkresultstest.Namespace
+		r         *ReconcileComplianceException
+		exception *compv1alpha1.ComplianceException
+		scan      *compv1alpha1.ComplianceScan
+		objs      []runtime.Object
+	)
+
+	newCheck := func(name string, status compv1alpha1.ComplianceCheckStatus) *compv1alpha1.ComplianceCheckResult {
+		check := checkresultstest.NewCheck(scan, name, status)
+		check.Annotations[compv1alpha1.ComplianceCheckResultRuleAnnotation] = "kubelet-enable-protect-kernel-defaults"
+		return check
+	}
+	getCheck := func(name string) *compv1alpha1.ComplianceCheckResult {
+		check := &compv1alpha1.ComplianceCheckResult{}
+		Expect(r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, check)).To(Succeed())
+		return check
+	}
+	getScan := func() *compv1alpha1.ComplianceScan {
+		scan := &compv1alpha1.ComplianceScan{}
+		Expect(r.Client.Get(ctx, types.NamespacedName{Name: "workers-scan", Namespace: namespace}, scan)).To(Succeed())
+		return scan
+	}
+	getException := func() *compv1alpha1.ComplianceException {
+		found := &compv1alpha1.ComplianceException{}
+		Expect(r.Client.Get(ctx, types.NamespacedName{Name: exception.Name, Namespace: namespace}, found)).To(Succeed())
+		return found
+	}
+	doReconcile := func() (reconcile.Result, error) {
+		return r.Reconcile(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: exception.Name, Namespace: namespace},
+		})
+	}
+
+	BeforeEach(func() {
+		exception = &compv1alpha1.ComplianceException{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "protect-kernel-defaults",
+				Namespace:  namespace,
+				Finalizers: []string{compv1alpha1.ComplianceExceptionFinalizer},
+			},
+			Spec: compv1alpha1.ComplianceExceptionSpec{
+				Rule:           "ocp4-kubelet-enable-protect-kernel-defaults",
+				Justification:  "The kernel defaults are enforced by the image",
+				Approver:       "security-team",
+				ExpirationDate: metav1.Time{Time: time.Now().Add(time.Hour)},
+			},
+		}
+		rule := &compv1alpha1.Rule{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ocp4-kubelet-enable-protect-kernel-defaults",
+				Namespace: namespace,
+				Annotations: map[string]string{
+					compv1alpha1.RuleIDAnnotationKey: "kubelet-enable-protect-kernel-defaults",
+				},
+			},
+			RulePayload: compv1alpha1.RulePayload{
+				ID: "xccdf_org.ssgproject.content_rule_kubelet_enable_protect_kernel_defaults",
+			},
+		}
+		scan = checkresultstest.NewScan("workers-scan", compv1alpha1.ScanTypeNode, compv1alpha1.ResultNonCompliant)
+		objs = []runtime.Object{
+			rule,
+			scan,
+			newCheck("workers-scan-kubelet-enable-protect-kernel-defaults", compv1alpha1.CheckResultFail),
+			newCheck("workers-scan-passing", compv1alpha1.CheckResultPass),
+		}
+	})
+
+	JustBeforeEach(func() {
+		client, err := checkresultstest.NewClient(append(objs, exception)...)
+		Expect(err).To(BeNil())
+		r = &ReconcileComplianceException{
+			Client:   client,
+			Scheme:   client.Scheme(),
+			Metrics:  metrics.NewMetrics(&metricsfakes.FakeImpl{}),
+			Recorder: &common.SafeRecorder{},
+		}
+	})
+
+	Context("with a new exception", func() {
+		BeforeEach(func() {
+			exception.Finalizers = nil
+		})
+
+		It("adds the finalizer", func() {
+			_, err := doReconcile()
+			Expect(err).To(BeNil())
+			Expect(getException().Finalizers).To(ContainElement(compv1alpha1.ComplianceExceptionFinalizer))
+		})
+	})
+
+	Context("with an active exception for a rule", func() {
+		It("waives the failing check and makes the scan compliant", func() {
+			result, err := doReconcile()
+			Expect(err).To(BeNil())
+			Expect(result.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+
+			found := getException()
+			Expect(found.Status.Phase).To(Equal(compv1alpha1.ExceptionPhaseActive))
+			Expect(found.Status.RuleID).To(Equal("kubelet-enable-protect-kernel-defaults"))
+
+			check := getCheck("workers-scan-kubelet-enable-protect-kernel-defaults")
+			Expect(check.IsWaived()).To(BeTrue())
+			Expect(check.Annotations).To(HaveKeyWithValue(compv1alpha1.ComplianceCheckResultWaivedByAnnotation, exception.Name))
+			Expect(getCheck("workers-scan-passing").IsWaived()).To(BeFalse())
+
+			scan := getScan()
+			Expect(scan.Status.Result).To(Equal(compv1alpha1.ResultCompliant))
+			Expect(scan.Status.WaivedChecks).To(Equal(1))
+		})
+	})
+
+	Context("with an exception that expired", func() {
+		BeforeEach(func() {
+			exception.Spec.ExpirationDate = metav1.Time{Time: time.Now().Add(-time.Minute)}
+			exception.Status.Phase = compv1alpha1.ExceptionPhaseActive
+			exception.Status.RuleID = "kubelet-enable-protect-kernel-defaults"
+
+			check := newCheck("workers-scan-kubelet-enable-protect-kernel-defaults", compv1alpha1.CheckResultFail)
+			check.Labels[compv1alpha1.ComplianceCheckResultWaivedLabel] = ""
+			check.Annotations[compv1alpha1.ComplianceCheckResultWaivedByAnnotation] = exception.Name
+			objs[2] = check
+
+			scan.Status.Result = compv1alpha1.ResultCompliant
+			scan.Status.WaivedChecks = 1
+		})
+
+		It("makes the check fail again", func() {
+			result, err := doReconcile()
+			Expect(err).To(BeNil())
+			Expect(result.RequeueAfter).To(BeZero())
+
+			Expect(getException().Status.Phase).To(Equal(compv1alpha1.ExceptionPhaseExpired))
+			Expect(getCheck("workers-scan-kubelet-enable-protect-kernel-defaults").IsWaived()).To(BeFalse())
+
+			scan := getScan()
+			Expect(scan.Status.Result).To(Equal(compv1alpha1.ResultNonCompliant))
+			Expect(scan.Status.WaivedChecks).To(BeZero())
+		})
+	})
+
+	Context("with an exception for a rule that doesn't exist", func() {
+		BeforeEach(func() {
+			exception.Spec.Rule = "ocp4-does-not-exist"
+		})
+
+		It("marks the exception as invalid", func() {
+			_, err := doReconcile()
+			Expect(err).To(BeNil())
+
+			found := getException()
+			Expect(found.Status.Phase).To(Equal(compv1alpha1.ExceptionPhaseInvalid))
+			Expect(found.Status.ErrorMessage).To(ContainSubstring("ocp4-does-not-exist"))
+			Expect(getCheck("workers-scan-kubelet-enable-protect-kernel-defaults").IsWaived()).To(BeFalse())
+			Expect(getScan().Status.Result).To(Equal(compv1alpha1.ResultNonCompliant))
+		})
+	})
+
+	Context("with an exception setting both a rule and a check result", func() {
+		BeforeEach(func() {
+			exception.Spec.CheckResult = "workers-scan-kubelet-enable-protect-kernel-defaults"
+		})
+
+		It("marks the exception as invalid", func() {
+			_, err := doReconcile()
+			Expect(err).To(BeNil())
+			Expect(getException().Status.Phase).To(Equal(compv1alpha1.ExceptionPhaseInvalid))
+		})
+	})
+
+	Context("with an exception being deleted", func() {
+		BeforeEach(func() {
+			exception.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+			exception.Status.Phase = compv1alpha1.ExceptionPhaseActive
+			exception.Status.RuleID = "kubelet-enable-protect-kernel-defaults"
+
+			check := newCheck("workers-scan-kubelet-enable-protect-kernel-defaults", compv1alpha1.CheckResultFail)
+			check.Labels[compv1alpha1.ComplianceCheckResultWaivedLabel] = ""
+			check.Annotations[compv1alpha1.ComplianceCheckResultWaivedByAnnotation] = exception.Name
+			objs[2] = check
+		})
+
+		It("makes the check fail again and removes the finalizer", func() {
+			_, err := doReconcile()
+			Expect(err).To(BeNil())
+			Expect(getCheck("workers-scan-kubelet-enable-protect-kernel-defaults").IsWaived()).To(BeFalse())
+
+			// Without its finalizer, the exception is gone
+			err = r.Client.Get(ctx, types.NamespacedName{Name: exception.Name, Namespace: namespace}, &compv1alpha1.ComplianceException{})
+			Expect(kerrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
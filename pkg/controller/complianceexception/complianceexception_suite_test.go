@@ -0,0 +1,13 @@
+package complianceexception
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestComplianceexception(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Complianceexception Suite")
+}
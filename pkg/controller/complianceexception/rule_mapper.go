@@ -0,0 +1,39 @@
+package complianceexception
+
+import (
+	"context"
+
+	"github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type ruleMapper struct {
+	client.Client
+}
+
+// Map enqueues the exceptions referring to a rule, so that exceptions
+// created before their rule was parsed get validated again
+func (t *ruleMapper) Map(ctx context.Context, obj client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+
+	exList := v1alpha1.ComplianceExceptionList{}
+	err := t.List(ctx, &exList, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		return requests
+	}
+
+	for _, ex := range exList.Items {
+		if ex.Spec.Rule != obj.GetName() {
+			continue
+		}
+		objKey := types.NamespacedName{
+			Name:      ex.GetName(),
+			Namespace: ex.GetNamespace(),
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: objKey})
+	}
+
+	return requests
+}
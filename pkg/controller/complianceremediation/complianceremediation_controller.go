@@ -15,16 +15,20 @@ import (
 	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics"
 	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
 	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -34,6 +38,10 @@ const ctrlName = "remediationctrl"
 
 var log = logf.Log.WithName(ctrlName)
 
+// approvalWebhookName is the name of the admission webhook that makes sure
+// users only sign off RemediationApprovals on their own behalf
+const approvalWebhookName = "vremediationapproval.compliance.openshift.io"
+
 const (
 	remediationNameAnnotationKey = "remediation/"
 	defaultDependencyRequeueTime = time.Second * 20
@@ -55,17 +63,25 @@ func Add(mgr manager.Manager, met *metrics.Metrics, _ utils.CtlplaneSchedulingIn
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(mgr manager.Manager, met *metrics.Metrics) reconcile.Reconciler {
 	return &ReconcileComplianceRemediation{Client: mgr.GetClient(), Scheme: mgr.GetScheme(),
-		Recorder: common.NewSafeRecorder(ctrlName, mgr),
-		Metrics:  met,
+		Recorder:               common.NewSafeRecorder(ctrlName, mgr),
+		WebhookReader:          mgr.GetClient(),
+		Metrics:                met,
+		NodeRemediationBackend: utils.GetNodeRemediationBackend(),
 	}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
 func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	whMapper := &webhookConfigurationMapper{mgr.GetClient()}
+
 	// Watch for changes to primary resource ComplianceRemediation
 	return ctrl.NewControllerManagedBy(mgr).
 		Named("complianceremediation-controller").
-		For(&compv1alpha1.ComplianceRemediation{}).Complete(r)
+		For(&compv1alpha1.ComplianceRemediation{}).
+		// RemediationApprovals are named after the remediation they approve
+		Watches(&compv1alpha1.RemediationApproval{}, &handler.EnqueueRequestForObject{}).
+		Watches(&admissionregistrationv1.ValidatingWebhookConfiguration{}, handler.EnqueueRequestsFromMapFunc(whMapper.Map)).
+		Complete(r)
 }
 
 // blank assignment to verify that ReconcileComplianceRemediation implements reconcile.Reconciler
@@ -78,7 +94,15 @@ type ReconcileComplianceRemediation struct {
 	Client   client.Client
 	Scheme   *runtime.Scheme
 	Recorder record.EventRecorder
-	Metrics  *metrics.Metrics
+	// Reads the webhook configurations and namespaces the approval webhook
+	// is checked against, the sign-offs of RemediationApprovals are ignored
+	// if unset. The manager's client serves them from its cache, which the
+	// controller keeps watching the webhook configurations through.
+	WebhookReader client.Reader
+	Metrics       *metrics.Metrics
+	// The backend the node remediations are applied with, MachineConfigs
+	// are used if unset
+	NodeRemediationBackend utils.NodeRemediationBackend
 }
 
 // Reconcile reads that state of the cluster for a ComplianceRemediation object and makes changes based on the state read
@@ -142,6 +166,10 @@ func (r *ReconcileComplianceRemediation) Reconcile(ctx context.Context, request
 		return common.ReturnWithRetriableError(reqLogger, common.WrapNonRetriableCtrlError(err))
 	}
 
+	if remediationInstance.HasAnnotation(compv1alpha1.RemediationRevertToRevisionAnnotation) {
+		return r.handleRevert(remediationInstance, reqLogger)
+	}
+
 	var reconcileErr error
 
 	if remediationInstance.HasUnmetDependencies() {
@@ -163,9 +191,20 @@ func (r *ReconcileComplianceRemediation) Reconcile(ctx context.Context, request
 		}
 	}
 
+	approvals, approvalErr := r.getApprovals(remediationInstance)
+	if approvalErr != nil {
+		return common.ReturnWithRetriableError(reqLogger, approvalErr)
+	}
+	remediationInstance.Status.Approvals = approvals
+
 	//if no UnmetDependencies, UnsetValue, ValueRequired
 	if !(remediationInstance.HasUnmetDependencies() || remediationInstance.HasAnnotation(compv1alpha1.RemediationUnsetValueAnnotation) || remediationInstance.HasAnnotation(compv1alpha1.RemediationValueRequiredAnnotation)) {
-		reconcileErr = r.reconcileRemediation(remediationInstance, reqLogger)
+		if remediationInstance.IsPendingApproval() {
+			reqLogger.Info("Not applying the remediation until it's approved",
+				"Required", approvals.Required, "SignedOffBy", approvals.SignedOffBy)
+		} else {
+			reconcileErr = r.reconcileRemediation(remediationInstance, reqLogger)
+		}
 	}
 
 	// this would have been much nicer with go 1.13 using errors.Is()
@@ -198,23 +237,17 @@ func (r *ReconcileComplianceRemediation) reconcileRemediation(instance *compv1al
 	if obj == nil {
 		return common.NewNonRetriableCtrlError("Invalid Remediation: No object given")
 	}
-	if utils.IsMachineConfig(obj) {
-		if err := r.verifyAndCompleteMC(obj, instance); err != nil {
-			return err
-		}
-	}
-	//verify if the remediation is kubeletconfig, and process it
-	if utils.IsKubeletConfig(obj) {
-		if err := r.verifyAndCompleteKC(obj, instance); err != nil {
-			return err
-		}
+	backend := r.getNodeRemediationBackend()
+	obj, err := backend.completeObject(obj, instance)
+	if err != nil {
+		return err
 	}
 
 	objectLogger := logger.WithValues("Object.Name", obj.GetName(), "Object.Namespace", obj.GetNamespace(), "Object.Kind", obj.GetKind())
 	objectLogger.Info("Reconciling remediation object")
 
 	found := obj.DeepCopy()
-	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}, found)
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}, found)
 
 	if kerrors.IsForbidden(err) {
 		return common.NewNonRetriableCtrlError(
@@ -226,6 +259,9 @@ func (r *ReconcileComplianceRemediation) reconcileRemediation(instance *compv1al
 			"Unable to get fix object for ComplianceRemediation. "+
 				"Make sure the CRD is installed: %w", err)
 	} else if kerrors.IsNotFound(err) {
+		if instance.Spec.DryRun {
+			return r.previewRemediation(instance, obj, nil, objectLogger)
+		}
 		if instance.Spec.Apply {
 			instance.AddOwnershipLabels(obj)
 			// Going through remediation list, to make sure all the related
@@ -234,6 +270,9 @@ func (r *ReconcileComplianceRemediation) reconcileRemediation(instance *compv1al
 			if err != nil {
 				return fmt.Errorf("failed to set related remediations to apply: %w", err)
 			}
+			if err := backend.prepare(obj, objectLogger); err != nil {
+				return err
+			}
 			err = r.createRemediation(obj, objectLogger)
 			if err != nil {
 				return fmt.Errorf("failed to create remediation: %w", err)
@@ -246,12 +285,23 @@ func (r *ReconcileComplianceRemediation) reconcileRemediation(instance *compv1al
 		return err
 	}
 
+	if instance.Spec.DryRun {
+		return r.previewRemediation(instance, obj, found, objectLogger)
+	}
+
 	if instance.Spec.Apply {
 		err = r.setRemediations(instance, objectLogger, true)
 		if err != nil {
 			return fmt.Errorf("failed to set related remediations to apply: %w", err)
 		}
-		return r.patchRemediation(obj, objectLogger)
+		if err := backend.prepare(obj, objectLogger); err != nil {
+			return err
+		}
+		return r.patchRemediationWithRevision(instance, obj, found, objectLogger)
+	}
+	if instance.Status.ApplicationState == compv1alpha1.RemediationReverted {
+		objectLogger.Info("The object was reverted, leaving it as is")
+		return nil
 	}
 	err = r.setRemediations(instance, objectLogger, false)
 	if err != nil {
@@ -293,11 +343,11 @@ func (r *ReconcileComplianceRemediation) setRemediations(instance *compv1alpha1.
 	return nil
 }
 
-func (r *ReconcileComplianceRemediation) createRemediation(remObj *unstructured.Unstructured, logger logr.Logger) error {
+func (r *ReconcileComplianceRemediation) createRemediation(remObj *unstructured.Unstructured, logger logr.Logger, opts ...client.CreateOption) error {
 	logger.Info("Remediation will be created")
 	compv1alpha1.AddRemediationAnnotation(remObj)
 
-	createErr := r.Client.Create(context.TODO(), remObj)
+	createErr := r.Client.Create(context.TODO(), remObj, opts...)
 
 	if kerrors.IsForbidden(createErr) {
 		// If the kind is not available in the cluster, we can't retry
@@ -309,10 +359,10 @@ func (r *ReconcileComplianceRemediation) createRemediation(remObj *unstructured.
 	return createErr
 }
 
-func (r *ReconcileComplianceRemediation) patchRemediation(remObj *unstructured.Unstructured, logger logr.Logger) error {
+func (r *ReconcileComplianceRemediation) patchRemediation(remObj *unstructured.Unstructured, logger logr.Logger, opts ...client.PatchOption) error {
 	logger.Info("Remediation patch object")
 
-	patchErr := r.Client.Patch(context.TODO(), remObj, client.Merge)
+	patchErr := r.Client.Patch(context.TODO(), remObj, client.Merge, opts...)
 
 	if kerrors.IsForbidden(patchErr) {
 		// If the kind is not available in the cluster, we can't retry
@@ -629,6 +679,147 @@ func isRemDepHandled(r *ReconcileComplianceRemediation, rem *compv1alpha1.Compli
 	return true, nil
 }
 
+// getApprovals returns the approvals the suite of the remediation requires
+// for it along with the users that signed it off, or nil if the remediation
+// doesn't need approvals
+func (r *ReconcileComplianceRemediation) getApprovals(rem *compv1alpha1.ComplianceRemediation) (*compv1alpha1.ComplianceRemediationApprovals, error) {
+	if rem.GetSuite() == "" {
+		return nil, nil
+	}
+	suite := &compv1alpha1.ComplianceSuite{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: rem.GetSuite(), Namespace: rem.Namespace}, suite)
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("couldn't get the suite of the remediation: %w", err)
+	}
+
+	severity := compv1alpha1.ComplianceCheckResultSeverity(rem.Annotations[compv1alpha1.RemediationSeverityAnnotation])
+	required := suite.Spec.RequiredRemediationApprovals(rem.Annotations[compv1alpha1.RemediationDisruptionAnnotation], severity)
+	if required == 0 {
+		return nil, nil
+	}
+
+	approvals := &compv1alpha1.ComplianceRemediationApprovals{Required: required}
+	approval := &compv1alpha1.RemediationApproval{}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: rem.Name, Namespace: rem.Namespace}, approval)
+	if kerrors.IsNotFound(err) {
+		return approvals, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("couldn't get the approval of the remediation: %w", err)
+	}
+
+	// Nothing but the admission webhook stops users from signing off on
+	// behalf of others, so the sign-offs can't be trusted without it
+	enforced, err := r.approvalWebhookEnforced(rem.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if !enforced {
+		log.Error(errors.New("the sign-offs of remediation approvals aren't validated"),
+			"Ignoring the sign-offs of the remediation until the admission webhook is registered",
+			"ComplianceRemediation.Name", rem.Name, "Webhook", approvalWebhookName)
+		return approvals, nil
+	}
+	approvals.SignedOffBy = approval.GetApprovers()
+	return approvals, nil
+}
+
+// approvalWebhookEnforced tells whether the admission webhook validating the
+// sign-offs of RemediationApprovals is registered such that it's called for
+// every RemediationApproval created or updated in the namespace, and that
+// the requests are rejected if it can't be reached
+func (r *ReconcileComplianceRemediation) approvalWebhookEnforced(namespace string) (bool, error) {
+	if r.WebhookReader == nil {
+		return false, nil
+	}
+	configs := &admissionregistrationv1.ValidatingWebhookConfigurationList{}
+	if err := r.WebhookReader.List(context.TODO(), configs); err != nil {
+		return false, fmt.Errorf("couldn't list the validating webhook configurations: %w", err)
+	}
+	for i := range configs.Items {
+		for j := range configs.Items[i].Webhooks {
+			wh := &configs.Items[i].Webhooks[j]
+			if wh.Name != approvalWebhookName {
+				continue
+			}
+			enforced, err := r.webhookEnforcedIn(wh, namespace)
+			if err != nil || enforced {
+				return enforced, err
+			}
+		}
+	}
+	return false, nil
+}
+
+func (r *ReconcileComplianceRemediation) webhookEnforcedIn(wh *admissionregistrationv1.ValidatingWebhook, namespace string) (bool, error) {
+	if wh.FailurePolicy != nil && *wh.FailurePolicy != admissionregistrationv1.Fail {
+		return false, nil
+	}
+	if wh.ClientConfig.Service == nil || wh.ClientConfig.Service.Namespace != namespace {
+		return false, nil
+	}
+	if len(wh.MatchConditions) > 0 || !isEmptySelector(wh.ObjectSelector) {
+		return false, nil
+	}
+	if !coversApprovals(wh.Rules) {
+		return false, nil
+	}
+	if isEmptySelector(wh.NamespaceSelector) {
+		return true, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(wh.NamespaceSelector)
+	if err != nil {
+		return false, nil
+	}
+	ns := &corev1.Namespace{}
+	if err := r.WebhookReader.Get(context.TODO(), types.NamespacedName{Name: namespace}, ns); err != nil {
+		return false, fmt.Errorf("couldn't get the namespace of the remediation: %w", err)
+	}
+	return selector.Matches(labels.Set(ns.Labels)), nil
+}
+
+func isEmptySelector(selector *metav1.LabelSelector) bool {
+	return selector == nil || (len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0)
+}
+
+// coversApprovals tells whether the rules of a webhook send it the creations
+// and updates of RemediationApprovals
+func coversApprovals(rules []admissionregistrationv1.RuleWithOperations) bool {
+	var create, update bool
+	for _, rule := range rules {
+		if !matchesAny(rule.APIGroups, compv1alpha1.SchemeGroupVersion.Group) ||
+			!matchesAny(rule.APIVersions, compv1alpha1.SchemeGroupVersion.Version) ||
+			!matchesAny(rule.Resources, "remediationapprovals") {
+			continue
+		}
+		if rule.Scope != nil && *rule.Scope == admissionregistrationv1.ClusterScope {
+			continue
+		}
+		for _, op := range rule.Operations {
+			switch op {
+			case admissionregistrationv1.OperationAll:
+				create, update = true, true
+			case admissionregistrationv1.Create:
+				create = true
+			case admissionregistrationv1.Update:
+				update = true
+			}
+		}
+	}
+	return create && update
+}
+
+func matchesAny(values []string, value string) bool {
+	for _, v := range values {
+		if v == value || v == "*" {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *ReconcileComplianceRemediation) reconcileRemediationStatus(instance *compv1alpha1.ComplianceRemediation,
 	logger logr.Logger, errorApplying error) error {
 	instanceCopy := instance.DeepCopy()
@@ -659,7 +850,8 @@ func (r *ReconcileComplianceRemediation) verifyAndCompleteMC(obj *unstructured.U
 	// The scans contain a nodeSelector that ultimately must match a machineConfigPool. The only way we can
 	// ensure it does is by checking if it matches any MachineConfigPool's labels.
 	// See also: https://github.com/openshift/machine-config-operator/blob/master/docs/custom-pools.md
-	if ok, _ := utils.AnyMcfgPoolLabelMatches(scan.Spec.NodeSelector, mcfgpools); !ok {
+	ok, pool := utils.AnyMcfgPoolLabelMatches(scan.GetNodeSelectorLabels(), mcfgpools)
+	if !ok {
 		return common.NewNonRetriableCtrlError("not applying remediation that doesn't have a matching MachineconfigPool. Scan: %s", scan.Name)
 	}
 	obj.SetName(rem.GetMcName())
@@ -667,7 +859,13 @@ func (r *ReconcileComplianceRemediation) verifyAndCompleteMC(obj *unstructured.U
 	if labels == nil {
 		labels = make(map[string]string)
 	}
-	labels[mcfgv1.MachineConfigRoleLabelKey] = utils.GetFirstNodeRole(scan.Spec.NodeSelector)
+	role := utils.GetFirstNodeRole(scan.GetNodeSelectorLabels())
+	// Scans selecting nodes by other labels than their role get the role
+	// of the pool the MachineConfigs are rendered for
+	if role == "" && pool.Spec.MachineConfigSelector != nil {
+		role = pool.Spec.MachineConfigSelector.MatchLabels[mcfgv1.MachineConfigRoleLabelKey]
+	}
+	labels[mcfgv1.MachineConfigRoleLabelKey] = role
 	obj.SetLabels(labels)
 	return nil
 }
@@ -687,7 +885,7 @@ func (r *ReconcileComplianceRemediation) verifyAndCompleteKC(obj *unstructured.U
 	// The scans contain a nodeSelector that ultimately must match a machineConfigPool. The only way we can
 	// ensure it does is by checking if it matches any MachineConfigPool's labels.
 	// See also: https://github.com/openshift/machine-config-operator/blob/master/docs/custom-pools.md
-	ok, pool := utils.AnyMcfgPoolLabelMatches(scan.Spec.NodeSelector, mcfgpools)
+	ok, pool := utils.AnyMcfgPoolLabelMatches(scan.GetNodeSelectorLabels(), mcfgpools)
 	if !ok {
 		return common.NewNonRetriableCtrlError("not applying remediation that doesn't have a matching MachineconfigPool. Scan: %s", scan.Name)
 	}
@@ -764,6 +962,10 @@ func isNoLongerOutdated(r *compv1alpha1.ComplianceRemediation) bool {
 }
 
 func (r *ReconcileComplianceRemediation) setRemediationStatus(rem *compv1alpha1.ComplianceRemediation, errorApplying error, logger logr.Logger) {
+	if !rem.Spec.DryRun {
+		rem.Status.DryRun = nil
+	}
+
 	if errorApplying != nil {
 		if wasErrorOnOptionalRemediation(rem, errorApplying) {
 			logger.Info("Optional remediation couldn't be applied")
@@ -778,12 +980,30 @@ func (r *ReconcileComplianceRemediation) setRemediationStatus(rem *compv1alpha1.
 		return
 	}
 
+	if rem.Spec.DryRun {
+		logger.Info("Remediation was applied in dry-run mode")
+		if rem.Status.ApplicationState != compv1alpha1.RemediationApplied {
+			rem.Status.ApplicationState = compv1alpha1.RemediationNotApplied
+		}
+		return
+	}
+
 	if !rem.Spec.Apply {
+		if rem.Status.ApplicationState == compv1alpha1.RemediationReverted {
+			logger.Info("Remediation remains reverted")
+			return
+		}
 		logger.Info("Remediation will now be unapplied")
 		rem.Status.ApplicationState = compv1alpha1.RemediationNotApplied
 		return
 	}
 
+	if rem.IsPendingApproval() {
+		logger.Info("Remediation is pending approval")
+		rem.Status.ApplicationState = compv1alpha1.RemediationPendingApproval
+		return
+	}
+
 	if rem.Spec.Outdated.Object != nil {
 		logger.Info("Remediation remains outdated")
 		rem.Status.ApplicationState = compv1alpha1.RemediationOutdated
@@ -6,9 +6,12 @@ import (
 
 	"github.com/ComplianceAsCode/compliance-operator/pkg/apis"
 	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
 	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics"
 	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics/metricsfakes"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
 	"github.com/clarketm/json"
+	igntypes "github.com/coreos/ignition/v2/config/v3_2/types"
 	"github.com/go-logr/logr"
 	"github.com/go-logr/zapr"
 	. "github.com/onsi/ginkgo"
@@ -16,6 +19,8 @@ import (
 	mcfgapi "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io"
 	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
 	"go.uber.org/zap"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -23,7 +28,10 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -550,6 +558,169 @@ var _ = Describe("Testing complianceremediation controller", func() {
 
 		})
 
+		Context("with the DaemonSet node remediation backend", func() {
+			BeforeEach(func() {
+				reconciler.NodeRemediationBackend = utils.NodeRemediationBackendDaemonSet
+			})
+
+			setRemediationObject := func(obj interface{}) {
+				unstructuredObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+				Expect(err).ToNot(HaveOccurred())
+				remediationinstance.Spec.Current.Object = &unstructured.Unstructured{
+					Object: unstructuredObj,
+				}
+				err = reconciler.Client.Update(context.TODO(), remediationinstance)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			It("should apply a MachineConfig through the node remediation agent", func() {
+				source := "data:,hardened%0A"
+				mode := 0600
+				enabled := true
+				unitContents := "[Service]\nExecStart=/bin/true\n"
+				rawIgn, err := json.Marshal(igntypes.Config{
+					Ignition: igntypes.Ignition{Version: "3.2.0"},
+					Storage: igntypes.Storage{
+						Files: []igntypes.File{
+							{
+								Node: igntypes.Node{Path: "/etc/sysctl.d/hardened.conf"},
+								FileEmbedded1: igntypes.FileEmbedded1{
+									Contents: igntypes.Resource{Source: &source},
+									Mode:     &mode,
+								},
+							},
+						},
+					},
+					Systemd: igntypes.Systemd{
+						Units: []igntypes.Unit{
+							{Name: "hardened.service", Enabled: &enabled, Contents: &unitContents},
+						},
+					},
+				})
+				Expect(err).ToNot(HaveOccurred())
+				setRemediationObject(&mcfgv1.MachineConfig{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "MachineConfig",
+						APIVersion: mcfgapi.GroupName + "/v1",
+					},
+					Spec: mcfgv1.MachineConfigSpec{
+						Config: runtime.RawExtension{Raw: rawIgn},
+					},
+				})
+
+				err = reconciler.reconcileRemediation(remediationinstance, logger)
+				Expect(err).To(BeNil())
+
+				By("not creating a MachineConfig")
+				foundMC := &mcfgv1.MachineConfig{}
+				err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: remediationinstance.GetMcName()}, foundMC)
+				Expect(kerrors.IsNotFound(err)).To(BeTrue())
+
+				By("storing the files and units for the agent")
+				foundCM := &corev1.ConfigMap{}
+				cmKey := types.NamespacedName{Name: remediationinstance.GetMcName(), Namespace: common.GetComplianceOperatorNamespace()}
+				err = reconciler.Client.Get(context.TODO(), cmKey, foundCM)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(foundCM.Labels).To(HaveKey(compv1alpha1.NodeRemediationLabel))
+				Expect(compv1alpha1.RemediationWasCreatedByOperator(foundCM)).To(BeTrue())
+				owner := metav1.GetControllerOf(foundCM)
+				Expect(owner).ToNot(BeNil())
+				Expect(owner.Kind).To(Equal("ComplianceRemediation"))
+				Expect(owner.Name).To(Equal(remediationinstance.Name))
+				nodeRem := &utils.NodeRemediation{}
+				Expect(json.Unmarshal([]byte(foundCM.Data[utils.NodeRemediationDataKey]), nodeRem)).To(Succeed())
+				Expect(nodeRem.NodeSelector).To(Equal(mcfgv1.MachineConfigRoleLabelKey + "=myRole"))
+				Expect(nodeRem.Files).To(ConsistOf(utils.NodeRemediationFile{
+					Path:     "/etc/sysctl.d/hardened.conf",
+					Mode:     0600,
+					Contents: []byte("hardened\n"),
+				}))
+				Expect(nodeRem.Units).To(ConsistOf(utils.NodeRemediationUnit{
+					Name:     "hardened.service",
+					Contents: unitContents,
+					Enabled:  &enabled,
+				}))
+
+				By("deploying the node remediation agent")
+				agent := &appsv1.DaemonSet{}
+				agentKey := types.NamespacedName{Name: utils.NodeRemediationAgentName, Namespace: common.GetComplianceOperatorNamespace()}
+				err = reconciler.Client.Get(context.TODO(), agentKey, agent)
+				Expect(err).ToNot(HaveOccurred())
+
+				By("updating the node remediation agent along with the operator")
+				agent.Spec.Template.Spec.Containers[0].Image = "old-operator-image"
+				err = reconciler.Client.Update(context.TODO(), agent)
+				Expect(err).ToNot(HaveOccurred())
+				err = reconciler.reconcileRemediation(remediationinstance, logger)
+				Expect(err).To(BeNil())
+				err = reconciler.Client.Get(context.TODO(), agentKey, agent)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(agent.Spec.Template.Spec.Containers[0].Image).To(Equal(utils.GetComponentImage(utils.OPERATOR)))
+			})
+
+			It("should refuse MachineConfigs writing files the agent may not write", func() {
+				source := "data:,forged%0A"
+				rawIgn, err := json.Marshal(igntypes.Config{
+					Ignition: igntypes.Ignition{Version: "3.2.0"},
+					Storage: igntypes.Storage{
+						Files: []igntypes.File{
+							{
+								Node:          igntypes.Node{Path: "/etc/shadow"},
+								FileEmbedded1: igntypes.FileEmbedded1{Contents: igntypes.Resource{Source: &source}},
+							},
+						},
+					},
+				})
+				Expect(err).ToNot(HaveOccurred())
+				setRemediationObject(&mcfgv1.MachineConfig{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "MachineConfig",
+						APIVersion: mcfgapi.GroupName + "/v1",
+					},
+					Spec: mcfgv1.MachineConfigSpec{
+						Config: runtime.RawExtension{Raw: rawIgn},
+					},
+				})
+
+				err = reconciler.reconcileRemediation(remediationinstance, logger)
+				Expect(err).ToNot(BeNil())
+				Expect(common.IsRetriable(err)).To(BeFalse())
+			})
+
+			It("should refuse MachineConfigs that the agent can't apply", func() {
+				setRemediationObject(&mcfgv1.MachineConfig{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "MachineConfig",
+						APIVersion: mcfgapi.GroupName + "/v1",
+					},
+					Spec: mcfgv1.MachineConfigSpec{
+						FIPS: true,
+					},
+				})
+
+				err := reconciler.reconcileRemediation(remediationinstance, logger)
+				Expect(err).ToNot(BeNil())
+				Expect(common.IsRetriable(err)).To(BeFalse())
+			})
+
+			It("should not apply optional KubeletConfig remediations", func() {
+				setRemediationObject(&mcfgv1.KubeletConfig{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "KubeletConfig",
+						APIVersion: mcfgapi.GroupName + "/v1",
+					},
+				})
+
+				err := reconciler.reconcileRemediation(remediationinstance, logger)
+				Expect(err).ToNot(BeNil())
+				Expect(common.IsRetriable(err)).To(BeFalse())
+				Expect(wasErrorOnOptionalRemediation(remediationinstance, err)).To(BeFalse())
+
+				remediationinstance.Annotations[compv1alpha1.RemediationOptionalAnnotation] = ""
+				Expect(wasErrorOnOptionalRemediation(remediationinstance, err)).To(BeTrue())
+			})
+		})
+
 		Context("with an outdated remediation object", func() {
 			BeforeEach(func() {
 				currentcm := &corev1.ConfigMap{
@@ -882,4 +1053,596 @@ var _ = Describe("Testing complianceremediation controller", func() {
 			})
 		})
 	})
+
+	Context("applying remediations that require approvals", func() {
+		var (
+			request       reconcile.Request
+			webhookConfig *admissionregistrationv1.ValidatingWebhookConfiguration
+		)
+
+		BeforeEach(func() {
+			suite := &compv1alpha1.ComplianceSuite{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "mySuite",
+				},
+				Spec: compv1alpha1.ComplianceSuiteSpec{
+					ComplianceSuiteSettings: compv1alpha1.ComplianceSuiteSettings{
+						RemediationApprovals: []compv1alpha1.RemediationApprovalRule{
+							{Severity: compv1alpha1.CheckResultSeverityHigh, Approvals: 2},
+							{Disruption: "high", Approvals: 1},
+						},
+					},
+				},
+			}
+			err := reconciler.Client.Create(context.TODO(), suite)
+			Expect(err).NotTo(HaveOccurred())
+
+			cm := &corev1.ConfigMap{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "ConfigMap",
+					APIVersion: "v1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-cm",
+					Namespace: "test-ns",
+				},
+			}
+			unstructuredCM, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cm)
+			Expect(err).ToNot(HaveOccurred())
+			remediationinstance.Spec.Current.Object = &unstructured.Unstructured{
+				Object: unstructuredCM,
+			}
+			remediationinstance.Spec.Apply = true
+			remediationinstance.Annotations = map[string]string{
+				compv1alpha1.RemediationSeverityAnnotation:   "high",
+				compv1alpha1.RemediationDisruptionAnnotation: "high",
+			}
+			err = reconciler.Client.Update(context.TODO(), remediationinstance)
+			Expect(err).NotTo(HaveOccurred())
+			remediationinstance.Status.ApplicationState = compv1alpha1.RemediationPending
+			err = reconciler.Client.Status().Update(context.TODO(), remediationinstance)
+			Expect(err).NotTo(HaveOccurred())
+
+			failurePolicy := admissionregistrationv1.Fail
+			webhookConfig = &admissionregistrationv1.ValidatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "validating-webhook-configuration",
+				},
+				Webhooks: []admissionregistrationv1.ValidatingWebhook{
+					{
+						Name: approvalWebhookName,
+						ClientConfig: admissionregistrationv1.WebhookClientConfig{
+							Service: &admissionregistrationv1.ServiceReference{
+								Name:      "compliance-operator-service",
+								Namespace: remediationinstance.Namespace,
+							},
+						},
+						Rules: []admissionregistrationv1.RuleWithOperations{
+							{
+								Operations: []admissionregistrationv1.OperationType{
+									admissionregistrationv1.Create,
+									admissionregistrationv1.Update,
+								},
+								Rule: admissionregistrationv1.Rule{
+									APIGroups:   []string{"compliance.openshift.io"},
+									APIVersions: []string{"v1alpha1"},
+									Resources:   []string{"remediationapprovals"},
+								},
+							},
+						},
+						FailurePolicy: &failurePolicy,
+					},
+				},
+			}
+			err = reconciler.Client.Create(context.TODO(), webhookConfig)
+			Expect(err).NotTo(HaveOccurred())
+			reconciler.WebhookReader = reconciler.Client
+
+			request = reconcile.Request{NamespacedName: types.NamespacedName{Name: remediationinstance.Name}}
+		})
+
+		signOff := func(approvers ...string) {
+			approval := &compv1alpha1.RemediationApproval{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: remediationinstance.Name,
+				},
+			}
+			for _, approver := range approvers {
+				approval.Spec.SignOffs = append(approval.Spec.SignOffs, compv1alpha1.RemediationSignOff{SignedOffBy: approver})
+			}
+			err := reconciler.Client.Create(context.TODO(), approval)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		getRemediation := func() *compv1alpha1.ComplianceRemediation {
+			rem := &compv1alpha1.ComplianceRemediation{}
+			err := reconciler.Client.Get(context.TODO(), request.NamespacedName, rem)
+			Expect(err).NotTo(HaveOccurred())
+			return rem
+		}
+
+		It("should only apply the remediation once enough users signed it off", func() {
+			_, err := reconciler.Reconcile(context.TODO(), request)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("the remediation should wait for two approvals")
+			rem := getRemediation()
+			Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationPendingApproval))
+			Expect(rem.Status.Approvals).To(Equal(&compv1alpha1.ComplianceRemediationApprovals{Required: 2}))
+			err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "my-cm", Namespace: "test-ns"}, &corev1.ConfigMap{})
+			Expect(kerrors.IsNotFound(err)).To(BeTrue())
+
+			By("a single sign-off not being enough")
+			approval := &compv1alpha1.RemediationApproval{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: remediationinstance.Name,
+				},
+				Spec: compv1alpha1.RemediationApprovalSpec{
+					SignOffs: []compv1alpha1.RemediationSignOff{
+						{SignedOffBy: "alice"},
+					},
+				},
+			}
+			err = reconciler.Client.Create(context.TODO(), approval)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = reconciler.Reconcile(context.TODO(), request)
+			Expect(err).NotTo(HaveOccurred())
+			rem = getRemediation()
+			Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationPendingApproval))
+			Expect(rem.Status.Approvals.SignedOffBy).To(ConsistOf("alice"))
+
+			By("applying the remediation once a second user signs it off")
+			approval.Spec.SignOffs = append(approval.Spec.SignOffs, compv1alpha1.RemediationSignOff{SignedOffBy: "bob"})
+			err = reconciler.Client.Update(context.TODO(), approval)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = reconciler.Reconcile(context.TODO(), request)
+			Expect(err).NotTo(HaveOccurred())
+			rem = getRemediation()
+			Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationApplied))
+			err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "my-cm", Namespace: "test-ns"}, &corev1.ConfigMap{})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("keeping the remediation applied if a sign-off is withdrawn")
+			approval.Spec.SignOffs = approval.Spec.SignOffs[:1]
+			err = reconciler.Client.Update(context.TODO(), approval)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = reconciler.Reconcile(context.TODO(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getRemediation().Status.ApplicationState).To(Equal(compv1alpha1.RemediationApplied))
+		})
+
+		It("should not need approvals for remediations no rule matches", func() {
+			remediationinstance.Annotations = map[string]string{
+				compv1alpha1.RemediationSeverityAnnotation: "low",
+			}
+			err := reconciler.Client.Update(context.TODO(), remediationinstance)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = reconciler.Reconcile(context.TODO(), request)
+			Expect(err).NotTo(HaveOccurred())
+			rem := getRemediation()
+			Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationApplied))
+			Expect(rem.Status.Approvals).To(BeNil())
+		})
+
+		expectSignOffsIgnored := func() {
+			_, err := reconciler.Reconcile(context.TODO(), request)
+			Expect(err).NotTo(HaveOccurred())
+			rem := getRemediation()
+			Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationPendingApproval))
+			Expect(rem.Status.Approvals).To(Equal(&compv1alpha1.ComplianceRemediationApprovals{Required: 2}))
+			err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "my-cm", Namespace: "test-ns"}, &corev1.ConfigMap{})
+			Expect(kerrors.IsNotFound(err)).To(BeTrue())
+		}
+
+		It("should ignore the sign-offs if the webhook isn't registered", func() {
+			err := reconciler.Client.Delete(context.TODO(), webhookConfig)
+			Expect(err).NotTo(HaveOccurred())
+			signOff("alice", "bob")
+			expectSignOffsIgnored()
+		})
+
+		It("should ignore the sign-offs if the webhook can't be read", func() {
+			reconciler.WebhookReader = nil
+			signOff("alice", "bob")
+			expectSignOffsIgnored()
+		})
+
+		It("should ignore the sign-offs if the webhook may be skipped", func() {
+			ignore := admissionregistrationv1.Ignore
+			webhookConfig.Webhooks[0].FailurePolicy = &ignore
+			err := reconciler.Client.Update(context.TODO(), webhookConfig)
+			Expect(err).NotTo(HaveOccurred())
+			signOff("alice", "bob")
+			expectSignOffsIgnored()
+		})
+
+		It("should ignore the sign-offs if the webhook doesn't validate updates", func() {
+			webhookConfig.Webhooks[0].Rules[0].Operations = []admissionregistrationv1.OperationType{admissionregistrationv1.Create}
+			err := reconciler.Client.Update(context.TODO(), webhookConfig)
+			Expect(err).NotTo(HaveOccurred())
+			signOff("alice", "bob")
+			expectSignOffsIgnored()
+		})
+
+		It("should ignore the sign-offs if the webhook is served from another namespace", func() {
+			webhookConfig.Webhooks[0].ClientConfig.Service.Namespace = "other-ns"
+			err := reconciler.Client.Update(context.TODO(), webhookConfig)
+			Expect(err).NotTo(HaveOccurred())
+			signOff("alice", "bob")
+			expectSignOffsIgnored()
+		})
+	})
+
+	Context("reverting remediations", func() {
+		var (
+			request  reconcile.Request
+			recorder *record.FakeRecorder
+		)
+
+		BeforeEach(func() {
+			recorder = record.NewFakeRecorder(10)
+			reconciler.Recorder = recorder
+
+			liveCM := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-cm",
+					Namespace: "test-ns",
+					Labels:    map[string]string{"app": "web"},
+				},
+				Data: map[string]string{
+					"key": "old-val",
+				},
+			}
+			err := reconciler.Client.Create(context.TODO(), liveCM)
+			Expect(err).NotTo(HaveOccurred())
+
+			cm := &corev1.ConfigMap{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "ConfigMap",
+					APIVersion: "v1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-cm",
+					Namespace: "test-ns",
+				},
+				Data: map[string]string{
+					"key":   "val",
+					"added": "val",
+				},
+			}
+			unstructuredCM, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cm)
+			Expect(err).ToNot(HaveOccurred())
+			remediationinstance.Spec.Current.Object = &unstructured.Unstructured{
+				Object: unstructuredCM,
+			}
+			remediationinstance.Spec.Apply = true
+			remediationinstance.Annotations = nil
+			err = reconciler.Client.Update(context.TODO(), remediationinstance)
+			Expect(err).NotTo(HaveOccurred())
+			remediationinstance.Status.ApplicationState = compv1alpha1.RemediationPending
+			err = reconciler.Client.Status().Update(context.TODO(), remediationinstance)
+			Expect(err).NotTo(HaveOccurred())
+
+			request = reconcile.Request{NamespacedName: types.NamespacedName{Name: remediationinstance.Name}}
+		})
+
+		getRemediation := func() *compv1alpha1.ComplianceRemediation {
+			rem := &compv1alpha1.ComplianceRemediation{}
+			err := reconciler.Client.Get(context.TODO(), request.NamespacedName, rem)
+			Expect(err).NotTo(HaveOccurred())
+			return rem
+		}
+		getCM := func() *corev1.ConfigMap {
+			cm := &corev1.ConfigMap{}
+			err := reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "my-cm", Namespace: "test-ns"}, cm)
+			Expect(err).NotTo(HaveOccurred())
+			return cm
+		}
+		getRevisionSecret := func(name string) *corev1.Secret {
+			secret := &corev1.Secret{}
+			err := reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: remediationinstance.Namespace}, secret)
+			Expect(err).NotTo(HaveOccurred())
+			return secret
+		}
+		getCMSnapshot := func() *unstructured.Unstructured {
+			obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(getCM())
+			Expect(err).NotTo(HaveOccurred())
+			return snapshotObject(&unstructured.Unstructured{Object: obj})
+		}
+		requestRevert := func(revision string) {
+			rem := getRemediation()
+			rem.Annotations = map[string]string{compv1alpha1.RemediationRevertToRevisionAnnotation: revision}
+			err := reconciler.Client.Update(context.TODO(), rem)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		It("should record the object before patching it and restore it", func() {
+			_, err := reconciler.Reconcile(context.TODO(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getCM().Data).To(Equal(map[string]string{"key": "val", "added": "val"}))
+
+			By("keeping the object as it was before the patch")
+			rem := getRemediation()
+			Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationApplied))
+			Expect(rem.Status.Revisions).To(HaveLen(1))
+			Expect(rem.Status.Revisions[0].Revision).To(BeEquivalentTo(1))
+			secret := getRevisionSecret(rem.Status.Revisions[0].SecretName)
+			Expect(secret.Labels).To(HaveKeyWithValue(compv1alpha1.RemediationRevisionLabel, rem.Name))
+			Expect(metav1.IsControlledBy(secret, rem)).To(BeTrue())
+			revObj := &unstructured.Unstructured{}
+			Expect(json.Unmarshal(secret.Data[revisionObjectKey], revObj)).To(Succeed())
+			data, _, _ := unstructured.NestedStringMap(revObj.Object, "data")
+			Expect(data).To(Equal(map[string]string{"key": "old-val"}))
+
+			By("not recording patches that don't change the object")
+			_, err = reconciler.Reconcile(context.TODO(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getRemediation().Status.Revisions).To(HaveLen(1))
+
+			By("reverting to the revision")
+			requestRevert("1")
+			_, err = reconciler.Reconcile(context.TODO(), request)
+			Expect(err).NotTo(HaveOccurred())
+			cm := getCM()
+			Expect(cm.Data).To(Equal(map[string]string{"key": "old-val"}))
+			Expect(cm.Labels).To(Equal(map[string]string{"app": "web"}))
+			Expect(recorder.Events).To(Receive(ContainSubstring("RemediationReverted")))
+
+			rem = getRemediation()
+			Expect(rem.Spec.Apply).To(BeFalse())
+			Expect(rem.HasAnnotation(compv1alpha1.RemediationRevertToRevisionAnnotation)).To(BeFalse())
+			Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationReverted))
+			Expect(rem.Status.LastRevert).NotTo(BeNil())
+			Expect(rem.Status.LastRevert.Revision).To(BeEquivalentTo(1))
+
+			By("leaving the reverted object alone")
+			_, err = reconciler.Reconcile(context.TODO(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getCM().Data).To(Equal(map[string]string{"key": "old-val"}))
+			Expect(getRemediation().Status.ApplicationState).To(Equal(compv1alpha1.RemediationReverted))
+		})
+
+		It("should drop requests to revert to unknown revisions", func() {
+			_, err := reconciler.Reconcile(context.TODO(), request)
+			Expect(err).NotTo(HaveOccurred())
+
+			requestRevert("5")
+			_, err = reconciler.Reconcile(context.TODO(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(Receive(ContainSubstring("RemediationRevertFailed")))
+
+			rem := getRemediation()
+			Expect(rem.HasAnnotation(compv1alpha1.RemediationRevertToRevisionAnnotation)).To(BeFalse())
+			Expect(rem.Spec.Apply).To(BeTrue())
+			Expect(getCM().Data).To(Equal(map[string]string{"key": "val", "added": "val"}))
+		})
+
+		It("should drop requests to revert to revisions whose Secret is gone", func() {
+			_, err := reconciler.Reconcile(context.TODO(), request)
+			Expect(err).NotTo(HaveOccurred())
+			secret := getRevisionSecret(getRemediation().Status.Revisions[0].SecretName)
+			Expect(reconciler.Client.Delete(context.TODO(), secret)).To(Succeed())
+
+			requestRevert("1")
+			_, err = reconciler.Reconcile(context.TODO(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(Receive(ContainSubstring("RemediationRevertFailed")))
+			Expect(getCM().Data).To(Equal(map[string]string{"key": "val", "added": "val"}))
+		})
+
+		It("should only keep the configured number of revisions", func() {
+			limit := int32(2)
+			remediationinstance.Spec.RevisionHistoryLimit = &limit
+			for i := 0; i < 3; i++ {
+				Expect(reconciler.addRevision(remediationinstance, getCMSnapshot(), logger)).To(Succeed())
+			}
+			revisions := remediationinstance.Status.Revisions
+			Expect(revisions).To(HaveLen(2))
+			Expect(revisions[0].Revision).To(BeEquivalentTo(2))
+			Expect(revisions[1].Revision).To(BeEquivalentTo(3))
+
+			By("deleting the Secrets of the dropped revisions")
+			secrets := &corev1.SecretList{}
+			err := reconciler.Client.List(context.TODO(), secrets,
+				runtimeclient.MatchingLabels{compv1alpha1.RemediationRevisionLabel: remediationinstance.Name})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(secrets.Items).To(ConsistOf(
+				HaveField("Name", revisions[0].SecretName),
+				HaveField("Name", revisions[1].SecretName)))
+
+			By("deleting all of them once the history is disabled")
+			limit = 0
+			Expect(reconciler.addRevision(remediationinstance, getCMSnapshot(), logger)).To(Succeed())
+			Expect(remediationinstance.Status.Revisions).To(BeEmpty())
+			err = reconciler.Client.List(context.TODO(), secrets,
+				runtimeclient.MatchingLabels{compv1alpha1.RemediationRevisionLabel: remediationinstance.Name})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(secrets.Items).To(BeEmpty())
+		})
+
+		It("should skip the revisions of objects that are too large", func() {
+			snapshot := getCMSnapshot()
+			Expect(unstructured.SetNestedField(snapshot.Object, strings.Repeat("x", revisionMaxObjectSize), "data", "large")).To(Succeed())
+			Expect(reconciler.addRevision(remediationinstance, snapshot, logger)).To(Succeed())
+			Expect(remediationinstance.Status.Revisions).To(BeEmpty())
+			Expect(recorder.Events).To(Receive(ContainSubstring("RevisionTooLarge")))
+		})
+	})
+
+	Context("previewing remediations", func() {
+		var dryRunErr error
+
+		BeforeEach(func() {
+			dryRunErr = nil
+			// The fake client returns dry-run objects as they were sent,
+			// dry-run patches are instead made against a copy of the live
+			// object so that the preview is the patched object
+			reconciler.Client = interceptor.NewClient(reconciler.Client.(runtimeclient.WithWatch), interceptor.Funcs{
+				Create: func(ctx context.Context, c runtimeclient.WithWatch, obj runtimeclient.Object, opts ...runtimeclient.CreateOption) error {
+					createOpts := &runtimeclient.CreateOptions{}
+					if createOpts.ApplyOptions(opts); len(createOpts.DryRun) > 0 && dryRunErr != nil {
+						return dryRunErr
+					}
+					return c.Create(ctx, obj, opts...)
+				},
+				Patch: func(ctx context.Context, c runtimeclient.WithWatch, obj runtimeclient.Object, patch runtimeclient.Patch, opts ...runtimeclient.PatchOption) error {
+					patchOpts := &runtimeclient.PatchOptions{}
+					if patchOpts.ApplyOptions(opts); len(patchOpts.DryRun) == 0 {
+						return c.Patch(ctx, obj, patch, opts...)
+					} else if dryRunErr != nil {
+						return dryRunErr
+					}
+					live := &unstructured.Unstructured{}
+					live.SetGroupVersionKind(obj.GetObjectKind().GroupVersionKind())
+					if err := c.Get(ctx, runtimeclient.ObjectKeyFromObject(obj), live); err != nil {
+						return err
+					}
+					return fake.NewClientBuilder().WithObjects(live).Build().Patch(ctx, obj, patch)
+				},
+			})
+
+			cm := &corev1.ConfigMap{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "ConfigMap",
+					APIVersion: "v1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-cm",
+					Namespace: "test-ns",
+				},
+				Data: map[string]string{
+					"key": "val",
+				},
+			}
+			unstructuredCM, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cm)
+			Expect(err).ToNot(HaveOccurred())
+			remediationinstance.Spec.Current.Object = &unstructured.Unstructured{
+				Object: unstructuredCM,
+			}
+			remediationinstance.Spec.Apply = true
+			remediationinstance.Spec.DryRun = true
+			err = reconciler.Client.Update(context.TODO(), remediationinstance)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("with no existing object", func() {
+			It("should preview the creation of the object", func() {
+				err := reconciler.reconcileRemediation(remediationinstance, logger)
+				Expect(err).To(BeNil())
+
+				By("the object should not be created")
+				err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "my-cm", Namespace: "test-ns"}, &corev1.ConfigMap{})
+				Expect(kerrors.IsNotFound(err)).To(BeTrue())
+
+				By("the status should show the object to be created")
+				dryRun := remediationinstance.Status.DryRun
+				Expect(dryRun).ToNot(BeNil())
+				Expect(dryRun.Operation).To(Equal(compv1alpha1.RemediationDryRunCreate))
+				Expect(dryRun.Diff).To(ContainSubstring("+  key: val"))
+
+				reconciler.setRemediationStatus(remediationinstance, nil, logger)
+				Expect(remediationinstance.Status.ApplicationState).To(Equal(compv1alpha1.RemediationNotApplied))
+			})
+		})
+
+		Context("with an existing object", func() {
+			BeforeEach(func() {
+				cm := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "my-cm",
+						Namespace: "test-ns",
+					},
+					Data: map[string]string{
+						"key":   "old-val",
+						"other": "val",
+					},
+				}
+				err := reconciler.Client.Create(context.TODO(), cm)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should preview the changes to the object", func() {
+				err := reconciler.reconcileRemediation(remediationinstance, logger)
+				Expect(err).To(BeNil())
+
+				By("the object should not be changed")
+				foundCM := &corev1.ConfigMap{}
+				err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "my-cm", Namespace: "test-ns"}, foundCM)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(foundCM.Data["key"]).To(Equal("old-val"))
+
+				By("the status should show the diff")
+				dryRun := remediationinstance.Status.DryRun
+				Expect(dryRun).ToNot(BeNil())
+				Expect(dryRun.Operation).To(Equal(compv1alpha1.RemediationDryRunUpdate))
+				Expect(dryRun.Diff).To(ContainSubstring("-  key: old-val"))
+				Expect(dryRun.Diff).To(ContainSubstring("+  key: val"))
+
+				By("the diff should keep the fields the patch doesn't set")
+				Expect(dryRun.Diff).ToNot(ContainSubstring("-  other: val"))
+			})
+		})
+
+		Context("with an invalid object", func() {
+			BeforeEach(func() {
+				dryRunErr = kerrors.NewBadRequest("the object is invalid")
+			})
+
+			It("should report an error", func() {
+				err := reconciler.reconcileRemediation(remediationinstance, logger)
+				Expect(err).ToNot(BeNil())
+				Expect(common.IsRetriable(err)).To(BeFalse())
+				Expect(remediationinstance.Status.DryRun).To(BeNil())
+
+				reconciler.setRemediationStatus(remediationinstance, err, logger)
+				Expect(remediationinstance.Status.ApplicationState).To(Equal(compv1alpha1.RemediationError))
+				Expect(remediationinstance.Status.ErrorMessage).To(ContainSubstring("the object is invalid"))
+			})
+		})
+	})
+
+	Context("validating remediations in the admission webhook", func() {
+		var (
+			validator *remediationValidator
+			oldRem    *compv1alpha1.ComplianceRemediation
+		)
+
+		BeforeEach(func() {
+			validator = &remediationValidator{}
+			cm := &unstructured.Unstructured{}
+			cm.SetAPIVersion("v1")
+			cm.SetKind("ConfigMap")
+			cm.SetName("my-cm")
+			remediationinstance.Spec.Current.Object = cm
+			oldRem = remediationinstance.DeepCopy()
+			oldRem.Status.Revisions = []compv1alpha1.ComplianceRemediationRevision{
+				{Revision: 1, SecretName: "testRem-rev-1"},
+			}
+		})
+
+		It("rejects remediations without a kind", func() {
+			remediationinstance.Spec.Current.Object.SetKind("")
+			_, err := validator.ValidateCreate(context.TODO(), remediationinstance)
+			Expect(err).To(MatchError(ContainSubstring("spec.current.object is invalid")))
+		})
+
+		It("only accepts reverts to kept revisions", func() {
+			rem := remediationinstance.DeepCopy()
+			rem.Annotations = map[string]string{compv1alpha1.RemediationRevertToRevisionAnnotation: "1"}
+			_, err := validator.ValidateCreate(context.TODO(), rem)
+			Expect(err).To(MatchError(ContainSubstring("revision 1 of the remediation object isn't kept")))
+
+			_, err = validator.ValidateUpdate(context.TODO(), oldRem, rem)
+			Expect(err).To(BeNil())
+
+			rem.Annotations[compv1alpha1.RemediationRevertToRevisionAnnotation] = "2"
+			_, err = validator.ValidateUpdate(context.TODO(), oldRem, rem)
+			Expect(err).To(MatchError(ContainSubstring("revision 2 of the remediation object isn't kept")))
+
+			rem.Annotations[compv1alpha1.RemediationRevertToRevisionAnnotation] = "latest"
+			_, err = validator.ValidateUpdate(context.TODO(), oldRem, rem)
+			Expect(err).To(MatchError(ContainSubstring("must be a revision number")))
+		})
+	})
 })
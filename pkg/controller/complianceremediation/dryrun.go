@@ -0,0 +1,114 @@
+package complianceremediation
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/pmezard/go-difflib/difflib"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+)
+
+// Keep the status well below the object size limit, MachineConfigs in
+// particular can be big
+const maxDryRunDiffSize = 32 * 1024
+
+// previewRemediation dry-runs the create or merge patch that applying the
+// remediation would perform and records in the status how it would change
+// the live object, which is nil if the object doesn't exist yet. Objects the
+// API server rejects are reported as a non-retriable error so that they show
+// up in the remediation status.
+func (r *ReconcileComplianceRemediation) previewRemediation(instance *compv1alpha1.ComplianceRemediation,
+	obj *unstructured.Unstructured, live *unstructured.Unstructured, logger logr.Logger) error {
+	logger.Info("Remediation will be applied in dry-run mode")
+	previous := instance.Status.DryRun
+	instance.Status.DryRun = nil
+
+	// The API server leaves the object as it would store it in preview
+	preview := obj.DeepCopy()
+	var err error
+	if live == nil {
+		instance.AddOwnershipLabels(preview)
+		err = r.createRemediation(preview, logger, client.DryRunAll)
+	} else {
+		err = r.patchRemediation(preview, logger, client.DryRunAll)
+	}
+	if kerrors.IsInvalid(err) || kerrors.IsBadRequest(err) {
+		return common.NewNonRetriableCtrlError("The remediation object is invalid: %w", err)
+	} else if err != nil {
+		return err
+	}
+
+	diff, err := diffObjects(live, preview)
+	if err != nil {
+		return fmt.Errorf("cannot compute the remediation diff: %w", err)
+	}
+	operation := compv1alpha1.RemediationDryRunUpdate
+	if live == nil {
+		operation = compv1alpha1.RemediationDryRunCreate
+	} else if diff == "" {
+		operation = compv1alpha1.RemediationDryRunNoChange
+	}
+	if previous != nil && previous.Operation == operation && previous.Diff == diff {
+		// Keep the status as is, updating the timestamp alone would
+		// trigger another reconcile right away
+		instance.Status.DryRun = previous
+		return nil
+	}
+	instance.Status.DryRun = &compv1alpha1.ComplianceRemediationDryRun{
+		Timestamp: metav1.Now(),
+		Operation: operation,
+		Diff:      diff,
+	}
+	return nil
+}
+
+// diffObjects returns a unified diff of the YAML representation of two
+// objects, leaving out the fields managed by the API server
+func diffObjects(live, preview *unstructured.Unstructured) (string, error) {
+	liveYAML := ""
+	if live != nil {
+		var err error
+		if liveYAML, err = comparableYAML(live); err != nil {
+			return "", err
+		}
+	}
+	previewYAML, err := comparableYAML(preview)
+	if err != nil {
+		return "", err
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(liveYAML),
+		B:        difflib.SplitLines(previewYAML),
+		FromFile: "live",
+		ToFile:   "remediated",
+		Context:  3,
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(diff) > maxDryRunDiffSize {
+		diff = diff[:maxDryRunDiffSize] + "\n... (truncated)\n"
+	}
+	return diff, nil
+}
+
+func comparableYAML(obj *unstructured.Unstructured) (string, error) {
+	o := obj.DeepCopy()
+	for _, field := range []string{"managedFields", "resourceVersion", "generation", "uid", "creationTimestamp"} {
+		unstructured.RemoveNestedField(o.Object, "metadata", field)
+	}
+	unstructured.RemoveNestedField(o.Object, "status")
+	out, err := yaml.Marshal(o.Object)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
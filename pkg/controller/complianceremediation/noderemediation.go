@@ -0,0 +1,250 @@
+package complianceremediation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+// nodeRemediationBackend applies the remediations that configure the nodes,
+// which the content gives as MachineConfigs and KubeletConfigs
+type nodeRemediationBackend interface {
+	// completeObject verifies a remediation and returns the object that is
+	// created in the cluster to apply it. Remediations that don't configure
+	// the nodes are returned unchanged.
+	completeObject(obj *unstructured.Unstructured, rem *compv1alpha1.ComplianceRemediation) (*unstructured.Unstructured, error)
+	// prepare makes sure the object returned by completeObject takes effect
+	// once it's created
+	prepare(obj *unstructured.Unstructured, logger logr.Logger) error
+}
+
+func (r *ReconcileComplianceRemediation) getNodeRemediationBackend() nodeRemediationBackend {
+	if r.NodeRemediationBackend == utils.NodeRemediationBackendDaemonSet {
+		return &daemonSetBackend{r: r}
+	}
+	return &machineConfigBackend{r: r}
+}
+
+// machineConfigBackend applies the node remediations as MachineConfigs and
+// KubeletConfigs rendered for the MachineConfigPool of the scanned nodes
+type machineConfigBackend struct {
+	r *ReconcileComplianceRemediation
+}
+
+func (b *machineConfigBackend) completeObject(obj *unstructured.Unstructured, rem *compv1alpha1.ComplianceRemediation) (*unstructured.Unstructured, error) {
+	if utils.IsMachineConfig(obj) {
+		if err := b.r.verifyAndCompleteMC(obj, rem); err != nil {
+			return nil, err
+		}
+	}
+	//verify if the remediation is kubeletconfig, and process it
+	if utils.IsKubeletConfig(obj) {
+		if err := b.r.verifyAndCompleteKC(obj, rem); err != nil {
+			return nil, err
+		}
+	}
+	return obj, nil
+}
+
+func (b *machineConfigBackend) prepare(_ *unstructured.Unstructured, _ logr.Logger) error {
+	return nil
+}
+
+// daemonSetBackend applies the files and systemd units of the MachineConfig
+// remediations through the node remediation agent. Each remediation is
+// stored in a ConfigMap that the agent applies to the scanned nodes, and
+// restores the nodes from once it's deleted.
+type daemonSetBackend struct {
+	r *ReconcileComplianceRemediation
+}
+
+func (b *daemonSetBackend) completeObject(obj *unstructured.Unstructured, rem *compv1alpha1.ComplianceRemediation) (*unstructured.Unstructured, error) {
+	if utils.IsKubeletConfig(obj) {
+		// Reported like a kind missing from the cluster, so that optional
+		// remediations end up not applied rather than in error
+		gvk := obj.GroupVersionKind()
+		return nil, common.NewNonRetriableCtrlError(
+			"KubeletConfig remediations can't be applied with the %s node remediation backend: %w",
+			utils.NodeRemediationBackendDaemonSet,
+			&meta.NoKindMatchError{GroupKind: gvk.GroupKind(), SearchedVersions: []string{gvk.Version}})
+	}
+	if !utils.IsMachineConfig(obj) {
+		return obj, nil
+	}
+
+	scan := &compv1alpha1.ComplianceScan{}
+	scanKey := types.NamespacedName{Name: rem.Labels[compv1alpha1.ComplianceScanLabel], Namespace: rem.Namespace}
+	if err := b.r.Client.Get(context.TODO(), scanKey, scan); err != nil {
+		return nil, fmt.Errorf("couldn't get scan for MC remediation: %w", err)
+	}
+	selector, err := scan.GetNodeLabelSelector()
+	if err != nil {
+		return nil, common.NewNonRetriableCtrlError("invalid node selector of scan %s: %s", scan.Name, err)
+	}
+
+	mc, err := utils.ParseMachineConfig(rem, obj)
+	if err != nil {
+		return nil, common.WrapNonRetriableCtrlError(err)
+	}
+	nodeRem, err := utils.NodeRemediationFromMachineConfig(mc, selector.String())
+	if err != nil {
+		return nil, common.NewNonRetriableCtrlError("The remediation '%s' can't be applied by the node remediation agent: %s", rem.Name, err)
+	}
+	data, err := json.Marshal(nodeRem)
+	if err != nil {
+		return nil, err
+	}
+
+	cm := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      rem.GetMcName(),
+			Namespace: common.GetComplianceOperatorNamespace(),
+			Labels: map[string]string{
+				compv1alpha1.NodeRemediationLabel: "",
+			},
+			// The agent only applies the ConfigMaps that the remediation
+			// they were rendered from controls
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(rem, compv1alpha1.SchemeGroupVersion.WithKind("ComplianceRemediation")),
+			},
+		},
+		Data: map[string]string{
+			utils.NodeRemediationDataKey: string(data),
+		},
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cm)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: content}, nil
+}
+
+// prepare makes sure the node remediation agent runs on the nodes
+func (b *daemonSetBackend) prepare(obj *unstructured.Unstructured, logger logr.Logger) error {
+	if _, ok := obj.GetLabels()[compv1alpha1.NodeRemediationLabel]; !ok {
+		return nil
+	}
+
+	agent := newNodeRemediationAgent()
+	found := &appsv1.DaemonSet{}
+	err := b.r.Client.Get(context.TODO(), types.NamespacedName{Name: agent.Name, Namespace: agent.Namespace}, found)
+	if err == nil {
+		// The agent is updated along with the operator, which changes its
+		// image
+		if equality.Semantic.DeepDerivative(agent.Spec.Template, found.Spec.Template) {
+			return nil
+		}
+		logger.Info("Updating the node remediation agent", "DaemonSet.Name", agent.Name)
+		found.Spec.Template = agent.Spec.Template
+		return b.r.Client.Update(context.TODO(), found)
+	} else if !kerrors.IsNotFound(err) {
+		return err
+	}
+
+	logger.Info("Creating the node remediation agent", "DaemonSet.Name", agent.Name)
+	err = b.r.Client.Create(context.TODO(), agent)
+	if kerrors.IsForbidden(err) {
+		return common.NewNonRetriableCtrlError(
+			"Unable to create the node remediation agent. "+
+				"Please update the compliance-operator's permissions: %s", err)
+	} else if kerrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+func newNodeRemediationAgent() *appsv1.DaemonSet {
+	trueP := true
+	namespace := common.GetComplianceOperatorNamespace()
+	labels := map[string]string{
+		"app": utils.NodeRemediationAgentName,
+	}
+
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      utils.NodeRemediationAgentName,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: utils.NodeRemediationAgentName,
+					HostPID:            true,
+					// The agent needs to run on all the nodes that could be
+					// remediated
+					Tolerations: []corev1.Toleration{
+						{
+							Operator: corev1.TolerationOpExists,
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:  "agent",
+							Image: utils.GetComponentImage(utils.OPERATOR),
+							Command: []string{
+								"compliance-operator", "node-remediation-agent",
+								"--namespace=" + namespace,
+								"--host-root=/host",
+							},
+							Env: []corev1.EnvVar{
+								{
+									Name: "NODE_NAME",
+									ValueFrom: &corev1.EnvVarSource{
+										FieldRef: &corev1.ObjectFieldSelector{
+											FieldPath: "spec.nodeName",
+										},
+									},
+								},
+							},
+							SecurityContext: &corev1.SecurityContext{
+								Privileged: &trueP,
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "host",
+									MountPath: "/host",
+								},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "host",
+							VolumeSource: corev1.VolumeSource{
+								HostPath: &corev1.HostPathVolumeSource{
+									Path: "/",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
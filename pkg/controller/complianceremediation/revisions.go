@@ -0,0 +1,275 @@
+package complianceremediation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+const (
+	defaultRevisionHistoryLimit = 3
+	// The key of the revision Secrets that holds the object as JSON
+	revisionObjectKey = "object.json"
+	// Leave some room below the 1MiB object size limit for the metadata
+	revisionMaxObjectSize = 900 * 1024
+)
+
+func revisionHistoryLimit(rem *compv1alpha1.ComplianceRemediation) int {
+	if rem.Spec.RevisionHistoryLimit == nil {
+		return defaultRevisionHistoryLimit
+	}
+	return int(*rem.Spec.RevisionHistoryLimit)
+}
+
+// snapshotObject returns the live object without the fields the API server
+// manages, so that it can be restored later on
+func snapshotObject(live *unstructured.Unstructured) *unstructured.Unstructured {
+	snapshot := live.DeepCopy()
+	for _, field := range []string{"managedFields", "resourceVersion", "generation", "uid", "creationTimestamp", "selfLink"} {
+		unstructured.RemoveNestedField(snapshot.Object, "metadata", field)
+	}
+	unstructured.RemoveNestedField(snapshot.Object, "status")
+	return snapshot
+}
+
+// patchRemediationWithRevision patches the live object and, if that changed
+// it, records the object as it was before in the remediation's revisions
+func (r *ReconcileComplianceRemediation) patchRemediationWithRevision(instance *compv1alpha1.ComplianceRemediation,
+	obj *unstructured.Unstructured, live *unstructured.Unstructured, logger logr.Logger) error {
+	snapshot := snapshotObject(live)
+	if err := r.patchRemediation(obj, logger); err != nil {
+		return err
+	}
+
+	// The patch leaves the object as the API server stored it in obj
+	before, err := comparableYAML(snapshot)
+	if err != nil {
+		return fmt.Errorf("cannot compare the remediation object: %w", err)
+	}
+	after, err := comparableYAML(obj)
+	if err != nil {
+		return fmt.Errorf("cannot compare the remediation object: %w", err)
+	}
+	if before == after {
+		return nil
+	}
+	return r.addRevision(instance, snapshot, logger)
+}
+
+// addRevision keeps the snapshot in a Secret owned by the remediation and
+// appends it to the remediation's revisions, deleting the oldest ones beyond
+// the history limit. Snapshots too large to be kept are skipped rather than
+// failing the patch that was already made.
+func (r *ReconcileComplianceRemediation) addRevision(instance *compv1alpha1.ComplianceRemediation,
+	snapshot *unstructured.Unstructured, logger logr.Logger) error {
+	limit := revisionHistoryLimit(instance)
+	if limit == 0 {
+		err := r.deleteRevisions(instance, instance.Status.Revisions)
+		instance.Status.Revisions = nil
+		return err
+	}
+
+	var next int64 = 1
+	if n := len(instance.Status.Revisions); n > 0 {
+		next = instance.Status.Revisions[n-1].Revision + 1
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("cannot serialize the remediation object: %w", err)
+	}
+	if len(data) > revisionMaxObjectSize {
+		logger.Info("Not recording the object before the patch, it is too large", "Revision", next, "Size", len(data))
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "RevisionTooLarge",
+			"Revision %d of %s %s is %d bytes, over the limit of %d bytes, it can't be reverted to",
+			next, snapshot.GetKind(), snapshot.GetName(), len(data), revisionMaxObjectSize)
+		return nil
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      utils.DNSLengthName("rev-", "%s-rev-%d", instance.Name, next),
+			Namespace: instance.Namespace,
+			Labels: map[string]string{
+				compv1alpha1.RemediationRevisionLabel: instance.Name,
+			},
+		},
+		Data: map[string][]byte{
+			revisionObjectKey: data,
+		},
+	}
+	if err := controllerutil.SetControllerReference(instance, secret, r.Scheme); err != nil {
+		return err
+	}
+	if err := r.Client.Create(context.TODO(), secret); kerrors.IsAlreadyExists(err) {
+		// Left over from a reconcile that failed to record the revision
+		found := &corev1.Secret{}
+		if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, found); err != nil {
+			return err
+		}
+		found.Labels = secret.Labels
+		found.Data = secret.Data
+		found.OwnerReferences = secret.OwnerReferences
+		if err := r.Client.Update(context.TODO(), found); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	revisions := append(instance.Status.Revisions, compv1alpha1.ComplianceRemediationRevision{
+		Revision:   next,
+		Timestamp:  metav1.Now(),
+		SecretName: secret.Name,
+	})
+	if len(revisions) > limit {
+		if err := r.deleteRevisions(instance, revisions[:len(revisions)-limit]); err != nil {
+			return err
+		}
+		revisions = revisions[len(revisions)-limit:]
+	}
+	instance.Status.Revisions = revisions
+	logger.Info("Recorded the object before the patch", "Revision", next, "Secret.Name", secret.Name)
+	return nil
+}
+
+// deleteRevisions deletes the Secrets keeping the given revisions
+func (r *ReconcileComplianceRemediation) deleteRevisions(instance *compv1alpha1.ComplianceRemediation,
+	revisions []compv1alpha1.ComplianceRemediationRevision) error {
+	for _, rev := range revisions {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      rev.SecretName,
+				Namespace: instance.Namespace,
+			},
+		}
+		if err := r.Client.Delete(context.TODO(), secret); err != nil && !kerrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// getRevisionObject reads the object kept in the Secret of a revision, or
+// returns nil if the Secret is gone
+func (r *ReconcileComplianceRemediation) getRevisionObject(instance *compv1alpha1.ComplianceRemediation,
+	rev *compv1alpha1.ComplianceRemediationRevision) (*unstructured.Unstructured, error) {
+	secret := &corev1.Secret{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: rev.SecretName, Namespace: instance.Namespace}, secret)
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{}
+	if err := json.Unmarshal(secret.Data[revisionObjectKey], obj); err != nil {
+		return nil, common.NewNonRetriableCtrlError("cannot read revision %d from Secret %s: %s", rev.Revision, rev.SecretName, err)
+	}
+	return obj, nil
+}
+
+// handleRevert restores the remediation object to the revision requested
+// through the annotation. The remediation is then unapplied and marked as
+// reverted so that neither the remediation nor the suite applies it again
+// until an administrator does.
+func (r *ReconcileComplianceRemediation) handleRevert(instance *compv1alpha1.ComplianceRemediation, logger logr.Logger) (reconcile.Result, error) {
+	value := instance.Annotations[compv1alpha1.RemediationRevertToRevisionAnnotation]
+	revision, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return r.dropRevertRequest(instance, logger, fmt.Sprintf("Invalid revision %q", value))
+	}
+	rev := instance.Status.GetRevision(revision)
+	if rev == nil {
+		return r.dropRevertRequest(instance, logger, fmt.Sprintf("Revision %d isn't kept anymore", revision))
+	}
+	obj, err := r.getRevisionObject(instance, rev)
+	if err != nil {
+		if !common.IsRetriable(err) {
+			return r.dropRevertRequest(instance, logger, err.Error())
+		}
+		return common.ReturnWithRetriableError(logger, err)
+	} else if obj == nil {
+		return r.dropRevertRequest(instance, logger, fmt.Sprintf("Revision %d isn't kept anymore", revision))
+	}
+
+	revLogger := logger.WithValues("Revision", revision, "Object.Name", obj.GetName(),
+		"Object.Namespace", obj.GetNamespace(), "Object.Kind", obj.GetKind())
+	if err := r.restoreRevision(obj, revLogger); err != nil {
+		if !common.IsRetriable(err) {
+			return r.dropRevertRequest(instance, revLogger, err.Error())
+		}
+		return common.ReturnWithRetriableError(revLogger, err)
+	}
+
+	rCopy := instance.DeepCopy()
+	delete(rCopy.Annotations, compv1alpha1.RemediationRevertToRevisionAnnotation)
+	rCopy.Spec.Apply = false
+	if err := r.Client.Update(context.TODO(), rCopy); err != nil {
+		return reconcile.Result{}, fmt.Errorf("unapplying the reverted remediation: %w", err)
+	}
+	rCopy.Status.ApplicationState = compv1alpha1.RemediationReverted
+	rCopy.Status.ErrorMessage = ""
+	rCopy.Status.LastRevert = &compv1alpha1.ComplianceRemediationRevert{
+		Revision:  revision,
+		Timestamp: metav1.Now(),
+	}
+	if err := r.Client.Status().Update(context.TODO(), rCopy); err != nil {
+		return reconcile.Result{}, fmt.Errorf("updating the status of the reverted remediation: %w", err)
+	}
+	r.Metrics.IncComplianceRemediationStatus(rCopy.Name, rCopy.Status)
+	r.Recorder.Eventf(rCopy, corev1.EventTypeNormal, "RemediationReverted",
+		"Reverted %s %s to revision %d", obj.GetKind(), obj.GetName(), revision)
+	revLogger.Info("Reverted the remediation object")
+	return reconcile.Result{}, nil
+}
+
+// restoreRevision replaces the live object with the one kept in a
+// revision, re-creating it if it's gone
+func (r *ReconcileComplianceRemediation) restoreRevision(obj *unstructured.Unstructured, logger logr.Logger) error {
+	live := obj.DeepCopy()
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}, live)
+	if kerrors.IsNotFound(err) {
+		logger.Info("The object is gone, re-creating it")
+		err = r.Client.Create(context.TODO(), obj)
+	} else if err == nil {
+		// Update rather than patch, so that the fields the remediation
+		// added are removed as well
+		obj.SetResourceVersion(live.GetResourceVersion())
+		err = r.Client.Update(context.TODO(), obj)
+	}
+
+	if kerrors.IsForbidden(err) {
+		return common.NewNonRetriableCtrlError(
+			"Unable to revert fix object from ComplianceRemediation. "+
+				"Please update the compliance-operator's permissions: %s", err)
+	}
+	return err
+}
+
+// dropRevertRequest removes a revert request that can't be honored and
+// reports why in an event
+func (r *ReconcileComplianceRemediation) dropRevertRequest(instance *compv1alpha1.ComplianceRemediation,
+	logger logr.Logger, reason string) (reconcile.Result, error) {
+	logger.Info("Can't revert the remediation object", "Reason", reason)
+	r.Recorder.Eventf(instance, corev1.EventTypeWarning, "RemediationRevertFailed",
+		"Can't revert the remediation object: %s", reason)
+	rCopy := instance.DeepCopy()
+	delete(rCopy.Annotations, compv1alpha1.RemediationRevertToRevisionAnnotation)
+	if err := r.Client.Update(context.TODO(), rCopy); err != nil {
+		return reconcile.Result{}, fmt.Errorf("removing the revert annotation: %w", err)
+	}
+	return reconcile.Result{}, nil
+}
@@ -0,0 +1,204 @@
+package complianceremediation
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+// AddWebhooks registers the webhooks validating ComplianceRemediations and
+// the sign-offs of their RemediationApprovals
+func AddWebhooks(mgr manager.Manager) error {
+	if err := ctrl.NewWebhookManagedBy(mgr).
+		For(&compv1alpha1.ComplianceRemediation{}).
+		WithValidator(&remediationValidator{}).
+		Complete(); err != nil {
+		return err
+	}
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&compv1alpha1.RemediationApproval{}).
+		WithValidator(&remediationApprovalValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-compliance-openshift-io-v1alpha1-complianceremediation,mutating=false,failurePolicy=fail,sideEffects=None,groups=compliance.openshift.io,resources=complianceremediations,verbs=create;update,versions=v1alpha1,name=vcomplianceremediation.compliance.openshift.io,admissionReviewVersions=v1
+
+// remediationValidator rejects the remediations the controller can't apply
+// and the revert requests it can't honor
+type remediationValidator struct{}
+
+var _ webhook.CustomValidator = &remediationValidator{}
+
+func (v *remediationValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	rem, ok := obj.(*compv1alpha1.ComplianceRemediation)
+	if !ok {
+		return nil, fmt.Errorf("expected a ComplianceRemediation but got a %T", obj)
+	}
+	if err := validateRemediationObjects(rem); err != nil {
+		return nil, err
+	}
+	return nil, validateRevertRequest(rem, nil)
+}
+
+func (v *remediationValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldRem, ok := oldObj.(*compv1alpha1.ComplianceRemediation)
+	if !ok {
+		return nil, fmt.Errorf("expected a ComplianceRemediation but got a %T", oldObj)
+	}
+	rem, ok := newObj.(*compv1alpha1.ComplianceRemediation)
+	if !ok {
+		return nil, fmt.Errorf("expected a ComplianceRemediation but got a %T", newObj)
+	}
+	if rem.DeletionTimestamp != nil {
+		return nil, nil
+	}
+	if err := validateRemediationObjects(rem); err != nil {
+		return nil, err
+	}
+	return nil, validateRevertRequest(rem, oldRem)
+}
+
+func (v *remediationValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateRemediationObjects makes sure that the kind of the objects of the
+// remediation is known. MachineConfigs and KubeletConfigs get their names
+// from the controller, so those aren't required.
+func validateRemediationObjects(rem *compv1alpha1.ComplianceRemediation) error {
+	if rem.Spec.Current.Object == nil {
+		return fmt.Errorf("No remediation specified. spec.current.object is empty")
+	}
+	if err := validateRemediationObject(rem.Spec.Current.Object); err != nil {
+		return fmt.Errorf("spec.current.object is invalid: %w", err)
+	}
+	if rem.Spec.Outdated.Object != nil {
+		if err := validateRemediationObject(rem.Spec.Outdated.Object); err != nil {
+			return fmt.Errorf("spec.outdated.object is invalid: %w", err)
+		}
+	}
+	return nil
+}
+
+func validateRemediationObject(obj *unstructured.Unstructured) error {
+	if obj.GetAPIVersion() == "" || obj.GetKind() == "" {
+		return fmt.Errorf("the apiVersion and kind must be set")
+	}
+	return nil
+}
+
+// validateRevertRequest makes sure that a revert is only requested to one of
+// the revisions the remediation keeps, which only exist once the remediation
+// was created
+func validateRevertRequest(rem, oldRem *compv1alpha1.ComplianceRemediation) error {
+	value, ok := rem.Annotations[compv1alpha1.RemediationRevertToRevisionAnnotation]
+	if !ok {
+		return nil
+	}
+	if oldRem != nil {
+		if oldValue, requested := oldRem.Annotations[compv1alpha1.RemediationRevertToRevisionAnnotation]; requested && oldValue == value {
+			// Already accepted
+			return nil
+		}
+	}
+	revision, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("the %s annotation must be a revision number, got %q",
+			compv1alpha1.RemediationRevertToRevisionAnnotation, value)
+	}
+	if oldRem == nil || oldRem.Status.GetRevision(revision) == nil {
+		return fmt.Errorf("revision %d of the remediation object isn't kept", revision)
+	}
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-compliance-openshift-io-v1alpha1-remediationapproval,mutating=false,failurePolicy=fail,sideEffects=None,groups=compliance.openshift.io,resources=remediationapprovals,verbs=create;update,versions=v1alpha1,name=vremediationapproval.compliance.openshift.io,admissionReviewVersions=v1
+
+// remediationApprovalValidator makes sure that the sign-offs of a
+// RemediationApproval can be trusted: users may only add, change or remove
+// the sign-off carrying their own name.
+type remediationApprovalValidator struct{}
+
+var _ webhook.CustomValidator = &remediationApprovalValidator{}
+
+func (v *remediationApprovalValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	approval, ok := obj.(*compv1alpha1.RemediationApproval)
+	if !ok {
+		return nil, fmt.Errorf("expected a RemediationApproval but got a %T", obj)
+	}
+	return nil, validateSignOffs(ctx, nil, approval.Spec.SignOffs)
+}
+
+func (v *remediationApprovalValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldApproval, ok := oldObj.(*compv1alpha1.RemediationApproval)
+	if !ok {
+		return nil, fmt.Errorf("expected a RemediationApproval but got a %T", oldObj)
+	}
+	newApproval, ok := newObj.(*compv1alpha1.RemediationApproval)
+	if !ok {
+		return nil, fmt.Errorf("expected a RemediationApproval but got a %T", newObj)
+	}
+	return nil, validateSignOffs(ctx, oldApproval.Spec.SignOffs, newApproval.Spec.SignOffs)
+}
+
+func (v *remediationApprovalValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateSignOffs checks that the user making the request only touched
+// their own sign-off, and that nobody signed off twice
+func validateSignOffs(ctx context.Context, oldSignOffs, newSignOffs []compv1alpha1.RemediationSignOff) error {
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	user := req.UserInfo.Username
+
+	oldByApprover, err := signOffsByApprover(oldSignOffs)
+	if err != nil {
+		return err
+	}
+	newByApprover, err := signOffsByApprover(newSignOffs)
+	if err != nil {
+		return err
+	}
+
+	for approver, signOff := range newByApprover {
+		if approver == user {
+			continue
+		}
+		oldSignOff, ok := oldByApprover[approver]
+		if !ok {
+			return fmt.Errorf("user %s can't sign off on behalf of %s", user, approver)
+		}
+		if oldSignOff != signOff {
+			return fmt.Errorf("user %s can't change the sign-off of %s", user, approver)
+		}
+	}
+	for approver := range oldByApprover {
+		if _, ok := newByApprover[approver]; !ok && approver != user {
+			return fmt.Errorf("user %s can't remove the sign-off of %s", user, approver)
+		}
+	}
+	return nil
+}
+
+func signOffsByApprover(signOffs []compv1alpha1.RemediationSignOff) (map[string]compv1alpha1.RemediationSignOff, error) {
+	byApprover := make(map[string]compv1alpha1.RemediationSignOff, len(signOffs))
+	for _, signOff := range signOffs {
+		if _, ok := byApprover[signOff.SignedOffBy]; ok {
+			return nil, fmt.Errorf("%s signed off more than once", signOff.SignedOffBy)
+		}
+		byApprover[signOff.SignedOffBy] = signOff
+	}
+	return byApprover, nil
+}
@@ -0,0 +1,40 @@
+package complianceremediation
+
+import (
+	"context"
+
+	"github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type webhookConfigurationMapper struct {
+	client.Client
+}
+
+// Map enqueues the remediations waiting for approvals, so that their
+// sign-offs are counted once the approval webhook is registered, or ignored
+// again once it's not
+func (t *webhookConfigurationMapper) Map(ctx context.Context, _ client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+
+	remList := v1alpha1.ComplianceRemediationList{}
+	err := t.List(ctx, &remList)
+	if err != nil {
+		return requests
+	}
+
+	for _, rem := range remList.Items {
+		if rem.Status.Approvals == nil {
+			continue
+		}
+		objKey := types.NamespacedName{
+			Name:      rem.GetName(),
+			Namespace: rem.GetNamespace(),
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: objKey})
+	}
+
+	return requests
+}
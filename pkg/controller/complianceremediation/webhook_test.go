@@ -0,0 +1,67 @@
+package complianceremediation
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+var _ = Describe("Validating RemediationApprovals in the admission webhook", func() {
+	var validator *remediationApprovalValidator
+
+	requestBy := func(user string) context.Context {
+		return admission.NewContextWithRequest(context.TODO(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				UserInfo: authenticationv1.UserInfo{Username: user},
+			},
+		})
+	}
+	newApproval := func(signOffs ...compv1alpha1.RemediationSignOff) *compv1alpha1.RemediationApproval {
+		return &compv1alpha1.RemediationApproval{Spec: compv1alpha1.RemediationApprovalSpec{SignOffs: signOffs}}
+	}
+
+	BeforeEach(func() {
+		validator = &remediationApprovalValidator{}
+	})
+
+	It("lets users sign off a remediation themselves", func() {
+		_, err := validator.ValidateCreate(requestBy("alice"), newApproval(compv1alpha1.RemediationSignOff{SignedOffBy: "alice"}))
+		Expect(err).To(BeNil())
+
+		old := newApproval(compv1alpha1.RemediationSignOff{SignedOffBy: "alice"})
+		_, err = validator.ValidateUpdate(requestBy("bob"), old,
+			newApproval(compv1alpha1.RemediationSignOff{SignedOffBy: "alice"}, compv1alpha1.RemediationSignOff{SignedOffBy: "bob", Comment: "CHG-42"}))
+		Expect(err).To(BeNil())
+	})
+
+	It("rejects sign-offs on behalf of other users", func() {
+		_, err := validator.ValidateCreate(requestBy("alice"), newApproval(compv1alpha1.RemediationSignOff{SignedOffBy: "bob"}))
+		Expect(err).To(MatchError(ContainSubstring("can't sign off on behalf of bob")))
+	})
+
+	It("rejects duplicated sign-offs", func() {
+		_, err := validator.ValidateCreate(requestBy("alice"),
+			newApproval(compv1alpha1.RemediationSignOff{SignedOffBy: "alice"}, compv1alpha1.RemediationSignOff{SignedOffBy: "alice"}))
+		Expect(err).To(MatchError(ContainSubstring("signed off more than once")))
+	})
+
+	It("only lets users change or withdraw their own sign-off", func() {
+		old := newApproval(compv1alpha1.RemediationSignOff{SignedOffBy: "alice"}, compv1alpha1.RemediationSignOff{SignedOffBy: "bob"})
+
+		_, err := validator.ValidateUpdate(requestBy("bob"), old,
+			newApproval(compv1alpha1.RemediationSignOff{SignedOffBy: "alice", Comment: "changed"}, compv1alpha1.RemediationSignOff{SignedOffBy: "bob"}))
+		Expect(err).To(MatchError(ContainSubstring("can't change the sign-off of alice")))
+
+		_, err = validator.ValidateUpdate(requestBy("bob"), old, newApproval(compv1alpha1.RemediationSignOff{SignedOffBy: "bob"}))
+		Expect(err).To(MatchError(ContainSubstring("can't remove the sign-off of alice")))
+
+		_, err = validator.ValidateUpdate(requestBy("bob"), old, newApproval(compv1alpha1.RemediationSignOff{SignedOffBy: "alice"}))
+		Expect(err).To(BeNil())
+	})
+})
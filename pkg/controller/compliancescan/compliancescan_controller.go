@@ -21,6 +21,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/record"
@@ -124,6 +125,7 @@ type ReconcileComplianceScan struct {
 //+kubebuilder:rbac:groups=scheduling.k8s.io,resources=priorityclasses,verbs=get,list,watch
 //+kubebuilder:rbac:groups=cluster.open-cluster-management.io,resources=clusterclaims,verbs=get,list,watch
 //+kubebuilder:rbac:groups=config.openshift.io,resources=infrastructures,verbs=get,list,watch
+//+kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get,create,delete
 
 // Reconcile reads that state of the cluster for a ComplianceScan object and makes changes based on the state read
 // and what is in the ComplianceScan.Spec
@@ -248,6 +250,27 @@ func (r *ReconcileComplianceScan) validate(instance *compv1alpha1.ComplianceScan
 		return false, nil
 	}
 
+	// validate node label selector
+	if _, err := instance.GetNodeLabelSelector(); err != nil {
+		if instance.Status.Phase == compv1alpha1.PhaseDone {
+			return false, nil
+		}
+		r.Recorder.Event(instance, corev1.EventTypeWarning, "InvalidNodeLabelSelector",
+			"The node label selector was invalid")
+		instanceCopy := instance.DeepCopy()
+		instanceCopy.Status.Result = compv1alpha1.ResultError
+		instanceCopy.Status.ErrorMessage = fmt.Sprintf("Node label selector is not valid: %s", err)
+		instanceCopy.Status.Phase = compv1alpha1.PhaseDone
+		instanceCopy.Status.EndTimestamp = &metav1.Time{Time: time.Now()}
+		instanceCopy.Status.SetConditionInvalid()
+		updateErr := r.Client.Status().Update(context.TODO(), instanceCopy)
+		if updateErr != nil {
+			return false, updateErr
+		}
+		r.Metrics.IncComplianceScanStatus(instanceCopy.Name, instanceCopy.Status)
+		return false, nil
+	}
+
 	// Set default storage if missing
 	if instance.Spec.RawResultStorage.Size == "" {
 		instanceCopy := instance.DeepCopy()
@@ -279,6 +302,22 @@ func (r *ReconcileComplianceScan) validate(instance *compv1alpha1.ComplianceScan
 		return false, nil
 	}
 
+	// validate the object storage settings
+	if err := validateRawResultStorage(&instance.Spec.RawResultStorage); err != nil {
+		instanceCopy := instance.DeepCopy()
+		instanceCopy.Status.ErrorMessage = fmt.Sprintf("Invalid raw result storage: %s", err)
+		instanceCopy.Status.Result = compv1alpha1.ResultError
+		instanceCopy.Status.Phase = compv1alpha1.PhaseDone
+		instanceCopy.Status.EndTimestamp = &metav1.Time{Time: time.Now()}
+		instanceCopy.Status.SetConditionInvalid()
+		err := r.Client.Status().Update(context.TODO(), instanceCopy)
+		if err != nil {
+			return false, err
+		}
+		r.Metrics.IncComplianceScanStatus(instanceCopy.Name, instanceCopy.Status)
+		return false, nil
+	}
+
 	return true, nil
 }
 
@@ -289,6 +328,7 @@ func (r *ReconcileComplianceScan) phasePendingHandler(instance *compv1alpha1.Com
 		instanceCopy := instance.DeepCopy()
 		delete(instanceCopy.Annotations, compv1alpha1.ComplianceCheckCountAnnotation)
 		delete(instanceCopy.Annotations, compv1alpha1.ComplianceScanRescanAnnotation)
+		delete(instanceCopy.Annotations, compv1alpha1.ComplianceScanRescanModeAnnotation)
 		delete(instanceCopy.Annotations, compv1alpha1.ComplianceScanTimeoutAnnotation)
 		err := r.Client.Update(context.TODO(), instanceCopy)
 		return reconcile.Result{}, err
@@ -332,19 +372,12 @@ func (r *ReconcileComplianceScan) phaseLaunchingHandler(h scanTypeHandler, logge
 		return reconcile.Result{}, err
 	}
 
-	if err = r.handleRootCASecret(scan, logger); err != nil {
-		logger.Error(err, "Cannot create CA secret")
-		return reconcile.Result{}, err
-	}
-
-	if err = r.handleResultServerSecret(scan, logger); err != nil {
-		logger.Error(err, "Cannot create result server cert secret")
-		return reconcile.Result{}, err
-	}
-
-	if err = r.handleResultClientSecret(scan, logger); err != nil {
-		logger.Error(err, "Cannot create result Client cert secret")
-		return reconcile.Result{}, err
+	if ready, err := r.certIssuerForScan(scan).ensureCertificates(scan, logger); err != nil || !ready {
+		if err != nil {
+			logger.Error(err, "Cannot issue the result server certificates")
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{Requeue: true, RequeueAfter: requeueAfterDefault}, nil
 	}
 
 	if resume, err := r.handleRawResultsForScan(scan, logger); err != nil || !resume {
@@ -444,11 +477,28 @@ func (r *ReconcileComplianceScan) createKubeletConfigCM(instance *compv1alpha1.C
 	return nil
 }
 
+// getNodeScanSelector returns the selector of the nodes a scan runs on. We
+// only scan Linux nodes.
+func getNodeScanSelector(instance *compv1alpha1.ComplianceScan) (labels.Selector, error) {
+	selector, err := instance.GetNodeLabelSelector()
+	if err != nil {
+		return nil, fmt.Errorf("invalid node label selector: %w", err)
+	}
+	osRequirement, err := labels.NewRequirement("kubernetes.io/os", selection.Equals, []string{"linux"})
+	if err != nil {
+		return nil, err
+	}
+	return selector.Add(*osRequirement), nil
+}
+
 func (r *ReconcileComplianceScan) getNodesForScan(instance *compv1alpha1.ComplianceScan) (corev1.NodeList, error) {
 	nodes := corev1.NodeList{}
-	nodeScanSelector := map[string]string{"kubernetes.io/os": "linux"}
+	selector, err := getNodeScanSelector(instance)
+	if err != nil {
+		return nodes, err
+	}
 	listOpts := client.ListOptions{
-		LabelSelector: labels.SelectorFromSet(labels.Merge(instance.Spec.NodeSelector, nodeScanSelector)),
+		LabelSelector: selector,
 	}
 
 	if err := r.Client.List(context.TODO(), &nodes, &listOpts); err != nil {
@@ -512,6 +562,12 @@ func (r *ReconcileComplianceScan) phaseRunningHandler(h scanTypeHandler, logger
 	}
 
 	if running {
+		// Renew the result server certificates if they're about to expire
+		// while the scan runs
+		if _, err := r.certIssuerForScan(h.getScan()).ensureCertificates(h.getScan(), logger); err != nil {
+			logger.Error(err, "Cannot renew the result server certificates")
+			return reconcile.Result{}, err
+		}
 		// The platform scan pod is still running, go back to queue.
 		return reconcile.Result{Requeue: true, RequeueAfter: requeueAfterDefault}, nil
 	}
@@ -652,11 +708,19 @@ func (r *ReconcileComplianceScan) phaseAggregatingHandler(h scanTypeHandler, log
 	}
 
 	// count the number of checks that were run
-	checkCount, err := r.fetchResultCounts(instance, logger)
+	checks, err := r.fetchCheckResults(instance, logger)
 	if err != nil {
 		logger.Error(err, "Cannot fetch the number of checks")
 		return reconcile.Result{}, err
 	}
+	checkCount := len(checks)
+
+	// The failures waived by a ComplianceException don't count, while the
+	// manual checks attested by a ComplianceAttestation do. The result comes
+	// straight from OpenSCAP, so previous waivers and attestations don't
+	// matter.
+	instance.Status.WaivedChecks, instance.Status.AttestedChecks = 0, 0
+	instance.Status.UpdateResultWithChecks(checks)
 
 	instanceCopy := instance.DeepCopy()
 
@@ -683,22 +747,98 @@ func (r *ReconcileComplianceScan) phaseAggregatingHandler(h scanTypeHandler, log
 	return reconcile.Result{}, nil
 }
 
-func (r *ReconcileComplianceScan) fetchResultCounts(scan *compv1alpha1.ComplianceScan, logger logr.Logger) (int, error) {
+func (r *ReconcileComplianceScan) fetchCheckResults(scan *compv1alpha1.ComplianceScan, logger logr.Logger) ([]compv1alpha1.ComplianceCheckResult, error) {
 	var checkList compv1alpha1.ComplianceCheckResultList
 	checkListOpts := client.MatchingLabels{
 		compv1alpha1.ComplianceScanLabel: scan.Name,
 	}
 	if err := r.Client.List(context.TODO(), &checkList, &checkListOpts); err != nil {
 		logger.Error(err, "Cannot list the check results")
-		return 0, err
+		return nil, err
+	}
+	return checkList.Items, nil
+}
+
+// setCheckResultMetrics exposes the check results of a finished scan as
+// metrics. The scan is already done at this point, so errors getting the
+// controls are only logged.
+func (r *ReconcileComplianceScan) setCheckResultMetrics(scan *compv1alpha1.ComplianceScan, checks []compv1alpha1.ComplianceCheckResult, logger logr.Logger) {
+	r.Metrics.SetComplianceScanCheckResults(scan.Name, checks)
+
+	failing := []metrics.FailingCheck{}
+	limit := scan.Spec.FailingCheckMetricsLimit
+	if limit > 0 {
+		var err error
+		failing, err = r.getFailingChecks(scan, checks)
+		if err != nil {
+			logger.Error(err, "Cannot get the controls of the failing checks")
+		}
+	}
+	if omitted := r.Metrics.SetComplianceScanFailingChecks(scan.Name, failing, limit); omitted > 0 {
+		logger.Info("Not all failing checks are exposed as metrics", "limit", limit, "omitted", omitted)
+	}
+}
+
+// getFailingChecks returns the failing checks along with the controls their
+// rule maps to
+func (r *ReconcileComplianceScan) getFailingChecks(scan *compv1alpha1.ComplianceScan, checks []compv1alpha1.ComplianceCheckResult) ([]metrics.FailingCheck, error) {
+	failing := []metrics.FailingCheck{}
+	for i := range checks {
+		if checks[i].Status == compv1alpha1.CheckResultFail {
+			failing = append(failing, metrics.FailingCheck{
+				Name:     checks[i].Name,
+				Severity: checks[i].Severity,
+			})
+		}
+	}
+	if len(failing) == 0 {
+		return failing, nil
+	}
+
+	ruleList := &compv1alpha1.RuleList{}
+	if err := r.Client.List(context.TODO(), ruleList, client.InNamespace(scan.Namespace)); err != nil {
+		// Still expose the failing checks, just without their controls
+		return failing, err
+	}
+	rules := utils.RulesByID(ruleList.Items)
+	ids := map[string]string{}
+	for i := range checks {
+		ids[checks[i].Name] = checks[i].ID
+	}
+	for i := range failing {
+		if rule := rules[ids[failing[i].Name]]; rule != nil {
+			failing[i].Controls = utils.GetRuleControls(rule)
+		}
 	}
-	return len(checkList.Items), nil
+	return failing, nil
 }
 
 func (r *ReconcileComplianceScan) phaseDoneHandler(h scanTypeHandler, instance *compv1alpha1.ComplianceScan, logger logr.Logger, doDelete bool) (reconcile.Result, error) {
 	var err error
 	logger.Info("Phase: Done")
 
+	if !doDelete && !instance.NeedsRescan() {
+		// The metrics only live in memory, setting them on every reconcile
+		// repopulates them once the operator restarts
+		checks, err := r.fetchCheckResults(instance, logger)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		r.setCheckResultMetrics(instance, checks, logger)
+	}
+
+	var targetedRescan *compv1alpha1.ComplianceScanTargetedRescan
+	if instance.NeedsRescan() {
+		targetedRescan, err = r.getTargetedRescan(instance)
+		if err != nil {
+			logger.Error(err, "Cannot get the checks to rescan")
+			return reconcile.Result{}, err
+		}
+		if instance.NeedsFailedRescan() && targetedRescan == nil {
+			return r.skipFailedRescan(instance, logger)
+		}
+	}
+
 	// the scan pods and the aggregator are done at this point and can be cleaned up
 	// unless we are running in debug mode and thus requested them to stay
 	// around for later inspection
@@ -727,18 +867,8 @@ func (r *ReconcileComplianceScan) phaseDoneHandler(h scanTypeHandler, instance *
 			return reconcile.Result{}, err
 		}
 
-		if err = r.deleteResultServerSecret(instance, logger); err != nil {
-			logger.Error(err, "Cannot delete result server cert secret")
-			return reconcile.Result{}, err
-		}
-
-		if err = r.deleteResultClientSecret(instance, logger); err != nil {
-			logger.Error(err, "Cannot delete result Client cert secret")
-			return reconcile.Result{}, err
-		}
-
-		if err = r.deleteRootCASecret(instance, logger); err != nil {
-			logger.Error(err, "Cannot delete CA secret")
+		if err = r.certIssuerForScan(instance).deleteCertificates(instance, logger); err != nil {
+			logger.Error(err, "Cannot delete the result server certificates")
 			return reconcile.Result{}, err
 		}
 
@@ -753,7 +883,14 @@ func (r *ReconcileComplianceScan) phaseDoneHandler(h scanTypeHandler, instance *
 		}
 
 		if instance.NeedsRescan() {
-			if err = r.deleteResultConfigMaps(instance, logger); err != nil {
+			// The results of the nodes that aren't rescanned are kept
+			// so they can be merged with the new ones
+			if targetedRescan != nil && len(targetedRescan.Nodes) > 0 {
+				err = r.deleteTargetedResultConfigMaps(instance, targetedRescan.Nodes, logger)
+			} else {
+				err = r.deleteResultConfigMaps(instance, logger)
+			}
+			if err != nil {
 				logger.Error(err, "Cannot delete result ConfigMaps")
 				return reconcile.Result{}, err
 			}
@@ -764,6 +901,8 @@ func (r *ReconcileComplianceScan) phaseDoneHandler(h scanTypeHandler, instance *
 			instanceCopy.Status.Phase = compv1alpha1.PhasePending
 			instanceCopy.Status.Result = compv1alpha1.ResultNotAvailable
 			instanceCopy.Status.StartTimestamp = &metav1.Time{Time: time.Now()}
+			instanceCopy.Status.Nodes = nil
+			instanceCopy.Status.TargetedRescan = targetedRescan
 			if instance.Status.CurrentIndex == math.MaxInt64 {
 				instanceCopy.Status.CurrentIndex = 0
 			} else {
@@ -822,6 +961,8 @@ func (r *ReconcileComplianceScan) scanDeleteHandler(instance *compv1alpha1.Compl
 			return reconcile.Result{}, err
 		}
 
+		r.Metrics.DeleteComplianceScanCheckMetrics(scanToBeDeleted.Name)
+
 		// remove our finalizer from the list and update it.
 		scanToBeDeleted.ObjectMeta.Finalizers = common.RemoveFinalizer(scanToBeDeleted.ObjectMeta.Finalizers, compv1alpha1.ScanFinalizer)
 		if err := r.Client.Update(context.TODO(), scanToBeDeleted); err != nil {
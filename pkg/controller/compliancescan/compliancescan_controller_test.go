@@ -12,12 +12,15 @@ import (
 	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
 	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics"
 	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics/metricsfakes"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
 	"github.com/go-logr/logr"
 	"github.com/go-logr/zapr"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -155,7 +158,8 @@ var _ = Describe("Testing compliancescan controller phases", func() {
 
 		objs = append(objs, nodeinstance1, nodeinstance2, caSecret, serverSecret, clientSecret, ns)
 		scheme := scheme.Scheme
-		scheme.AddKnownTypes(compv1alpha1.SchemeGroupVersion, compliancescaninstance)
+		scheme.AddKnownTypes(compv1alpha1.SchemeGroupVersion, compliancescaninstance,
+			&compv1alpha1.ComplianceCheckResult{}, &compv1alpha1.ComplianceCheckResultList{})
 
 		statusObjs := []runtimeclient.Object{}
 		statusObjs = append(statusObjs, compliancescaninstance)
@@ -233,6 +237,31 @@ var _ = Describe("Testing compliancescan controller phases", func() {
 				Expect(scan.Status.Result).To(Equal(compv1alpha1.ResultError))
 			})
 		})
+
+		Context("With the s3 RawResultStorage backend and no bucket", func() {
+			It("report an error and move to phase DONE", func() {
+				compliancescaninstance.Spec.RawResultStorage.Backend = compv1alpha1.RawResultStorageBackendS3
+				compliancescaninstance.Spec.RawResultStorage.S3 = &compv1alpha1.S3StorageSettings{
+					Endpoint:              "https://s3.example.com",
+					CredentialsSecretName: "s3-credentials",
+				}
+				compliancescaninstance.Status.Phase = "PENDING"
+				cont, err := reconciler.validate(compliancescaninstance, logger)
+				Expect(cont).To(BeFalse())
+				Expect(err).To(BeNil())
+
+				scan := &compv1alpha1.ComplianceScan{}
+				key := types.NamespacedName{
+					Name:      compliancescaninstance.Name,
+					Namespace: compliancescaninstance.Namespace,
+				}
+				err = reconciler.Client.Get(context.TODO(), key, scan)
+				Expect(err).To(BeNil())
+				Expect(scan.Status.Phase).To(Equal(compv1alpha1.PhaseDone))
+				Expect(scan.Status.Result).To(Equal(compv1alpha1.ResultError))
+				Expect(scan.Status.ErrorMessage).To(ContainSubstring("bucket"))
+			})
+		})
 	})
 	Context("On the PENDING phase", func() {
 		It("should update the compliancescan instance to phase LAUNCHING", func() {
@@ -314,6 +343,65 @@ var _ = Describe("Testing compliancescan controller phases", func() {
 			})
 		})
 
+		Context("With the s3 RawResultStorage backend", func() {
+			BeforeEach(func() {
+				compliancescaninstance.Spec.RawResultStorage.Backend = compv1alpha1.RawResultStorageBackendS3
+				compliancescaninstance.Spec.RawResultStorage.S3 = &compv1alpha1.S3StorageSettings{
+					Endpoint:              "https://minio.example.com:9000",
+					Bucket:                "results",
+					Prefix:                "cluster-a",
+					CredentialsSecretName: "s3-credentials",
+					TLSSecretName:         "s3-ca",
+				}
+			})
+			It("should not create a PVC and have the result server upload to the bucket", func() {
+				result, err := reconciler.phaseLaunchingHandler(handler, logger)
+				Expect(result).ToNot(BeNil())
+				Expect(err).To(BeNil())
+				Expect(compliancescaninstance.Status.Phase).To(Equal(compv1alpha1.PhaseRunning))
+
+				pvc := &corev1.PersistentVolumeClaim{}
+				err = reconciler.Client.Get(context.TODO(), types.NamespacedName{
+					Name:      getPVCForScanName(compliancescaninstance.Name),
+					Namespace: common.GetComplianceOperatorNamespace(),
+				}, pvc)
+				Expect(kerrors.IsNotFound(err)).To(BeTrue())
+
+				rs := &appsv1.Deployment{}
+				err = reconciler.Client.Get(context.TODO(), types.NamespacedName{
+					Name:      getResultServerName(compliancescaninstance),
+					Namespace: common.GetComplianceOperatorNamespace(),
+				}, rs)
+				Expect(err).To(BeNil())
+				podSpec := rs.Spec.Template.Spec
+				for _, v := range podSpec.Volumes {
+					Expect(v.PersistentVolumeClaim).To(BeNil())
+				}
+				Expect(podSpec.Volumes).To(ContainElement(HaveField("Name", "s3-tls")))
+				container := podSpec.Containers[0]
+
+				By("giving the uploads a writable directory to be spooled to")
+				Expect(*container.SecurityContext.ReadOnlyRootFilesystem).To(BeTrue())
+				Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: "tmp-dir", MountPath: "/tmp"}))
+				Expect(podSpec.Volumes).To(ContainElement(corev1.Volume{
+					Name:         "tmp-dir",
+					VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+				}))
+
+				Expect(container.Command).To(ContainElements(
+					"--storage-backend=s3",
+					"--s3-endpoint=https://minio.example.com:9000",
+					"--s3-bucket=results",
+					"--s3-region=us-east-1",
+					"--s3-prefix=cluster-a/test",
+					"--s3-ca=/etc/pki/s3/ca.crt",
+				))
+				Expect(container.Env).To(HaveLen(2))
+				Expect(container.Env[0].ValueFrom.SecretKeyRef.Name).To(Equal("s3-credentials"))
+				Expect(container.Env[0].ValueFrom.SecretKeyRef.Key).To(Equal("AWS_ACCESS_KEY_ID"))
+			})
+		})
+
 		Context("with the PVC set", func() {
 			BeforeEach(func() {
 				compliancescaninstance.Status.ResultsStorage.Name = getPVCForScanName(compliancescaninstance.Name)
@@ -423,6 +511,142 @@ var _ = Describe("Testing compliancescan controller phases", func() {
 		})
 	})
 
+	Context("Rolling through the nodes", func() {
+		var setPodSucceeded = func(node *corev1.Node) {
+			pod := &corev1.Pod{}
+			key := types.NamespacedName{
+				Name:      getPodForNodeName(compliancescaninstance.Name, node.Name),
+				Namespace: common.GetComplianceOperatorNamespace(),
+			}
+			err := reconciler.Client.Get(context.TODO(), key, pod)
+			Expect(err).To(BeNil())
+			pod.Status.Phase = corev1.PodSucceeded
+			err = reconciler.Client.Status().Update(context.TODO(), pod)
+			Expect(err).To(BeNil())
+		}
+		var countPods = func() int {
+			pods := &corev1.PodList{}
+			err := reconciler.Client.List(context.TODO(), pods)
+			Expect(err).To(BeNil())
+			return len(pods.Items)
+		}
+
+		BeforeEach(func() {
+			compliancescaninstance.Spec.MaxConcurrentNodes = 1
+			err := reconciler.Client.Update(context.TODO(), compliancescaninstance)
+			Expect(err).To(BeNil())
+			compliancescaninstance.Status.ResultsStorage.Name = getPVCForScanName(compliancescaninstance.Name)
+			compliancescaninstance.Status.ResultsStorage.Namespace = common.GetComplianceOperatorNamespace()
+			err = reconciler.Client.Status().Update(context.TODO(), compliancescaninstance)
+			Expect(err).To(BeNil())
+		})
+
+		It("should only scan as many nodes at once as allowed", func() {
+			_, err := reconciler.phaseLaunchingHandler(handler, logger)
+			Expect(err).To(BeNil())
+			Expect(compliancescaninstance.Status.Phase).To(Equal(compv1alpha1.PhaseRunning))
+			Expect(compliancescaninstance.Status.Nodes).To(Equal([]compv1alpha1.ComplianceScanNodeStatus{
+				{Name: nodeinstance1.Name, Phase: compv1alpha1.PhaseRunning},
+				{Name: nodeinstance2.Name, Phase: compv1alpha1.PhasePending},
+			}))
+			Expect(countPods()).To(Equal(1))
+
+			By("waiting for the scan of the first node")
+			_, err = reconciler.phaseRunningHandler(handler, logger)
+			Expect(err).To(BeNil())
+			Expect(compliancescaninstance.Status.Phase).To(Equal(compv1alpha1.PhaseRunning))
+			Expect(countPods()).To(Equal(1))
+
+			By("scanning the second node once the first one is done")
+			setPodSucceeded(nodeinstance1)
+			_, err = reconciler.phaseRunningHandler(handler, logger)
+			Expect(err).To(BeNil())
+			Expect(compliancescaninstance.Status.Phase).To(Equal(compv1alpha1.PhaseRunning))
+			Expect(compliancescaninstance.Status.Nodes).To(Equal([]compv1alpha1.ComplianceScanNodeStatus{
+				{Name: nodeinstance1.Name, Phase: compv1alpha1.PhaseDone},
+				{Name: nodeinstance2.Name, Phase: compv1alpha1.PhaseRunning},
+			}))
+			Expect(countPods()).To(Equal(2))
+
+			ready, _, err := handler.shouldLaunchAggregator()
+			Expect(err).To(BeNil())
+			Expect(ready).To(BeFalse())
+
+			By("aggregating once every node is done")
+			setPodSucceeded(nodeinstance2)
+			_, err = reconciler.phaseRunningHandler(handler, logger)
+			Expect(err).To(BeNil())
+			Expect(compliancescaninstance.Status.Phase).To(Equal(compv1alpha1.PhaseAggregating))
+			Expect(compliancescaninstance.Status.Nodes).To(Equal([]compv1alpha1.ComplianceScanNodeStatus{
+				{Name: nodeinstance1.Name, Phase: compv1alpha1.PhaseDone},
+				{Name: nodeinstance2.Name, Phase: compv1alpha1.PhaseDone},
+			}))
+		})
+	})
+
+	Context("Getting the failing checks for the metrics", func() {
+		var checks []compv1alpha1.ComplianceCheckResult
+
+		BeforeEach(func() {
+			rule := &compv1alpha1.Rule{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "ocp4-accounts-restrict-service-account-tokens",
+					Annotations: map[string]string{
+						compv1alpha1.RuleControlAnnotationPrefix + "NIST-800-53": "AC-6;AC-2",
+						compv1alpha1.RuleControlAnnotationPrefix + "CIS-OCP":     "5.1.6",
+					},
+				},
+				RulePayload: compv1alpha1.RulePayload{
+					ID: "xccdf_org.ssgproject.content_rule_accounts_restrict_service_account_tokens",
+				},
+			}
+			reconciler.Scheme.AddKnownTypes(compv1alpha1.SchemeGroupVersion, rule, &compv1alpha1.RuleList{})
+			err := reconciler.Client.Create(context.TODO(), rule)
+			Expect(err).To(BeNil())
+
+			checks = []compv1alpha1.ComplianceCheckResult{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-accounts-restrict-service-account-tokens"},
+					ID:         rule.ID,
+					Status:     compv1alpha1.CheckResultFail,
+					Severity:   compv1alpha1.CheckResultSeverityMedium,
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-unknown-rule"},
+					ID:         "xccdf_org.ssgproject.content_rule_unknown",
+					Status:     compv1alpha1.CheckResultFail,
+					Severity:   compv1alpha1.CheckResultSeverityHigh,
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-passing"},
+					ID:         rule.ID,
+					Status:     compv1alpha1.CheckResultPass,
+					Severity:   compv1alpha1.CheckResultSeverityHigh,
+				},
+			}
+		})
+
+		It("should only return the failing checks labelled with their controls", func() {
+			failing, err := reconciler.getFailingChecks(compliancescaninstance, checks)
+			Expect(err).To(BeNil())
+			Expect(failing).To(ConsistOf(
+				metrics.FailingCheck{
+					Name:     "test-accounts-restrict-service-account-tokens",
+					Severity: compv1alpha1.CheckResultSeverityMedium,
+					Controls: []utils.RuleControl{
+						{Standard: "CIS-OCP", ID: "5.1.6"},
+						{Standard: "NIST-800-53", ID: "AC-2"},
+						{Standard: "NIST-800-53", ID: "AC-6"},
+					},
+				},
+				metrics.FailingCheck{
+					Name:     "test-unknown-rule",
+					Severity: compv1alpha1.CheckResultSeverityHigh,
+				},
+			))
+		})
+	})
+
 	Context("On the DONE phase", func() {
 		Context("with delete flag off", func() {
 			BeforeEach(func() {
@@ -551,5 +775,142 @@ var _ = Describe("Testing compliancescan controller phases", func() {
 				Expect(secrets.Items).To(BeEmpty())
 			})
 		})
+		Context("rescanning only the failed checks", func() {
+			const (
+				passingRule      = "xccdf_org.ssgproject.content_rule_passing"
+				inconsistentRule = "xccdf_org.ssgproject.content_rule_inconsistent"
+			)
+			var resultCMExists = func(node *corev1.Node) bool {
+				key := types.NamespacedName{
+					Name:      getConfigMapForNodeName(compliancescaninstance.Name, node.Name),
+					Namespace: common.GetComplianceOperatorNamespace(),
+				}
+				err := reconciler.Client.Get(context.TODO(), key, &corev1.ConfigMap{})
+				if kerrors.IsNotFound(err) {
+					return false
+				}
+				Expect(err).To(BeNil())
+				return true
+			}
+
+			BeforeEach(func() {
+				results := []*compv1alpha1.ComplianceCheckResult{
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "test-passing"},
+						ID:         passingRule,
+						Status:     compv1alpha1.CheckResultPass,
+					},
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "test-inconsistent",
+							Annotations: map[string]string{
+								compv1alpha1.ComplianceCheckResultMostCommonAnnotation:         string(compv1alpha1.CheckResultPass),
+								compv1alpha1.ComplianceCheckResultInconsistentSourceAnnotation: nodeinstance2.Name + ":FAIL",
+							},
+						},
+						ID:     inconsistentRule,
+						Status: compv1alpha1.CheckResultInconsistent,
+					},
+				}
+				for _, res := range results {
+					res.Labels = map[string]string{compv1alpha1.ComplianceScanLabel: compliancescaninstance.Name}
+					Expect(reconciler.Client.Create(context.TODO(), res)).To(Succeed())
+				}
+				for _, node := range []*corev1.Node{nodeinstance1, nodeinstance2} {
+					cm := &corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      getConfigMapForNodeName(compliancescaninstance.Name, node.Name),
+							Namespace: common.GetComplianceOperatorNamespace(),
+							Labels: map[string]string{
+								compv1alpha1.ComplianceScanLabel: compliancescaninstance.Name,
+								compv1alpha1.ResultLabel:         "",
+							},
+						},
+					}
+					Expect(reconciler.Client.Create(context.TODO(), cm)).To(Succeed())
+				}
+
+				compliancescaninstance.Annotations = map[string]string{
+					compv1alpha1.ComplianceScanRescanAnnotation:     "",
+					compv1alpha1.ComplianceScanRescanModeAnnotation: compv1alpha1.RescanModeFailed,
+				}
+				err := reconciler.Client.Update(context.TODO(), compliancescaninstance)
+				Expect(err).To(BeNil())
+				compliancescaninstance.Status.Phase = compv1alpha1.PhaseDone
+				err = reconciler.Client.Status().Update(context.TODO(), compliancescaninstance)
+				Expect(err).To(BeNil())
+			})
+
+			It("should only rescan the failed checks on the nodes they failed on", func() {
+				_, err := reconciler.phaseDoneHandler(handler, compliancescaninstance, logger, dontDelete)
+				Expect(err).To(BeNil())
+
+				scan := &compv1alpha1.ComplianceScan{}
+				err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: compliancescaninstance.Name}, scan)
+				Expect(err).To(BeNil())
+				Expect(scan.Status.Phase).To(Equal(compv1alpha1.PhasePending))
+				Expect(scan.Status.TargetedRescan).To(Equal(&compv1alpha1.ComplianceScanTargetedRescan{
+					Rules: []string{inconsistentRule},
+					Nodes: []string{nodeinstance2.Name},
+				}))
+				// The results of the node that isn't rescanned are kept
+				Expect(resultCMExists(nodeinstance1)).To(BeTrue())
+				Expect(resultCMExists(nodeinstance2)).To(BeFalse())
+
+				By("only scanning the node the check failed on")
+				scan.Status.ResultsStorage.Name = getPVCForScanName(scan.Name)
+				scan.Status.ResultsStorage.Namespace = common.GetComplianceOperatorNamespace()
+				err = reconciler.Client.Status().Update(context.TODO(), scan)
+				Expect(err).To(BeNil())
+				h, err := getScanTypeHandler(&reconciler, scan, logger)
+				Expect(err).To(BeNil())
+				_, err = reconciler.phaseLaunchingHandler(h, logger)
+				Expect(err).To(BeNil())
+
+				pods := &corev1.PodList{}
+				err = reconciler.Client.List(context.TODO(), pods, client.MatchingLabels{"workload": "scanner"})
+				Expect(err).To(BeNil())
+				Expect(pods.Items).To(HaveLen(1))
+				Expect(pods.Items[0].Name).To(Equal(getPodForNodeName(scan.Name, nodeinstance2.Name)))
+
+				By("evaluating a tailoring selecting the failed checks")
+				cm := &corev1.ConfigMap{}
+				err = reconciler.Client.Get(context.TODO(), types.NamespacedName{
+					Name:      getTargetedRescanTailoringCMName(scan.Name),
+					Namespace: common.GetComplianceOperatorNamespace(),
+				}, cm)
+				Expect(err).To(BeNil())
+				Expect(cm.Data["tailoring.xml"]).To(ContainSubstring(`<xccdf-1.2:select idref="` + inconsistentRule + `" selected="true">`))
+				Expect(cm.Data["tailoring.xml"]).To(ContainSubstring(`<xccdf-1.2:select idref="` + passingRule + `" selected="false">`))
+
+				env := &corev1.ConfigMap{}
+				err = reconciler.Client.Get(context.TODO(), types.NamespacedName{
+					Name:      envCmForScan(scan),
+					Namespace: common.GetComplianceOperatorNamespace(),
+				}, env)
+				Expect(err).To(BeNil())
+				Expect(env.Data[OpenScapProfileEnvName]).To(Equal(getScanProfileID(scan)))
+				Expect(env.Data[OpenScapTailoringDirEnvName]).To(Equal(OpenScapTailoringDir))
+			})
+
+			It("should leave the results alone if no checks failed", func() {
+				err := reconciler.Client.Delete(context.TODO(), &compv1alpha1.ComplianceCheckResult{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-inconsistent"},
+				})
+				Expect(err).To(BeNil())
+
+				_, err = reconciler.phaseDoneHandler(handler, compliancescaninstance, logger, dontDelete)
+				Expect(err).To(BeNil())
+
+				scan := &compv1alpha1.ComplianceScan{}
+				err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: compliancescaninstance.Name}, scan)
+				Expect(err).To(BeNil())
+				Expect(scan.Status.Phase).To(Equal(compv1alpha1.PhaseDone))
+				Expect(scan.Status.TargetedRescan).To(BeNil())
+				Expect(scan.NeedsRescan()).To(BeFalse())
+				Expect(resultCMExists(nodeinstance1)).To(BeTrue())
+				Expect(resultCMExists(nodeinstance2)).To(BeTrue())
+			})
+		})
 	})
 })
@@ -236,7 +236,7 @@ func commonOpenScapEnvCm(name string, scan *compv1alpha1.ComplianceScan) *corev1
 			},
 		},
 		Data: map[string]string{
-			OpenScapProfileEnvName:   scan.Spec.Profile,
+			OpenScapProfileEnvName:   getScanProfileID(scan),
 			OpenScapContentEnvName:   content,
 			OpenScapReportDirEnvName: "/reports",
 		},
@@ -253,7 +253,7 @@ func commonOpenScapEnvCm(name string, scan *compv1alpha1.ComplianceScan) *corev1
 		cm.Data[OpenScapVerbosityeEnvName] = debugEnvVar
 	}
 
-	if scan.Spec.TailoringConfigMap != nil {
+	if hasTailoring(scan) {
 		cm.Data[OpenScapTailoringDirEnvName] = OpenScapTailoringDir
 	}
 
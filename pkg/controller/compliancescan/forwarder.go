@@ -1,26 +1,55 @@
 package compliancescan
 
 import (
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
 )
 
-func NewForwarder(s *compv1alpha1.ComplianceScan) Forwarder {
+// NewForwarder returns the Forwarder implementation configured for the scan.
+// The client is used to fetch any Secrets referenced by the forwarding
+// settings and may be nil if the scan doesn't configure result forwarding.
+func NewForwarder(c client.Reader, s *compv1alpha1.ComplianceScan) (Forwarder, error) {
 	// Figure out what type of forwarding implementation we need based on
 	// scan configuration. By default, use the noopForwarder which doesn't
 	// do anything and maintains backwards compatibility.
+	if rf := s.Spec.ResultForwarding; rf != nil {
+		switch rf.Provider {
+		case compv1alpha1.ResultForwardingProviderHTTP:
+			logf.Log.Info("Forwarding compliance results and remediations over HTTP")
+			f, err := newHTTPForwarder(c, s)
+			if err != nil {
+				return nil, err
+			}
+			return f, nil
+		case compv1alpha1.ResultForwardingProviderSyslog:
+			logf.Log.Info("Forwarding compliance results and remediations over syslog")
+			f, err := newSyslogForwarder(c, s)
+			if err != nil {
+				return nil, err
+			}
+			return f, nil
+		default:
+			return nil, fmt.Errorf("unknown result forwarding provider '%s'", rf.Provider)
+		}
+	}
 	if s.Spec.Debug {
 		logf.Log.Info("Forwarding compliance results and remediations to logs")
-		return logForwarder{}
+		return logForwarder{}, nil
 	}
 	logf.Log.Info("Result and remediation forwarding is disabled")
-	return noopForwarder{}
+	return noopForwarder{}, nil
 }
 
 type Forwarder interface {
 	SendComplianceCheckResult(c *compv1alpha1.ComplianceCheckResult) error
 	SendComplianceRemediation(r *compv1alpha1.ComplianceRemediation) error
+	// Flush delivers anything the implementation still holds on to.
+	// It must be called once all the results were sent.
+	Flush() error
 }
 
 type logForwarder struct{}
@@ -35,6 +64,10 @@ func (f logForwarder) SendComplianceRemediation(r *compv1alpha1.ComplianceRemedi
 	return nil
 }
 
+func (f logForwarder) Flush() error {
+	return nil
+}
+
 type noopForwarder struct{}
 
 func (f noopForwarder) SendComplianceCheckResult(c *compv1alpha1.ComplianceCheckResult) error {
@@ -44,3 +77,7 @@ func (f noopForwarder) SendComplianceCheckResult(c *compv1alpha1.ComplianceCheck
 func (f noopForwarder) SendComplianceRemediation(r *compv1alpha1.ComplianceRemediation) error {
 	return nil
 }
+
+func (f noopForwarder) Flush() error {
+	return nil
+}
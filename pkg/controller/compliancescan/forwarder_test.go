@@ -1,11 +1,25 @@
 package compliancescan
 
 import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/version"
 )
 
 var _ = Describe("Test forwarding factory", func() {
@@ -26,7 +40,8 @@ var _ = Describe("Test forwarding factory", func() {
 					},
 				},
 			}
-			f := NewForwarder(s)
+			f, err := NewForwarder(nil, s)
+			Expect(err).To(BeNil())
 			Expect(f).To(Equal(noopForwarder{}))
 		})
 	})
@@ -48,9 +63,295 @@ var _ = Describe("Test forwarding factory", func() {
 					},
 				},
 			}
-			f := NewForwarder(s)
+			f, err := NewForwarder(nil, s)
+			Expect(err).To(BeNil())
 			Expect(f).To(Equal(logForwarder{}))
 		})
 	})
 
+	Context("With http result forwarding configured", func() {
+		var (
+			s        *compv1alpha1.ComplianceScan
+			server   *httptest.Server
+			mu       sync.Mutex
+			batches  []forwardingBatch
+			failures int
+		)
+
+		BeforeEach(func() {
+			batches = nil
+			failures = 0
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				if failures > 0 {
+					failures--
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				b := forwardingBatch{}
+				if err := json.NewDecoder(req.Body).Decode(&b); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				batches = append(batches, b)
+			}))
+			s = &compv1alpha1.ComplianceScan{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "openshift-compliance",
+					UID:       "1234",
+				},
+				Spec: compv1alpha1.ComplianceScanSpec{
+					ScanType: compv1alpha1.ScanTypeNode,
+					ComplianceScanSettings: compv1alpha1.ComplianceScanSettings{
+						ResultForwarding: &compv1alpha1.ResultForwardingSettings{
+							Provider: compv1alpha1.ResultForwardingProviderHTTP,
+							HTTP: &compv1alpha1.HTTPForwardingSettings{
+								Endpoint:   server.URL,
+								BatchSize:  2,
+								MaxRetries: 2,
+							},
+							ExtraMetadata: map[string]string{"clusterName": "cluster-a"},
+						},
+					},
+				},
+			}
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should send results in batches", func() {
+			f, err := NewForwarder(nil, s)
+			Expect(err).To(BeNil())
+			for _, id := range []string{"rule-a", "rule-b", "rule-c"} {
+				err = f.SendComplianceCheckResult(&compv1alpha1.ComplianceCheckResult{
+					ID:       id,
+					Status:   compv1alpha1.CheckResultFail,
+					Severity: compv1alpha1.CheckResultSeverityHigh,
+				})
+				Expect(err).To(BeNil())
+			}
+			// The first two results fill up a batch, the last one is
+			// only sent on flush
+			Expect(batches).To(HaveLen(1))
+			Expect(f.Flush()).To(Succeed())
+			Expect(batches).To(HaveLen(2))
+
+			Expect(batches[0].Scan).To(Equal("test"))
+			Expect(batches[0].CheckResults).To(HaveLen(2))
+			Expect(batches[0].CheckResults[0].Rule).To(Equal("rule-a"))
+			Expect(batches[0].CheckResults[0].Outcome).To(Equal("FAIL"))
+			Expect(batches[0].CheckResults[0].Subject).To(Equal("cluster-a"))
+			Expect(batches[0].CheckResults[0].AssessmentID).To(Equal("1234-0"))
+			Expect(batches[1].CheckResults).To(HaveLen(1))
+			Expect(batches[1].CheckResults[0].Rule).To(Equal("rule-c"))
+		})
+
+		It("should retry failed requests", func() {
+			failures = 2
+			f, err := NewForwarder(nil, s)
+			Expect(err).To(BeNil())
+			err = f.SendComplianceRemediation(&compv1alpha1.ComplianceRemediation{
+				ObjectMeta: metav1.ObjectMeta{Name: "rem-a"},
+			})
+			Expect(err).To(BeNil())
+			Expect(f.Flush()).To(Succeed())
+			Expect(batches).To(HaveLen(1))
+			Expect(batches[0].Remediations[0].Name).To(Equal("rem-a"))
+		})
+
+		It("should give up after running out of retries", func() {
+			failures = 10
+			f, err := NewForwarder(nil, s)
+			Expect(err).To(BeNil())
+			err = f.SendComplianceCheckResult(&compv1alpha1.ComplianceCheckResult{ID: "rule-a"})
+			Expect(err).To(BeNil())
+			Expect(f.Flush()).NotTo(Succeed())
+			Expect(batches).To(BeEmpty())
+		})
+
+		It("should fail with an invalid endpoint", func() {
+			s.Spec.ResultForwarding.HTTP.Endpoint = "not a url"
+			_, err := NewForwarder(nil, s)
+			Expect(err).NotTo(BeNil())
+		})
+
+		It("should fail if the TLS secret is missing", func() {
+			s.Spec.ResultForwarding.HTTP.TLSSecretName = "missing"
+			c := fake.NewClientBuilder().Build()
+			_, err := NewForwarder(c, s)
+			Expect(err).NotTo(BeNil())
+		})
+	})
+
+	Context("With syslog result forwarding configured", func() {
+		var s *compv1alpha1.ComplianceScan
+
+		newSyslogScan := func(address string, protocol compv1alpha1.SyslogProtocol, format compv1alpha1.SyslogFormat) *compv1alpha1.ComplianceScan {
+			return &compv1alpha1.ComplianceScan{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "openshift-compliance",
+					UID:       "1234",
+				},
+				Spec: compv1alpha1.ComplianceScanSpec{
+					ScanType: compv1alpha1.ScanTypeNode,
+					ComplianceScanSettings: compv1alpha1.ComplianceScanSettings{
+						ResultForwarding: &compv1alpha1.ResultForwardingSettings{
+							Provider: compv1alpha1.ResultForwardingProviderSyslog,
+							Syslog: &compv1alpha1.SyslogForwardingSettings{
+								Address:  address,
+								Protocol: protocol,
+								Format:   format,
+							},
+							ExtraMetadata: map[string]string{"clusterName": "cluster-a"},
+						},
+					},
+				},
+			}
+		}
+
+		checkResult := &compv1alpha1.ComplianceCheckResult{
+			ID:          "xccdf_org.ssgproject.content_rule_audit_enabled",
+			Status:      compv1alpha1.CheckResultFail,
+			Severity:    compv1alpha1.CheckResultSeverityHigh,
+			Description: "Enable auditing\nAuditing must be enabled",
+		}
+
+		// readTCPMessages reads octet counted messages from the first
+		// connection made to the listener
+		readTCPMessages := func(l net.Listener, n int) <-chan []string {
+			out := make(chan []string, 1)
+			go func() {
+				defer GinkgoRecover()
+				conn, err := l.Accept()
+				Expect(err).To(BeNil())
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				msgs := []string{}
+				for i := 0; i < n; i++ {
+					lenStr, err := r.ReadString(' ')
+					Expect(err).To(BeNil())
+					msgLen, err := strconv.Atoi(strings.TrimSpace(lenStr))
+					Expect(err).To(BeNil())
+					buf := make([]byte, msgLen)
+					_, err = io.ReadFull(r, buf)
+					Expect(err).To(BeNil())
+					msgs = append(msgs, string(buf))
+				}
+				out <- msgs
+			}()
+			return out
+		}
+
+		It("should send RFC 5424 messages over TCP", func() {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).To(BeNil())
+			defer l.Close()
+			received := readTCPMessages(l, 2)
+
+			s = newSyslogScan(l.Addr().String(), compv1alpha1.SyslogProtocolTCP, compv1alpha1.SyslogFormatRFC5424)
+			f, err := NewForwarder(nil, s)
+			Expect(err).To(BeNil())
+			Expect(f.SendComplianceCheckResult(checkResult)).To(Succeed())
+			Expect(f.SendComplianceRemediation(&compv1alpha1.ComplianceRemediation{
+				ObjectMeta: metav1.ObjectMeta{Name: "rem-a"},
+			})).To(Succeed())
+			Expect(f.Flush()).To(Succeed())
+
+			var msgs []string
+			Eventually(received).Should(Receive(&msgs))
+			// local0.err
+			Expect(msgs[0]).To(HavePrefix("<131>1 "))
+			Expect(msgs[0]).To(ContainSubstring(" cluster-a compliance-operator - CheckResult "))
+			Expect(msgs[0]).To(ContainSubstring(`[compliance@32473 scan="test" assessmentID="1234-0" rule="xccdf_org.ssgproject.content_rule_audit_enabled" status="FAIL" severity="high"]`))
+			Expect(msgs[0]).To(ContainSubstring(`[extra@32473 clusterName="cluster-a"]`))
+			// local0.info
+			Expect(msgs[1]).To(HavePrefix("<134>1 "))
+			Expect(msgs[1]).To(ContainSubstring(`remediation="rem-a"`))
+		})
+
+		It("should send CEF messages over UDP", func() {
+			conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+			Expect(err).To(BeNil())
+			defer conn.Close()
+
+			s = newSyslogScan(conn.LocalAddr().String(), compv1alpha1.SyslogProtocolUDP, compv1alpha1.SyslogFormatCEF)
+			f, err := NewForwarder(nil, s)
+			Expect(err).To(BeNil())
+			Expect(f.SendComplianceCheckResult(checkResult)).To(Succeed())
+			Expect(f.Flush()).To(Succeed())
+
+			buf := make([]byte, 4096)
+			Expect(conn.SetReadDeadline(time.Now().Add(5 * time.Second))).To(Succeed())
+			n, _, err := conn.ReadFrom(buf)
+			Expect(err).To(BeNil())
+			// The header, the NILVALUE for the structured data and the CEF
+			// event as the MSG
+			Expect(string(buf[:n])).To(MatchRegexp(`^<131>1 \S+ cluster-a compliance-operator - CheckResult - ` +
+				regexp.QuoteMeta("CEF:0|ComplianceAsCode|compliance-operator|"+version.Version+
+					"|xccdf_org.ssgproject.content_rule_audit_enabled|Enable auditing|8|"+
+					"outcome=failure cs1Label=scan cs1=test cs2Label=assessmentID cs2=1234-0 cs3Label=status cs3=FAIL dhost=cluster-a") + `$`))
+		})
+
+		It("should stop sending once connecting failed", func() {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).To(BeNil())
+			// Nothing listens on the address anymore
+			Expect(l.Close()).To(Succeed())
+
+			s = newSyslogScan(l.Addr().String(), compv1alpha1.SyslogProtocolTCP, compv1alpha1.SyslogFormatRFC5424)
+			f, err := NewForwarder(nil, s)
+			Expect(err).To(BeNil())
+			sendErr := f.SendComplianceCheckResult(checkResult)
+			Expect(sendErr).To(MatchError(ContainSubstring("cannot connect to syslog server")))
+
+			By("not connecting again once the server is back")
+			l, err = net.Listen("tcp", l.Addr().String())
+			Expect(err).To(BeNil())
+			defer l.Close()
+			Expect(f.SendComplianceCheckResult(checkResult)).To(Equal(sendErr))
+		})
+
+		It("should give up once the timeout elapsed", func() {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).To(BeNil())
+			defer l.Close()
+			received := readTCPMessages(l, 1)
+
+			s = newSyslogScan(l.Addr().String(), compv1alpha1.SyslogProtocolTCP, compv1alpha1.SyslogFormatRFC5424)
+			s.Spec.ResultForwarding.Syslog.Timeout = "1s"
+			f, err := NewForwarder(nil, s)
+			Expect(err).To(BeNil())
+			Expect(f.SendComplianceCheckResult(checkResult)).To(Succeed())
+			Eventually(received).Should(Receive())
+
+			time.Sleep(time.Second)
+			Expect(f.SendComplianceCheckResult(checkResult)).To(MatchError(ContainSubstring("timed out forwarding results")))
+		})
+
+		It("should fail with an invalid address", func() {
+			s = newSyslogScan("not-an-address", compv1alpha1.SyslogProtocolTCP, compv1alpha1.SyslogFormatRFC5424)
+			_, err := NewForwarder(nil, s)
+			Expect(err).NotTo(BeNil())
+		})
+
+		It("should map severities and statuses to CEF", func() {
+			Expect(cefSeverity(compv1alpha1.CheckResultSeverityHigh)).To(Equal(8))
+			Expect(cefSeverity(compv1alpha1.CheckResultSeverityMedium)).To(Equal(5))
+			Expect(cefSeverity(compv1alpha1.CheckResultSeverityLow)).To(Equal(3))
+			Expect(cefSeverity(compv1alpha1.CheckResultSeverityUnknown)).To(Equal(0))
+			Expect(cefOutcome(compv1alpha1.CheckResultPass)).To(Equal("success"))
+			Expect(cefOutcome(compv1alpha1.CheckResultFail)).To(Equal("failure"))
+			Expect(cefOutcome(compv1alpha1.CheckResultError)).To(Equal("error"))
+		})
+
+		It("should escape CEF and structured data values", func() {
+			Expect(cefEvent("a|b", "name", 0, [][2]string{{"msg", "x=y"}})).To(HaveSuffix(`|a\|b|name|0|msg=x\=y`))
+			Expect(syslogSDElement("id", [][2]string{{"k", `a"b]`}})).To(Equal(`[id k="a\"b\]"]`))
+		})
+	})
 })
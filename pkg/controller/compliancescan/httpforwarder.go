@@ -0,0 +1,244 @@
+package compliancescan
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	backoff "github.com/cenkalti/backoff/v4"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+)
+
+const (
+	defaultForwardingBatchSize  = 50
+	defaultForwardingMaxRetries = 5
+	defaultForwardingTimeout    = 30 * time.Second
+)
+
+// forwardedCheckResult is the payload sent for every ComplianceCheckResult.
+// It follows the Result message described in the results-forwarding
+// enhancement.
+type forwardedCheckResult struct {
+	Subject      string            `json:"subject,omitempty"`
+	Rule         string            `json:"rule"`
+	AssessmentID string            `json:"assessment_id"`
+	Outcome      string            `json:"outcome"`
+	Description  string            `json:"description,omitempty"`
+	Severity     string            `json:"severity,omitempty"`
+	Instructions string            `json:"instructions,omitempty"`
+	Extra        map[string]string `json:"extra,omitempty"`
+}
+
+// forwardedRemediation is the payload sent for every ComplianceRemediation
+type forwardedRemediation struct {
+	Name         string                 `json:"name"`
+	AssessmentID string                 `json:"assessment_id"`
+	Type         string                 `json:"type,omitempty"`
+	Object       map[string]interface{} `json:"object,omitempty"`
+	Extra        map[string]string      `json:"extra,omitempty"`
+}
+
+// forwardingBatch is the body of a single request sent by the httpForwarder
+type forwardingBatch struct {
+	Scan         string                 `json:"scan"`
+	Namespace    string                 `json:"namespace"`
+	CheckResults []forwardedCheckResult `json:"checkResults,omitempty"`
+	Remediations []forwardedRemediation `json:"remediations,omitempty"`
+}
+
+func (b *forwardingBatch) len() int {
+	return len(b.CheckResults) + len(b.Remediations)
+}
+
+// httpForwarder POSTs results to an HTTP endpoint in JSON batches
+type httpForwarder struct {
+	client       *http.Client
+	endpoint     string
+	batchSize    int
+	maxRetries   int
+	assessmentID string
+	extra        map[string]string
+	pending      forwardingBatch
+}
+
+func newHTTPForwarder(c client.Reader, s *compv1alpha1.ComplianceScan) (*httpForwarder, error) {
+	settings := s.Spec.ResultForwarding.HTTP
+	if settings == nil {
+		return nil, fmt.Errorf("the http result forwarding provider requires the 'http' settings")
+	}
+	if _, err := url.ParseRequestURI(settings.Endpoint); err != nil {
+		return nil, fmt.Errorf("invalid result forwarding endpoint: %w", err)
+	}
+
+	timeout := defaultForwardingTimeout
+	if settings.Timeout != "" {
+		var err error
+		timeout, err = time.ParseDuration(settings.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse result forwarding timeout: %w", err)
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if settings.TLSSecretName != "" {
+		tlsConfig, err := forwardingTLSConfig(c, settings.TLSSecretName)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	batchSize := settings.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultForwardingBatchSize
+	}
+	maxRetries := settings.MaxRetries
+	if maxRetries < 0 {
+		maxRetries = defaultForwardingMaxRetries
+	}
+
+	return &httpForwarder{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+		},
+		endpoint:     settings.Endpoint,
+		batchSize:    batchSize,
+		maxRetries:   maxRetries,
+		assessmentID: getAssessmentID(s),
+		extra:        s.Spec.ResultForwarding.ExtraMetadata,
+		pending: forwardingBatch{
+			Scan:      s.Name,
+			Namespace: s.Namespace,
+		},
+	}, nil
+}
+
+// forwardingTLSConfig builds a TLS client configuration out of the client
+// certificate and optional CA stored in the given Secret.
+func forwardingTLSConfig(c client.Reader, secretName string) (*tls.Config, error) {
+	if c == nil {
+		return nil, fmt.Errorf("cannot fetch Secret %s without a client", secretName)
+	}
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Name: secretName, Namespace: common.GetComplianceOperatorNamespace()}
+	if err := c.Get(context.TODO(), key, secret); err != nil {
+		return nil, fmt.Errorf("cannot get result forwarding TLS Secret %s: %w", secretName, err)
+	}
+
+	cert, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, fmt.Errorf("cannot load client certificate from Secret %s: %w", secretName, err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if ca, ok := secret.Data[CACertDataKey]; ok && len(ca) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("cannot parse %s from Secret %s", CACertDataKey, secretName)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+// getAssessmentID returns an ID that's unique to a specific run of a scan
+func getAssessmentID(s *compv1alpha1.ComplianceScan) string {
+	return fmt.Sprintf("%s-%d", s.UID, s.Status.CurrentIndex)
+}
+
+func (f *httpForwarder) SendComplianceCheckResult(c *compv1alpha1.ComplianceCheckResult) error {
+	f.pending.CheckResults = append(f.pending.CheckResults, forwardedCheckResult{
+		Subject:      f.extra["clusterName"],
+		Rule:         c.ID,
+		AssessmentID: f.assessmentID,
+		Outcome:      string(c.Status),
+		Description:  c.Description,
+		Severity:     string(c.Severity),
+		Instructions: c.Instructions,
+		Extra:        f.extra,
+	})
+	return f.flushIfFull()
+}
+
+func (f *httpForwarder) SendComplianceRemediation(r *compv1alpha1.ComplianceRemediation) error {
+	fr := forwardedRemediation{
+		Name:         r.Name,
+		AssessmentID: f.assessmentID,
+		Type:         string(r.Spec.Type),
+		Extra:        f.extra,
+	}
+	if r.Spec.Current.Object != nil {
+		fr.Object = r.Spec.Current.Object.Object
+	}
+	f.pending.Remediations = append(f.pending.Remediations, fr)
+	return f.flushIfFull()
+}
+
+func (f *httpForwarder) flushIfFull() error {
+	if f.pending.len() < f.batchSize {
+		return nil
+	}
+	return f.Flush()
+}
+
+// Flush sends all the pending results. The pending batch is dropped even
+// if delivering it failed so that a broken endpoint doesn't make the
+// batches grow unbounded.
+func (f *httpForwarder) Flush() error {
+	if f.pending.len() == 0 {
+		return nil
+	}
+	body, err := json.Marshal(&f.pending)
+	f.pending.CheckResults = nil
+	f.pending.Remediations = nil
+	if err != nil {
+		return fmt.Errorf("cannot encode forwarded results: %w", err)
+	}
+
+	return backoff.Retry(func() error {
+		return f.post(body)
+	}, backoff.WithMaxRetries(backoff.NewExponentialBackOff(), uint64(f.maxRetries)))
+}
+
+func (f *httpForwarder) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, f.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return backoff.Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Drain the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	respErr := fmt.Errorf("result forwarding endpoint returned %s", resp.Status)
+	// Client errors other than throttling won't go away by retrying
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return backoff.Permanent(respErr)
+	}
+	return respErr
+}
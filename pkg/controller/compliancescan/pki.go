@@ -1,85 +1,396 @@
 package compliancescan
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 
 	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
 	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
 )
 
-func (r *ReconcileComplianceScan) handleRootCASecret(instance *compv1alpha1.ComplianceScan, logger logr.Logger) error {
-	exist, err := secretExists(r.Client, RootCAPrefix+instance.Name, common.GetComplianceOperatorNamespace())
+const (
+	// DefaultCertDuration is how long the result server certificates are
+	// valid for when the scan doesn't say otherwise
+	DefaultCertDuration = 24 * time.Hour
+)
+
+var certManagerCertificateGVK = schema.GroupVersionKind{
+	Group:   "cert-manager.io",
+	Version: "v1",
+	Kind:    "Certificate",
+}
+
+// resultCertIssuer issues the certificates the result server and the result
+// collectors authenticate each other with. The certificates are stored in
+// the result-server-cert-<scan> and result-client-cert-<scan> Secrets, which
+// hold the certificate, the key and the CA bundle to verify the peer with.
+type resultCertIssuer interface {
+	// ensureCertificates issues the certificates, or renews them if they
+	// are about to expire. It returns false if the certificates aren't
+	// ready yet.
+	ensureCertificates(instance *compv1alpha1.ComplianceScan, logger logr.Logger) (bool, error)
+	// deleteCertificates removes what ensureCertificates created
+	deleteCertificates(instance *compv1alpha1.ComplianceScan, logger logr.Logger) error
+}
+
+func (r *ReconcileComplianceScan) certIssuerForScan(instance *compv1alpha1.ComplianceScan) resultCertIssuer {
+	switch instance.Spec.RawResultStorage.GetCertificates().GetIssuer() {
+	case compv1alpha1.ResultCertificateIssuerCA:
+		return &caSecretCertIssuer{r}
+	case compv1alpha1.ResultCertificateIssuerCertManager:
+		return &certManagerCertIssuer{r}
+	}
+	return &selfSignedCertIssuer{r}
+}
+
+// certOptionsForScan returns the options the certificates of the scan are
+// issued with and how long before they expire they're renewed
+func certOptionsForScan(instance *compv1alpha1.ComplianceScan) (utils.CertOptions, time.Duration, error) {
+	settings := instance.Spec.RawResultStorage.GetCertificates()
+	opts := utils.CertOptions{
+		Validity:     DefaultCertDuration,
+		KeyAlgorithm: string(settings.GetKeyAlgorithm()),
+		KeySize:      settings.KeySize,
+	}
+	if settings.Duration != "" {
+		d, err := time.ParseDuration(settings.Duration)
+		if err != nil {
+			return opts, 0, fmt.Errorf("cannot parse the certificate duration %s: %w", settings.Duration, err)
+		}
+		if d <= 0 {
+			return opts, 0, fmt.Errorf("the certificate duration must be positive, got %s", settings.Duration)
+		}
+		opts.Validity = d
+	}
+	renewBefore := opts.Validity / 3
+	if settings.RenewBefore != "" {
+		d, err := time.ParseDuration(settings.RenewBefore)
+		if err != nil {
+			return opts, 0, fmt.Errorf("cannot parse the certificate renewBefore %s: %w", settings.RenewBefore, err)
+		}
+		if d <= 0 || d >= opts.Validity {
+			return opts, 0, fmt.Errorf("the certificate renewBefore must be positive and shorter than its duration, got %s", settings.RenewBefore)
+		}
+		renewBefore = d
+	}
+	return opts, renewBefore, nil
+}
+
+// validateResultCertificates makes sure the settings the selected issuer
+// needs are present
+func validateResultCertificates(settings *compv1alpha1.RawResultStorageSettings) error {
+	certs := settings.GetCertificates()
+	switch certs.GetIssuer() {
+	case compv1alpha1.ResultCertificateIssuerSelfSigned:
+	case compv1alpha1.ResultCertificateIssuerCA:
+		if certs.CASecretName == "" {
+			return fmt.Errorf("the ca certificate issuer requires a caSecretName")
+		}
+	case compv1alpha1.ResultCertificateIssuerCertManager:
+		if certs.IssuerRef == nil || certs.IssuerRef.Name == "" {
+			return fmt.Errorf("the certManager certificate issuer requires an issuerRef")
+		}
+	default:
+		return fmt.Errorf("unknown certificate issuer %s", certs.Issuer)
+	}
+
+	if _, _, err := certOptionsForScan(&compv1alpha1.ComplianceScan{
+		Spec: compv1alpha1.ComplianceScanSpec{
+			ComplianceScanSettings: compv1alpha1.ComplianceScanSettings{RawResultStorage: *settings},
+		},
+	}); err != nil {
+		return err
+	}
+	return utils.ValidateKeyOptions(string(certs.GetKeyAlgorithm()), certs.KeySize)
+}
+
+// ensureLeafSecret issues the certificate in the named Secret if it's
+// missing, about to expire, or not signed by the CA in caCert anymore.
+// bundle is the set of CAs the peer is verified with.
+func (r *ReconcileComplianceScan) ensureLeafSecret(name string, caCert, bundle []byte, renewBefore time.Duration,
+	issue func() (cert, key []byte, err error), logger logr.Logger) error {
+	ns := common.GetComplianceOperatorNamespace()
+	found := &corev1.Secret{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: ns}, found)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err == nil && !leafNeedsRenewal(found, caCert, bundle, renewBefore) {
+		return nil
+	}
+
+	cert, key, err := issue()
 	if err != nil {
 		return err
 	}
-	if exist {
+	secret := certSecret(name, ns, cert, key, bundle)
+	if found.Name == "" {
+		logger.Info("Creating certificate", "Secret.Name", name)
+		err = r.Client.Create(context.TODO(), secret)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
 		return nil
 	}
+	logger.Info("Renewing certificate", "Secret.Name", name)
+	found.Data = secret.Data
+	return r.Client.Update(context.TODO(), found)
+}
+
+func leafNeedsRenewal(secret *corev1.Secret, caCert, bundle []byte, renewBefore time.Duration) bool {
+	cert := secret.Data[corev1.TLSCertKey]
+	if !utils.CertSignedBy(cert, caCert) || !bytes.Equal(secret.Data[CACertDataKey], bundle) {
+		return true
+	}
+	if !utils.CertNeedsRenewal(cert, renewBefore, time.Now()) {
+		return false
+	}
+	// A certificate can't outlive its CA, so there's no point in renewing
+	// it before the CA is
+	leaf, err := utils.ParseCertificatePEM(cert)
+	if err != nil {
+		return true
+	}
+	ca, err := utils.ParseCertificatePEM(caCert)
+	if err != nil {
+		return true
+	}
+	return leaf.NotAfter.Before(ca.NotAfter)
+}
 
-	logger.Info("creating CA", "ComplianceScan.Name", instance.Name)
-	secret, err := makeCASecret(instance, common.GetComplianceOperatorNamespace())
+// ensureLeafSecrets issues the server and the client certificate with the
+// given CA
+func (r *ReconcileComplianceScan) ensureLeafSecrets(instance *compv1alpha1.ComplianceScan, caCert, caKey, bundle []byte, logger logr.Logger) error {
+	opts, renewBefore, err := certOptionsForScan(instance)
+	if err != nil {
+		return err
+	}
+	err = r.ensureLeafSecret(getServerCertSecretName(instance), caCert, bundle, renewBefore, func() ([]byte, []byte, error) {
+		return utils.NewServerCert(caCert, caKey, getResultServerName(instance), opts)
+	}, logger)
 	if err != nil {
 		return err
 	}
+	return r.ensureLeafSecret(getClientCertSecretName(instance), caCert, bundle, renewBefore, func() ([]byte, []byte, error) {
+		return utils.NewClientCert(caCert, caKey, instance.Name+ClientCertInstanceSuffix, opts)
+	}, logger)
+}
 
-	// Create the CA secret.
-	err = r.Client.Create(context.TODO(), secret)
-	if err != nil && !errors.IsAlreadyExists(err) {
+func (r *ReconcileComplianceScan) deleteLeafSecrets(instance *compv1alpha1.ComplianceScan, logger logr.Logger) error {
+	if err := r.deleteResultServerSecret(instance, logger); err != nil {
 		return err
 	}
+	return r.deleteResultClientSecret(instance, logger)
+}
 
-	return nil
+// selfSignedCertIssuer creates a CA for every scan. When the CA is renewed,
+// the previous one is kept in the bundle until the certificates it signed
+// are renewed too.
+type selfSignedCertIssuer struct {
+	r *ReconcileComplianceScan
 }
 
-func (r *ReconcileComplianceScan) handleResultServerSecret(instance *compv1alpha1.ComplianceScan, logger logr.Logger) error {
-	exist, err := secretExists(r.Client, ServerCertPrefix+instance.Name, common.GetComplianceOperatorNamespace())
+func (i *selfSignedCertIssuer) ensureCertificates(instance *compv1alpha1.ComplianceScan, logger logr.Logger) (bool, error) {
+	ca, err := i.r.handleRootCASecret(instance, logger)
 	if err != nil {
+		return false, err
+	}
+	caCert := ca.Data[corev1.TLSCertKey]
+	bundle := append(append([]byte{}, caCert...), ca.Data[CACertDataKey]...)
+	return true, i.r.ensureLeafSecrets(instance, caCert, ca.Data[corev1.TLSPrivateKeyKey], bundle, logger)
+}
+
+func (i *selfSignedCertIssuer) deleteCertificates(instance *compv1alpha1.ComplianceScan, logger logr.Logger) error {
+	if err := i.r.deleteLeafSecrets(instance, logger); err != nil {
 		return err
 	}
-	if exist {
-		return nil
+	return i.r.deleteRootCASecret(instance, logger)
+}
+
+// caSecretCertIssuer signs the certificates with a CA provided by the
+// administrator
+type caSecretCertIssuer struct {
+	r *ReconcileComplianceScan
+}
+
+func (i *caSecretCertIssuer) ensureCertificates(instance *compv1alpha1.ComplianceScan, logger logr.Logger) (bool, error) {
+	name := instance.Spec.RawResultStorage.GetCertificates().CASecretName
+	ca := &corev1.Secret{}
+	key := types.NamespacedName{Name: name, Namespace: common.GetComplianceOperatorNamespace()}
+	if err := i.r.Client.Get(context.TODO(), key, ca); err != nil {
+		if errors.IsNotFound(err) {
+			logger.Info("Waiting for the CA secret to exist", "Secret.Name", name)
+			return false, nil
+		}
+		return false, err
+	}
+	caCert, caKey := ca.Data[corev1.TLSCertKey], ca.Data[corev1.TLSPrivateKeyKey]
+	if len(caCert) == 0 || len(caKey) == 0 {
+		return false, fmt.Errorf("the CA secret %s must have the %s and %s keys", name, corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
 	}
+	bundle := append(append([]byte{}, caCert...), ca.Data[CACertDataKey]...)
+	return true, i.r.ensureLeafSecrets(instance, caCert, caKey, bundle, logger)
+}
+
+func (i *caSecretCertIssuer) deleteCertificates(instance *compv1alpha1.ComplianceScan, logger logr.Logger) error {
+	return i.r.deleteLeafSecrets(instance, logger)
+}
+
+// certManagerCertIssuer requests the certificates from cert-manager, which
+// takes care of renewing them
+type certManagerCertIssuer struct {
+	r *ReconcileComplianceScan
+}
 
-	logger.Info("creating server cert", "ComplianceScan.Name", instance.Name)
-	secret, err := makeServerCertSecret(r.Client, instance, common.GetComplianceOperatorNamespace())
+func (i *certManagerCertIssuer) ensureCertificates(instance *compv1alpha1.ComplianceScan, logger logr.Logger) (bool, error) {
+	server, err := certManagerCertificate(instance, getServerCertSecretName(instance), getResultServerName(instance), "server auth")
 	if err != nil {
-		return err
+		return false, err
+	}
+	client, err := certManagerCertificate(instance, getClientCertSecretName(instance), instance.Name+ClientCertInstanceSuffix, "client auth")
+	if err != nil {
+		return false, err
 	}
 
-	// Create the server cert secret.
-	err = r.Client.Create(context.TODO(), secret)
-	if err != nil && !errors.IsAlreadyExists(err) {
-		return err
+	ready := true
+	for _, cert := range []*unstructured.Unstructured{server, client} {
+		err := i.r.Client.Create(context.TODO(), cert)
+		if err == nil {
+			logger.Info("Created cert-manager Certificate", "Certificate.Name", cert.GetName())
+		} else if !errors.IsAlreadyExists(err) {
+			return false, err
+		}
+
+		secret := &corev1.Secret{}
+		err = i.r.Client.Get(context.TODO(), types.NamespacedName{Name: cert.GetName(), Namespace: cert.GetNamespace()}, secret)
+		if err != nil && !errors.IsNotFound(err) {
+			return false, err
+		}
+		if len(secret.Data[corev1.TLSCertKey]) == 0 || len(secret.Data[CACertDataKey]) == 0 {
+			logger.Info("Waiting for cert-manager to issue the certificate", "Certificate.Name", cert.GetName())
+			ready = false
+		}
 	}
-	return nil
+	return ready, nil
 }
 
-func (r *ReconcileComplianceScan) handleResultClientSecret(instance *compv1alpha1.ComplianceScan, logger logr.Logger) error {
-	exist, err := secretExists(r.Client, ClientCertPrefix+instance.Name, common.GetComplianceOperatorNamespace())
+func (i *certManagerCertIssuer) deleteCertificates(instance *compv1alpha1.ComplianceScan, logger logr.Logger) error {
+	for _, name := range []string{getServerCertSecretName(instance), getClientCertSecretName(instance)} {
+		logger.Info("Deleting cert-manager Certificate", "Certificate.Name", name)
+		cert := &unstructured.Unstructured{}
+		cert.SetGroupVersionKind(certManagerCertificateGVK)
+		cert.SetName(name)
+		cert.SetNamespace(common.GetComplianceOperatorNamespace())
+		err := i.r.Client.Delete(context.TODO(), cert)
+		if err != nil && !errors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+			return err
+		}
+	}
+	// cert-manager leaves the Secrets behind
+	return i.r.deleteLeafSecrets(instance, logger)
+}
+
+// certManagerCertificate returns a cert-manager Certificate that stores the
+// certificate in the Secret of the same name
+func certManagerCertificate(instance *compv1alpha1.ComplianceScan, name, commonName, usage string) (*unstructured.Unstructured, error) {
+	settings := instance.Spec.RawResultStorage.GetCertificates()
+	opts, renewBefore, err := certOptionsForScan(instance)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if exist {
-		return nil
+
+	issuerRef := map[string]interface{}{
+		"name": settings.IssuerRef.Name,
+	}
+	if settings.IssuerRef.Kind != "" {
+		issuerRef["kind"] = settings.IssuerRef.Kind
+	}
+	if settings.IssuerRef.Group != "" {
+		issuerRef["group"] = settings.IssuerRef.Group
+	}
+	privateKey := map[string]interface{}{
+		"algorithm":      string(settings.GetKeyAlgorithm()),
+		"encoding":       "PKCS8",
+		"rotationPolicy": "Always",
+	}
+	if settings.KeySize != 0 {
+		privateKey["size"] = int64(settings.KeySize)
+	}
+	spec := map[string]interface{}{
+		"secretName":  name,
+		"commonName":  commonName,
+		"duration":    opts.Validity.String(),
+		"renewBefore": renewBefore.String(),
+		"usages":      []interface{}{"digital signature", "key encipherment", usage},
+		"privateKey":  privateKey,
+		"issuerRef":   issuerRef,
+	}
+	if usage == "server auth" {
+		spec["dnsNames"] = []interface{}{commonName}
 	}
 
-	logger.Info("creating Client cert", "ComplianceScan.Name", instance.Name)
-	secret, err := makeClientCertSecret(r.Client, instance, common.GetComplianceOperatorNamespace())
-	if err != nil {
-		return err
+	cert := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	cert.SetGroupVersionKind(certManagerCertificateGVK)
+	cert.SetName(name)
+	cert.SetNamespace(common.GetComplianceOperatorNamespace())
+	cert.SetLabels(map[string]string{compv1alpha1.ComplianceScanLabel: instance.Name})
+	return cert, nil
+}
+
+// handleRootCASecret creates the CA of the scan, or renews it if it's about
+// to expire. The previous CA is kept in the ca.crt key of the Secret.
+func (r *ReconcileComplianceScan) handleRootCASecret(instance *compv1alpha1.ComplianceScan, logger logr.Logger) (*corev1.Secret, error) {
+	ns := common.GetComplianceOperatorNamespace()
+	found := &corev1.Secret{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: getCASecretName(instance), Namespace: ns}, found)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
 	}
 
-	// Create the Client cert secret.
-	err = r.Client.Create(context.TODO(), secret)
-	if err != nil && !errors.IsAlreadyExists(err) {
-		return err
+	if errors.IsNotFound(err) {
+		logger.Info("creating CA", "ComplianceScan.Name", instance.Name)
+		secret, err := makeCASecret(instance, ns)
+		if err != nil {
+			return nil, err
+		}
+		err = r.Client.Create(context.TODO(), secret)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return nil, err
+		}
+		return secret, nil
 	}
 
-	return nil
+	_, renewBefore, err := certOptionsForScan(instance)
+	if err != nil {
+		return nil, err
+	}
+	previous := found.Data[corev1.TLSCertKey]
+	if !utils.CertNeedsRenewal(previous, renewBefore, time.Now()) {
+		return found, nil
+	}
+
+	logger.Info("renewing CA", "ComplianceScan.Name", instance.Name)
+	secret, err := makeCASecret(instance, ns)
+	if err != nil {
+		return nil, err
+	}
+	found.Data = secret.Data
+	found.Data[CACertDataKey] = previous
+	if err := r.Client.Update(context.TODO(), found); err != nil {
+		return nil, err
+	}
+	return found, nil
 }
 
 func (r *ReconcileComplianceScan) deleteRootCASecret(instance *compv1alpha1.ComplianceScan, logger logr.Logger) error {
@@ -0,0 +1,305 @@
+package compliancescan
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/ComplianceAsCode/compliance-operator/pkg/apis"
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+var _ = Describe("Result server certificates", func() {
+	var (
+		ns       = common.GetComplianceOperatorNamespace()
+		logger   = logr.Discard()
+		r        *ReconcileComplianceScan
+		scan     *compv1alpha1.ComplianceScan
+		settings *compv1alpha1.ResultCertificateSettings
+		objs     []runtime.Object
+	)
+
+	getSecret := func(name string) *corev1.Secret {
+		secret := &corev1.Secret{}
+		Expect(r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: ns}, secret)).To(Succeed())
+		return secret
+	}
+	secretExists := func(name string) bool {
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: ns}, &corev1.Secret{})
+		if kerrors.IsNotFound(err) {
+			return false
+		}
+		Expect(err).To(BeNil())
+		return true
+	}
+	ensure := func() bool {
+		ready, err := r.certIssuerForScan(scan).ensureCertificates(scan, logger)
+		Expect(err).To(BeNil())
+		return ready
+	}
+	// expectSignedBy checks that the certificates were issued by the given
+	// CA and that the peer is verified with it
+	expectSignedBy := func(caCert []byte) {
+		for _, name := range []string{getServerCertSecretName(scan), getClientCertSecretName(scan)} {
+			secret := getSecret(name)
+			Expect(utils.CertSignedBy(secret.Data[corev1.TLSCertKey], caCert)).To(BeTrue())
+			Expect(string(secret.Data[CACertDataKey])).To(HavePrefix(string(caCert)))
+		}
+	}
+
+	BeforeEach(func() {
+		settings = &compv1alpha1.ResultCertificateSettings{}
+		scan = &compv1alpha1.ComplianceScan{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-scan",
+				Namespace: ns,
+			},
+			Spec: compv1alpha1.ComplianceScanSpec{
+				ScanType: compv1alpha1.ScanTypeNode,
+				ComplianceScanSettings: compv1alpha1.ComplianceScanSettings{
+					RawResultStorage: compv1alpha1.RawResultStorageSettings{
+						Certificates: settings,
+					},
+				},
+			},
+		}
+		objs = nil
+	})
+
+	JustBeforeEach(func() {
+		cscheme := scheme.Scheme
+		Expect(apis.AddToScheme(cscheme)).To(Succeed())
+		r = &ReconcileComplianceScan{
+			Client: fake.NewClientBuilder().WithScheme(cscheme).WithRuntimeObjects(objs...).Build(),
+			Scheme: cscheme,
+		}
+	})
+
+	Context("validating the settings", func() {
+		validate := func() error {
+			return validateResultCertificates(&scan.Spec.RawResultStorage)
+		}
+
+		It("accepts the defaults", func() {
+			scan.Spec.RawResultStorage.Certificates = nil
+			Expect(validate()).To(Succeed())
+		})
+
+		It("requires what the issuer needs", func() {
+			settings.Issuer = compv1alpha1.ResultCertificateIssuerCA
+			Expect(validate()).To(MatchError(ContainSubstring("caSecretName")))
+			settings.CASecretName = "my-ca"
+			Expect(validate()).To(Succeed())
+
+			settings.Issuer = compv1alpha1.ResultCertificateIssuerCertManager
+			Expect(validate()).To(MatchError(ContainSubstring("issuerRef")))
+			settings.IssuerRef = &compv1alpha1.CertManagerIssuerReference{Name: "my-issuer"}
+			Expect(validate()).To(Succeed())
+		})
+
+		It("rejects invalid durations and keys", func() {
+			settings.Duration = "a week"
+			Expect(validate()).ToNot(Succeed())
+			settings.Duration = "2h"
+			settings.RenewBefore = "3h"
+			Expect(validate()).To(MatchError(ContainSubstring("shorter than its duration")))
+			settings.RenewBefore = "30m"
+			Expect(validate()).To(Succeed())
+
+			settings.KeySize = 1024
+			Expect(validate()).ToNot(Succeed())
+			settings.KeyAlgorithm = compv1alpha1.CertificateKeyAlgorithmECDSA
+			settings.KeySize = 384
+			Expect(validate()).To(Succeed())
+		})
+
+		It("rejects the scan settings of the admission webhooks", func() {
+			settings.Issuer = compv1alpha1.ResultCertificateIssuerCertManager
+			err := ValidateScanSettings(&scan.Spec.ComplianceScanSettings)
+			Expect(err).To(MatchError(ContainSubstring("invalid raw result storage")))
+		})
+	})
+
+	Context("with the self-signed issuer", func() {
+		It("creates a CA and the certificates it signs", func() {
+			Expect(ensure()).To(BeTrue())
+			ca := getSecret(getCASecretName(scan))
+			expectSignedBy(ca.Data[corev1.TLSCertKey])
+
+			server, err := utils.ParseCertificatePEM(getSecret(getServerCertSecretName(scan)).Data[corev1.TLSCertKey])
+			Expect(err).To(BeNil())
+			Expect(server.DNSNames).To(ConsistOf(getResultServerName(scan)))
+			Expect(server.NotAfter).To(BeTemporally("~", time.Now().Add(DefaultCertDuration), time.Minute))
+		})
+
+		It("leaves valid certificates alone", func() {
+			Expect(ensure()).To(BeTrue())
+			server := getSecret(getServerCertSecretName(scan))
+			Expect(ensure()).To(BeTrue())
+			Expect(getSecret(getServerCertSecretName(scan)).Data).To(Equal(server.Data))
+		})
+
+		Context("with a CA that is about to expire", func() {
+			var oldCA *corev1.Secret
+
+			BeforeEach(func() {
+				settings.Duration = "1h"
+				settings.RenewBefore = "20m"
+				// Expires in less than renewBefore
+				caCert, caKey, err := utils.ComplianceOperatorRootCA(RootCAPrefix+scan.Name, utils.CertOptions{Validity: 10 * time.Minute})
+				Expect(err).To(BeNil())
+				oldCA = certSecret(getCASecretName(scan), ns, caCert, caKey, []byte{})
+				server, err := serverCertSecret(scan, caCert, caKey, ns)
+				Expect(err).To(BeNil())
+				client, err := clientCertSecret(scan, caCert, caKey, ns)
+				Expect(err).To(BeNil())
+				objs = append(objs, oldCA, server, client)
+			})
+
+			It("renews the CA and the certificates, and keeps trusting the previous CA", func() {
+				Expect(ensure()).To(BeTrue())
+				ca := getSecret(getCASecretName(scan))
+				Expect(ca.Data[corev1.TLSCertKey]).ToNot(Equal(oldCA.Data[corev1.TLSCertKey]))
+				Expect(ca.Data[CACertDataKey]).To(Equal(oldCA.Data[corev1.TLSCertKey]))
+
+				expectSignedBy(ca.Data[corev1.TLSCertKey])
+				bundle := string(getSecret(getServerCertSecretName(scan)).Data[CACertDataKey])
+				Expect(bundle).To(HaveSuffix(string(oldCA.Data[corev1.TLSCertKey])))
+			})
+		})
+
+		It("deletes the CA and the certificates", func() {
+			Expect(ensure()).To(BeTrue())
+			Expect(r.certIssuerForScan(scan).deleteCertificates(scan, logger)).To(Succeed())
+			Expect(secretExists(getCASecretName(scan))).To(BeFalse())
+			Expect(secretExists(getServerCertSecretName(scan))).To(BeFalse())
+			Expect(secretExists(getClientCertSecretName(scan))).To(BeFalse())
+		})
+	})
+
+	Context("with a CA from a Secret", func() {
+		var caSecret *corev1.Secret
+
+		BeforeEach(func() {
+			settings.Issuer = compv1alpha1.ResultCertificateIssuerCA
+			settings.CASecretName = "corporate-ca"
+			caCert, caKey, err := utils.ComplianceOperatorRootCA("corporate-ca", utils.CertOptions{Validity: 24 * time.Hour})
+			Expect(err).To(BeNil())
+			caSecret = certSecret("corporate-ca", ns, caCert, caKey, []byte{})
+		})
+
+		It("waits for the Secret to exist", func() {
+			Expect(ensure()).To(BeFalse())
+			Expect(secretExists(getServerCertSecretName(scan))).To(BeFalse())
+		})
+
+		Context("once the Secret exists", func() {
+			BeforeEach(func() {
+				objs = append(objs, caSecret)
+			})
+
+			It("signs the certificates with it", func() {
+				Expect(ensure()).To(BeTrue())
+				expectSignedBy(caSecret.Data[corev1.TLSCertKey])
+				Expect(secretExists(getCASecretName(scan))).To(BeFalse())
+			})
+
+			It("doesn't delete the CA", func() {
+				Expect(ensure()).To(BeTrue())
+				Expect(r.certIssuerForScan(scan).deleteCertificates(scan, logger)).To(Succeed())
+				Expect(secretExists("corporate-ca")).To(BeTrue())
+				Expect(secretExists(getServerCertSecretName(scan))).To(BeFalse())
+			})
+		})
+
+		Context("after the CA was replaced", func() {
+			BeforeEach(func() {
+				oldCert, oldKey, err := utils.ComplianceOperatorRootCA("corporate-ca", utils.CertOptions{Validity: 24 * time.Hour})
+				Expect(err).To(BeNil())
+				server, err := serverCertSecret(scan, oldCert, oldKey, ns)
+				Expect(err).To(BeNil())
+				objs = append(objs, caSecret, server)
+			})
+
+			It("issues the certificates again", func() {
+				Expect(ensure()).To(BeTrue())
+				expectSignedBy(caSecret.Data[corev1.TLSCertKey])
+			})
+		})
+	})
+
+	Context("with cert-manager", func() {
+		BeforeEach(func() {
+			settings.Issuer = compv1alpha1.ResultCertificateIssuerCertManager
+			settings.IssuerRef = &compv1alpha1.CertManagerIssuerReference{Name: "corporate", Kind: "ClusterIssuer"}
+			settings.Duration = "12h"
+			settings.KeyAlgorithm = compv1alpha1.CertificateKeyAlgorithmECDSA
+		})
+
+		getCertificate := func(name string) *unstructured.Unstructured {
+			cert := &unstructured.Unstructured{}
+			cert.SetGroupVersionKind(certManagerCertificateGVK)
+			Expect(r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: ns}, cert)).To(Succeed())
+			return cert
+		}
+
+		It("requests the certificates and waits for them to be issued", func() {
+			Expect(ensure()).To(BeFalse())
+
+			cert := getCertificate(getServerCertSecretName(scan))
+			spec := cert.Object["spec"].(map[string]interface{})
+			Expect(spec["secretName"]).To(Equal(getServerCertSecretName(scan)))
+			Expect(spec["dnsNames"]).To(ConsistOf(getResultServerName(scan)))
+			Expect(spec["duration"]).To(Equal("12h0m0s"))
+			Expect(spec["renewBefore"]).To(Equal("4h0m0s"))
+			Expect(spec["usages"]).To(ContainElement("server auth"))
+			Expect(spec["issuerRef"]).To(HaveKeyWithValue("kind", "ClusterIssuer"))
+			Expect(spec["privateKey"]).To(HaveKeyWithValue("algorithm", "ECDSA"))
+
+			spec = getCertificate(getClientCertSecretName(scan)).Object["spec"].(map[string]interface{})
+			Expect(spec["usages"]).To(ContainElement("client auth"))
+			Expect(spec).ToNot(HaveKey("dnsNames"))
+		})
+
+		Context("once cert-manager issued the certificates", func() {
+			BeforeEach(func() {
+				caCert, caKey, err := utils.ComplianceOperatorRootCA("corporate-ca", utils.CertOptions{Validity: 24 * time.Hour})
+				Expect(err).To(BeNil())
+				server, err := serverCertSecret(scan, caCert, caKey, ns)
+				Expect(err).To(BeNil())
+				client, err := clientCertSecret(scan, caCert, caKey, ns)
+				Expect(err).To(BeNil())
+				objs = append(objs, server, client)
+			})
+
+			It("is ready", func() {
+				Expect(ensure()).To(BeTrue())
+			})
+
+			It("deletes the Certificates and their Secrets", func() {
+				Expect(ensure()).To(BeTrue())
+				Expect(r.certIssuerForScan(scan).deleteCertificates(scan, logger)).To(Succeed())
+
+				cert := &unstructured.Unstructured{}
+				cert.SetGroupVersionKind(certManagerCertificateGVK)
+				err := r.Client.Get(context.TODO(), types.NamespacedName{Name: getServerCertSecretName(scan), Namespace: ns}, cert)
+				Expect(kerrors.IsNotFound(err)).To(BeTrue())
+				Expect(secretExists(getServerCertSecretName(scan))).To(BeFalse())
+				Expect(secretExists(getClientCertSecretName(scan))).To(BeFalse())
+			})
+		})
+	})
+})
@@ -2,6 +2,9 @@ package compliancescan
 
 import (
 	"context"
+	"fmt"
+	"net/url"
+	"path"
 
 	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
 	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
@@ -24,6 +27,10 @@ var (
 // that the PVC gets created, and, if necessary, the scan instance will get updated too.
 // Returns whether the reconcile loop should continue or not, and an error if encountered.
 func (r *ReconcileComplianceScan) handleRawResultsForScan(instance *compv1alpha1.ComplianceScan, logger logr.Logger) (bool, error) {
+	// The result server uploads the results to the bucket by itself
+	if instance.Spec.RawResultStorage.GetBackend() == compv1alpha1.RawResultStorageBackendS3 {
+		return true, nil
+	}
 	// Create PVC
 	pvc := getPVCForScan(instance)
 	logger.Info("Creating PVC for scan", "PersistentVolumeClaim.Name", pvc.Name, "PersistentVolumeClaim.Namespace", pvc.Namespace)
@@ -97,6 +104,43 @@ func instanceNeedsResultStorageReference(scan *compv1alpha1.ComplianceScan, pvc
 		scan.Status.ResultsStorage.Namespace != pvc.Namespace
 }
 
+// validateRawResultStorage makes sure the raw result storage settings are
+// usable
+func validateRawResultStorage(settings *compv1alpha1.RawResultStorageSettings) error {
+	if err := validateRawResultStorageBackend(settings); err != nil {
+		return err
+	}
+	return validateResultCertificates(settings)
+}
+
+// validateRawResultStorageBackend makes sure the settings the selected
+// backend needs are present
+func validateRawResultStorageBackend(settings *compv1alpha1.RawResultStorageSettings) error {
+	switch settings.GetBackend() {
+	case compv1alpha1.RawResultStorageBackendPVC:
+		return nil
+	case compv1alpha1.RawResultStorageBackendS3:
+		s3 := settings.S3
+		if s3 == nil {
+			return fmt.Errorf("the s3 backend requires the s3 settings")
+		}
+		if s3.Endpoint == "" || s3.Bucket == "" || s3.CredentialsSecretName == "" {
+			return fmt.Errorf("the s3 backend requires an endpoint, a bucket and a credentialsSecretName")
+		}
+		if u, err := url.Parse(s3.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("the s3 endpoint %s must be an http or https URL", s3.Endpoint)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown backend %s", settings.Backend)
+}
+
+// getS3PrefixForScan returns the prefix the results of a scan are stored
+// under in the bucket
+func getS3PrefixForScan(instance *compv1alpha1.ComplianceScan) string {
+	return path.Join(instance.Spec.RawResultStorage.S3.Prefix, instance.Name)
+}
+
 // GetPVCForScanName Get's the PVC name for a scan
 func getPVCForScanName(scanName string) string {
 	return scanName
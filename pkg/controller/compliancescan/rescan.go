@@ -0,0 +1,236 @@
+package compliancescan
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/xccdf"
+)
+
+// getTargetedRescan returns the rules and nodes a rescan of the scan
+// evaluates, or nil if the whole profile is rescanned. Only the failed and
+// inconsistent checks are rescanned if requested with the rescan-mode
+// annotation, while retrying a targeted rescan that timed out rescans the
+// same checks again.
+func (r *ReconcileComplianceScan) getTargetedRescan(instance *compv1alpha1.ComplianceScan) (*compv1alpha1.ComplianceScanTargetedRescan, error) {
+	if !instance.NeedsFailedRescan() {
+		if instance.NeedsTimeoutRescan() {
+			return instance.Status.TargetedRescan, nil
+		}
+		return nil, nil
+	}
+
+	results := &compv1alpha1.ComplianceCheckResultList{}
+	err := r.Client.List(context.TODO(), results,
+		client.InNamespace(instance.Namespace),
+		client.MatchingLabels{compv1alpha1.ComplianceScanLabel: instance.Name})
+	if err != nil {
+		return nil, err
+	}
+
+	rules := []string{}
+	nodes := map[string]bool{}
+	allNodes := instance.GetScanType() == compv1alpha1.ScanTypePlatform
+	for i := range results.Items {
+		result := &results.Items[i]
+		switch result.Status {
+		case compv1alpha1.CheckResultFail:
+			allNodes = true
+		case compv1alpha1.CheckResultInconsistent:
+			inconsistentNodes, ok := getInconsistentNodes(result)
+			if !ok {
+				allNodes = true
+			}
+			for _, node := range inconsistentNodes {
+				nodes[node] = true
+			}
+		default:
+			continue
+		}
+		rules = append(rules, result.ID)
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	sort.Strings(rules)
+
+	targeted := &compv1alpha1.ComplianceScanTargetedRescan{Rules: rules}
+	if !allNodes {
+		for node := range nodes {
+			targeted.Nodes = append(targeted.Nodes, node)
+		}
+		sort.Strings(targeted.Nodes)
+	}
+	return targeted, nil
+}
+
+// getInconsistentNodes returns the nodes an inconsistent check needs to be
+// rescanned on: the nodes whose status differed from the most common one.
+// Returns false if the check needs to be rescanned on all the nodes, which
+// is the case when most of the nodes failed it.
+func getInconsistentNodes(result *compv1alpha1.ComplianceCheckResult) ([]string, bool) {
+	if result.Annotations[compv1alpha1.ComplianceCheckResultMostCommonAnnotation] == string(compv1alpha1.CheckResultFail) {
+		return nil, false
+	}
+	sources := result.Annotations[compv1alpha1.ComplianceCheckResultInconsistentSourceAnnotation]
+	if sources == "" {
+		return nil, false
+	}
+
+	nodes := []string{}
+	for _, source := range strings.Split(sources, ",") {
+		node, _, _ := strings.Cut(source, ":")
+		nodes = append(nodes, node)
+	}
+	return nodes, true
+}
+
+// skipFailedRescan removes the rescan annotations from a scan which has no
+// failed or inconsistent checks to rescan, leaving its results as they are
+func (r *ReconcileComplianceScan) skipFailedRescan(instance *compv1alpha1.ComplianceScan, logger logr.Logger) (reconcile.Result, error) {
+	logger.Info("The scan has no failed or inconsistent checks to rescan")
+	if r.Recorder != nil {
+		r.Recorder.Event(instance, corev1.EventTypeNormal, "NothingToRescan",
+			"The scan has no failed or inconsistent checks to rescan")
+	}
+	instanceCopy := instance.DeepCopy()
+	delete(instanceCopy.Annotations, compv1alpha1.ComplianceScanRescanAnnotation)
+	delete(instanceCopy.Annotations, compv1alpha1.ComplianceScanRescanModeAnnotation)
+	err := r.Client.Update(context.TODO(), instanceCopy)
+	return reconcile.Result{}, err
+}
+
+// deleteTargetedResultConfigMaps deletes the result ConfigMaps of the nodes
+// that are rescanned. The results of the other nodes are kept, they are
+// merged with the new results during the aggregation.
+func (r *ReconcileComplianceScan) deleteTargetedResultConfigMaps(instance *compv1alpha1.ComplianceScan, nodes []string, logger logr.Logger) error {
+	for _, node := range nodes {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      getConfigMapForNodeName(instance.Name, node),
+				Namespace: common.GetComplianceOperatorNamespace(),
+			},
+		}
+		err := r.Client.Delete(context.TODO(), cm)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		logger.Info("Deleted the result ConfigMap of a rescanned node", "ConfigMap.Name", cm.Name, "Node.Name", node)
+	}
+	return nil
+}
+
+// reconcileTargetedRescanTailoring creates the tailoring that restricts a
+// targeted rescan to the rules it rescans and mounts it in the pod
+func (r *ReconcileComplianceScan) reconcileTargetedRescanTailoring(instance *compv1alpha1.ComplianceScan, pod *corev1.Pod, logger logr.Logger) error {
+	cmName := getTargetedRescanTailoringCMName(instance.Name)
+	cmNamespace := common.GetComplianceOperatorNamespace()
+
+	cm := &corev1.ConfigMap{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: cmName, Namespace: cmNamespace}, cm)
+	if errors.IsNotFound(err) {
+		tailoring, err := r.getTargetedRescanTailoring(instance, logger)
+		if err != nil {
+			return err
+		}
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      cmName,
+				Namespace: cmNamespace,
+				Labels: map[string]string{
+					compv1alpha1.ComplianceScanLabel: instance.Name,
+					compv1alpha1.ScriptLabel:         "",
+				},
+			},
+			Data: map[string]string{
+				"tailoring.xml": tailoring,
+			},
+		}
+		logger.Info("Creating targeted rescan Tailoring ConfigMap", "ConfigMap.Name", cmName, "ConfigMap.Namespace", cmNamespace)
+		if err := r.Client.Create(context.TODO(), cm); err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	return r.addTailoringVolume(cmName, pod)
+}
+
+func (r *ReconcileComplianceScan) getTargetedRescanTailoring(instance *compv1alpha1.ComplianceScan, logger logr.Logger) (string, error) {
+	targeted := map[string]bool{}
+	for _, rule := range instance.Status.TargetedRescan.Rules {
+		targeted[rule] = true
+	}
+
+	// The rules that were checked but aren't rescanned are deselected
+	results := &compv1alpha1.ComplianceCheckResultList{}
+	err := r.Client.List(context.TODO(), results,
+		client.InNamespace(instance.Namespace),
+		client.MatchingLabels{compv1alpha1.ComplianceScanLabel: instance.Name})
+	if err != nil {
+		return "", err
+	}
+	otherRules := []string{}
+	for i := range results.Items {
+		if !targeted[results.Items[i].ID] {
+			otherRules = append(otherRules, results.Items[i].ID)
+		}
+	}
+
+	tailoring := ""
+	if instance.Spec.TailoringConfigMap != nil {
+		if instance.Spec.TailoringConfigMap.Name == "" {
+			return "", common.NewNonRetriableCtrlError("tailoring config map name can't be empty")
+		}
+		privName := getReplicatedTailoringCMName(instance.Name)
+		privNs := common.GetComplianceOperatorNamespace()
+		err := r.reconcileReplicatedTailoringConfigMap(instance, instance.Spec.TailoringConfigMap.Name, instance.Namespace,
+			privName, privNs, instance.Name, logger)
+		if err != nil {
+			return "", err
+		}
+		privCM := &corev1.ConfigMap{}
+		if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: privName, Namespace: privNs}, privCM); err != nil {
+			return "", err
+		}
+		tailoring = privCM.Data["tailoring.xml"]
+	}
+
+	out, err := xccdf.TargetedRescanToXML(instance.Name, absContentPath(instance.Spec.Content), instance.Spec.Profile,
+		tailoring, instance.Status.TargetedRescan.Rules, otherRules)
+	if err != nil {
+		return "", common.NewNonRetriableCtrlError("couldn't generate the tailoring of the rescan: %s", err)
+	}
+	return out, nil
+}
+
+// hasTailoring tells whether the scan pods are given a tailoring file
+func hasTailoring(scan *compv1alpha1.ComplianceScan) bool {
+	return scan.Spec.TailoringConfigMap != nil || scan.Status.TargetedRescan != nil
+}
+
+// getScanProfileID returns the ID of the XCCDF profile the scan pods
+// evaluate
+func getScanProfileID(scan *compv1alpha1.ComplianceScan) string {
+	if scan.Status.TargetedRescan != nil {
+		return xccdf.GetTargetedRescanProfileID(scan.Name)
+	}
+	return scan.Spec.Profile
+}
+
+func getTargetedRescanTailoringCMName(instanceName string) string {
+	return utils.DNSLengthName("rescan-tp-", "rescan-tp-%s", instanceName)
+}
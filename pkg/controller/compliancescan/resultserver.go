@@ -170,7 +170,7 @@ func resultServer(scanInstance *compv1alpha1.ComplianceScan, labels map[string]s
 	podFSGroup, podUid int64, logger logr.Logger) *appsv1.Deployment {
 	falseP := false
 	trueP := true
-	return &appsv1.Deployment{
+	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      getResultServerName(scanInstance),
 			Namespace: common.GetComplianceOperatorNamespace(),
@@ -255,6 +255,83 @@ func resultServer(scanInstance *compv1alpha1.ComplianceScan, labels map[string]s
 			},
 		},
 	}
+	if scanInstance.Spec.RawResultStorage.GetBackend() == compv1alpha1.RawResultStorageBackendS3 {
+		useS3ResultStorage(scanInstance, &deployment.Spec.Template.Spec)
+	}
+	return deployment
+}
+
+// useS3ResultStorage makes the result server upload the results to the
+// configured bucket instead of storing them in the scan's PVC
+func useS3ResultStorage(scanInstance *compv1alpha1.ComplianceScan, podSpec *corev1.PodSpec) {
+	s3 := scanInstance.Spec.RawResultStorage.S3
+	region := s3.Region
+	if region == "" {
+		region = compv1alpha1.DefaultS3Region
+	}
+
+	volumes := []corev1.Volume{}
+	for _, v := range podSpec.Volumes {
+		if v.Name != "arfreports" {
+			volumes = append(volumes, v)
+		}
+	}
+	podSpec.Volumes = volumes
+	container := &podSpec.Containers[0]
+	mounts := []corev1.VolumeMount{}
+	for _, m := range container.VolumeMounts {
+		if m.Name != "arfreports" {
+			mounts = append(mounts, m)
+		}
+	}
+	// The root filesystem is read-only, and the uploads are spooled to a
+	// temporary file since the scan pods don't always send their length
+	container.VolumeMounts = append(mounts, corev1.VolumeMount{
+		Name:      "tmp-dir",
+		MountPath: "/tmp",
+	})
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: "tmp-dir",
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	})
+	container.Command = append(container.Command,
+		"--storage-backend="+string(compv1alpha1.RawResultStorageBackendS3),
+		"--s3-endpoint="+s3.Endpoint,
+		"--s3-bucket="+s3.Bucket,
+		"--s3-region="+region,
+		"--s3-prefix="+getS3PrefixForScan(scanInstance),
+	)
+	for _, key := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"} {
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name: key,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: s3.CredentialsSecretName},
+					Key:                  key,
+				},
+			},
+		})
+	}
+
+	if s3.TLSSecretName != "" {
+		container.Command = append(container.Command, "--s3-ca=/etc/pki/s3/ca.crt")
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      "s3-tls",
+			MountPath: "/etc/pki/s3",
+			ReadOnly:  true,
+		})
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: "s3-tls",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: s3.TLSSecretName,
+					Items:      []corev1.KeyToPath{{Key: "ca.crt", Path: "ca.crt"}},
+				},
+			},
+		})
+	}
 }
 
 func resultServerService(scanInstance *compv1alpha1.ComplianceScan, labels map[string]string) *corev1.Service {
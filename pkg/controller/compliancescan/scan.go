@@ -29,7 +29,11 @@ const (
 
 func (r *ReconcileComplianceScan) launchScanPod(instance *compv1alpha1.ComplianceScan, pod *corev1.Pod, logger logr.Logger) error {
 	podLogger := logger.WithValues("Pod.Name", pod.Name)
-	if instance.Spec.TailoringConfigMap != nil {
+	if instance.Status.TargetedRescan != nil {
+		if err := r.reconcileTargetedRescanTailoring(instance, pod, logger); err != nil {
+			return err
+		}
+	} else if instance.Spec.TailoringConfigMap != nil {
 		if err := r.reconcileTailoring(instance, pod, logger); err != nil {
 			return err
 		}
@@ -367,11 +371,11 @@ func (r *ReconcileComplianceScan) newPlatformScanPod(scanInstance *compv1alpha1.
 		"compliance-operator", "api-resource-collector",
 		"--content=/content/" + scanInstance.Spec.Content,
 		"--resultdir=" + PlatformScanDataRoot,
-		"--profile=" + scanInstance.Spec.Profile,
+		"--profile=" + getScanProfileID(scanInstance),
 		"--warnings-output-file=/reports/warning_output",
 		"--platform=" + os.Getenv("PLATFORM"),
 	}
-	if scanInstance.Spec.TailoringConfigMap != nil {
+	if hasTailoring(scanInstance) {
 		// NOTE(jaosorior): Adding the tailoring volume is handled in the
 		// addTailoringVolume function
 		tailoringArg := fmt.Sprintf("--tailoring=%s/tailoring.xml", OpenScapTailoringDir)
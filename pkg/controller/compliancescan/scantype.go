@@ -4,6 +4,8 @@ import (
 	"context"
 	goerrors "errors"
 	"fmt"
+	"reflect"
+	"slices"
 	"strings"
 	"time"
 
@@ -13,7 +15,6 @@ import (
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -86,15 +87,27 @@ func (nh *nodeScanTypeHandler) getTargetNodes() ([]corev1.Node, error) {
 	case compv1alpha1.ScanTypePlatform:
 		return nodes.Items, nil // Nodes are only relevant to the node scan type. Return the empty node list otherwise.
 	case compv1alpha1.ScanTypeNode:
-		// we only scan Linux nodes
-		nodeScanSelector := map[string]string{"kubernetes.io/os": "linux"}
+		selector, err := getNodeScanSelector(nh.scan)
+		if err != nil {
+			return nodes.Items, err
+		}
 		listOpts := client.ListOptions{
-			LabelSelector: labels.SelectorFromSet(labels.Merge(nh.scan.Spec.NodeSelector, nodeScanSelector)),
+			LabelSelector: selector,
 		}
 
 		if err := nh.r.Client.List(context.TODO(), &nodes, &listOpts); err != nil {
 			return nodes.Items, err
 		}
+		// A targeted rescan only runs on the nodes the checks failed on
+		if targeted := nh.scan.Status.TargetedRescan; targeted != nil && len(targeted.Nodes) > 0 {
+			targetNodes := []corev1.Node{}
+			for _, node := range nodes.Items {
+				if slices.Contains(targeted.Nodes, node.Name) {
+					targetNodes = append(targetNodes, node)
+				}
+			}
+			return targetNodes, nil
+		}
 	}
 
 	return nodes.Items, nil
@@ -137,18 +150,25 @@ func (nh *nodeScanTypeHandler) validate() (bool, error) {
 }
 
 func (nh *nodeScanTypeHandler) createScanWorkload() error {
+	if nh.isRollingScan() {
+		nh.syncNodeProgress()
+		// Relaunch the pods that went missing while the nodes were being
+		// scanned, then carry on with the pending nodes
+		for _, progress := range nh.scan.Status.Nodes {
+			if progress.Phase != compv1alpha1.PhaseRunning {
+				continue
+			}
+			if err := nh.launchScanPodForNode(nh.getNode(progress.Name)); err != nil {
+				return err
+			}
+		}
+		_, err := nh.launchPendingNodes()
+		return err
+	}
+
 	// On each eligible node..
 	for idx := range nh.nodes {
-		node := &nh.nodes[idx]
-		// ..schedule a pod..
-		nh.l.Info("Creating a pod for node", "Pod.Name", node.Name)
-		pod := newScanPodForNode(nh.scan, node, nh.l)
-		if priorityClassExist, why := utils.ValidatePriorityClassExist(nh.scan.Spec.PriorityClass, nh.r.Client); !priorityClassExist {
-			nh.l.Info(why, "Scan.Name", nh.scan.Name)
-			nh.r.Recorder.Eventf(nh.scan, corev1.EventTypeWarning, "PriorityClass", why+" Scan:"+nh.scan.Name)
-			pod.Spec.PriorityClassName = ""
-		}
-		if err := nh.r.launchScanPod(nh.scan, pod, nh.l); err != nil {
+		if err := nh.launchScanPodForNode(&nh.nodes[idx]); err != nil {
 			return err
 		}
 	}
@@ -156,6 +176,88 @@ func (nh *nodeScanTypeHandler) createScanWorkload() error {
 	return nil
 }
 
+func (nh *nodeScanTypeHandler) launchScanPodForNode(node *corev1.Node) error {
+	// ..schedule a pod..
+	nh.l.Info("Creating a pod for node", "Pod.Name", node.Name)
+	pod := newScanPodForNode(nh.scan, node, nh.l)
+	if priorityClassExist, why := utils.ValidatePriorityClassExist(nh.scan.Spec.PriorityClass, nh.r.Client); !priorityClassExist {
+		nh.l.Info(why, "Scan.Name", nh.scan.Name)
+		nh.r.Recorder.Eventf(nh.scan, corev1.EventTypeWarning, "PriorityClass", why+" Scan:"+nh.scan.Name)
+		pod.Spec.PriorityClassName = ""
+	}
+	return nh.r.launchScanPod(nh.scan, pod, nh.l)
+}
+
+// isRollingScan tells whether the scan rolls through the nodes instead of
+// scanning all of them at once
+func (nh *nodeScanTypeHandler) isRollingScan() bool {
+	return nh.scan.Spec.MaxConcurrentNodes > 0
+}
+
+func (nh *nodeScanTypeHandler) getNode(name string) *corev1.Node {
+	for idx := range nh.nodes {
+		if nh.nodes[idx].Name == name {
+			return &nh.nodes[idx]
+		}
+	}
+	return nil
+}
+
+// syncNodeProgress makes the progress tracked in the status match the
+// nodes to scan. Nodes that joined since the scan started are pending and
+// the ones that left are forgotten. Returns whether the status changed.
+func (nh *nodeScanTypeHandler) syncNodeProgress() bool {
+	progress := make([]compv1alpha1.ComplianceScanNodeStatus, 0, len(nh.nodes))
+	tracked := map[string]bool{}
+	for _, nodeProgress := range nh.scan.Status.Nodes {
+		if nh.getNode(nodeProgress.Name) != nil {
+			progress = append(progress, nodeProgress)
+			tracked[nodeProgress.Name] = true
+		}
+	}
+	for idx := range nh.nodes {
+		if !tracked[nh.nodes[idx].Name] {
+			progress = append(progress, compv1alpha1.ComplianceScanNodeStatus{
+				Name:  nh.nodes[idx].Name,
+				Phase: compv1alpha1.PhasePending,
+			})
+		}
+	}
+	if reflect.DeepEqual(progress, nh.scan.Status.Nodes) {
+		return false
+	}
+	nh.scan.Status.Nodes = progress
+	return true
+}
+
+// launchPendingNodes launches the scan pods of as many pending nodes as the
+// concurrency limit allows. Returns whether any node was launched.
+func (nh *nodeScanTypeHandler) launchPendingNodes() (bool, error) {
+	running := 0
+	for _, progress := range nh.scan.Status.Nodes {
+		if progress.Phase == compv1alpha1.PhaseRunning {
+			running++
+		}
+	}
+	launched := false
+	for idx := range nh.scan.Status.Nodes {
+		progress := &nh.scan.Status.Nodes[idx]
+		if running >= nh.scan.Spec.MaxConcurrentNodes {
+			break
+		}
+		if progress.Phase != compv1alpha1.PhasePending {
+			continue
+		}
+		if err := nh.launchScanPodForNode(nh.getNode(progress.Name)); err != nil {
+			return launched, err
+		}
+		progress.Phase = compv1alpha1.PhaseRunning
+		running++
+		launched = true
+	}
+	return launched, nil
+}
+
 func (nh *nodeScanTypeHandler) handleRunningScan() (bool, []string, error) {
 	// scan.Spec.ComplianceScanSettings.Timeout is in string format, e.g. "1h30m"
 	// so we need to parse it
@@ -168,43 +270,15 @@ func (nh *nodeScanTypeHandler) handleRunningScan() (bool, []string, error) {
 			return true, timeoutNodes, fmt.Errorf("couldn't parse timeout: %w", err)
 		}
 	}
+	if nh.isRollingScan() {
+		return nh.handleRollingScan(timeoutVal)
+	}
 	for idx := range nh.nodes {
 		node := &nh.nodes[idx]
-		var unschedulableErr *podUnschedulableError
 		var timeoutErr *common.TimeoutError
-		running, err := isPodRunningInNode(nh.r, nh.scan, node, timeoutVal, nh.l)
+		running, err := nh.isPodRunningInNode(node, timeoutVal)
 		if errors.IsNotFound(err) {
-			// Let's go back to the previous state and make sure all the nodes are covered.
-			nh.l.Info("Phase: Running: A pod is missing. Going to state LAUNCHING to make sure we launch it",
-				"compliancescan", nh.scan.ObjectMeta.Name, "node", node.Name)
-			nh.scan.Status.Phase = compv1alpha1.PhaseLaunching
-			err = nh.r.Client.Status().Update(context.TODO(), nh.scan)
-			if err != nil {
-				return true, timeoutNodes, err
-			}
-			return true, timeoutNodes, nil
-		} else if goerrors.As(err, &unschedulableErr) {
-			// Create custom error message for this pod that couldn't be scheduled
-			cmName := getConfigMapForNodeName(nh.scan.Name, node.Name)
-			errorReader := strings.NewReader(err.Error())
-			cm := utils.GetResultConfigMap(nh.scan, cmName, "error-msg", node.Name,
-				errorReader, false, common.PodUnschedulableExitCode, "")
-			cmKey := types.NamespacedName{Name: cm.Name, Namespace: cm.Namespace}
-			foundcm := corev1.ConfigMap{}
-			cmGetErr := nh.r.Client.Get(context.TODO(), cmKey, &foundcm)
-
-			if errors.IsNotFound(cmGetErr) {
-				if cmCreateErr := nh.r.Client.Create(context.TODO(), cm); cmCreateErr != nil {
-					if !errors.IsAlreadyExists(cmCreateErr) {
-						return true, timeoutNodes, cmCreateErr
-					}
-				}
-			} else if cmGetErr != nil {
-				return true, timeoutNodes, cmGetErr
-			}
-
-			// We're good, the CM that tells us about this error is already there
-			// let's continue to check the next pod
+			return nh.relaunchMissingPod(node)
 		} else if goerrors.As(err, &timeoutErr) {
 			nh.l.Info("Timeout while waiting for the Node scan pod to be finished.")
 			timeoutNodes = append(timeoutNodes, node.Name)
@@ -219,8 +293,107 @@ func (nh *nodeScanTypeHandler) handleRunningScan() (bool, []string, error) {
 	return false, timeoutNodes, nil
 }
 
+// handleRollingScan marks the nodes whose scan pod finished as done and
+// launches the scans of the pending nodes in their place. The scan is
+// running until every node is done.
+func (nh *nodeScanTypeHandler) handleRollingScan(timeoutVal time.Duration) (bool, []string, error) {
+	changed := nh.syncNodeProgress()
+	for idx := range nh.scan.Status.Nodes {
+		progress := &nh.scan.Status.Nodes[idx]
+		if progress.Phase != compv1alpha1.PhaseRunning {
+			continue
+		}
+		node := nh.getNode(progress.Name)
+		var timeoutErr *common.TimeoutError
+		running, err := nh.isPodRunningInNode(node, timeoutVal)
+		if errors.IsNotFound(err) {
+			return nh.relaunchMissingPod(node)
+		} else if goerrors.As(err, &timeoutErr) {
+			nh.l.Info("Timeout while waiting for the Node scan pod to be finished.")
+			return true, []string{node.Name}, nil
+		} else if err != nil {
+			return true, []string{}, err
+		}
+		if !running {
+			nh.l.Info("Node scan finished", "node", node.Name)
+			progress.Phase = compv1alpha1.PhaseDone
+			changed = true
+		}
+	}
+
+	launched, err := nh.launchPendingNodes()
+	if err != nil {
+		return true, []string{}, err
+	}
+	if changed || launched {
+		if err := nh.r.Client.Status().Update(context.TODO(), nh.scan); err != nil {
+			return true, []string{}, err
+		}
+	}
+	return !nh.allNodesDone(), []string{}, nil
+}
+
+func (nh *nodeScanTypeHandler) allNodesDone() bool {
+	for _, progress := range nh.scan.Status.Nodes {
+		if progress.Phase != compv1alpha1.PhaseDone {
+			return false
+		}
+	}
+	return true
+}
+
+// isPodRunningInNode tells whether the scan pod of a node is still running.
+// Pods that can't be scheduled are reported as finished, with the error
+// stored as the result of the node.
+func (nh *nodeScanTypeHandler) isPodRunningInNode(node *corev1.Node, timeoutVal time.Duration) (bool, error) {
+	var unschedulableErr *podUnschedulableError
+	running, err := isPodRunningInNode(nh.r, nh.scan, node, timeoutVal, nh.l)
+	if !goerrors.As(err, &unschedulableErr) {
+		return running, err
+	}
+
+	// Create custom error message for this pod that couldn't be scheduled
+	cmName := getConfigMapForNodeName(nh.scan.Name, node.Name)
+	errorReader := strings.NewReader(err.Error())
+	cm := utils.GetResultConfigMap(nh.scan, cmName, "error-msg", node.Name,
+		errorReader, false, common.PodUnschedulableExitCode, "")
+	cmKey := types.NamespacedName{Name: cm.Name, Namespace: cm.Namespace}
+	foundcm := corev1.ConfigMap{}
+	cmGetErr := nh.r.Client.Get(context.TODO(), cmKey, &foundcm)
+
+	if errors.IsNotFound(cmGetErr) {
+		if cmCreateErr := nh.r.Client.Create(context.TODO(), cm); cmCreateErr != nil {
+			if !errors.IsAlreadyExists(cmCreateErr) {
+				return false, cmCreateErr
+			}
+		}
+	} else if cmGetErr != nil {
+		return false, cmGetErr
+	}
+
+	// We're good, the CM that tells us about this error is already there
+	// let's continue to check the next pod
+	return false, nil
+}
+
+func (nh *nodeScanTypeHandler) relaunchMissingPod(node *corev1.Node) (bool, []string, error) {
+	// Let's go back to the previous state and make sure all the nodes are covered.
+	nh.l.Info("Phase: Running: A pod is missing. Going to state LAUNCHING to make sure we launch it",
+		"compliancescan", nh.scan.ObjectMeta.Name, "node", node.Name)
+	nh.scan.Status.Phase = compv1alpha1.PhaseLaunching
+	err := nh.r.Client.Status().Update(context.TODO(), nh.scan)
+	if err != nil {
+		return true, []string{}, err
+	}
+	return true, []string{}, nil
+}
+
 func (nh *nodeScanTypeHandler) shouldLaunchAggregator() (bool, string, error) {
 	var warnings string
+	// Wait for the scans of every node to be collected
+	if nh.isRollingScan() && !nh.allNodesDone() {
+		return false, "", nil
+	}
 	for _, node := range nh.nodes {
 		foundCM, err := getNodeScanCM(nh.r, nh.scan, node.Name)
 
@@ -0,0 +1,414 @@
+package compliancescan
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	backoff "github.com/cenkalti/backoff/v4"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/version"
+)
+
+const (
+	syslogAppName = "compliance-operator"
+	// syslogSDID is the structured data ID used for the result
+	// parameters. 32473 is the private enterprise number reserved for
+	// documentation by RFC 5612.
+	syslogSDID      = "compliance@32473"
+	syslogExtraSDID = "extra@32473"
+	// syslogFacility is local0
+	syslogFacility   = 16
+	syslogMaxRetries = 3
+
+	syslogMsgIDCheckResult = "CheckResult"
+	syslogMsgIDRemediation = "Remediation"
+
+	cefVendor = "ComplianceAsCode"
+)
+
+// syslog severities as defined in RFC 5424
+const (
+	syslogSeverityError   = 3
+	syslogSeverityWarning = 4
+	syslogSeverityNotice  = 5
+	syslogSeverityInfo    = 6
+)
+
+// syslogForwarder sends every result as a single syslog message
+type syslogForwarder struct {
+	network      string
+	address      string
+	timeout      time.Duration
+	tlsConfig    *tls.Config
+	format       compv1alpha1.SyslogFormat
+	hostname     string
+	scanName     string
+	assessmentID string
+	extra        map[string]string
+	conn         net.Conn
+	// deadline bounds the time spent forwarding all the results of the
+	// scan, an unreachable server would stall the aggregator otherwise
+	deadline time.Time
+	// err is the error forwarding failed with, nothing is sent after it
+	err error
+}
+
+func newSyslogForwarder(c client.Reader, s *compv1alpha1.ComplianceScan) (*syslogForwarder, error) {
+	settings := s.Spec.ResultForwarding.Syslog
+	if settings == nil {
+		return nil, fmt.Errorf("the syslog result forwarding provider requires the 'syslog' settings")
+	}
+	if _, _, err := net.SplitHostPort(settings.Address); err != nil {
+		return nil, fmt.Errorf("invalid syslog address: %w", err)
+	}
+
+	timeout := defaultForwardingTimeout
+	if settings.Timeout != "" {
+		var err error
+		timeout, err = time.ParseDuration(settings.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse result forwarding timeout: %w", err)
+		}
+	}
+
+	f := &syslogForwarder{
+		address:      settings.Address,
+		timeout:      timeout,
+		format:       settings.Format,
+		scanName:     s.Name,
+		assessmentID: getAssessmentID(s),
+		extra:        s.Spec.ResultForwarding.ExtraMetadata,
+		deadline:     time.Now().Add(timeout),
+	}
+
+	switch settings.Protocol {
+	case compv1alpha1.SyslogProtocolTCP, "":
+		f.network = "tcp"
+	case compv1alpha1.SyslogProtocolUDP:
+		f.network = "udp"
+	case compv1alpha1.SyslogProtocolTLS:
+		f.network = "tcp"
+		f.tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		if settings.TLSSecretName != "" {
+			var err error
+			f.tlsConfig, err = forwardingTLSConfig(c, settings.TLSSecretName)
+			if err != nil {
+				return nil, err
+			}
+		}
+		host, _, _ := net.SplitHostPort(settings.Address)
+		f.tlsConfig.ServerName = host
+	default:
+		return nil, fmt.Errorf("unknown syslog protocol '%s'", settings.Protocol)
+	}
+
+	switch settings.Format {
+	case compv1alpha1.SyslogFormatRFC5424, "":
+		f.format = compv1alpha1.SyslogFormatRFC5424
+	case compv1alpha1.SyslogFormatCEF:
+	default:
+		return nil, fmt.Errorf("unknown syslog format '%s'", settings.Format)
+	}
+
+	// Prefer the cluster name over the name of the aggregator pod as
+	// that's what the SIEM will want to correlate the results with
+	f.hostname = f.extra["clusterName"]
+	if f.hostname == "" {
+		f.hostname, _ = os.Hostname()
+	}
+
+	return f, nil
+}
+
+func (f *syslogForwarder) SendComplianceCheckResult(c *compv1alpha1.ComplianceCheckResult) error {
+	var msg string
+	if f.format == compv1alpha1.SyslogFormatCEF {
+		// CEF events carry no structured data, the NILVALUE stands in
+		// for it
+		msg = "- " + f.formatCheckResultCEF(c)
+	} else {
+		msg = f.formatCheckResultRFC5424(c)
+	}
+	return f.send(f.header(syslogCheckResultSeverity(c), syslogMsgIDCheckResult) + msg)
+}
+
+func (f *syslogForwarder) SendComplianceRemediation(r *compv1alpha1.ComplianceRemediation) error {
+	var msg string
+	if f.format == compv1alpha1.SyslogFormatCEF {
+		// CEF events carry no structured data, the NILVALUE stands in
+		// for it
+		msg = "- " + f.formatRemediationCEF(r)
+	} else {
+		msg = f.formatRemediationRFC5424(r)
+	}
+	return f.send(f.header(syslogSeverityInfo, syslogMsgIDRemediation) + msg)
+}
+
+// Flush closes the connection to the syslog server. Messages are written as
+// they're sent, so there's nothing else left to deliver.
+func (f *syslogForwarder) Flush() error {
+	if f.conn == nil {
+		return nil
+	}
+	err := f.conn.Close()
+	f.conn = nil
+	return err
+}
+
+// header returns the RFC 5424 header of a message including the trailing
+// space
+func (f *syslogForwarder) header(severity int, msgID string) string {
+	return fmt.Sprintf("<%d>1 %s %s %s - %s ",
+		syslogFacility*8+severity,
+		time.Now().UTC().Format(time.RFC3339Nano),
+		syslogHeaderField(f.hostname, 255),
+		syslogAppName,
+		msgID)
+}
+
+func (f *syslogForwarder) formatCheckResultRFC5424(c *compv1alpha1.ComplianceCheckResult) string {
+	sd := syslogSDElement(syslogSDID, [][2]string{
+		{"scan", f.scanName},
+		{"assessmentID", f.assessmentID},
+		{"rule", c.ID},
+		{"status", string(c.Status)},
+		{"severity", string(c.Severity)},
+	})
+	return sd + f.extraSDElement() + " " + fmt.Sprintf("%s %s", c.ID, c.Status)
+}
+
+func (f *syslogForwarder) formatRemediationRFC5424(r *compv1alpha1.ComplianceRemediation) string {
+	sd := syslogSDElement(syslogSDID, [][2]string{
+		{"scan", f.scanName},
+		{"assessmentID", f.assessmentID},
+		{"remediation", r.Name},
+		{"type", string(r.Spec.Type)},
+	})
+	return sd + f.extraSDElement() + " " + fmt.Sprintf("remediation %s available", r.Name)
+}
+
+func (f *syslogForwarder) extraSDElement() string {
+	if len(f.extra) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(f.extra))
+	for k := range f.extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	params := make([][2]string, 0, len(keys))
+	for _, k := range keys {
+		params = append(params, [2]string{k, f.extra[k]})
+	}
+	return syslogSDElement(syslogExtraSDID, params)
+}
+
+func (f *syslogForwarder) formatCheckResultCEF(c *compv1alpha1.ComplianceCheckResult) string {
+	name := strings.SplitN(c.Description, "\n", 2)[0]
+	if name == "" {
+		name = c.ID
+	}
+	ext := [][2]string{
+		{"outcome", cefOutcome(c.Status)},
+		{"cs1Label", "scan"},
+		{"cs1", f.scanName},
+		{"cs2Label", "assessmentID"},
+		{"cs2", f.assessmentID},
+		{"cs3Label", "status"},
+		{"cs3", string(c.Status)},
+		{"dhost", f.hostname},
+	}
+	return cefEvent(c.ID, name, cefSeverity(c.Severity), ext)
+}
+
+func (f *syslogForwarder) formatRemediationCEF(r *compv1alpha1.ComplianceRemediation) string {
+	ext := [][2]string{
+		{"cs1Label", "scan"},
+		{"cs1", f.scanName},
+		{"cs2Label", "assessmentID"},
+		{"cs2", f.assessmentID},
+		{"cs4Label", "remediationType"},
+		{"cs4", string(r.Spec.Type)},
+		{"dhost", f.hostname},
+	}
+	return cefEvent(r.Name, "Remediation available", 0, ext)
+}
+
+// send writes a single message, reconnecting if the connection broke. Once
+// sending a message failed, the following ones fail right away with the
+// same error.
+func (f *syslogForwarder) send(msg string) error {
+	if f.err != nil {
+		return f.err
+	}
+
+	var frame []byte
+	if f.network == "udp" {
+		frame = []byte(msg)
+	} else {
+		// Octet counting framing, RFC 6587 section 3.4.1
+		frame = []byte(fmt.Sprintf("%d %s", len(msg), msg))
+	}
+
+	f.err = backoff.Retry(func() error {
+		if time.Now().After(f.deadline) {
+			return backoff.Permanent(fmt.Errorf("timed out forwarding results to syslog server %s after %s",
+				f.address, f.timeout))
+		}
+		if f.conn == nil {
+			// Only a broken connection is worth retrying, a server
+			// that can't be connected to won't be any sooner
+			if err := f.connect(); err != nil {
+				return backoff.Permanent(err)
+			}
+		}
+		if _, err := f.conn.Write(frame); err != nil {
+			f.conn.Close()
+			f.conn = nil
+			return err
+		}
+		return nil
+	}, backoff.WithMaxRetries(backoff.NewExponentialBackOff(), syslogMaxRetries))
+	return f.err
+}
+
+func (f *syslogForwarder) connect() error {
+	dialer := &net.Dialer{Deadline: f.deadline}
+	var err error
+	if f.tlsConfig != nil {
+		f.conn, err = tls.DialWithDialer(dialer, f.network, f.address, f.tlsConfig)
+	} else {
+		f.conn, err = dialer.Dial(f.network, f.address)
+	}
+	if err != nil {
+		f.conn = nil
+		return fmt.Errorf("cannot connect to syslog server %s: %w", f.address, err)
+	}
+	if err := f.conn.SetWriteDeadline(f.deadline); err != nil {
+		f.conn.Close()
+		f.conn = nil
+		return err
+	}
+	return nil
+}
+
+func syslogCheckResultSeverity(c *compv1alpha1.ComplianceCheckResult) int {
+	switch c.Status {
+	case compv1alpha1.CheckResultError:
+		return syslogSeverityError
+	case compv1alpha1.CheckResultFail, compv1alpha1.CheckResultInconsistent:
+		if c.Severity == compv1alpha1.CheckResultSeverityHigh {
+			return syslogSeverityError
+		}
+		return syslogSeverityWarning
+	case compv1alpha1.CheckResultManual:
+		return syslogSeverityNotice
+	default:
+		return syslogSeverityInfo
+	}
+}
+
+// syslogHeaderField returns the value as a valid header field which may only
+// consist of printable ASCII characters
+func syslogHeaderField(value string, maxLen int) string {
+	field := strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, value)
+	if len(field) > maxLen {
+		field = field[:maxLen]
+	}
+	if field == "" {
+		return "-"
+	}
+	return field
+}
+
+func syslogSDElement(id string, params [][2]string) string {
+	var b strings.Builder
+	b.WriteString("[" + id)
+	for _, p := range params {
+		// SD-NAME can't contain '=', ' ', ']' or '"'
+		name := strings.Map(func(r rune) rune {
+			if r == '=' || r == ']' || r == '"' {
+				return -1
+			}
+			return r
+		}, syslogHeaderField(p[0], 32))
+		if name == "-" || name == "" {
+			continue
+		}
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(p[1])
+		fmt.Fprintf(&b, ` %s="%s"`, name, value)
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+func cefEvent(signatureID, name string, severity int, ext [][2]string) string {
+	header := strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ")
+	extension := strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+
+	parts := make([]string, 0, len(ext))
+	for _, e := range ext {
+		if e[1] == "" {
+			continue
+		}
+		parts = append(parts, e[0]+"="+extension.Replace(e[1]))
+	}
+	return fmt.Sprintf("CEF:0|%s|%s|%s|%s|%s|%d|%s",
+		cefVendor,
+		syslogAppName,
+		version.Version,
+		header.Replace(signatureID),
+		header.Replace(name),
+		severity,
+		strings.Join(parts, " "))
+}
+
+// cefSeverity maps the severity of a rule to the 0-10 CEF scale
+func cefSeverity(s compv1alpha1.ComplianceCheckResultSeverity) int {
+	switch s {
+	case compv1alpha1.CheckResultSeverityHigh:
+		return 8
+	case compv1alpha1.CheckResultSeverityMedium:
+		return 5
+	case compv1alpha1.CheckResultSeverityLow:
+		return 3
+	case compv1alpha1.CheckResultSeverityInfo:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// cefOutcome maps the status of a check to the CEF outcome field
+func cefOutcome(s compv1alpha1.ComplianceCheckStatus) string {
+	switch s {
+	case compv1alpha1.CheckResultPass:
+		return "success"
+	case compv1alpha1.CheckResultFail:
+		return "failure"
+	case compv1alpha1.CheckResultError:
+		return "error"
+	case compv1alpha1.CheckResultInconsistent:
+		return "inconsistent"
+	case compv1alpha1.CheckResultManual:
+		return "manual"
+	case compv1alpha1.CheckResultNotApplicable:
+		return "not-applicable"
+	case compv1alpha1.CheckResultInfo:
+		return "info"
+	default:
+		return "unknown"
+	}
+}
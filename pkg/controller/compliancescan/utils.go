@@ -29,7 +29,6 @@ const (
 	ServerCertPrefix             = "result-server-cert-"
 	ClientCertPrefix             = "result-client-cert-"
 	RootCAPrefix                 = "root-ca-"
-	CertValidityDays             = 1
 	KubeletConfigCMSuffix        = "-runtime-kubeletconfig"
 )
 
@@ -52,21 +51,13 @@ func absContentPath(relContentPath string) string {
 	return path.Join("/content/", relContentPath)
 }
 
-// Issue a server cert using the instance Root CA (it needs to be created prior to calling this function).
-func makeServerCertSecret(c client.Client, instance *compv1alpha1.ComplianceScan, namespace string) (*v1.Secret, error) {
-	// Creating the server cert, first fetch the root CA.
-	caSecret := &corev1.Secret{}
-	err := c.Get(context.TODO(), types.NamespacedName{Name: RootCAPrefix + instance.Name, Namespace: namespace}, caSecret)
+// Issue a server cert (signed by caKey) for instance and return in a secret.
+func serverCertSecret(instance *compv1alpha1.ComplianceScan, ca, caKey []byte, namespace string) (*v1.Secret, error) {
+	opts, _, err := certOptionsForScan(instance)
 	if err != nil {
 		return nil, err
 	}
-
-	return serverCertSecret(instance, caSecret.Data[corev1.TLSCertKey], caSecret.Data[corev1.TLSPrivateKeyKey], namespace)
-}
-
-// Issue a server cert (signed by caKey) for instance and return in a secret. Separated from makeServerCertSecret() to help with testing.
-func serverCertSecret(instance *compv1alpha1.ComplianceScan, ca, caKey []byte, namespace string) (*v1.Secret, error) {
-	cert, key, err := utils.NewServerCert(ca, caKey, instance.Name+ServerCertInstanceSuffix, CertValidityDays)
+	cert, key, err := utils.NewServerCert(ca, caKey, instance.Name+ServerCertInstanceSuffix, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -74,21 +65,13 @@ func serverCertSecret(instance *compv1alpha1.ComplianceScan, ca, caKey []byte, n
 	return certSecret(getServerCertSecretName(instance), namespace, cert, key, ca), nil
 }
 
-// Issue a Client cert using the instance Root CA (it needs to be created prior to calling this function).
-func makeClientCertSecret(c client.Client, instance *compv1alpha1.ComplianceScan, namespace string) (*v1.Secret, error) {
-	// Creating the Client cert, first fetch the root CA.
-	caSecret := &corev1.Secret{}
-	err := c.Get(context.TODO(), types.NamespacedName{Name: RootCAPrefix + instance.Name, Namespace: namespace}, caSecret)
+// Issue a Client cert (signed by caKey) for instance and return in a secret.
+func clientCertSecret(instance *compv1alpha1.ComplianceScan, ca, caKey []byte, namespace string) (*v1.Secret, error) {
+	opts, _, err := certOptionsForScan(instance)
 	if err != nil {
 		return nil, err
 	}
-
-	return clientCertSecret(instance, caSecret.Data[corev1.TLSCertKey], caSecret.Data[corev1.TLSPrivateKeyKey], namespace)
-}
-
-// Issue a Client cert (signed by caKey) for instance and return in a secret. Separated from makeClientCertSecret() to help with testing.
-func clientCertSecret(instance *compv1alpha1.ComplianceScan, ca, caKey []byte, namespace string) (*v1.Secret, error) {
-	cert, key, err := utils.NewClientCert(ca, caKey, instance.Name+ClientCertInstanceSuffix, CertValidityDays)
+	cert, key, err := utils.NewClientCert(ca, caKey, instance.Name+ClientCertInstanceSuffix, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -97,7 +80,11 @@ func clientCertSecret(instance *compv1alpha1.ComplianceScan, ca, caKey []byte, n
 }
 
 func makeCASecret(instance *compv1alpha1.ComplianceScan, namespace string) (*v1.Secret, error) {
-	cert, key, err := utils.ComplianceOperatorRootCA(RootCAPrefix+instance.Name, CertValidityDays)
+	opts, _, err := certOptionsForScan(instance)
+	if err != nil {
+		return nil, err
+	}
+	cert, key, err := utils.ComplianceOperatorRootCA(RootCAPrefix+instance.Name, opts)
 	if err != nil {
 		return nil, err
 	}
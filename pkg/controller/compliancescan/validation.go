@@ -0,0 +1,30 @@
+package compliancescan
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+// ValidateScanSettings checks the scan settings that are otherwise only
+// found invalid once a scan runs with them. It's used by the admission
+// webhooks of the objects the settings are copied from.
+func ValidateScanSettings(settings *compv1alpha1.ComplianceScanSettings) error {
+	if settings.Timeout != "" {
+		if _, err := time.ParseDuration(settings.Timeout); err != nil {
+			return fmt.Errorf("cannot parse timeout value %s: %w", settings.Timeout, err)
+		}
+	}
+	if settings.RawResultStorage.Size != "" {
+		if _, err := resource.ParseQuantity(settings.RawResultStorage.Size); err != nil {
+			return fmt.Errorf("cannot parse raw result storage size %s: %w", settings.RawResultStorage.Size, err)
+		}
+	}
+	if err := validateRawResultStorage(&settings.RawResultStorage); err != nil {
+		return fmt.Errorf("invalid raw result storage: %w", err)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package compliancesuite
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
@@ -16,6 +17,7 @@ import (
 	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -56,6 +58,8 @@ func newReconciler(mgr manager.Manager, met *metrics.Metrics, si utils.CtlplaneS
 		Recorder:       mgr.GetEventRecorderFor("suitectrl"),
 		Metrics:        met,
 		schedulingInfo: si,
+
+		NodeRemediationBackend: utils.GetNodeRemediationBackend(),
 	}
 }
 
@@ -84,6 +88,9 @@ type ReconcileComplianceSuite struct {
 	// helps us schedule platform scans on the nodes labeled for the
 	// compliance operator's control plane
 	schedulingInfo utils.CtlplaneSchedulingInfo
+	// The backend the node remediations are applied with, MachineConfigs
+	// are used if unset
+	NodeRemediationBackend utils.NodeRemediationBackend
 }
 
 // Reconcile reads that state of the cluster for a ComplianceSuite object and makes changes based on the state read
@@ -174,10 +181,14 @@ func (r *ReconcileComplianceSuite) Reconcile(ctx context.Context, request reconc
 	if suiteCopy.IsResultAvailable() {
 		sCopy := suite.DeepCopy()
 		sCopy.Status.SetConditionReady()
+		sCopy.Status.SetDeferredRemediations(suiteCopy.Status.DeferredRemediations)
 		updateErr := r.Client.Status().Update(context.TODO(), sCopy)
 		if updateErr != nil {
 			return reconcile.Result{}, fmt.Errorf("Error setting ready status for suite: %w", updateErr)
 		}
+		if err := r.reconcileOSCAL(suiteCopy, reqLogger); err != nil {
+			return common.ReturnWithRetriableError(reqLogger, err)
+		}
 		return res, r.reconcileScanRerunnerCronJob(suiteCopy, reqLogger)
 	}
 
@@ -202,6 +213,9 @@ func (r *ReconcileComplianceSuite) validateSuite(suite *compv1alpha1.ComplianceS
 	if isValid, errorMsg := r.validateSchedule(suite); !isValid {
 		return isValid, errorMsg
 	}
+	if isValid, errorMsg := validateMaintenanceWindows(suite); !isValid {
+		return isValid, errorMsg
+	}
 	return true, ""
 }
 
@@ -434,7 +448,11 @@ func launchScanForSuite(r *ReconcileComplianceSuite, suite *compv1alpha1.Complia
 		}
 		for _, profile := range profiles.Items {
 			if profile.ID == scanProfile {
-				if scanWrap.Name == utils.GetScanNameFromProfile(profile.Name, scanWrap.NodeSelector) {
+				scanName := utils.GetScanNameFromProfile(profile.Name, scanWrap.NodeSelector)
+				if scanWrap.NodeLabelSelector != nil {
+					scanName = utils.GetScanNameFromNodeLabelSelector(profile.Name, scanWrap.NodeLabelSelector)
+				}
+				if scanWrap.Name == scanName {
 					profileUniqueID = profile.GetLabels()[compv1alpha1.ProfileGuidLabel]
 					break
 				}
@@ -478,6 +496,10 @@ func newScanForSuite(suite *compv1alpha1.ComplianceSuite, scanWrap *compv1alpha1
 // Reconcile the remediation application in the suite. Note that the suite that this takes is already
 // a copy, so it's safe to modify.
 func (r *ReconcileComplianceSuite) reconcileRemediations(suite *compv1alpha1.ComplianceSuite, logger logr.Logger) (reconcile.Result, error) {
+	// Set again below if the remediations are deferred, and stored along
+	// with the Ready condition
+	suite.Status.DeferredRemediations = nil
+
 	// We don't need to do anything else unless auto-applied is enabled
 	if !suite.ShouldApplyRemediations() {
 		return reconcile.Result{}, nil
@@ -496,9 +518,13 @@ func (r *ReconcileComplianceSuite) reconcileRemediations(suite *compv1alpha1.Com
 		return reconcile.Result{}, err
 	}
 
-	if err := r.Client.List(context.TODO(), mcfgpools); err != nil {
-		log.Error(err, "Failed to list pools")
-		return reconcile.Result{}, err
+	// Without MachineConfigs, the node remediations are applied like any
+	// other remediation and there are no pools to pause
+	if r.usesMachineConfigPools() {
+		if err := r.Client.List(context.TODO(), mcfgpools); err != nil {
+			log.Error(err, "Failed to list pools")
+			return reconcile.Result{}, err
+		}
 	}
 
 	// We only post-process when everything is done.
@@ -509,6 +535,30 @@ func (r *ReconcileComplianceSuite) reconcileRemediations(suite *compv1alpha1.Com
 		return reconcile.Result{}, nil
 	}
 
+	// Remediations applied automatically wait for a maintenance window, and
+	// so does unpausing the pools they're applied to. Applying them through
+	// the annotation isn't deferred.
+	if suite.Spec.AutoApplyRemediations && !suite.ApplyRemediationsAnnotationSet() && len(suite.Spec.MaintenanceWindows) > 0 {
+		now := time.Now()
+		open, next, err := inMaintenanceWindow(suite.Spec.MaintenanceWindows, now)
+		if err != nil {
+			return reconcile.Result{}, common.WrapNonRetriableCtrlError(err)
+		}
+		if !open {
+			deferred, err := r.getDeferredRemediations(remList, mcfgpools)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			if deferred != nil {
+				deferred.NextWindow = metav1.NewTime(next)
+				suite.Status.DeferredRemediations = deferred
+				logger.Info("Deferring remediations until the next maintenance window", "NextWindow", next,
+					"ComplianceRemediations", deferred.Remediations, "MachineConfigPools", deferred.MachineConfigPools)
+				return reconcile.Result{RequeueAfter: next.Sub(now)}, nil
+			}
+		}
+	}
+
 	// Construct the list of the statuses
 	for _, rem := range remList.Items {
 		// get relevant scan
@@ -525,6 +575,12 @@ func (r *ReconcileComplianceSuite) reconcileRemediations(suite *compv1alpha1.Com
 			continue
 		}
 
+		// Remediations reverted by an administrator are only applied
+		// again by hand
+		if rem.Status.ApplicationState == compv1alpha1.RemediationReverted {
+			continue
+		}
+
 		if err := r.applyRemediation(rem, suite, scan, mcfgpools, affectedMcfgPools, logger); err != nil {
 			return reconcile.Result{}, err
 		}
@@ -544,6 +600,13 @@ func (r *ReconcileComplianceSuite) reconcileRemediations(suite *compv1alpha1.Com
 				r.Recorder.Event(suite, corev1.EventTypeWarning, "CannotRemediate", "Remediation needs-review. Values not set"+" Remediation:"+rem.Name)
 				continue
 			}
+			if rem.Status.ApplicationState == compv1alpha1.RemediationPendingApproval {
+				r.Recorder.Event(suite, corev1.EventTypeNormal, "RemediationPendingApproval", "Remediation is waiting for sign-offs"+" Remediation:"+rem.Name)
+				continue
+			}
+			if rem.Status.ApplicationState == compv1alpha1.RemediationReverted {
+				continue
+			}
 			logger.Info("Remediation not applied yet. Skipping post-processing", "ComplianceRemediation.Name", rem.Name)
 			return reconcile.Result{Requeue: true, RequeueAfter: 10 * time.Second}, nil
 		}
@@ -583,13 +646,53 @@ func (r *ReconcileComplianceSuite) reconcileRemediations(suite *compv1alpha1.Com
 	return reconcile.Result{}, nil
 }
 
+// getDeferredRemediations returns the remediations of the finished scans
+// that aren't applied yet and the paused pools they'd be applied to, or nil if
+// there is nothing to defer
+func (r *ReconcileComplianceSuite) getDeferredRemediations(remList *compv1alpha1.ComplianceRemediationList,
+	mcfgpools *mcfgv1.MachineConfigPoolList) (*compv1alpha1.DeferredRemediations, error) {
+	deferred := &compv1alpha1.DeferredRemediations{}
+	pools := map[string]bool{}
+	for i := range remList.Items {
+		rem := &remList.Items[i]
+		scan := &compv1alpha1.ComplianceScan{}
+		scanKey := types.NamespacedName{Name: rem.GetScan(), Namespace: rem.Namespace}
+		if err := r.Client.Get(context.TODO(), scanKey, scan); err != nil {
+			return nil, err
+		}
+		if scan.Status.Phase != compv1alpha1.PhaseDone {
+			continue
+		}
+
+		if !rem.IsApplied() && rem.Status.ApplicationState != compv1alpha1.RemediationNeedsReview &&
+			rem.Status.ApplicationState != compv1alpha1.RemediationPendingApproval &&
+			rem.Status.ApplicationState != compv1alpha1.RemediationReverted {
+			deferred.Remediations = append(deferred.Remediations, rem.Name)
+		}
+		if !r.usesMachineConfigPools() || !(utils.IsMachineConfig(rem.Spec.Current.Object) || utils.IsKubeletConfig(rem.Spec.Current.Object)) {
+			continue
+		}
+		if pool := r.getAffectedMcfgPool(scan, mcfgpools); pool != nil && pool.Spec.Paused && !pools[pool.Name] {
+			pools[pool.Name] = true
+			deferred.MachineConfigPools = append(deferred.MachineConfigPools, pool.Name)
+		}
+	}
+
+	if len(deferred.Remediations) == 0 && len(deferred.MachineConfigPools) == 0 {
+		return nil, nil
+	}
+	sort.Strings(deferred.Remediations)
+	sort.Strings(deferred.MachineConfigPools)
+	return deferred, nil
+}
+
 func (r *ReconcileComplianceSuite) applyRemediation(rem compv1alpha1.ComplianceRemediation,
 	suite *compv1alpha1.ComplianceSuite,
 	scan *compv1alpha1.ComplianceScan,
 	mcfgpools *mcfgv1.MachineConfigPoolList,
 	affectedMcfgPools map[string]*mcfgv1.MachineConfigPool,
 	logger logr.Logger) error {
-	if utils.IsMachineConfig(rem.Spec.Current.Object) || utils.IsKubeletConfig(rem.Spec.Current.Object) {
+	if r.usesMachineConfigPools() && (utils.IsMachineConfig(rem.Spec.Current.Object) || utils.IsKubeletConfig(rem.Spec.Current.Object)) {
 		// get affected pool
 		pool := r.getAffectedMcfgPool(scan, mcfgpools)
 		// we only need to operate on pools that are affected
@@ -614,6 +717,12 @@ func (r *ReconcileComplianceSuite) applyRemediation(rem compv1alpha1.ComplianceR
 	return nil
 }
 
+// usesMachineConfigPools tells whether the node remediations are applied
+// through MachineConfigPools, which are paused while applying them
+func (r *ReconcileComplianceSuite) usesMachineConfigPools() bool {
+	return r.NodeRemediationBackend == "" || r.NodeRemediationBackend == utils.NodeRemediationBackendMachineConfig
+}
+
 func (r *ReconcileComplianceSuite) applyGenericRemediation(rem compv1alpha1.ComplianceRemediation,
 	suite *compv1alpha1.ComplianceSuite,
 	logger logr.Logger) error {
@@ -661,7 +770,7 @@ func (r *ReconcileComplianceSuite) applyMcfgRemediationAndPausePool(rem compv1al
 func (r *ReconcileComplianceSuite) getAffectedMcfgPool(scan *compv1alpha1.ComplianceScan, mcfgpools *mcfgv1.MachineConfigPoolList) *mcfgv1.MachineConfigPool {
 	for i := range mcfgpools.Items {
 		pool := &mcfgpools.Items[i]
-		if utils.McfgPoolLabelMatches(scan.Spec.NodeSelector, pool) {
+		if utils.McfgPoolLabelMatches(scan.GetNodeSelectorLabels(), pool) {
 			return pool
 		}
 	}
@@ -3,6 +3,7 @@ package compliancesuite
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics"
 	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics/metricsfakes"
@@ -25,6 +26,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/oscal"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
 )
 
 var _ = Describe("ComplianceSuiteController", func() {
@@ -385,6 +389,88 @@ var _ = Describe("ComplianceSuiteController", func() {
 				BeforeEach(suiteAndScansInDonePhase)
 				It("Should apply the remediation", reconcileShouldApplyTheRemediationAndHandlePausingPools)
 
+				Context("With the DaemonSet node remediation backend", func() {
+					BeforeEach(func() {
+						reconciler.NodeRemediationBackend = utils.NodeRemediationBackendDaemonSet
+					})
+					It("Should apply the remediation without pausing the pool", func() {
+						rem := reconcileAndGetRemediation()
+						Expect(rem.Spec.Apply).To(BeTrue())
+
+						p := &mcfgv1.MachineConfigPool{}
+						err := reconciler.Client.Get(ctx, types.NamespacedName{Name: poolName}, p)
+						Expect(err).To(BeNil())
+						Expect(p.Spec.Paused).To(BeFalse())
+					})
+				})
+
+				Context("With a maintenance window", func() {
+					It("Should defer applying the remediation until the window opens", func() {
+						// A window opening tomorrow is closed today
+						tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+						suite.Spec.MaintenanceWindows = []compv1alpha1.MaintenanceWindow{
+							{
+								Days:     []compv1alpha1.Weekday{compv1alpha1.Weekday(tomorrow.Weekday().String())},
+								Duration: metav1.Duration{Duration: time.Hour},
+							},
+						}
+						res, err := reconciler.reconcileRemediations(suite, logger)
+						Expect(err).To(BeNil())
+						Expect(res.RequeueAfter).To(BeNumerically(">", 0))
+
+						By("the remediation and the pool not being touched")
+						rem := &compv1alpha1.ComplianceRemediation{}
+						err = reconciler.Client.Get(ctx, types.NamespacedName{Name: remediationName, Namespace: namespace}, rem)
+						Expect(err).To(BeNil())
+						Expect(rem.Spec.Apply).To(BeFalse())
+						p := &mcfgv1.MachineConfigPool{}
+						poolkey := types.NamespacedName{Name: poolName}
+						err = reconciler.Client.Get(ctx, poolkey, p)
+						Expect(err).To(BeNil())
+						Expect(p.Spec.Paused).To(BeFalse())
+
+						By("the deferred work being shown in the status")
+						deferred := suite.Status.DeferredRemediations
+						Expect(deferred).ToNot(BeNil())
+						Expect(deferred.Remediations).To(Equal([]string{remediationName}))
+						Expect(deferred.MachineConfigPools).To(BeEmpty())
+						Expect(deferred.NextWindow.UTC().Day()).To(Equal(tomorrow.Day()))
+
+						By("keeping a pool paused outside of the window")
+						p.Spec.Paused = true
+						err = reconciler.Client.Update(ctx, p)
+						Expect(err).To(BeNil())
+						rem.Spec.Apply = true
+						err = reconciler.Client.Update(ctx, rem)
+						Expect(err).To(BeNil())
+						rem.Status.ApplicationState = compv1alpha1.RemediationApplied
+						err = reconciler.Client.Status().Update(ctx, rem)
+						Expect(err).To(BeNil())
+						_, err = reconciler.reconcileRemediations(suite, logger)
+						Expect(err).To(BeNil())
+						Expect(suite.Status.DeferredRemediations.Remediations).To(BeEmpty())
+						Expect(suite.Status.DeferredRemediations.MachineConfigPools).To(Equal([]string{poolName}))
+						err = reconciler.Client.Get(ctx, poolkey, p)
+						Expect(err).To(BeNil())
+						Expect(p.Spec.Paused).To(BeTrue())
+
+						By("unpausing the pool once the window opens")
+						suite.Spec.MaintenanceWindows = []compv1alpha1.MaintenanceWindow{
+							{
+								Schedule: "0 * * * *",
+								Duration: metav1.Duration{Duration: time.Hour},
+								TimeZone: "Europe/Prague",
+							},
+						}
+						_, err = reconciler.reconcileRemediations(suite, logger)
+						Expect(err).To(BeNil())
+						Expect(suite.Status.DeferredRemediations).To(BeNil())
+						err = reconciler.Client.Get(ctx, poolkey, p)
+						Expect(err).To(BeNil())
+						Expect(p.Spec.Paused).To(BeFalse())
+					})
+				})
+
 				Context("With remove-outdated annotation", func() {
 					BeforeEach(prepareForRemoveOutdatedScenarios)
 					It("Should remove the outdated remediation and remove the annotation", func() {
@@ -926,4 +1012,98 @@ var _ = Describe("ComplianceSuiteController", func() {
 		})
 	})
 
+	Context("When generating OSCAL documents", func() {
+		var cmKey types.NamespacedName
+
+		BeforeEach(func() {
+			cmKey = types.NamespacedName{Name: GetOSCALConfigMapName(suiteName), Namespace: namespace}
+
+			rule := &compv1alpha1.Rule{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "rhcos4-audit-rules",
+					Namespace: namespace,
+					Annotations: map[string]string{
+						compv1alpha1.RuleControlAnnotationPrefix + "NIST-800-53": "AU-2;AU-12(1)",
+					},
+				},
+				RulePayload: compv1alpha1.RulePayload{
+					ID:    "xccdf_org.ssgproject.content_rule_audit_rules",
+					Title: "Audit rules",
+				},
+			}
+			check := &compv1alpha1.ComplianceCheckResult{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "testScanNode-audit-rules",
+					Namespace: namespace,
+					Labels: map[string]string{
+						compv1alpha1.SuiteLabel:          suiteName,
+						compv1alpha1.ComplianceScanLabel: "testScanNode",
+					},
+				},
+				ID:     "xccdf_org.ssgproject.content_rule_audit_rules",
+				Status: compv1alpha1.CheckResultFail,
+			}
+			resultCM := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "testScanNode-node-1-pod",
+					Namespace: common.GetComplianceOperatorNamespace(),
+					Labels: map[string]string{
+						compv1alpha1.ComplianceScanLabel: "testScanNode",
+						compv1alpha1.ResultLabel:         "",
+					},
+					Annotations: map[string]string{
+						"openscap-scan-result/node": "node-1",
+					},
+				},
+			}
+			for _, obj := range []client.Object{rule, check, resultCM} {
+				Expect(reconciler.Client.Create(ctx, obj)).To(Succeed())
+			}
+			suiteAndScansInDonePhase()
+		})
+
+		It("Should not create the ConfigMap unless enabled", func() {
+			Expect(reconciler.reconcileOSCAL(suite, logger)).To(Succeed())
+			cm := &corev1.ConfigMap{}
+			err := reconciler.Client.Get(ctx, cmKey, cm)
+			Expect(err).NotTo(BeNil())
+		})
+
+		It("Should store the assessment results and component definition", func() {
+			suite.Spec.GenerateOSCAL = true
+			Expect(reconciler.reconcileOSCAL(suite, logger)).To(Succeed())
+
+			cm := &corev1.ConfigMap{}
+			Expect(reconciler.Client.Get(ctx, cmKey, cm)).To(Succeed())
+			Expect(cm.Data).To(HaveKey(OSCALAssessmentResultsKey))
+			Expect(cm.Data).To(HaveKey(OSCALComponentDefinitionKey))
+			Expect(cm.OwnerReferences).To(HaveLen(1))
+
+			ar := &oscal.AssessmentResultsDocument{}
+			Expect(json.Unmarshal([]byte(cm.Data[OSCALAssessmentResultsKey]), ar)).To(Succeed())
+			res := ar.AssessmentResults.Results[0]
+			Expect(res.Observations).To(HaveLen(1))
+			Expect(res.Observations[0].Subjects).To(HaveLen(1))
+			Expect(res.Observations[0].Subjects[0].Title).To(Equal("node-1"))
+			Expect(res.Findings).To(HaveLen(2))
+			Expect(res.Findings[0].Target.Status.State).To(Equal("not-satisfied"))
+
+			By("Not regenerating the documents for the same scan run")
+			cm.Data[OSCALAssessmentResultsKey] = "untouched"
+			Expect(reconciler.Client.Update(ctx, cm)).To(Succeed())
+			Expect(reconciler.reconcileOSCAL(suite, logger)).To(Succeed())
+			Expect(reconciler.Client.Get(ctx, cmKey, cm)).To(Succeed())
+			Expect(cm.Data[OSCALAssessmentResultsKey]).To(Equal("untouched"))
+
+			By("Regenerating the documents once an exception waives a check")
+			check := &compv1alpha1.ComplianceCheckResult{}
+			Expect(reconciler.Client.Get(ctx, types.NamespacedName{Name: "testScanNode-audit-rules", Namespace: namespace}, check)).To(Succeed())
+			check.Labels[compv1alpha1.ComplianceCheckResultWaivedLabel] = ""
+			check.Annotations = map[string]string{compv1alpha1.ComplianceCheckResultWaivedByAnnotation: "audit-rules"}
+			Expect(reconciler.Client.Update(ctx, check)).To(Succeed())
+			Expect(reconciler.reconcileOSCAL(suite, logger)).To(Succeed())
+			Expect(reconciler.Client.Get(ctx, cmKey, cm)).To(Succeed())
+			Expect(cm.Data[OSCALAssessmentResultsKey]).To(ContainSubstring(`"waived-by"`))
+		})
+	})
 })
@@ -0,0 +1,116 @@
+package compliancesuite
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	// The operator image doesn't necessarily ship the time zone database
+	_ "time/tzdata"
+
+	cron "github.com/robfig/cron/v3"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+var cronWeekdays = map[compv1alpha1.Weekday]string{
+	"Sunday":    "0",
+	"Monday":    "1",
+	"Tuesday":   "2",
+	"Wednesday": "3",
+	"Thursday":  "4",
+	"Friday":    "5",
+	"Saturday":  "6",
+}
+
+// maintenanceWindowSchedule returns the schedule the window opens on. Weekly
+// windows are converted to a cron schedule.
+func maintenanceWindowSchedule(window *compv1alpha1.MaintenanceWindow) (cron.Schedule, error) {
+	if (window.Schedule == "") == (len(window.Days) == 0) {
+		return nil, fmt.Errorf("exactly one of schedule and days must be set")
+	}
+	if window.Duration.Duration <= 0 {
+		return nil, fmt.Errorf("the duration must be positive")
+	}
+
+	// The time zone is given by timeZone only, so that the schedule
+	// doesn't end up with two of them
+	if strings.HasPrefix(window.Schedule, "CRON_TZ=") || strings.HasPrefix(window.Schedule, "TZ=") {
+		return nil, fmt.Errorf("the schedule can't set a time zone, use timeZone instead")
+	}
+
+	spec := window.Schedule
+	if spec == "" {
+		hour, minute := 0, 0
+		if window.StartTime != "" {
+			startTime, err := time.Parse("15:04", window.StartTime)
+			if err != nil {
+				return nil, fmt.Errorf("invalid start time %s", window.StartTime)
+			}
+			hour, minute = startTime.Hour(), startTime.Minute()
+		}
+		days := make([]string, 0, len(window.Days))
+		for _, day := range window.Days {
+			cronDay, ok := cronWeekdays[day]
+			if !ok {
+				return nil, fmt.Errorf("invalid day %s", day)
+			}
+			days = append(days, cronDay)
+		}
+		spec = fmt.Sprintf("%d %d * * %s", minute, hour, strings.Join(days, ","))
+	}
+	// Without a time zone, the schedule would be in the local time zone of
+	// the operator rather than in UTC
+	timeZone := window.TimeZone
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
+	spec = fmt.Sprintf("CRON_TZ=%s %s", timeZone, spec)
+
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule: %w", err)
+	}
+	return schedule, nil
+}
+
+// validateMaintenanceWindows validates the maintenance windows of the suite.
+// Else it returns false (not valid) and an error message
+func validateMaintenanceWindows(suite *compv1alpha1.ComplianceSuite) (bool, string) {
+	for i := range suite.Spec.MaintenanceWindows {
+		schedule, err := maintenanceWindowSchedule(&suite.Spec.MaintenanceWindows[i])
+		if err != nil {
+			return false, fmt.Sprintf("ComplianceSuite's maintenance window %d is invalid: %s", i, err)
+		}
+		if schedule.Next(time.Now()).IsZero() {
+			return false, fmt.Sprintf("ComplianceSuite's maintenance window %d never opens", i)
+		}
+	}
+	return true, ""
+}
+
+// inMaintenanceWindow tells whether any of the windows is open at the given
+// time. If none is, it also returns when the next one opens.
+func inMaintenanceWindow(windows []compv1alpha1.MaintenanceWindow, now time.Time) (bool, time.Time, error) {
+	var next time.Time
+	for i := range windows {
+		schedule, err := maintenanceWindowSchedule(&windows[i])
+		if err != nil {
+			return false, time.Time{}, err
+		}
+		// The first opening after the start of the window that would
+		// still be open now is either in the past, meaning the window is
+		// open, or the next time the window opens
+		opens := schedule.Next(now.Add(-windows[i].Duration.Duration))
+		if opens.IsZero() {
+			// The schedule never matches
+			continue
+		}
+		if !opens.After(now) {
+			return true, time.Time{}, nil
+		}
+		if next.IsZero() || opens.Before(next) {
+			next = opens
+		}
+	}
+	return false, next, nil
+}
@@ -0,0 +1,76 @@
+package compliancesuite
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+var _ = Describe("Maintenance windows", func() {
+	// Saturdays from 22:00 to 02:00 in Prague, which is UTC+2 in June
+	weekly := compv1alpha1.MaintenanceWindow{
+		Days:      []compv1alpha1.Weekday{"Saturday"},
+		StartTime: "22:00",
+		Duration:  metav1.Duration{Duration: 4 * time.Hour},
+		TimeZone:  "Europe/Prague",
+	}
+
+	It("tells whether a weekly window is open in its time zone", func() {
+		windows := []compv1alpha1.MaintenanceWindow{weekly}
+
+		open, _, err := inMaintenanceWindow(windows, time.Date(2024, time.June, 15, 21, 0, 0, 0, time.UTC))
+		Expect(err).To(BeNil())
+		Expect(open).To(BeTrue())
+
+		open, next, err := inMaintenanceWindow(windows, time.Date(2024, time.June, 16, 1, 0, 0, 0, time.UTC))
+		Expect(err).To(BeNil())
+		Expect(open).To(BeFalse())
+		Expect(next.UTC()).To(Equal(time.Date(2024, time.June, 22, 20, 0, 0, 0, time.UTC)))
+	})
+
+	It("returns the window opening first", func() {
+		windows := []compv1alpha1.MaintenanceWindow{
+			weekly,
+			{Schedule: "30 3 * * *", Duration: metav1.Duration{Duration: time.Hour}},
+		}
+		open, next, err := inMaintenanceWindow(windows, time.Date(2024, time.June, 17, 12, 0, 0, 0, time.UTC))
+		Expect(err).To(BeNil())
+		Expect(open).To(BeFalse())
+		Expect(next.UTC()).To(Equal(time.Date(2024, time.June, 18, 3, 30, 0, 0, time.UTC)))
+	})
+
+	It("defaults to UTC regardless of the local time zone", func() {
+		local := time.Local
+		defer func() { time.Local = local }()
+		time.Local = time.FixedZone("UTC+5", 5*60*60)
+
+		windows := []compv1alpha1.MaintenanceWindow{
+			{Schedule: "30 3 * * *", Duration: metav1.Duration{Duration: time.Hour}},
+		}
+		open, _, err := inMaintenanceWindow(windows, time.Date(2024, time.June, 17, 4, 0, 0, 0, time.UTC))
+		Expect(err).To(BeNil())
+		Expect(open).To(BeTrue())
+	})
+
+	It("rejects invalid windows", func() {
+		invalid := []compv1alpha1.MaintenanceWindow{
+			{Duration: metav1.Duration{Duration: time.Hour}},
+			{Schedule: "0 2 * * 6", Days: []compv1alpha1.Weekday{"Saturday"}, Duration: metav1.Duration{Duration: time.Hour}},
+			{Schedule: "0 2 * * 6"},
+			{Schedule: "0 2 * * 6", Duration: metav1.Duration{Duration: time.Hour}, TimeZone: "Mars/Olympus_Mons"},
+			{Schedule: "0 0 30 2 *", Duration: metav1.Duration{Duration: time.Hour}},
+			{Schedule: "CRON_TZ=Europe/Prague 0 2 * * 6", Duration: metav1.Duration{Duration: time.Hour}},
+			{Schedule: "TZ=Europe/Prague 0 2 * * 6", Duration: metav1.Duration{Duration: time.Hour}, TimeZone: "Europe/Prague"},
+		}
+		for _, window := range invalid {
+			suite := &compv1alpha1.ComplianceSuite{}
+			suite.Spec.MaintenanceWindows = []compv1alpha1.MaintenanceWindow{window}
+			valid, _ := validateMaintenanceWindows(suite)
+			Expect(valid).To(BeFalse(), "window %v should be invalid", window)
+		}
+	})
+})
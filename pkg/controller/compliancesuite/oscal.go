@@ -0,0 +1,208 @@
+package compliancesuite
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dsnet/compress/bzip2"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/oscal"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+const (
+	oscalConfigMapSuffix = "-oscal"
+	// oscalVersionAnnotation records which scan runs and results the OSCAL
+	// documents were generated from, so that they're only regenerated after
+	// a rescan or once exceptions or attestations changed the results
+	oscalVersionAnnotation = "compliance.openshift.io/oscal-version"
+
+	OSCALAssessmentResultsKey   = "assessment-results.json"
+	OSCALComponentDefinitionKey = "component-definition.json"
+	oscalCompressedSuffix       = ".bzip2"
+	// Leave some room below the 1MiB object size limit for the metadata
+	oscalMaxUncompressedSize = 900 * 1024
+
+	resultNodeAnnotation = "openscap-scan-result/node"
+)
+
+// GetOSCALConfigMapName returns the name of the ConfigMap holding the OSCAL
+// documents of a suite
+func GetOSCALConfigMapName(suiteName string) string {
+	return suiteName + oscalConfigMapSuffix
+}
+
+// reconcileOSCAL renders the results of a finished suite as OSCAL Assessment
+// Results and a Component Definition and stores them in a ConfigMap owned by
+// the suite
+func (r *ReconcileComplianceSuite) reconcileOSCAL(suite *compv1alpha1.ComplianceSuite, logger logr.Logger) error {
+	if !suite.Spec.GenerateOSCAL || suite.Status.Phase != compv1alpha1.PhaseDone {
+		return nil
+	}
+
+	scans, err := r.getOSCALScanResults(suite)
+	if err != nil {
+		return err
+	}
+	version := oscal.DocumentVersion(scans)
+
+	cmKey := types.NamespacedName{Name: GetOSCALConfigMapName(suite.Name), Namespace: suite.Namespace}
+	found := &corev1.ConfigMap{}
+	err = r.Client.Get(context.TODO(), cmKey, found)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	} else if err == nil && found.Annotations[oscalVersionAnnotation] == version {
+		return nil
+	}
+
+	ruleList := &compv1alpha1.RuleList{}
+	if err := r.Client.List(context.TODO(), ruleList, client.InNamespace(suite.Namespace)); err != nil {
+		return err
+	}
+	rules := utils.RulesByID(ruleList.Items)
+
+	now := time.Now()
+	results, err := json.Marshal(oscal.NewAssessmentResults(suite, scans, rules, now))
+	if err != nil {
+		return err
+	}
+	components, err := json.Marshal(oscal.NewComponentDefinition(suite, scans, rules, now))
+	if err != nil {
+		return err
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cmKey.Name,
+			Namespace: cmKey.Namespace,
+			Labels: map[string]string{
+				compv1alpha1.SuiteLabel: suite.Name,
+			},
+			Annotations: map[string]string{
+				oscalVersionAnnotation: version,
+			},
+		},
+	}
+	if len(results)+len(components) > oscalMaxUncompressedSize {
+		cm.BinaryData = map[string][]byte{}
+		if cm.BinaryData[OSCALAssessmentResultsKey+oscalCompressedSuffix], err = compressOSCAL(results); err != nil {
+			return err
+		}
+		if cm.BinaryData[OSCALComponentDefinitionKey+oscalCompressedSuffix], err = compressOSCAL(components); err != nil {
+			return err
+		}
+	} else {
+		cm.Data = map[string]string{
+			OSCALAssessmentResultsKey:   string(results),
+			OSCALComponentDefinitionKey: string(components),
+		}
+	}
+	if err := controllerutil.SetControllerReference(suite, cm, r.Scheme); err != nil {
+		return err
+	}
+
+	if found.Name == "" {
+		logger.Info("Creating OSCAL ConfigMap", "ConfigMap.Name", cm.Name)
+		return r.Client.Create(context.TODO(), cm)
+	}
+	logger.Info("Updating OSCAL ConfigMap", "ConfigMap.Name", cm.Name)
+	foundCopy := found.DeepCopy()
+	foundCopy.Labels = cm.Labels
+	foundCopy.Annotations = cm.Annotations
+	foundCopy.Data = cm.Data
+	foundCopy.BinaryData = cm.BinaryData
+	foundCopy.OwnerReferences = cm.OwnerReferences
+	return r.Client.Update(context.TODO(), foundCopy)
+}
+
+// getOSCALScanResults gathers the check results of every scan of the suite
+// along with the nodes the scan ran on
+func (r *ReconcileComplianceSuite) getOSCALScanResults(suite *compv1alpha1.ComplianceSuite) ([]oscal.ScanResults, error) {
+	// The check results are read directly from the API server to avoid
+	// caching all of them just for this
+	checkList := &compv1alpha1.ComplianceCheckResultList{}
+	if err := r.Reader.List(context.TODO(), checkList,
+		client.InNamespace(suite.Namespace),
+		client.MatchingLabels{compv1alpha1.SuiteLabel: suite.Name}); err != nil {
+		return nil, err
+	}
+	checksByScan := map[string][]compv1alpha1.ComplianceCheckResult{}
+	for _, check := range checkList.Items {
+		scanName := check.Labels[compv1alpha1.ComplianceScanLabel]
+		checksByScan[scanName] = append(checksByScan[scanName], check)
+	}
+
+	scans := make([]oscal.ScanResults, 0, len(suite.Spec.Scans))
+	for i := range suite.Spec.Scans {
+		scan := &compv1alpha1.ComplianceScan{}
+		key := types.NamespacedName{Name: suite.Spec.Scans[i].Name, Namespace: suite.Namespace}
+		if err := r.Client.Get(context.TODO(), key, scan); err != nil {
+			return nil, err
+		}
+		nodes, err := r.getScanResultNodes(scan)
+		if err != nil {
+			return nil, err
+		}
+		scans = append(scans, oscal.ScanResults{
+			Scan:         scan,
+			Nodes:        nodes,
+			CheckResults: checksByScan[scan.Name],
+		})
+	}
+	return scans, nil
+}
+
+// getScanResultNodes returns the nodes that reported results for a node scan
+// based on the result ConfigMaps. Only the metadata of the ConfigMaps is
+// fetched as the results themselves aren't needed.
+func (r *ReconcileComplianceSuite) getScanResultNodes(scan *compv1alpha1.ComplianceScan) ([]string, error) {
+	if scan.GetScanType() != compv1alpha1.ScanTypeNode {
+		return nil, nil
+	}
+	cmList := &metav1.PartialObjectMetadataList{}
+	cmList.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMapList"))
+	if err := r.Reader.List(context.TODO(), cmList,
+		client.InNamespace(common.GetComplianceOperatorNamespace()),
+		client.MatchingLabels{
+			compv1alpha1.ComplianceScanLabel: scan.Name,
+			compv1alpha1.ResultLabel:         "",
+		}); err != nil {
+		return nil, fmt.Errorf("cannot list result ConfigMaps of scan %s: %w", scan.Name, err)
+	}
+	nodes := []string{}
+	for _, cm := range cmList.Items {
+		if node := cm.Annotations[resultNodeAnnotation]; node != "" {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Strings(nodes)
+	return nodes, nil
+}
+
+func compressOSCAL(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := bzip2.NewWriter(&buf, &bzip2.WriterConfig{Level: bzip2.BestCompression})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,90 @@
+package compliancesuite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/compliancescan"
+)
+
+// AddWebhooks registers the webhook validating ComplianceSuites
+func AddWebhooks(mgr manager.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&compv1alpha1.ComplianceSuite{}).
+		WithValidator(&suiteValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-compliance-openshift-io-v1alpha1-compliancesuite,mutating=false,failurePolicy=fail,sideEffects=None,groups=compliance.openshift.io,resources=compliancesuites,verbs=create;update,versions=v1alpha1,name=vcompliancesuite.compliance.openshift.io,admissionReviewVersions=v1
+
+// suiteValidator rejects the suites that the controller would otherwise
+// only mark as invalid once reconciled
+type suiteValidator struct{}
+
+var _ webhook.CustomValidator = &suiteValidator{}
+
+func (v *suiteValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	suite, ok := obj.(*compv1alpha1.ComplianceSuite)
+	if !ok {
+		return nil, fmt.Errorf("expected a ComplianceSuite but got a %T", obj)
+	}
+	return nil, validateSuiteSpec(suite)
+}
+
+func (v *suiteValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	suite, ok := newObj.(*compv1alpha1.ComplianceSuite)
+	if !ok {
+		return nil, fmt.Errorf("expected a ComplianceSuite but got a %T", newObj)
+	}
+	// Don't get in the way of removing the finalizer
+	if suite.DeletionTimestamp != nil {
+		return nil, nil
+	}
+	return nil, validateSuiteSpec(suite)
+}
+
+func (v *suiteValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateSuiteSettings runs the validations of the suite controller on
+// suite settings, which ScanSettings carry as well
+func ValidateSuiteSettings(settings *compv1alpha1.ComplianceSuiteSettings) error {
+	suite := &compv1alpha1.ComplianceSuite{}
+	suite.Spec.ComplianceSuiteSettings = *settings
+	r := &ReconcileComplianceSuite{}
+	if valid, errorMsg := r.validateSuite(suite); !valid {
+		return errors.New(errorMsg)
+	}
+	return nil
+}
+
+func validateSuiteSpec(suite *compv1alpha1.ComplianceSuite) error {
+	if err := ValidateSuiteSettings(&suite.Spec.ComplianceSuiteSettings); err != nil {
+		return err
+	}
+	for i := range suite.Spec.Scans {
+		scanWrap := &suite.Spec.Scans[i]
+		scan := &compv1alpha1.ComplianceScan{Spec: scanWrap.ComplianceScanSpec}
+		if scan.Spec.ScanType != "" {
+			if _, err := scan.GetScanTypeIfValid(); err != nil {
+				return fmt.Errorf("scan %s: scan type '%s' is not valid", scanWrap.Name, scan.Spec.ScanType)
+			}
+		}
+		if _, err := scan.GetNodeLabelSelector(); err != nil {
+			return fmt.Errorf("scan %s: node label selector is not valid: %w", scanWrap.Name, err)
+		}
+		if err := compliancescan.ValidateScanSettings(&scanWrap.ComplianceScanSettings); err != nil {
+			return fmt.Errorf("scan %s: %w", scanWrap.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package compliancesuite
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+var _ = Describe("Validating ComplianceSuites in the admission webhook", func() {
+	var (
+		validator *suiteValidator
+		suite     *compv1alpha1.ComplianceSuite
+	)
+
+	BeforeEach(func() {
+		validator = &suiteValidator{}
+		suite = &compv1alpha1.ComplianceSuite{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-suite"},
+		}
+		suite.Spec.Schedule = "0 1 * * *"
+		suite.Spec.Scans = []compv1alpha1.ComplianceScanSpecWrapper{
+			{Name: "workers-scan"},
+		}
+		suite.Spec.Scans[0].ScanType = compv1alpha1.ScanTypeNode
+		suite.Spec.Scans[0].Timeout = "30m"
+	})
+
+	It("accepts a valid suite", func() {
+		_, err := validator.ValidateCreate(context.TODO(), suite)
+		Expect(err).To(BeNil())
+	})
+
+	It("rejects invalid suite settings", func() {
+		suite.Spec.Schedule = "every night"
+		_, err := validator.ValidateCreate(context.TODO(), suite)
+		Expect(err).To(MatchError(ContainSubstring("schedule is wrongly formatted")))
+	})
+
+	It("rejects invalid scans", func() {
+		suite.Spec.Scans[0].ScanType = "Cluster"
+		_, err := validator.ValidateCreate(context.TODO(), suite)
+		Expect(err).To(MatchError(ContainSubstring("scan workers-scan: scan type 'Cluster' is not valid")))
+
+		suite.Spec.Scans[0].ScanType = compv1alpha1.ScanTypeNode
+		suite.Spec.Scans[0].Timeout = "half an hour"
+		_, err = validator.ValidateUpdate(context.TODO(), suite, suite)
+		Expect(err).To(MatchError(ContainSubstring("scan workers-scan: cannot parse timeout value")))
+	})
+
+	It("doesn't prevent deleting suites", func() {
+		suite.Spec.Schedule = "every night"
+		now := metav1.Now()
+		suite.DeletionTimestamp = &now
+		_, err := validator.ValidateUpdate(context.TODO(), suite, suite)
+		Expect(err).To(BeNil())
+	})
+})
@@ -11,6 +11,10 @@ import (
 // AddToManagerFuncs is a list of functions to add all Controllers to the Manager
 var AddToManagerFuncs []func(manager.Manager, *metrics.Metrics, utils.CtlplaneSchedulingInfo, *kubernetes.Clientset) error
 
+// AddWebhooksToManagerFuncs is a list of functions to add all admission
+// webhooks to the Manager
+var AddWebhooksToManagerFuncs []func(manager.Manager) error
+
 // AddToManager adds all Controllers to the Manager
 func AddToManager(m manager.Manager,
 	met *metrics.Metrics,
@@ -29,3 +33,13 @@ func AddToManager(m manager.Manager,
 	}
 	return nil
 }
+
+// AddWebhooksToManager adds all admission webhooks to the Manager
+func AddWebhooksToManager(m manager.Manager) error {
+	for _, f := range AddWebhooksToManagerFuncs {
+		if err := f(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
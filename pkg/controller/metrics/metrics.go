@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"fmt"
 	"net/http"
+	"sort"
 
 	"github.com/go-logr/logr"
 	libgocrypto "github.com/openshift/library-go/pkg/crypto"
@@ -14,6 +15,7 @@ import (
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
 )
 
 const (
@@ -23,6 +25,8 @@ const (
 	metricNameComplianceScanError         = "compliance_scan_error_total"
 	metricNameComplianceRemediationStatus = "compliance_remediation_status_total"
 	metricNameComplianceStateGauge        = "compliance_state"
+	metricNameComplianceScanCheckResults  = "compliance_scan_check_results"
+	metricNameComplianceScanFailingCheck  = "compliance_scan_failing_check"
 
 	metricLabelScanResult       = "result"
 	metricLabelScanName         = "name"
@@ -31,6 +35,11 @@ const (
 	metricLabelScanError        = "error"
 	metricLabelRemediationName  = "name"
 	metricLabelRemediationState = "state"
+	metricLabelCheckStatus      = "status"
+	metricLabelCheckSeverity    = "severity"
+	metricLabelCheckName        = "check"
+	metricLabelControlStandard  = "standard"
+	metricLabelControlID        = "control"
 
 	HandlerPath                  = "/metrics-co"
 	ControllerMetricsServiceName = "metrics-co"
@@ -57,6 +66,16 @@ type ControllerMetrics struct {
 	metricComplianceScanStatus        *prometheus.CounterVec
 	metricComplianceRemediationStatus *prometheus.CounterVec
 	metricComplianceStateGauge        *prometheus.GaugeVec
+	metricComplianceScanCheckResults  *prometheus.GaugeVec
+	metricComplianceScanFailingCheck  *prometheus.GaugeVec
+}
+
+// FailingCheck describes a failing ComplianceCheckResult exposed with a
+// series per control its rule maps to
+type FailingCheck struct {
+	Name     string
+	Severity v1alpha1.ComplianceCheckResultSeverity
+	Controls []utils.RuleControl
 }
 
 func DefaultControllerMetrics() *ControllerMetrics {
@@ -102,6 +121,32 @@ func DefaultControllerMetrics() *ControllerMetrics {
 				metricLabelSuiteName,
 			},
 		),
+		metricComplianceScanCheckResults: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name:      metricNameComplianceScanCheckResults,
+				Namespace: metricNamespace,
+				Help:      "A gauge for the number of ComplianceCheckResults of a ComplianceScan by status and severity",
+			},
+			[]string{
+				metricLabelScanName,
+				metricLabelCheckStatus,
+				metricLabelCheckSeverity,
+			},
+		),
+		metricComplianceScanFailingCheck: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name:      metricNameComplianceScanFailingCheck,
+				Namespace: metricNamespace,
+				Help:      "A gauge set to 1 for every control of every failing ComplianceCheckResult of a ComplianceScan, up to the limit of checks configured in the ScanSetting",
+			},
+			[]string{
+				metricLabelScanName,
+				metricLabelCheckName,
+				metricLabelCheckSeverity,
+				metricLabelControlStandard,
+				metricLabelControlID,
+			},
+		),
 	}
 }
 
@@ -125,6 +170,8 @@ func (m *Metrics) Register() error {
 		metricNameComplianceScanStatus:        m.metrics.metricComplianceScanStatus,
 		metricNameComplianceRemediationStatus: m.metrics.metricComplianceRemediationStatus,
 		metricNameComplianceStateGauge:        m.metrics.metricComplianceStateGauge,
+		metricNameComplianceScanCheckResults:  m.metrics.metricComplianceScanCheckResults,
+		metricNameComplianceScanFailingCheck:  m.metrics.metricComplianceScanFailingCheck,
 	} {
 		m.log.Info(fmt.Sprintf("Registering metric: %s", name))
 		if err := m.impl.Register(collector); err != nil {
@@ -197,3 +244,96 @@ func (m *Metrics) SetComplianceStateOutOfCompliance(name string) {
 func (m *Metrics) SetComplianceStateInCompliance(name string) {
 	m.metrics.metricComplianceStateGauge.WithLabelValues(name).Set(METRIC_STATE_COMPLIANT)
 }
+
+// SetComplianceScanCheckResults replaces the counts of the check results of
+// a scan by status and severity.
+func (m *Metrics) SetComplianceScanCheckResults(scanName string, checks []v1alpha1.ComplianceCheckResult) {
+	type key struct {
+		status   v1alpha1.ComplianceCheckStatus
+		severity v1alpha1.ComplianceCheckResultSeverity
+	}
+	counts := map[key]int{}
+	for i := range checks {
+		counts[key{checks[i].Status, checks[i].Severity}]++
+	}
+
+	m.metrics.metricComplianceScanCheckResults.DeletePartialMatch(prometheus.Labels{
+		metricLabelScanName: scanName,
+	})
+	for k, count := range counts {
+		m.metrics.metricComplianceScanCheckResults.With(prometheus.Labels{
+			metricLabelScanName:      scanName,
+			metricLabelCheckStatus:   string(k.status),
+			metricLabelCheckSeverity: string(k.severity),
+		}).Set(float64(count))
+	}
+}
+
+// SetComplianceScanFailingChecks replaces the series of the failing checks
+// of a scan, one per control of the check or a single one without a control
+// if its rule maps to none. To bound the cardinality, at most limit checks
+// are exposed, most severe first. Returns how many checks were left out.
+func (m *Metrics) SetComplianceScanFailingChecks(scanName string, checks []FailingCheck, limit int) int {
+	m.metrics.metricComplianceScanFailingCheck.DeletePartialMatch(prometheus.Labels{
+		metricLabelScanName: scanName,
+	})
+	if limit <= 0 {
+		return 0
+	}
+
+	sorted := make([]FailingCheck, len(checks))
+	copy(sorted, checks)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		si, sj := severityRank(sorted[i].Severity), severityRank(sorted[j].Severity)
+		if si != sj {
+			return si < sj
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	omitted := 0
+	if len(sorted) > limit {
+		omitted = len(sorted) - limit
+		sorted = sorted[:limit]
+	}
+	for _, check := range sorted {
+		controls := check.Controls
+		if len(controls) == 0 {
+			controls = []utils.RuleControl{{}}
+		}
+		for _, control := range controls {
+			m.metrics.metricComplianceScanFailingCheck.With(prometheus.Labels{
+				metricLabelScanName:        scanName,
+				metricLabelCheckName:       check.Name,
+				metricLabelCheckSeverity:   string(check.Severity),
+				metricLabelControlStandard: control.Standard,
+				metricLabelControlID:       control.ID,
+			}).Set(1)
+		}
+	}
+	return omitted
+}
+
+// DeleteComplianceScanCheckMetrics removes the check result metrics of a
+// deleted scan.
+func (m *Metrics) DeleteComplianceScanCheckMetrics(scanName string) {
+	m.metrics.metricComplianceScanCheckResults.DeletePartialMatch(prometheus.Labels{
+		metricLabelScanName: scanName,
+	})
+	m.metrics.metricComplianceScanFailingCheck.DeletePartialMatch(prometheus.Labels{
+		metricLabelScanName: scanName,
+	})
+}
+
+func severityRank(severity v1alpha1.ComplianceCheckResultSeverity) int {
+	switch severity {
+	case v1alpha1.CheckResultSeverityHigh:
+		return 0
+	case v1alpha1.CheckResultSeverityMedium:
+		return 1
+	case v1alpha1.CheckResultSeverityLow:
+		return 2
+	case v1alpha1.CheckResultSeverityInfo:
+		return 3
+	}
+	return 4
+}
@@ -26,6 +26,7 @@ import (
 
 	"github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
 	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics/metricsfakes"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
 )
 
 var errTest = errors.New("")
@@ -77,6 +78,17 @@ func TestComplianceOperatorMetrics(t *testing.T) {
 		return int(*m.Counter.Value)
 	}
 
+	countSeries := func(col prometheus.Collector) int {
+		c := make(chan prometheus.Metric, 100)
+		col.Collect(c)
+		close(c)
+		return len(c)
+	}
+
+	checkResult := func(status v1alpha1.ComplianceCheckStatus, severity v1alpha1.ComplianceCheckResultSeverity) v1alpha1.ComplianceCheckResult {
+		return v1alpha1.ComplianceCheckResult{Status: status, Severity: severity}
+	}
+
 	for _, tc := range []struct {
 		when func(m *Metrics)
 		then func(m *Metrics)
@@ -117,6 +129,105 @@ func TestComplianceOperatorMetrics(t *testing.T) {
 				require.Equal(t, 1, getMetricValue(ctr))
 			},
 		},
+		{ // check results by status and severity
+			when: func(m *Metrics) {
+				m.SetComplianceScanCheckResults("scan", []v1alpha1.ComplianceCheckResult{
+					checkResult(v1alpha1.CheckResultFail, v1alpha1.CheckResultSeverityHigh),
+					checkResult(v1alpha1.CheckResultFail, v1alpha1.CheckResultSeverityHigh),
+					checkResult(v1alpha1.CheckResultPass, v1alpha1.CheckResultSeverityHigh),
+				})
+			},
+			then: func(m *Metrics) {
+				require.Equal(t, 2, countSeries(m.metrics.metricComplianceScanCheckResults))
+				ctr, err := m.metrics.metricComplianceScanCheckResults.GetMetricWith(prometheus.Labels{
+					metricLabelScanName:      "scan",
+					metricLabelCheckStatus:   "FAIL",
+					metricLabelCheckSeverity: "high",
+				})
+				require.Nil(t, err)
+				require.Equal(t, 2, getMetricValue(ctr))
+			},
+		},
+		{ // check results replace the previous ones of the scan only
+			when: func(m *Metrics) {
+				m.SetComplianceScanCheckResults("scan", []v1alpha1.ComplianceCheckResult{
+					checkResult(v1alpha1.CheckResultFail, v1alpha1.CheckResultSeverityHigh),
+				})
+				m.SetComplianceScanCheckResults("other", []v1alpha1.ComplianceCheckResult{
+					checkResult(v1alpha1.CheckResultFail, v1alpha1.CheckResultSeverityHigh),
+				})
+				m.SetComplianceScanCheckResults("scan", []v1alpha1.ComplianceCheckResult{
+					checkResult(v1alpha1.CheckResultPass, v1alpha1.CheckResultSeverityLow),
+				})
+			},
+			then: func(m *Metrics) {
+				require.Equal(t, 2, countSeries(m.metrics.metricComplianceScanCheckResults))
+				ctr, err := m.metrics.metricComplianceScanCheckResults.GetMetricWith(prometheus.Labels{
+					metricLabelScanName:      "scan",
+					metricLabelCheckStatus:   "PASS",
+					metricLabelCheckSeverity: "low",
+				})
+				require.Nil(t, err)
+				require.Equal(t, 1, getMetricValue(ctr))
+			},
+		},
+		{ // failing checks are disabled by default
+			when: func(m *Metrics) {
+				omitted := m.SetComplianceScanFailingChecks("scan", []FailingCheck{
+					{Name: "check", Severity: v1alpha1.CheckResultSeverityHigh},
+				}, 0)
+				require.Equal(t, 0, omitted)
+			},
+			then: func(m *Metrics) {
+				require.Equal(t, 0, countSeries(m.metrics.metricComplianceScanFailingCheck))
+			},
+		},
+		{ // failing checks over the limit leave out the least severe
+			when: func(m *Metrics) {
+				omitted := m.SetComplianceScanFailingChecks("scan", []FailingCheck{
+					{Name: "low", Severity: v1alpha1.CheckResultSeverityLow},
+					{Name: "high-b", Severity: v1alpha1.CheckResultSeverityHigh},
+					{Name: "medium", Severity: v1alpha1.CheckResultSeverityMedium},
+					{Name: "high-a", Severity: v1alpha1.CheckResultSeverityHigh, Controls: []utils.RuleControl{
+						{Standard: "NIST-800-53", ID: "AC-2"},
+						{Standard: "NIST-800-53", ID: "AC-6"},
+					}},
+				}, 2)
+				require.Equal(t, 2, omitted)
+			},
+			then: func(m *Metrics) {
+				// A series per control, a single one without a control for
+				// checks whose rule maps to none
+				require.Equal(t, 3, countSeries(m.metrics.metricComplianceScanFailingCheck))
+				for _, labels := range []prometheus.Labels{
+					{metricLabelCheckName: "high-a", metricLabelControlStandard: "NIST-800-53", metricLabelControlID: "AC-2"},
+					{metricLabelCheckName: "high-a", metricLabelControlStandard: "NIST-800-53", metricLabelControlID: "AC-6"},
+					{metricLabelCheckName: "high-b", metricLabelControlStandard: "", metricLabelControlID: ""},
+				} {
+					labels[metricLabelScanName] = "scan"
+					labels[metricLabelCheckSeverity] = "high"
+					ctr, err := m.metrics.metricComplianceScanFailingCheck.GetMetricWith(labels)
+					require.Nil(t, err)
+					require.Equal(t, 1, getMetricValue(ctr))
+				}
+				require.Equal(t, 3, countSeries(m.metrics.metricComplianceScanFailingCheck))
+			},
+		},
+		{ // deleting a scan removes its check metrics
+			when: func(m *Metrics) {
+				m.SetComplianceScanCheckResults("scan", []v1alpha1.ComplianceCheckResult{
+					checkResult(v1alpha1.CheckResultFail, v1alpha1.CheckResultSeverityHigh),
+				})
+				m.SetComplianceScanFailingChecks("scan", []FailingCheck{
+					{Name: "check", Severity: v1alpha1.CheckResultSeverityHigh},
+				}, 10)
+				m.DeleteComplianceScanCheckMetrics("scan")
+			},
+			then: func(m *Metrics) {
+				require.Equal(t, 0, countSeries(m.metrics.metricComplianceScanCheckResults))
+				require.Equal(t, 0, countSeries(m.metrics.metricComplianceScanFailingCheck))
+			},
+		},
 	} {
 		mock := &metricsfakes.FakeImpl{}
 		sut := New()
@@ -12,6 +12,8 @@ import (
 
 	"fmt"
 	"path"
+	"reflect"
+	"strings"
 
 	compliancev1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
 	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
@@ -39,6 +41,8 @@ var log = logf.Log.WithName("profilebundlectrl")
 
 var oneReplica int32 = 1
 
+const contentVerificationKeyDir = "/content-verification-key"
+
 func (r *ReconcileProfileBundle) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&compliancev1alpha1.ProfileBundle{}).
@@ -192,7 +196,7 @@ func (r *ReconcileProfileBundle) Reconcile(ctx context.Context, request reconcil
 		return reconcile.Result{}, err
 	}
 
-	if workloadNeedsUpdate(effectiveImage, found) {
+	if workloadNeedsUpdate(effectiveImage, depl, found) {
 		pbCopy := instance.DeepCopy()
 		pbCopy.Status.DataStreamStatus = compliancev1alpha1.DataStreamPending
 		pbCopy.Status.ErrorMessage = ""
@@ -402,7 +406,7 @@ func (r *ReconcileProfileBundle) newWorkloadForBundle(pb *compliancev1alpha1.Pro
 	falseP := false
 	trueP := true
 	labels := getWorkloadLabels(pb)
-	return &appsv1.Deployment{
+	depl := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      pb.Name + "-" + pb.Namespace + "-pp",
 			Namespace: common.GetComplianceOperatorNamespace(),
@@ -433,7 +437,7 @@ func (r *ReconcileProfileBundle) newWorkloadForBundle(pb *compliancev1alpha1.Pro
 							Command: []string{
 								"sh",
 								"-c",
-								fmt.Sprintf("cp %s /content | /bin/true", path.Join("/", pb.Spec.ContentFile)),
+								fmt.Sprintf("cp %s /content | /bin/true", strings.Join(getContentFiles(pb), " ")),
 							},
 							ImagePullPolicy: corev1.PullAlways,
 							SecurityContext: &corev1.SecurityContext{
@@ -549,6 +553,53 @@ func (r *ReconcileProfileBundle) newWorkloadForBundle(pb *compliancev1alpha1.Pro
 			},
 		},
 	}
+	addContentVerification(pb, depl)
+	return depl
+}
+
+// getContentFiles returns the files to copy from the content image, the
+// detached signature of the content being copied along with it if needed
+func getContentFiles(pb *compliancev1alpha1.ProfileBundle) []string {
+	files := []string{path.Join("/", pb.Spec.ContentFile)}
+	verification := pb.Spec.ContentVerification
+	if verification != nil && verification.PublicKeySecret != "" {
+		files = append(files, path.Join("/", verification.GetSignatureFile(pb.Spec.ContentFile)))
+	}
+	return files
+}
+
+// addContentVerification makes the profileparser verify the content before
+// parsing it
+func addContentVerification(pb *compliancev1alpha1.ProfileBundle, depl *appsv1.Deployment) {
+	verification := pb.Spec.ContentVerification
+	if verification == nil {
+		return
+	}
+	podSpec := &depl.Spec.Template.Spec
+	parser := &podSpec.InitContainers[1]
+
+	if verification.SHA256 != "" {
+		parser.Command = append(parser.Command, "--content-sha256", verification.SHA256)
+	}
+	if verification.PublicKeySecret != "" {
+		parser.Command = append(parser.Command,
+			"--signature-path", path.Join("/content", verification.GetSignatureFile(pb.Spec.ContentFile)),
+			"--public-key-path", path.Join(contentVerificationKeyDir, compliancev1alpha1.ContentPublicKeySecretKey),
+		)
+		parser.VolumeMounts = append(parser.VolumeMounts, corev1.VolumeMount{
+			Name:      "content-verification-key",
+			MountPath: contentVerificationKeyDir,
+			ReadOnly:  true,
+		})
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: "content-verification-key",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: verification.PublicKeySecret,
+				},
+			},
+		})
+	}
 }
 
 // podStartupError returns false if for some reason the pod couldn't even
@@ -577,7 +628,7 @@ func podStartupError(pod *corev1.Pod) bool {
 	return false
 }
 
-func workloadNeedsUpdate(image string, depl *appsv1.Deployment) bool {
+func workloadNeedsUpdate(image string, expected, depl *appsv1.Deployment) bool {
 	initContainers := depl.Spec.Template.Spec.InitContainers
 	if len(initContainers) != 2 {
 		// For some weird reason we don't have the amount of init containers we expect.
@@ -587,7 +638,16 @@ func workloadNeedsUpdate(image string, depl *appsv1.Deployment) bool {
 	isSameContentImage := false
 	isSaneProfileparserImage := false
 
+	// The content verification settings are passed in the commands
+	expectedCommands := map[string][]string{}
+	for _, container := range expected.Spec.Template.Spec.InitContainers {
+		expectedCommands[container.Name] = container.Command
+	}
+
 	for _, container := range initContainers {
+		if !reflect.DeepEqual(container.Command, expectedCommands[container.Name]) {
+			return true
+		}
 		if container.Name == "content-container" {
 			// we need an update if the image reference doesn't match.
 			isSameContentImage = container.Image == image
@@ -597,5 +657,23 @@ func workloadNeedsUpdate(image string, depl *appsv1.Deployment) bool {
 		}
 	}
 
+	// The public key of the content verification is mounted from a Secret
+	if !reflect.DeepEqual(workloadSecretVolumes(expected), workloadSecretVolumes(depl)) {
+		return true
+	}
+
 	return !(isSameContentImage && isSaneProfileparserImage)
 }
+
+// workloadSecretVolumes maps the volumes of the workload to the Secrets they
+// mount. Only the names are compared as the API server defaults the rest.
+func workloadSecretVolumes(depl *appsv1.Deployment) map[string]string {
+	volumes := map[string]string{}
+	for _, volume := range depl.Spec.Template.Spec.Volumes {
+		volumes[volume.Name] = ""
+		if volume.Secret != nil {
+			volumes[volume.Name] = volume.Secret.SecretName
+		}
+	}
+	return volumes
+}
@@ -0,0 +1,53 @@
+package profilebundle
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+var _ = Describe("Testing the profileparser workload", func() {
+	const image = "quay.io/complianceascode/ocp4:latest"
+	var (
+		r  *ReconcileProfileBundle
+		pb *compv1alpha1.ProfileBundle
+	)
+
+	BeforeEach(func() {
+		r = &ReconcileProfileBundle{}
+		pb = &compv1alpha1.ProfileBundle{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ocp4",
+				Namespace: "openshift-compliance",
+			},
+			Spec: compv1alpha1.ProfileBundleSpec{
+				ContentImage: image,
+				ContentFile:  "ssg-ocp4-ds.xml",
+				ContentVerification: &compv1alpha1.ContentVerification{
+					PublicKeySecret: "content-key",
+				},
+			},
+		}
+	})
+
+	It("doesn't update an up to date workload", func() {
+		depl := r.newWorkloadForBundle(pb, image)
+		Expect(workloadNeedsUpdate(image, depl, depl.DeepCopy())).To(BeFalse())
+	})
+
+	It("updates the workload when the public key Secret changes", func() {
+		depl := r.newWorkloadForBundle(pb, image)
+		pb.Spec.ContentVerification.PublicKeySecret = "rotated-content-key"
+		expected := r.newWorkloadForBundle(pb, image)
+		Expect(workloadNeedsUpdate(image, expected, depl)).To(BeTrue())
+	})
+
+	It("updates the workload when the content verification is dropped", func() {
+		depl := r.newWorkloadForBundle(pb, image)
+		pb.Spec.ContentVerification = nil
+		expected := r.newWorkloadForBundle(pb, image)
+		Expect(workloadNeedsUpdate(image, expected, depl)).To(BeTrue())
+	})
+})
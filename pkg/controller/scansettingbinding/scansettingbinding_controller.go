@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
 	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
 	"github.com/go-logr/logr"
+	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -356,8 +358,13 @@ func (r *ReconcileScanSettingBinding) applyConstraint(
 			}, "error validating ScanSetting '%s' roles: %w", v1setting.GetName(), valErr)
 	}
 
-	// create per-role scans
-	suite.Spec.Scans = r.createScansWithSelector(suite, &v1setting, logger)
+	if valErr := r.validateNodeSelectors(&v1setting); valErr != nil {
+		return common.NewRetriableCtrlErrorWithCustomHandler(
+			func() (reconcile.Result, error) {
+				return reconcile.Result{}, nil
+			}, "error validating ScanSetting '%s' node selectors: %w", v1setting.GetName(), valErr)
+	}
+
 	// apply settings for suite - deep copy to future proof in case there are any slices or so later
 	suite.Spec.ComplianceSuiteSettings = *v1setting.ComplianceSuiteSettings.DeepCopy()
 	// apply settings for scans, need to DeepCopy as ScanSetting contains a slice
@@ -365,19 +372,23 @@ func (r *ReconcileScanSettingBinding) applyConstraint(
 		scan := &suite.Spec.Scans[i]
 		scan.ComplianceScanSettings = *v1setting.ComplianceScanSettings.DeepCopy()
 	}
+	// create per-role scans, with the settings overridden for their role,
+	// and per-selector scans
+	suite.Spec.Scans = r.createScansWithSelector(suite, &v1setting, logger)
+	r.warnOnScansWithoutMcfgPool(instance, suite.Spec.Scans, logger)
 
 	return nil
 }
 
 func (r *ReconcileScanSettingBinding) validateRoles(setting *compliancev1alpha1.ScanSetting) error {
-	if len(setting.Roles) == 0 {
+	if len(setting.Roles) == 0 && len(setting.NodeSelectors) == 0 {
 		r.Eventf(setting, corev1.EventTypeWarning, "EmptyRoles",
 			"The ScanSetting's roles are empty. Node scans won't be scheduled.")
-		return nil
+		return validateRoleOverrides(setting)
 	}
 	// This is fine and expected
 	if len(setting.Roles) == 1 && setting.Roles[0] == compliancev1alpha1.AllRoles {
-		return nil
+		return validateRoleOverrides(setting)
 	}
 	for _, role := range setting.Roles {
 		if role == compliancev1alpha1.AllRoles {
@@ -387,6 +398,41 @@ func (r *ReconcileScanSettingBinding) validateRoles(setting *compliancev1alpha1.
 			return fmt.Errorf("role %s is invalid", role)
 		}
 	}
+	return validateRoleOverrides(setting)
+}
+
+// validateRoleOverrides makes sure that settings are only overridden for the
+// roles that are scanned
+func validateRoleOverrides(setting *compliancev1alpha1.ScanSetting) error {
+	for role := range setting.RoleOverrides {
+		if !slices.Contains(setting.Roles, role) {
+			return fmt.Errorf("settings are overridden for role %s, which is not in the roles", role)
+		}
+	}
+	return nil
+}
+
+// validateNodeSelectors makes sure that the node selectors are valid and
+// that every scan created for them gets a name of its own
+func (r *ReconcileScanSettingBinding) validateNodeSelectors(setting *compliancev1alpha1.ScanSetting) error {
+	names := map[string]bool{}
+	for _, role := range setting.Roles {
+		names[r.sanitizeRoleForName(role)] = true
+	}
+	for i := range setting.NodeSelectors {
+		selector := &setting.NodeSelectors[i]
+		if len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0 {
+			return fmt.Errorf("node selector %d is empty, use the %s role to scan all nodes", i, compliancev1alpha1.AllRoles)
+		}
+		if _, err := metav1.LabelSelectorAsSelector(selector); err != nil {
+			return fmt.Errorf("node selector %d is invalid: %w", i, err)
+		}
+		name := strings.TrimPrefix(utils.GetScanNameFromNodeLabelSelector("", selector), "-")
+		if names[name] {
+			return fmt.Errorf("node selector %d would create scans named like the ones of another node selector or role", i)
+		}
+		names[name] = true
+	}
 	return nil
 }
 
@@ -403,9 +449,20 @@ func (r *ReconcileScanSettingBinding) createScansWithSelector(
 				scanCopy := scan.DeepCopy()
 				scanCopy.Name = scan.Name + "-" + r.sanitizeRoleForName(role)
 				scanCopy.NodeSelector = utils.GetNodeRoleSelector(role)
+				if override, ok := v1setting.RoleOverrides[role]; ok {
+					logger.Info("Overriding the settings of the per-role scan", "role", role)
+					override.ApplyTo(&scanCopy.ComplianceScanSettings)
+				}
 				logger.Info("Adding per-role scan", "scanCopy.Name", scanCopy.Name)
 				scansWithSelector = append(scansWithSelector, *scanCopy)
 			}
+			for i := range v1setting.NodeSelectors {
+				scanCopy := scan.DeepCopy()
+				scanCopy.Name = utils.GetScanNameFromNodeLabelSelector(scan.Name, &v1setting.NodeSelectors[i])
+				scanCopy.NodeLabelSelector = v1setting.NodeSelectors[i].DeepCopy()
+				logger.Info("Adding per-selector scan", "scanCopy.Name", scanCopy.Name)
+				scansWithSelector = append(scansWithSelector, *scanCopy)
+			}
 		} else {
 			scanCopy := scan.DeepCopy()
 			logger.Info("Adding platform scan", "scanCopy.Name", scanCopy.Name)
@@ -417,6 +474,47 @@ func (r *ReconcileScanSettingBinding) createScansWithSelector(
 	return scansWithSelector
 }
 
+// warnOnScansWithoutMcfgPool warns about the node scans selecting nodes by a
+// label selector that doesn't map to any MachineConfigPool, as their
+// remediations can't be applied
+func (r *ReconcileScanSettingBinding) warnOnScansWithoutMcfgPool(
+	instance *compliancev1alpha1.ScanSettingBinding,
+	scans []compliancev1alpha1.ComplianceScanSpecWrapper,
+	logger logr.Logger,
+) {
+	unmatched, err := r.getScansWithoutMcfgPool(scans)
+	if err != nil {
+		// Not every cluster has MachineConfigPools
+		logger.Info("Couldn't list the MachineConfigPools, not verifying the node selectors", "error", err.Error())
+		return
+	}
+	for _, scanName := range unmatched {
+		r.Eventf(instance, corev1.EventTypeWarning, "NoMatchingMachineConfigPool",
+			"The node selector of scan %s doesn't match any MachineConfigPool. Its remediations can't be applied.", scanName)
+	}
+}
+
+func (r *ReconcileScanSettingBinding) getScansWithoutMcfgPool(scans []compliancev1alpha1.ComplianceScanSpecWrapper) ([]string, error) {
+	var pools *mcfgv1.MachineConfigPoolList
+	unmatched := []string{}
+	for i := range scans {
+		if scans[i].ScanType != compliancev1alpha1.ScanTypeNode || scans[i].NodeLabelSelector == nil {
+			continue
+		}
+		if pools == nil {
+			pools = &mcfgv1.MachineConfigPoolList{}
+			if err := r.Client.List(context.TODO(), pools); err != nil {
+				return nil, err
+			}
+		}
+		scan := &compliancev1alpha1.ComplianceScan{Spec: scans[i].ComplianceScanSpec}
+		if ok, _ := utils.AnyMcfgPoolLabelMatches(scan.GetNodeSelectorLabels(), pools); !ok {
+			unmatched = append(unmatched, scans[i].Name)
+		}
+	}
+	return unmatched, nil
+}
+
 // returns a sanitized role name that can be used
 // for a name. Note that it is also assumed that validation
 // has already taken place.
@@ -11,7 +11,11 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
+	mcfgapi "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io"
+	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
 	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -187,6 +191,7 @@ var _ = Describe("Testing scansettingbinding controller", func() {
 
 		scheme := scheme.Scheme
 		scheme.AddKnownTypes(compv1alpha1.SchemeGroupVersion, objs...)
+		Expect(mcfgapi.Install(scheme)).To(Succeed())
 
 		statusObjs := []runtimeclient.Object{}
 		statusObjs = append(statusObjs, ssb, scratchTP)
@@ -367,6 +372,210 @@ var _ = Describe("Testing scansettingbinding controller", func() {
 		})
 	})
 
+	Context("Creates a suite with per-role setting overrides", func() {
+		JustBeforeEach(func() {
+			strict := false
+			setting.RoleOverrides = map[string]compv1alpha1.ScanSettingRoleOverride{
+				"master": {
+					StrictNodeScan: &strict,
+					Timeout:        "1h",
+					ScanLimits: map[corev1.ResourceName]resource.Quantity{
+						corev1.ResourceMemory: resource.MustParse("1Gi"),
+					},
+				},
+			}
+			setting.ScanLimits = map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("100m"),
+				corev1.ResourceMemory: resource.MustParse("500Mi"),
+			}
+			err := reconciler.Client.Update(context.TODO(), setting)
+			Expect(err).To(BeNil())
+
+			bindingTypeMeta := v1.TypeMeta{}
+			bindingTypeMeta.SetGroupVersionKind(compv1alpha1.SchemeGroupVersion.WithKind("ScanSettingBinding"))
+			ssb = &compv1alpha1.ScanSettingBinding{
+				TypeMeta: bindingTypeMeta,
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "overridden-compliance-requirements",
+					Namespace: common.GetComplianceOperatorNamespace(),
+				},
+				Profiles: []compv1alpha1.NamedObjectReference{
+					{
+						Name:     profRhcosE8.Name,
+						Kind:     profRhcosE8.Kind,
+						APIGroup: profRhcosE8.APIVersion,
+					},
+				},
+				SettingsRef: &compv1alpha1.NamedObjectReference{
+					Name:     setting.Name,
+					Kind:     setting.Kind,
+					APIGroup: setting.APIVersion,
+				},
+			}
+
+			ssb.Status.SetConditionPending()
+
+			err = reconciler.Client.Create(context.TODO(), ssb)
+			Expect(err).To(BeNil())
+		})
+
+		It("Should only override the settings of the scans of the role", func() {
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: ssb.Namespace,
+					Name:      ssb.Name,
+				},
+			})
+			Expect(err).To(BeNil())
+
+			err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: ssb.Name, Namespace: ssb.Namespace}, suite)
+			Expect(err).To(BeNil())
+			Expect(suite.Spec.Scans).To(HaveLen(2))
+
+			for _, scan := range suite.Spec.Scans {
+				Expect(scan.Debug).To(BeTrue())
+				switch scan.Name {
+				case profRhcosE8.Name + "-master":
+					Expect(scan.Timeout).To(Equal("1h"))
+					Expect(scan.StrictNodeScan).ToNot(BeNil())
+					Expect(*scan.StrictNodeScan).To(BeFalse())
+					Expect(scan.ScanLimits).To(HaveLen(2))
+					Expect(scan.ScanLimits[corev1.ResourceCPU]).To(Equal(resource.MustParse("100m")))
+					Expect(scan.ScanLimits[corev1.ResourceMemory]).To(Equal(resource.MustParse("1Gi")))
+				case profRhcosE8.Name + "-worker":
+					Expect(scan.Timeout).To(BeEmpty())
+					Expect(scan.StrictNodeScan).To(BeNil())
+					Expect(scan.ScanLimits[corev1.ResourceMemory]).To(Equal(resource.MustParse("500Mi")))
+				default:
+					Fail("unexpected scan " + scan.Name)
+				}
+			}
+		})
+	})
+
+	Context("Creates a suite with node selectors", func() {
+		var gpuSelector, zoneSelector v1.LabelSelector
+
+		JustBeforeEach(func() {
+			gpuSelector = v1.LabelSelector{
+				MatchLabels: map[string]string{"hardware": "gpu"},
+			}
+			zoneSelector = v1.LabelSelector{
+				MatchLabels: map[string]string{"node-role.kubernetes.io/worker": ""},
+				MatchExpressions: []v1.LabelSelectorRequirement{
+					{
+						Key:      "zone",
+						Operator: v1.LabelSelectorOpNotIn,
+						Values:   []string{"zone-a"},
+					},
+				},
+			}
+			setting.Roles = []string{"master"}
+			setting.NodeSelectors = []v1.LabelSelector{gpuSelector, zoneSelector}
+			err := reconciler.Client.Update(context.TODO(), setting)
+			Expect(err).To(BeNil())
+
+			bindingTypeMeta := v1.TypeMeta{}
+			bindingTypeMeta.SetGroupVersionKind(compv1alpha1.SchemeGroupVersion.WithKind("ScanSettingBinding"))
+			ssb = &compv1alpha1.ScanSettingBinding{
+				TypeMeta: bindingTypeMeta,
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "selected-compliance-requirements",
+					Namespace: common.GetComplianceOperatorNamespace(),
+				},
+				Profiles: []compv1alpha1.NamedObjectReference{
+					{
+						Name:     profRhcosE8.Name,
+						Kind:     profRhcosE8.Kind,
+						APIGroup: profRhcosE8.APIVersion,
+					},
+				},
+				SettingsRef: &compv1alpha1.NamedObjectReference{
+					Name:     setting.Name,
+					Kind:     setting.Kind,
+					APIGroup: setting.APIVersion,
+				},
+			}
+
+			ssb.Status.SetConditionPending()
+
+			err = reconciler.Client.Create(context.TODO(), ssb)
+			Expect(err).To(BeNil())
+		})
+
+		It("Should create a scan for every node selector", func() {
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: ssb.Namespace,
+					Name:      ssb.Name,
+				},
+			})
+			Expect(err).To(BeNil())
+
+			err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: ssb.Name, Namespace: ssb.Namespace}, suite)
+			Expect(err).To(BeNil())
+
+			scanNames := []string{}
+			for _, scan := range suite.Spec.Scans {
+				scanNames = append(scanNames, scan.Name)
+				switch scan.Name {
+				case profRhcosE8.Name + "-master":
+					Expect(scan.NodeSelector).To(Equal(masterSelector))
+					Expect(scan.NodeLabelSelector).To(BeNil())
+				case profRhcosE8.Name + "-hardware-gpu":
+					Expect(scan.NodeSelector).To(BeEmpty())
+					Expect(*scan.NodeLabelSelector).To(Equal(gpuSelector))
+				case profRhcosE8.Name + "-node-role-kubernetes-io-worker-zone-notin-zone-a":
+					Expect(scan.NodeSelector).To(BeEmpty())
+					Expect(*scan.NodeLabelSelector).To(Equal(zoneSelector))
+				}
+				Expect(scan.Debug).To(BeTrue())
+			}
+			Expect(scanNames).To(ConsistOf(
+				profRhcosE8.Name+"-master",
+				profRhcosE8.Name+"-hardware-gpu",
+				profRhcosE8.Name+"-node-role-kubernetes-io-worker-zone-notin-zone-a",
+			))
+		})
+
+		It("Should find the scans whose nodes don't map to a MachineConfigPool", func() {
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: ssb.Namespace,
+					Name:      ssb.Name,
+				},
+			})
+			Expect(err).To(BeNil())
+
+			err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: ssb.Name, Namespace: ssb.Namespace}, suite)
+			Expect(err).To(BeNil())
+
+			unmatched, err := reconciler.getScansWithoutMcfgPool(suite.Spec.Scans)
+			Expect(err).To(BeNil())
+			Expect(unmatched).To(ConsistOf(
+				profRhcosE8.Name+"-hardware-gpu",
+				profRhcosE8.Name+"-node-role-kubernetes-io-worker-zone-notin-zone-a",
+			))
+
+			gpuPool := &mcfgv1.MachineConfigPool{
+				ObjectMeta: v1.ObjectMeta{Name: "gpu"},
+				Spec: mcfgv1.MachineConfigPoolSpec{
+					NodeSelector: &v1.LabelSelector{
+						MatchLabels: map[string]string{"hardware": "gpu"},
+					},
+				},
+			}
+			err = reconciler.Client.Create(context.TODO(), gpuPool)
+			Expect(err).To(BeNil())
+
+			unmatched, err = reconciler.getScansWithoutMcfgPool(suite.Spec.Scans)
+			Expect(err).To(BeNil())
+			Expect(unmatched).To(ConsistOf(
+				profRhcosE8.Name + "-node-role-kubernetes-io-worker-zone-notin-zone-a",
+			))
+		})
+	})
+
 	Context("Creates a simple suite from a TailoredProfile", func() {
 		JustBeforeEach(func() {
 			bindingTypeMeta := v1.TypeMeta{}
@@ -835,6 +1044,46 @@ var _ = Describe("Testing scansettingbinding controller", func() {
 			Entry("empty string", []string{""}),
 			Entry("invalid character", []string{"l33t$"}),
 		)
+
+		DescribeTable("Should fail the validation of node selectors if they include ",
+			func(selectors []v1.LabelSelector) {
+				ss := &compv1alpha1.ScanSetting{
+					Roles:         []string{"master"},
+					NodeSelectors: selectors,
+				}
+				err := reconciler.validateNodeSelectors(ss)
+				Expect(err).ToNot(BeNil(), "validation should have returned an error")
+			},
+			Entry("an empty selector", []v1.LabelSelector{{}}),
+			Entry("an invalid operator", []v1.LabelSelector{{
+				MatchExpressions: []v1.LabelSelectorRequirement{{Key: "zone", Operator: "Near", Values: []string{"a"}}},
+			}}),
+			Entry("an invalid label", []v1.LabelSelector{{
+				MatchLabels: map[string]string{"zone": "l33t$"},
+			}}),
+			Entry("duplicates", []v1.LabelSelector{
+				{MatchLabels: map[string]string{"zone": "a"}},
+				{MatchLabels: map[string]string{"zone": "a"}},
+			}),
+			Entry("a selector named like a role", []v1.LabelSelector{{
+				MatchLabels: map[string]string{"master": ""},
+			}}),
+		)
+
+		It("fails if settings are overridden for a role that isn't scanned", func() {
+			ss := &compv1alpha1.ScanSetting{
+				Roles: []string{"master", "worker"},
+				RoleOverrides: map[string]compv1alpha1.ScanSettingRoleOverride{
+					"infra": {Timeout: "1h"},
+				},
+			}
+			err := reconciler.validateRoles(ss)
+			Expect(err).To(MatchError(ContainSubstring("role infra")))
+
+			ss.Roles = append(ss.Roles, "infra")
+			err = reconciler.validateRoles(ss)
+			Expect(err).To(BeNil())
+		})
 	})
 
 })
@@ -0,0 +1,183 @@
+package scansettingbinding
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	compliancev1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/compliancescan"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/compliancesuite"
+)
+
+// AddWebhooks registers the webhooks validating ScanSettings and
+// ScanSettingBindings, and the one defaulting ScanSettingBindings
+func AddWebhooks(mgr manager.Manager) error {
+	err := ctrl.NewWebhookManagedBy(mgr).
+		For(&compliancev1alpha1.ScanSetting{}).
+		WithValidator(newScanSettingValidator()).
+		Complete()
+	if err != nil {
+		return err
+	}
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&compliancev1alpha1.ScanSettingBinding{}).
+		WithValidator(&bindingValidator{}).
+		WithDefaulter(&bindingDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-compliance-openshift-io-v1alpha1-scansetting,mutating=false,failurePolicy=fail,sideEffects=None,groups=compliance.openshift.io,resources=scansettings,verbs=create;update,versions=v1alpha1,name=vscansetting.compliance.openshift.io,admissionReviewVersions=v1
+
+// scanSettingValidator rejects the ScanSettings that the bindings using
+// them would otherwise fail on
+type scanSettingValidator struct {
+	// Only used for its validations, it doesn't record events
+	r *ReconcileScanSettingBinding
+}
+
+var _ webhook.CustomValidator = &scanSettingValidator{}
+
+func newScanSettingValidator() *scanSettingValidator {
+	return &scanSettingValidator{
+		r: &ReconcileScanSettingBinding{
+			roleVal:     regexp.MustCompile(roleValRegexp),
+			invalidRole: regexp.MustCompile(invalidRoleRegexp),
+		},
+	}
+}
+
+func (v *scanSettingValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	setting, ok := obj.(*compliancev1alpha1.ScanSetting)
+	if !ok {
+		return nil, fmt.Errorf("expected a ScanSetting but got a %T", obj)
+	}
+	return v.validate(setting)
+}
+
+func (v *scanSettingValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	setting, ok := newObj.(*compliancev1alpha1.ScanSetting)
+	if !ok {
+		return nil, fmt.Errorf("expected a ScanSetting but got a %T", newObj)
+	}
+	return v.validate(setting)
+}
+
+func (v *scanSettingValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *scanSettingValidator) validate(setting *compliancev1alpha1.ScanSetting) (admission.Warnings, error) {
+	var warnings admission.Warnings
+	if len(setting.Roles) == 0 && len(setting.NodeSelectors) == 0 {
+		warnings = append(warnings, "The ScanSetting's roles are empty. Node scans won't be scheduled.")
+	}
+	if err := v.r.validateRoles(setting); err != nil {
+		return warnings, err
+	}
+	if err := v.r.validateNodeSelectors(setting); err != nil {
+		return warnings, err
+	}
+	if err := compliancesuite.ValidateSuiteSettings(&setting.ComplianceSuiteSettings); err != nil {
+		return warnings, err
+	}
+	if err := compliancescan.ValidateScanSettings(&setting.ComplianceScanSettings); err != nil {
+		return warnings, err
+	}
+	for role, override := range setting.RoleOverrides {
+		if override.Timeout == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(override.Timeout); err != nil {
+			return warnings, fmt.Errorf("cannot parse the timeout value %s of role %s: %w", override.Timeout, role, err)
+		}
+	}
+	return warnings, nil
+}
+
+// +kubebuilder:webhook:path=/validate-compliance-openshift-io-v1alpha1-scansettingbinding,mutating=false,failurePolicy=fail,sideEffects=None,groups=compliance.openshift.io,resources=scansettingbindings,verbs=create;update,versions=v1alpha1,name=vscansettingbinding.compliance.openshift.io,admissionReviewVersions=v1
+
+// bindingValidator makes sure that the bindings only reference the kinds of
+// objects the controller knows how to resolve
+type bindingValidator struct{}
+
+var _ webhook.CustomValidator = &bindingValidator{}
+
+func (v *bindingValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	binding, ok := obj.(*compliancev1alpha1.ScanSettingBinding)
+	if !ok {
+		return nil, fmt.Errorf("expected a ScanSettingBinding but got a %T", obj)
+	}
+	return nil, validateBinding(binding)
+}
+
+func (v *bindingValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	binding, ok := newObj.(*compliancev1alpha1.ScanSettingBinding)
+	if !ok {
+		return nil, fmt.Errorf("expected a ScanSettingBinding but got a %T", newObj)
+	}
+	return nil, validateBinding(binding)
+}
+
+func (v *bindingValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateBinding(binding *compliancev1alpha1.ScanSettingBinding) error {
+	for i := range binding.Profiles {
+		if err := validateReference(&binding.Profiles[i], "Profile", "TailoredProfile"); err != nil {
+			return fmt.Errorf("profile %d is invalid: %w", i, err)
+		}
+	}
+	if binding.SettingsRef != nil {
+		if err := validateReference(binding.SettingsRef, "ScanSetting"); err != nil {
+			return fmt.Errorf("settingsRef is invalid: %w", err)
+		}
+	}
+	return nil
+}
+
+func validateReference(ref *compliancev1alpha1.NamedObjectReference, kinds ...string) error {
+	if ref.Name == "" {
+		return fmt.Errorf("the name is missing")
+	}
+	if !slices.Contains(kinds, ref.Kind) {
+		return fmt.Errorf("kind %s is not one of %s", ref.Kind, strings.Join(kinds, ", "))
+	}
+	gv, err := schema.ParseGroupVersion(ref.APIGroup)
+	if err != nil || gv != compliancev1alpha1.SchemeGroupVersion {
+		return fmt.Errorf("apiGroup %s is not %s", ref.APIGroup, compliancev1alpha1.SchemeGroupVersion)
+	}
+	return nil
+}
+
+// +kubebuilder:webhook:path=/mutate-compliance-openshift-io-v1alpha1-scansettingbinding,mutating=true,failurePolicy=fail,sideEffects=None,groups=compliance.openshift.io,resources=scansettingbindings,verbs=create;update,versions=v1alpha1,name=mscansettingbinding.compliance.openshift.io,admissionReviewVersions=v1
+
+// bindingDefaulter fills in the API group of the profiles, which the
+// settingsRef gets from the CRD
+type bindingDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &bindingDefaulter{}
+
+func (d *bindingDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	binding, ok := obj.(*compliancev1alpha1.ScanSettingBinding)
+	if !ok {
+		return fmt.Errorf("expected a ScanSettingBinding but got a %T", obj)
+	}
+	for i := range binding.Profiles {
+		if binding.Profiles[i].APIGroup == "" {
+			binding.Profiles[i].APIGroup = compliancev1alpha1.SchemeGroupVersion.String()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package scansettingbinding
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+var _ = Describe("Testing the admission webhooks", func() {
+	When("validating ScanSettings", func() {
+		var (
+			validator *scanSettingValidator
+			setting   *compv1alpha1.ScanSetting
+		)
+
+		BeforeEach(func() {
+			validator = newScanSettingValidator()
+			setting = &compv1alpha1.ScanSetting{
+				ObjectMeta: v1.ObjectMeta{Name: "my-setting"},
+				Roles:      []string{"master", "worker"},
+			}
+			setting.Schedule = "0 1 * * *"
+			setting.Timeout = "30m"
+		})
+
+		It("accepts a valid ScanSetting", func() {
+			warnings, err := validator.ValidateCreate(context.TODO(), setting)
+			Expect(err).To(BeNil())
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("warns about ScanSettings without roles", func() {
+			setting.Roles = nil
+			warnings, err := validator.ValidateCreate(context.TODO(), setting)
+			Expect(err).To(BeNil())
+			Expect(warnings).To(ContainElement(ContainSubstring("roles are empty")))
+		})
+
+		It("rejects invalid roles", func() {
+			setting.Roles = []string{"worker", compv1alpha1.AllRoles}
+			_, err := validator.ValidateCreate(context.TODO(), setting)
+			Expect(err).To(MatchError(ContainSubstring("cannot be used alongside other roles")))
+
+			setting.Roles = []string{"not/a/role"}
+			_, err = validator.ValidateUpdate(context.TODO(), setting, setting)
+			Expect(err).To(MatchError(ContainSubstring("role not/a/role is invalid")))
+		})
+
+		It("rejects invalid schedules", func() {
+			setting.Schedule = "every night"
+			_, err := validator.ValidateCreate(context.TODO(), setting)
+			Expect(err).To(MatchError(ContainSubstring("schedule is wrongly formatted")))
+		})
+
+		It("rejects invalid timeouts", func() {
+			setting.Timeout = "half an hour"
+			_, err := validator.ValidateCreate(context.TODO(), setting)
+			Expect(err).To(MatchError(ContainSubstring("cannot parse timeout value")))
+
+			setting.Timeout = "30m"
+			setting.RoleOverrides = map[string]compv1alpha1.ScanSettingRoleOverride{
+				"master": {Timeout: "1 hour"},
+			}
+			_, err = validator.ValidateCreate(context.TODO(), setting)
+			Expect(err).To(MatchError(ContainSubstring("of role master")))
+		})
+	})
+
+	When("validating ScanSettingBindings", func() {
+		var (
+			validator *bindingValidator
+			binding   *compv1alpha1.ScanSettingBinding
+		)
+
+		BeforeEach(func() {
+			validator = &bindingValidator{}
+			binding = &compv1alpha1.ScanSettingBinding{
+				ObjectMeta: v1.ObjectMeta{Name: "my-binding"},
+				Profiles: []compv1alpha1.NamedObjectReference{
+					{Name: "ocp4-cis", Kind: "Profile", APIGroup: "compliance.openshift.io/v1alpha1"},
+					{Name: "my-tailoring", Kind: "TailoredProfile", APIGroup: "compliance.openshift.io/v1alpha1"},
+				},
+				SettingsRef: &compv1alpha1.NamedObjectReference{
+					Name: "default", Kind: "ScanSetting", APIGroup: "compliance.openshift.io/v1alpha1",
+				},
+			}
+		})
+
+		It("accepts a valid ScanSettingBinding", func() {
+			_, err := validator.ValidateCreate(context.TODO(), binding)
+			Expect(err).To(BeNil())
+		})
+
+		It("rejects references to unknown kinds", func() {
+			binding.Profiles[1].Kind = "ScanSetting"
+			_, err := validator.ValidateCreate(context.TODO(), binding)
+			Expect(err).To(MatchError(ContainSubstring("profile 1 is invalid: kind ScanSetting is not one of Profile, TailoredProfile")))
+
+			binding.Profiles[1].Kind = "TailoredProfile"
+			binding.SettingsRef.APIGroup = "compliance.openshift.io"
+			_, err = validator.ValidateCreate(context.TODO(), binding)
+			Expect(err).To(MatchError(ContainSubstring("settingsRef is invalid: apiGroup compliance.openshift.io is not")))
+		})
+
+		It("defaults the API group of the profiles", func() {
+			binding.Profiles[0].APIGroup = ""
+			err := (&bindingDefaulter{}).Default(context.TODO(), binding)
+			Expect(err).To(BeNil())
+			Expect(binding.Profiles[0].APIGroup).To(Equal("compliance.openshift.io/v1alpha1"))
+
+			_, err = validator.ValidateCreate(context.TODO(), binding)
+			Expect(err).To(BeNil())
+		})
+	})
+})
@@ -269,10 +269,28 @@ func (r *ReconcileTailoredProfile) Reconcile(ctx context.Context, request reconc
 		return reconcile.Result{}, varErr
 	}
 
+	var signingKey *xccdf.SigningKey
+	if instance.Spec.SigningKeySecret != "" {
+		var keyErr error
+		signingKey, keyErr = r.getSigningKey(instance)
+		if keyErr != nil && !common.IsRetriable(keyErr) {
+			// Surface the error.
+			suerr := r.handleTailoredProfileStatusError(instance, keyErr)
+			return reconcile.Result{}, suerr
+		} else if keyErr != nil {
+			return reconcile.Result{}, keyErr
+		}
+	}
+
 	// Get tailored profile config map
 	tpcm := newTailoredProfileCM(instance)
 
-	tpcm.Data[tailoringFile], err = xccdf.TailoredProfileToXML(instance, p, pb, rules, variables)
+	if signingKey != nil {
+		reqLogger.Info("Signing the tailoring", "Secret.Name", instance.Spec.SigningKeySecret)
+		tpcm.Data[tailoringFile], err = xccdf.SignedTailoredProfileToXML(instance, p, pb, rules, variables, signingKey)
+	} else {
+		tpcm.Data[tailoringFile], err = xccdf.TailoredProfileToXML(instance, p, pb, rules, variables)
+	}
 	if err != nil {
 		return reconcile.Result{}, err
 	}
@@ -558,6 +576,29 @@ func (r *ReconcileTailoredProfile) getVariablesFromSelections(tp *cmpv1alpha1.Ta
 	return variableList, nil
 }
 
+// getSigningKey gets the key to sign the tailoring with from the Secret
+// referenced by the TailoredProfile
+func (r *ReconcileTailoredProfile) getSigningKey(tp *cmpv1alpha1.TailoredProfile) (*xccdf.SigningKey, error) {
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Name: tp.Spec.SigningKeySecret, Namespace: tp.Namespace}
+	if err := r.Client.Get(context.TODO(), key, secret); kerrors.IsNotFound(err) {
+		return nil, common.NewNonRetriableCtrlError("signing key Secret %s not found", tp.Spec.SigningKeySecret)
+	} else if err != nil {
+		return nil, err
+	}
+
+	keyPEM, ok := secret.Data[corev1.TLSPrivateKeyKey]
+	if !ok {
+		return nil, common.NewNonRetriableCtrlError("signing key Secret %s has no %s key",
+			tp.Spec.SigningKeySecret, corev1.TLSPrivateKeyKey)
+	}
+	signingKey, err := xccdf.ParseSigningKey(keyPEM, secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return nil, common.NewNonRetriableCtrlError("invalid signing key in Secret %s: %s", tp.Spec.SigningKeySecret, err)
+	}
+	return signingKey, nil
+}
+
 func (r *ReconcileTailoredProfile) updateTailoredProfileStatusReady(tp *cmpv1alpha1.TailoredProfile, out metav1.Object) error {
 	// Never update the original (update the copy)
 	tpCopy := tp.DeepCopy()
@@ -2,7 +2,14 @@ package tailoredprofile
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"math/big"
+	"time"
 
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 
@@ -10,8 +17,10 @@ import (
 	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics/metricsfakes"
 
 	"github.com/ComplianceAsCode/compliance-operator/pkg/apis"
+	"github.com/beevik/etree"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	dsig "github.com/russellhaering/goxmldsig"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -1250,4 +1259,158 @@ var _ = Describe("TailoredprofileController", func() {
 			})
 		})
 	})
+
+	When("signing the tailoring", func() {
+		var (
+			tpName = "signed-tailoring"
+			key    *rsa.PrivateKey
+			cert   *x509.Certificate
+			tpKey  = types.NamespacedName{Name: tpName, Namespace: namespace}
+			tpReq  = reconcile.Request{NamespacedName: tpKey}
+		)
+
+		BeforeEach(func() {
+			var err error
+			key, err = rsa.GenerateKey(rand.Reader, 2048)
+			Expect(err).To(BeNil())
+			template := &x509.Certificate{
+				SerialNumber: big.NewInt(1),
+				Subject:      pkix.Name{CommonName: "tailoring-signer"},
+				NotBefore:    time.Now().Add(-time.Minute),
+				NotAfter:     time.Now().Add(time.Hour),
+			}
+			certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+			Expect(err).To(BeNil())
+			cert, err = x509.ParseCertificate(certDER)
+			Expect(err).To(BeNil())
+
+			tp := &compv1alpha1.TailoredProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      tpName,
+					Namespace: namespace,
+				},
+				Spec: compv1alpha1.TailoredProfileSpec{
+					Extends: profileName,
+					DisableRules: []compv1alpha1.RuleReferenceSpec{
+						{
+							Name:      "rule-2",
+							Rationale: "Why not",
+						},
+					},
+					SigningKeySecret: "tailoring-signing-key",
+				},
+			}
+			createErr := r.Client.Create(ctx, tp)
+			Expect(createErr).To(BeNil())
+		})
+
+		It("signs the tailoring with the key of the Secret", func() {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "tailoring-signing-key",
+					Namespace: namespace,
+				},
+				Type: corev1.SecretTypeTLS,
+				Data: map[string][]byte{
+					corev1.TLSPrivateKeyKey: pem.EncodeToMemory(&pem.Block{
+						Type:  "RSA PRIVATE KEY",
+						Bytes: x509.MarshalPKCS1PrivateKey(key),
+					}),
+					corev1.TLSCertKey: pem.EncodeToMemory(&pem.Block{
+						Type:  "CERTIFICATE",
+						Bytes: cert.Raw,
+					}),
+				},
+			}
+			Expect(r.Client.Create(ctx, secret)).To(Succeed())
+
+			By("Reconciling twice (setting ownership, then the output)")
+			_, err := r.Reconcile(context.TODO(), tpReq)
+			Expect(err).To(BeNil())
+			_, err = r.Reconcile(context.TODO(), tpReq)
+			Expect(err).To(BeNil())
+
+			tp := &compv1alpha1.TailoredProfile{}
+			Expect(r.Client.Get(ctx, tpKey, tp)).To(Succeed())
+			Expect(tp.Status.State).To(Equal(compv1alpha1.TailoredProfileStateReady))
+
+			cm := &corev1.ConfigMap{}
+			cmKey := types.NamespacedName{Name: tp.Status.OutputRef.Name, Namespace: tp.Status.OutputRef.Namespace}
+			Expect(r.Client.Get(ctx, cmKey, cm)).To(Succeed())
+
+			doc := etree.NewDocument()
+			Expect(doc.ReadFromString(cm.Data["tailoring.xml"])).To(Succeed())
+			validation := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{
+				Roots: []*x509.Certificate{cert},
+			})
+			_, err = validation.Validate(doc.Root())
+			Expect(err).To(BeNil())
+		})
+
+		It("reports an error if the Secret doesn't exist", func() {
+			_, err := r.Reconcile(context.TODO(), tpReq)
+			Expect(err).To(BeNil())
+			_, err = r.Reconcile(context.TODO(), tpReq)
+			Expect(err).To(BeNil())
+
+			tp := &compv1alpha1.TailoredProfile{}
+			Expect(r.Client.Get(ctx, tpKey, tp)).To(Succeed())
+			Expect(tp.Status.State).To(Equal(compv1alpha1.TailoredProfileStateError))
+			Expect(tp.Status.ErrorMessage).To(ContainSubstring("tailoring-signing-key"))
+		})
+	})
+
+	When("validating TailoredProfiles in the admission webhook", func() {
+		var validator *tailoredProfileValidator
+
+		newTP := func(extends string, rules ...string) *compv1alpha1.TailoredProfile {
+			tp := &compv1alpha1.TailoredProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "tailoring",
+					Namespace: namespace,
+				},
+				Spec: compv1alpha1.TailoredProfileSpec{
+					Extends: extends,
+				},
+			}
+			for _, rule := range rules {
+				tp.Spec.EnableRules = append(tp.Spec.EnableRules, compv1alpha1.RuleReferenceSpec{Name: rule, Rationale: "Why not"})
+			}
+			return tp
+		}
+
+		BeforeEach(func() {
+			validator = &tailoredProfileValidator{client: r.Client}
+		})
+
+		It("accepts TailoredProfiles referencing existing rules", func() {
+			_, err := validator.ValidateCreate(ctx, newTP(profileName, "rule-3"))
+			Expect(err).To(BeNil())
+		})
+
+		It("rejects TailoredProfiles referencing missing objects", func() {
+			_, err := validator.ValidateCreate(ctx, newTP(profileName, "rule-404"))
+			Expect(err).To(MatchError(ContainSubstring("rule-404")))
+
+			_, err = validator.ValidateCreate(ctx, newTP("no-such-profile", "rule-3"))
+			Expect(err).To(MatchError(ContainSubstring("no-such-profile")))
+		})
+
+		It("rejects TailoredProfiles mixing rule types", func() {
+			_, err := validator.ValidateCreate(ctx, newTP("", "rule-5", "rule-8"))
+			Expect(err).To(MatchError(ContainSubstring("didn't match expected type")))
+		})
+
+		It("only validates updates of the spec", func() {
+			tp := newTP(profileName, "rule-404")
+			updated := tp.DeepCopy()
+			updated.Labels = map[string]string{"updated": "true"}
+			_, err := validator.ValidateUpdate(ctx, tp, updated)
+			Expect(err).To(BeNil())
+
+			updated.Spec.Description = "changed"
+			_, err = validator.ValidateUpdate(ctx, tp, updated)
+			Expect(err).To(MatchError(ContainSubstring("rule-404")))
+		})
+	})
 })
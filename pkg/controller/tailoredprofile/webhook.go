@@ -0,0 +1,94 @@
+package tailoredprofile
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	cmpv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+// AddWebhooks registers the webhook validating TailoredProfiles
+func AddWebhooks(mgr manager.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&cmpv1alpha1.TailoredProfile{}).
+		WithValidator(&tailoredProfileValidator{client: mgr.GetClient()}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-compliance-openshift-io-v1alpha1-tailoredprofile,mutating=false,failurePolicy=fail,sideEffects=None,groups=compliance.openshift.io,resources=tailoredprofiles,verbs=create;update,versions=v1alpha1,name=vtailoredprofile.compliance.openshift.io,admissionReviewVersions=v1
+
+// tailoredProfileValidator rejects the TailoredProfiles that the controller
+// would put in the error state because of what they reference
+type tailoredProfileValidator struct {
+	client client.Client
+}
+
+var _ webhook.CustomValidator = &tailoredProfileValidator{}
+
+func (v *tailoredProfileValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	tp, ok := obj.(*cmpv1alpha1.TailoredProfile)
+	if !ok {
+		return nil, fmt.Errorf("expected a TailoredProfile but got a %T", obj)
+	}
+	return nil, v.validate(tp)
+}
+
+func (v *tailoredProfileValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldTp, ok := oldObj.(*cmpv1alpha1.TailoredProfile)
+	if !ok {
+		return nil, fmt.Errorf("expected a TailoredProfile but got a %T", oldObj)
+	}
+	tp, ok := newObj.(*cmpv1alpha1.TailoredProfile)
+	if !ok {
+		return nil, fmt.Errorf("expected a TailoredProfile but got a %T", newObj)
+	}
+	// The rules a TailoredProfile references may be gone after a content
+	// update, which mustn't prevent the controller from updating its
+	// metadata or pruning them
+	if tp.DeletionTimestamp != nil || reflect.DeepEqual(oldTp.Spec, tp.Spec) {
+		return nil, nil
+	}
+	return nil, v.validate(tp)
+}
+
+func (v *tailoredProfileValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate looks up the profile, rules and variables the TailoredProfile
+// references the same way the controller does
+func (v *tailoredProfileValidator) validate(tp *cmpv1alpha1.TailoredProfile) error {
+	r := &ReconcileTailoredProfile{Client: v.client}
+
+	var pb *cmpv1alpha1.ProfileBundle
+	var err error
+	if tp.Spec.Extends != "" {
+		_, pb, err = r.getProfileInfoFromExtends(tp)
+	} else {
+		if !isValidationRequired(tp) {
+			return fmt.Errorf("Custom TailoredProfile with no extends does not have any rules enabled")
+		}
+		pb, err = r.getProfileBundleFromRulesOrVars(tp)
+	}
+	if err != nil {
+		return err
+	}
+
+	rules, err := r.getRulesFromSelections(tp, pb)
+	if err != nil {
+		return err
+	}
+	if err := assertValidRuleTypes(rules); err != nil {
+		return err
+	}
+	_, err = r.getVariablesFromSelections(tp, pb)
+	return err
+}
@@ -0,0 +1,379 @@
+package oscal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+const (
+	findingSatisfied    = "satisfied"
+	findingNotSatisfied = "not-satisfied"
+
+	subjectTypeComponent     = "component"
+	subjectTypeInventoryItem = "inventory-item"
+)
+
+type AssessmentResultsDocument struct {
+	AssessmentResults AssessmentResults `json:"assessment-results"`
+}
+
+type AssessmentResults struct {
+	UUID     string   `json:"uuid"`
+	Metadata Metadata `json:"metadata"`
+	ImportAP ImportAP `json:"import-ap"`
+	Results  []Result `json:"results"`
+}
+
+type ImportAP struct {
+	Href string `json:"href"`
+}
+
+type Result struct {
+	UUID             string           `json:"uuid"`
+	Title            string           `json:"title"`
+	Description      string           `json:"description"`
+	Start            time.Time        `json:"start"`
+	End              *time.Time       `json:"end,omitempty"`
+	Props            []Property       `json:"props,omitempty"`
+	LocalDefinitions LocalDefinitions `json:"local-definitions"`
+	ReviewedControls ReviewedControls `json:"reviewed-controls"`
+	Observations     []Observation    `json:"observations,omitempty"`
+	Findings         []Finding        `json:"findings,omitempty"`
+}
+
+type LocalDefinitions struct {
+	Components     []SystemComponent `json:"components,omitempty"`
+	InventoryItems []InventoryItem   `json:"inventory-items,omitempty"`
+}
+
+type SystemComponent struct {
+	UUID        string          `json:"uuid"`
+	Type        string          `json:"type"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Status      ComponentStatus `json:"status"`
+}
+
+type ComponentStatus struct {
+	State string `json:"state"`
+}
+
+type InventoryItem struct {
+	UUID        string     `json:"uuid"`
+	Description string     `json:"description"`
+	Props       []Property `json:"props,omitempty"`
+}
+
+type ReviewedControls struct {
+	ControlSelections []ControlSelection `json:"control-selections"`
+}
+
+type ControlSelection struct {
+	IncludeControls []ControlRef `json:"include-controls,omitempty"`
+}
+
+type ControlRef struct {
+	ControlID string `json:"control-id"`
+}
+
+type Observation struct {
+	UUID        string     `json:"uuid"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Props       []Property `json:"props,omitempty"`
+	Methods     []string   `json:"methods"`
+	Subjects    []Subject  `json:"subjects,omitempty"`
+	Collected   time.Time  `json:"collected"`
+}
+
+type Subject struct {
+	SubjectUUID string     `json:"subject-uuid"`
+	Type        string     `json:"type"`
+	Title       string     `json:"title,omitempty"`
+	Props       []Property `json:"props,omitempty"`
+}
+
+type Finding struct {
+	UUID                string               `json:"uuid"`
+	Title               string               `json:"title"`
+	Description         string               `json:"description"`
+	Props               []Property           `json:"props,omitempty"`
+	Target              FindingTarget        `json:"target"`
+	RelatedObservations []RelatedObservation `json:"related-observations,omitempty"`
+}
+
+type FindingTarget struct {
+	Type     string        `json:"type"`
+	TargetID string        `json:"target-id"`
+	Status   FindingStatus `json:"status"`
+}
+
+type FindingStatus struct {
+	State  string `json:"state"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type RelatedObservation struct {
+	ObservationUUID string `json:"observation-uuid"`
+}
+
+// controlFinding accumulates the observations related to a control
+type controlFinding struct {
+	control      Control
+	observations []string
+	statuses     []compv1alpha1.ComplianceCheckStatus
+}
+
+// NewAssessmentResults joins the check results of the suite with the control
+// annotations of the rules they were produced by. Every check result becomes
+// an observation whose subjects are the nodes the check ran on, and every
+// control the rules map to becomes a finding related to those observations.
+func NewAssessmentResults(suite *compv1alpha1.ComplianceSuite, scans []ScanResults, rules map[string]*compv1alpha1.Rule, now time.Time) *AssessmentResultsDocument {
+	version := DocumentVersion(scans)
+	clusterUUID := newUUID(suite, "component/cluster")
+	result := Result{
+		UUID:        newUUID(suite, "result/"+version),
+		Title:       fmt.Sprintf("Results of ComplianceSuite %s", suite.Name),
+		Description: fmt.Sprintf("Results of the scans of ComplianceSuite %s/%s", suite.Namespace, suite.Name),
+		Start:       now.UTC().Truncate(time.Second),
+		Props: []Property{
+			{Name: "suite", Value: suite.Name, NS: PropNamespace},
+		},
+		LocalDefinitions: LocalDefinitions{
+			Components: []SystemComponent{
+				{
+					UUID:        clusterUUID,
+					Type:        "this-system",
+					Title:       "Cluster",
+					Description: "The cluster the platform scans were run against",
+					Status:      ComponentStatus{State: "operational"},
+				},
+			},
+		},
+		ReviewedControls: ReviewedControls{ControlSelections: []ControlSelection{{}}},
+	}
+
+	nodeUUIDs := map[string]string{}
+	findings := map[Control]*controlFinding{}
+	var start, end *time.Time
+
+	for _, sr := range scans {
+		scan := sr.Scan
+		if ts := scan.Status.StartTimestamp; ts != nil && (start == nil || ts.Time.Before(*start)) {
+			t := ts.Time.UTC()
+			start = &t
+		}
+		if ts := scan.Status.EndTimestamp; ts != nil && (end == nil || ts.Time.After(*end)) {
+			t := ts.Time.UTC()
+			end = &t
+		}
+		collected := now
+		if scan.Status.EndTimestamp != nil {
+			collected = scan.Status.EndTimestamp.Time
+		} else if scan.Status.StartTimestamp != nil {
+			collected = scan.Status.StartTimestamp.Time
+		}
+
+		for _, node := range sr.Nodes {
+			if _, ok := nodeUUIDs[node]; !ok {
+				nodeUUIDs[node] = newUUID(suite, "node/"+node)
+			}
+		}
+
+		for i := range sr.CheckResults {
+			cr := &sr.CheckResults[i]
+			rule := rules[cr.ID]
+			obs := Observation{
+				UUID:        newUUID(suite, "observation/"+version+"/"+cr.Name),
+				Title:       cr.ID,
+				Description: ruleTitle(rule, cr),
+				Props: []Property{
+					{Name: "scan", Value: scan.Name, NS: PropNamespace},
+					{Name: "check-result", Value: cr.Name, NS: PropNamespace},
+					{Name: "result", Value: string(cr.Status), NS: PropNamespace},
+					{Name: "severity", Value: string(cr.Severity), NS: PropNamespace},
+				},
+				Methods:   []string{observationMethod(cr)},
+				Subjects:  observationSubjects(cr, sr.Nodes, nodeUUIDs, clusterUUID),
+				Collected: collected.UTC().Truncate(time.Second),
+			}
+			if exception := waivedBy(cr); exception != "" {
+				obs.Props = append(obs.Props, Property{Name: "waived-by", Value: exception, NS: PropNamespace})
+			}
+			status := cr.Status
+			if attested := cr.AttestedStatus(); attested != "" {
+				// Manual checks count with the outcome they were attested with
+				status = attested
+				obs.Props = append(obs.Props,
+					Property{Name: "attested-result", Value: string(attested), NS: PropNamespace},
+					Property{Name: "attested-by", Value: attestedBy(cr), NS: PropNamespace})
+			}
+			if rule != nil {
+				obs.Props = append(obs.Props, Property{Name: "rule", Value: rule.Name, NS: PropNamespace})
+				for _, ctrl := range RuleControls(rule) {
+					f, ok := findings[ctrl]
+					if !ok {
+						f = &controlFinding{control: ctrl}
+						findings[ctrl] = f
+					}
+					f.observations = append(f.observations, obs.UUID)
+					f.statuses = append(f.statuses, status)
+				}
+			}
+			result.Observations = append(result.Observations, obs)
+		}
+	}
+
+	if start != nil {
+		result.Start = *start
+	}
+	result.End = end
+
+	nodes := make([]string, 0, len(nodeUUIDs))
+	for node := range nodeUUIDs {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	for _, node := range nodes {
+		result.LocalDefinitions.InventoryItems = append(result.LocalDefinitions.InventoryItems, InventoryItem{
+			UUID:        nodeUUIDs[node],
+			Description: fmt.Sprintf("Node %s", node),
+			Props:       []Property{{Name: "fqdn", Value: node}},
+		})
+	}
+
+	controls := make([]Control, 0, len(findings))
+	for ctrl := range findings {
+		controls = append(controls, ctrl)
+	}
+	sortControls(controls)
+	seenIDs := map[string]bool{}
+	for _, ctrl := range controls {
+		f := findings[ctrl]
+		if id := ctrl.OSCALID(); !seenIDs[id] {
+			seenIDs[id] = true
+			result.ReviewedControls.ControlSelections[0].IncludeControls = append(
+				result.ReviewedControls.ControlSelections[0].IncludeControls, ControlRef{ControlID: id})
+		}
+		result.Findings = append(result.Findings, newFinding(suite, version, f))
+	}
+
+	return &AssessmentResultsDocument{
+		AssessmentResults: AssessmentResults{
+			UUID:     newUUID(suite, "assessment-results/"+version),
+			Metadata: newMetadata(fmt.Sprintf("Compliance Operator assessment results of %s", suite.Name), now, scans),
+			ImportAP: ImportAP{
+				Href: fmt.Sprintf("#%s", newUUID(suite, "assessment-plan")),
+			},
+			Results: []Result{result},
+		},
+	}
+}
+
+func newFinding(suite *compv1alpha1.ComplianceSuite, version string, f *controlFinding) Finding {
+	state, reason := findingState(f.statuses)
+	finding := Finding{
+		UUID:  newUUID(suite, "finding/"+version+"/"+f.control.Standard+"/"+f.control.ID),
+		Title: fmt.Sprintf("%s %s", f.control.Standard, f.control.ID),
+		Description: fmt.Sprintf("Control %s of %s was assessed by %d check(s)",
+			f.control.ID, f.control.Standard, len(f.observations)),
+		Props: []Property{
+			{Name: "standard", Value: f.control.Standard, NS: PropNamespace},
+			{Name: "control", Value: f.control.ID, NS: PropNamespace},
+		},
+		Target: FindingTarget{
+			Type:     "objective-id",
+			TargetID: f.control.OSCALID() + "_obj",
+			Status:   FindingStatus{State: state, Reason: reason},
+		},
+	}
+	for _, obs := range f.observations {
+		finding.RelatedObservations = append(finding.RelatedObservations, RelatedObservation{ObservationUUID: obs})
+	}
+	return finding
+}
+
+// findingState returns whether a control is satisfied based on the statuses
+// of the checks it's assessed by. A control is only satisfied when none of
+// its checks failed or need to be looked at manually.
+func findingState(statuses []compv1alpha1.ComplianceCheckStatus) (string, string) {
+	reason := ""
+	for _, status := range statuses {
+		switch status {
+		case compv1alpha1.CheckResultPass, compv1alpha1.CheckResultNotApplicable:
+		case compv1alpha1.CheckResultFail, compv1alpha1.CheckResultInconsistent:
+			return findingNotSatisfied, "fail"
+		default:
+			reason = "other"
+		}
+	}
+	if reason != "" {
+		return findingNotSatisfied, reason
+	}
+	return findingSatisfied, "pass"
+}
+
+func observationMethod(cr *compv1alpha1.ComplianceCheckResult) string {
+	if cr.Status == compv1alpha1.CheckResultManual {
+		return "EXAMINE"
+	}
+	return "TEST"
+}
+
+// observationSubjects returns the nodes a check ran on along with the status
+// of the check on each node, or the cluster for platform checks
+func observationSubjects(cr *compv1alpha1.ComplianceCheckResult, nodes []string, nodeUUIDs map[string]string, clusterUUID string) []Subject {
+	if len(nodes) == 0 {
+		return []Subject{
+			{
+				SubjectUUID: clusterUUID,
+				Type:        subjectTypeComponent,
+				Title:       "Cluster",
+				Props:       []Property{{Name: "result", Value: string(cr.Status), NS: PropNamespace}},
+			},
+		}
+	}
+
+	nodeStatus := inconsistentNodeStatuses(cr)
+	defaultStatus := string(cr.Status)
+	if mostCommon, ok := cr.Annotations[compv1alpha1.ComplianceCheckResultMostCommonAnnotation]; ok {
+		defaultStatus = mostCommon
+	}
+
+	subjects := make([]Subject, 0, len(nodes))
+	for _, node := range nodes {
+		status, ok := nodeStatus[node]
+		if !ok {
+			status = defaultStatus
+		}
+		subjects = append(subjects, Subject{
+			SubjectUUID: nodeUUIDs[node],
+			Type:        subjectTypeInventoryItem,
+			Title:       node,
+			Props:       []Property{{Name: "result", Value: status, NS: PropNamespace}},
+		})
+	}
+	return subjects
+}
+
+// inconsistentNodeStatuses parses the node:status list the aggregator
+// annotates inconsistent results with
+func inconsistentNodeStatuses(cr *compv1alpha1.ComplianceCheckResult) map[string]string {
+	statuses := map[string]string{}
+	sources, ok := cr.Annotations[compv1alpha1.ComplianceCheckResultInconsistentSourceAnnotation]
+	if !ok {
+		return statuses
+	}
+	for _, src := range strings.Split(sources, ",") {
+		idx := strings.LastIndex(src, ":")
+		if idx < 0 {
+			continue
+		}
+		statuses[src[:idx]] = src[idx+1:]
+	}
+	return statuses
+}
@@ -0,0 +1,120 @@
+package oscal
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+type ComponentDefinitionDocument struct {
+	ComponentDefinition ComponentDefinition `json:"component-definition"`
+}
+
+type ComponentDefinition struct {
+	UUID       string      `json:"uuid"`
+	Metadata   Metadata    `json:"metadata"`
+	Components []Component `json:"components,omitempty"`
+}
+
+type Component struct {
+	UUID                   string                  `json:"uuid"`
+	Type                   string                  `json:"type"`
+	Title                  string                  `json:"title"`
+	Description            string                  `json:"description"`
+	Props                  []Property              `json:"props,omitempty"`
+	ControlImplementations []ControlImplementation `json:"control-implementations,omitempty"`
+}
+
+type ControlImplementation struct {
+	UUID                    string                   `json:"uuid"`
+	Source                  string                   `json:"source"`
+	Description             string                   `json:"description"`
+	ImplementedRequirements []ImplementedRequirement `json:"implemented-requirements"`
+}
+
+type ImplementedRequirement struct {
+	UUID        string     `json:"uuid"`
+	ControlID   string     `json:"control-id"`
+	Description string     `json:"description"`
+	Props       []Property `json:"props,omitempty"`
+}
+
+// NewComponentDefinition describes every scan of the suite as a validation
+// component which implements the controls its rules map to
+func NewComponentDefinition(suite *compv1alpha1.ComplianceSuite, scans []ScanResults, rules map[string]*compv1alpha1.Rule, now time.Time) *ComponentDefinitionDocument {
+	version := DocumentVersion(scans)
+	doc := &ComponentDefinitionDocument{
+		ComponentDefinition: ComponentDefinition{
+			UUID:     newUUID(suite, "component-definition/"+version),
+			Metadata: newMetadata(fmt.Sprintf("Compliance Operator component definition of %s", suite.Name), now, scans),
+		},
+	}
+
+	for _, sr := range scans {
+		scan := sr.Scan
+		component := Component{
+			UUID:        newUUID(suite, "component/scan/"+scan.Name),
+			Type:        "validation",
+			Title:       scan.Name,
+			Description: fmt.Sprintf("Compliance Operator %s scan using profile %s", scan.Spec.ScanType, scan.Spec.Profile),
+			Props: []Property{
+				{Name: "scan", Value: scan.Name, NS: PropNamespace},
+				{Name: "profile", Value: scan.Spec.Profile, NS: PropNamespace},
+			},
+		}
+
+		// standard -> control -> rules implementing it
+		byStandard := map[string]map[Control][]string{}
+		for i := range sr.CheckResults {
+			cr := &sr.CheckResults[i]
+			rule := rules[cr.ID]
+			if rule == nil {
+				continue
+			}
+			for _, ctrl := range RuleControls(rule) {
+				if byStandard[ctrl.Standard] == nil {
+					byStandard[ctrl.Standard] = map[Control][]string{}
+				}
+				byStandard[ctrl.Standard][ctrl] = append(byStandard[ctrl.Standard][ctrl], rule.Name)
+			}
+		}
+
+		standards := make([]string, 0, len(byStandard))
+		for std := range byStandard {
+			standards = append(standards, std)
+		}
+		sort.Strings(standards)
+
+		for _, std := range standards {
+			controls := make([]Control, 0, len(byStandard[std]))
+			for ctrl := range byStandard[std] {
+				controls = append(controls, ctrl)
+			}
+			sortControls(controls)
+
+			impl := ControlImplementation{
+				UUID:        newUUID(suite, "control-implementation/"+scan.Name+"/"+std),
+				Source:      controls[0].source(),
+				Description: fmt.Sprintf("Controls of %s checked by scan %s", std, scan.Name),
+			}
+			for _, ctrl := range controls {
+				req := ImplementedRequirement{
+					UUID:        newUUID(suite, "implemented-requirement/"+scan.Name+"/"+std+"/"+ctrl.ID),
+					ControlID:   ctrl.OSCALID(),
+					Description: fmt.Sprintf("%s %s", std, ctrl.ID),
+				}
+				for _, ruleName := range byStandard[std][ctrl] {
+					req.Props = append(req.Props, Property{Name: "Rule_Id", Value: ruleName, NS: PropNamespace})
+				}
+				impl.ImplementedRequirements = append(impl.ImplementedRequirements, req)
+			}
+			component.ControlImplementations = append(component.ControlImplementations, impl)
+		}
+
+		doc.ComponentDefinition.Components = append(doc.ComponentDefinition.Components, component)
+	}
+
+	return doc
+}
@@ -0,0 +1,185 @@
+// Package oscal renders the results of a ComplianceSuite as NIST OSCAL
+// documents.
+package oscal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+const (
+	// Version is the OSCAL version the documents conform to
+	Version = "1.1.2"
+	// PropNamespace is the namespace of the properties the operator adds
+	// to the documents
+	PropNamespace = "https://github.com/ComplianceAsCode/compliance-operator"
+
+	nistStandard = "NIST-800-53"
+)
+
+// standardSources maps the standards known to the profile parser to the
+// catalog the controls come from. Standards without an OSCAL catalog point
+// to the reference the profile parser matches on.
+var standardSources = map[string]string{
+	nistStandard:  "https://raw.githubusercontent.com/usnistgov/oscal-content/main/nist.gov/SP800-53/rev4/json/NIST_SP-800-53_rev4_catalog.json",
+	"CIS-OCP":     "https://www.cisecurity.org/benchmark/kubernetes/",
+	"CIS-RHEL":    "https://www.cisecurity.org/benchmark/red_hat_linux/",
+	"NERC-CIP":    "https://www.nerc.com/pa/Stand/Standard%20Purpose%20Statement%20DL/US_Standard_One-Stop-Shop.xlsx",
+	"PCI-DSS":     "https://www.pcisecuritystandards.org/documents/PCI_DSS_v3-2-1.pdf",
+	"PCI-DSS-4-0": "https://docs-prv.pcisecuritystandards.org/PCI%20DSS/Standard/PCI-DSS-v4_0.pdf",
+	"STIG":        "https://public.cyber.mil/stigs/downloads/?_dl_facet_stigs=container-platform",
+}
+
+// nistControlRegexp matches NIST 800-53 controls with optional enhancements,
+// e.g. AC-2(1). Anything trailing, like statement letters, is ignored.
+var nistControlRegexp = regexp.MustCompile(`^([A-Za-z]{2})-(\d+)((?:\(\d+\))*)`)
+
+type Metadata struct {
+	Title        string    `json:"title"`
+	LastModified time.Time `json:"last-modified"`
+	Version      string    `json:"version"`
+	OSCALVersion string    `json:"oscal-version"`
+}
+
+type Property struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	NS      string `json:"ns,omitempty"`
+	Remarks string `json:"remarks,omitempty"`
+}
+
+// ScanResults groups the results of a single scan of the suite together with
+// the nodes the scan ran on. Nodes is empty for platform scans.
+type ScanResults struct {
+	Scan         *compv1alpha1.ComplianceScan
+	Nodes        []string
+	CheckResults []compv1alpha1.ComplianceCheckResult
+}
+
+// Control is a control of a compliance standard
+type Control struct {
+	Standard string
+	ID       string
+}
+
+// OSCALID returns the ID the control is referenced with in OSCAL documents
+func (c Control) OSCALID() string {
+	if c.Standard != nistStandard {
+		return c.ID
+	}
+	m := nistControlRegexp.FindStringSubmatch(c.ID)
+	if m == nil {
+		return strings.ToLower(c.ID)
+	}
+	id := strings.ToLower(m[1]) + "-" + m[2]
+	for _, enh := range strings.Split(m[3], ")") {
+		enh = strings.TrimPrefix(enh, "(")
+		if enh != "" {
+			id += "." + enh
+		}
+	}
+	return id
+}
+
+func (c Control) source() string {
+	if src, ok := standardSources[c.Standard]; ok {
+		return src
+	}
+	return "#" + c.Standard
+}
+
+// RuleControls returns the controls a rule maps to based on its control
+// annotations
+func RuleControls(rule *compv1alpha1.Rule) []Control {
+	controls := []Control{}
+	for _, ctrl := range utils.GetRuleControls(rule) {
+		controls = append(controls, Control(ctrl))
+	}
+	return controls
+}
+
+func sortControls(controls []Control) {
+	sort.Slice(controls, func(i, j int) bool {
+		if controls[i].Standard != controls[j].Standard {
+			return controls[i].Standard < controls[j].Standard
+		}
+		return controls[i].ID < controls[j].ID
+	})
+}
+
+// newUUID returns a UUID that's stable for the same suite and name so that
+// regenerating a document doesn't change the identifiers in it
+func newUUID(suite *compv1alpha1.ComplianceSuite, name string) string {
+	ns := uuid.NewSHA1(uuid.NameSpaceURL, []byte(PropNamespace+"/"+string(suite.UID)))
+	return uuid.NewSHA1(ns, []byte(name)).String()
+}
+
+// DocumentVersion identifies the scan runs a document was generated from,
+// followed by a digest of their results. ComplianceExceptions and
+// ComplianceAttestations change the results of a scan run after the fact,
+// the digest makes sure that the documents are regenerated when they do.
+func DocumentVersion(scans []ScanResults) string {
+	parts := make([]string, 0, len(scans))
+	state := []string{}
+	for _, s := range scans {
+		parts = append(parts, fmt.Sprintf("%s-%d", s.Scan.Name, s.Scan.Status.CurrentIndex))
+		state = append(state, fmt.Sprintf("%s/%s/%d/%d", s.Scan.Name, s.Scan.Status.Result,
+			s.Scan.Status.WaivedChecks, s.Scan.Status.AttestedChecks))
+		for i := range s.CheckResults {
+			cr := &s.CheckResults[i]
+			state = append(state, fmt.Sprintf("%s/%s/%s/%s/%s/%s", s.Scan.Name, cr.Name, cr.Status,
+				waivedBy(cr), cr.AttestedStatus(), attestedBy(cr)))
+		}
+	}
+	sort.Strings(parts)
+	sort.Strings(state)
+	digest := sha256.Sum256([]byte(strings.Join(state, "\n")))
+	return strings.Join(parts, ",") + "+" + hex.EncodeToString(digest[:4])
+}
+
+// waivedBy returns the name of the ComplianceException waiving a check
+// result, if any
+func waivedBy(cr *compv1alpha1.ComplianceCheckResult) string {
+	if !cr.IsWaived() {
+		return ""
+	}
+	return cr.Annotations[compv1alpha1.ComplianceCheckResultWaivedByAnnotation]
+}
+
+// attestedBy returns the name of the ComplianceAttestation attesting a
+// manual check result, if any
+func attestedBy(cr *compv1alpha1.ComplianceCheckResult) string {
+	if cr.AttestedStatus() == "" {
+		return ""
+	}
+	return cr.Annotations[compv1alpha1.ComplianceCheckResultAttestedByAnnotation]
+}
+
+func newMetadata(title string, now time.Time, scans []ScanResults) Metadata {
+	return Metadata{
+		Title:        title,
+		LastModified: now.UTC().Truncate(time.Second),
+		Version:      DocumentVersion(scans),
+		OSCALVersion: Version,
+	}
+}
+
+func ruleTitle(rule *compv1alpha1.Rule, cr *compv1alpha1.ComplianceCheckResult) string {
+	if rule != nil && rule.Title != "" {
+		return rule.Title
+	}
+	if title := strings.SplitN(cr.Description, "\n", 2)[0]; title != "" {
+		return title
+	}
+	return cr.ID
+}
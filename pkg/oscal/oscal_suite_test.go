@@ -0,0 +1,13 @@
+package oscal
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestOSCAL(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "OSCAL Suite")
+}
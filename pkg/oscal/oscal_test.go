@@ -0,0 +1,203 @@
+package oscal
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils/ruletest"
+)
+
+func newTestCheckResult(name, id string, status compv1alpha1.ComplianceCheckStatus) compv1alpha1.ComplianceCheckResult {
+	return compv1alpha1.ComplianceCheckResult{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		ID:         id,
+		Status:     status,
+		Severity:   compv1alpha1.CheckResultSeverityMedium,
+	}
+}
+
+var _ = Describe("OSCAL", func() {
+	var (
+		suite *compv1alpha1.ComplianceSuite
+		scans []ScanResults
+		rules map[string]*compv1alpha1.Rule
+		now   time.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+		suite = &compv1alpha1.ComplianceSuite{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-suite",
+				Namespace: "openshift-compliance",
+				UID:       "suite-uid",
+			},
+		}
+		rules = utils.RulesByID([]compv1alpha1.Rule{
+			ruletest.NewRule("ocp4-audit", "rule_audit", map[string]string{
+				compv1alpha1.RuleControlAnnotationPrefix + "NIST-800-53": "AU-2;AU-12(1)",
+				compv1alpha1.RuleControlAnnotationPrefix + "CIS-OCP":     "1.2.22",
+			}),
+			ruletest.NewRule("rhcos4-selinux", "rule_selinux", map[string]string{
+				compv1alpha1.RuleControlAnnotationPrefix + "NIST-800-53": "AC-3;AU-2",
+			}),
+			ruletest.NewRule("ocp4-no-controls", "rule_none", nil),
+		})
+
+		masterCheck := newTestCheckResult("master-selinux", "rule_selinux", compv1alpha1.CheckResultInconsistent)
+		masterCheck.Annotations = map[string]string{
+			compv1alpha1.ComplianceCheckResultInconsistentSourceAnnotation: "master-2:FAIL",
+			compv1alpha1.ComplianceCheckResultMostCommonAnnotation:         "PASS",
+		}
+		scans = []ScanResults{
+			{
+				Scan: &compv1alpha1.ComplianceScan{
+					ObjectMeta: metav1.ObjectMeta{Name: "ocp4-cis"},
+					Spec: compv1alpha1.ComplianceScanSpec{
+						ScanType: compv1alpha1.ScanTypePlatform,
+						Profile:  "xccdf_org.ssgproject.content_profile_cis",
+					},
+				},
+				CheckResults: []compv1alpha1.ComplianceCheckResult{
+					newTestCheckResult("ocp4-audit", "rule_audit", compv1alpha1.CheckResultPass),
+					newTestCheckResult("ocp4-none", "rule_none", compv1alpha1.CheckResultFail),
+				},
+			},
+			{
+				Scan: &compv1alpha1.ComplianceScan{
+					ObjectMeta: metav1.ObjectMeta{Name: "rhcos4-master"},
+					Spec: compv1alpha1.ComplianceScanSpec{
+						ScanType: compv1alpha1.ScanTypeNode,
+						Profile:  "xccdf_org.ssgproject.content_profile_moderate",
+					},
+					Status: compv1alpha1.ComplianceScanStatus{CurrentIndex: 2},
+				},
+				Nodes:        []string{"master-1", "master-2"},
+				CheckResults: []compv1alpha1.ComplianceCheckResult{masterCheck},
+			},
+		}
+	})
+
+	It("converts NIST controls to OSCAL control IDs", func() {
+		Expect(Control{Standard: "NIST-800-53", ID: "AC-2"}.OSCALID()).To(Equal("ac-2"))
+		Expect(Control{Standard: "NIST-800-53", ID: "AU-12(1)"}.OSCALID()).To(Equal("au-12.1"))
+		Expect(Control{Standard: "NIST-800-53", ID: "SC-7(5)(a)"}.OSCALID()).To(Equal("sc-7.5"))
+		Expect(Control{Standard: "CIS-OCP", ID: "1.2.22"}.OSCALID()).To(Equal("1.2.22"))
+	})
+
+	Context("Assessment results", func() {
+		var res Result
+
+		BeforeEach(func() {
+			doc := NewAssessmentResults(suite, scans, rules, now)
+			Expect(doc.AssessmentResults.Metadata.OSCALVersion).To(Equal(Version))
+			Expect(doc.AssessmentResults.Metadata.Version).To(MatchRegexp(`^ocp4-cis-0,rhcos4-master-2\+[0-9a-f]{8}$`))
+			Expect(doc.AssessmentResults.Results).To(HaveLen(1))
+			res = doc.AssessmentResults.Results[0]
+		})
+
+		It("creates an observation per check result", func() {
+			Expect(res.Observations).To(HaveLen(3))
+			Expect(res.Observations[0].Title).To(Equal("rule_audit"))
+			Expect(res.Observations[0].Description).To(Equal("Title of ocp4-audit"))
+			Expect(res.Observations[0].Subjects).To(HaveLen(1))
+			Expect(res.Observations[0].Subjects[0].Type).To(Equal("component"))
+		})
+
+		It("links node observations to the nodes with their status", func() {
+			obs := res.Observations[2]
+			Expect(obs.Subjects).To(HaveLen(2))
+			Expect(obs.Subjects[0].Title).To(Equal("master-1"))
+			Expect(obs.Subjects[0].Props[0].Value).To(Equal("PASS"))
+			Expect(obs.Subjects[1].Title).To(Equal("master-2"))
+			Expect(obs.Subjects[1].Props[0].Value).To(Equal("FAIL"))
+			Expect(res.LocalDefinitions.InventoryItems).To(HaveLen(2))
+			Expect(obs.Subjects[0].SubjectUUID).To(Equal(res.LocalDefinitions.InventoryItems[0].UUID))
+		})
+
+		It("creates a finding per control", func() {
+			// AC-3, AU-12(1), AU-2 and CIS 1.2.22
+			Expect(res.Findings).To(HaveLen(4))
+			byTitle := map[string]Finding{}
+			for _, f := range res.Findings {
+				byTitle[f.Title] = f
+			}
+
+			au2 := byTitle["NIST-800-53 AU-2"]
+			Expect(au2.Target.TargetID).To(Equal("au-2_obj"))
+			Expect(au2.RelatedObservations).To(HaveLen(2))
+			Expect(au2.Target.Status.State).To(Equal("not-satisfied"))
+
+			au12 := byTitle["NIST-800-53 AU-12(1)"]
+			Expect(au12.Target.Status.State).To(Equal("satisfied"))
+			Expect(au12.RelatedObservations).To(ConsistOf(RelatedObservation{ObservationUUID: res.Observations[0].UUID}))
+
+			Expect(byTitle).To(HaveKey("CIS-OCP 1.2.22"))
+			Expect(res.ReviewedControls.ControlSelections[0].IncludeControls).To(ConsistOf(
+				ControlRef{ControlID: "ac-3"},
+				ControlRef{ControlID: "au-12.1"},
+				ControlRef{ControlID: "au-2"},
+				ControlRef{ControlID: "1.2.22"},
+			))
+		})
+
+		It("is stable across regenerations", func() {
+			again := NewAssessmentResults(suite, scans, rules, now.Add(time.Hour))
+			Expect(again.AssessmentResults.Results[0].UUID).To(Equal(res.UUID))
+			Expect(again.AssessmentResults.Results[0].Findings[0].UUID).To(Equal(res.Findings[0].UUID))
+		})
+
+		It("records the waived and attested checks", func() {
+			version := DocumentVersion(scans)
+			waived := &scans[0].CheckResults[1]
+			waived.Labels = map[string]string{compv1alpha1.ComplianceCheckResultWaivedLabel: ""}
+			waived.Annotations = map[string]string{compv1alpha1.ComplianceCheckResultWaivedByAnnotation: "accepted-risk"}
+			Expect(DocumentVersion(scans)).ToNot(Equal(version))
+
+			version = DocumentVersion(scans)
+			attested := &scans[1].CheckResults[0]
+			attested.Status = compv1alpha1.CheckResultManual
+			attested.Labels = map[string]string{compv1alpha1.ComplianceCheckResultAttestedLabel: "PASS"}
+			attested.Annotations[compv1alpha1.ComplianceCheckResultAttestedByAnnotation] = "selinux-review"
+			Expect(DocumentVersion(scans)).ToNot(Equal(version))
+
+			doc := NewAssessmentResults(suite, scans, rules, now)
+			res = doc.AssessmentResults.Results[0]
+			Expect(res.Observations[1].Props).To(ContainElement(
+				Property{Name: "waived-by", Value: "accepted-risk", NS: PropNamespace}))
+			Expect(res.Observations[2].Props).To(ContainElements(
+				Property{Name: "attested-result", Value: "PASS", NS: PropNamespace},
+				Property{Name: "attested-by", Value: "selinux-review", NS: PropNamespace}))
+			for _, f := range res.Findings {
+				if f.Title == "NIST-800-53 AC-3" {
+					Expect(f.Target.Status.State).To(Equal("satisfied"))
+				}
+			}
+		})
+	})
+
+	Context("Component definition", func() {
+		It("creates a component per scan implementing the controls of its rules", func() {
+			doc := NewComponentDefinition(suite, scans, rules, now)
+			components := doc.ComponentDefinition.Components
+			Expect(components).To(HaveLen(2))
+
+			Expect(components[0].Title).To(Equal("ocp4-cis"))
+			Expect(components[0].ControlImplementations).To(HaveLen(2))
+			cis := components[0].ControlImplementations[0]
+			Expect(cis.Source).To(Equal("https://www.cisecurity.org/benchmark/kubernetes/"))
+			Expect(cis.ImplementedRequirements).To(HaveLen(1))
+			Expect(cis.ImplementedRequirements[0].ControlID).To(Equal("1.2.22"))
+			Expect(cis.ImplementedRequirements[0].Props[0].Value).To(Equal("ocp4-audit"))
+
+			nist := components[1].ControlImplementations[0]
+			Expect(nist.ImplementedRequirements).To(HaveLen(2))
+			Expect(nist.ImplementedRequirements[0].ControlID).To(Equal("ac-3"))
+		})
+	})
+})
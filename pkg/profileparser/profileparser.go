@@ -29,7 +29,7 @@ const (
 	machineConfigFixType  = "urn:xccdf:fix:script:ignition"
 	kubernetesFixType     = "urn:xccdf:fix:script:kubernetes"
 	valuePrefix           = "xccdf_org.ssgproject.content_value_"
-	controlAnnotationBase = "control.compliance.openshift.io/"
+	controlAnnotationBase = cmpv1alpha1.RuleControlAnnotationPrefix
 
 	rhacmStdsAnnotationKey   = "policies.open-cluster-management.io/standards"
 	rhacmCtrlsAnnotationsKey = "policies.open-cluster-management.io/controls"
@@ -42,6 +42,12 @@ type ParserConfig struct {
 	ProfileBundleKey types.NamespacedName
 	Client           runtimeclient.Client
 	Scheme           *k8sruntime.Scheme
+	// The expected sha256 checksum of the data stream, if any
+	ContentSHA256 string
+	// The detached signature of the data stream and the public key to
+	// verify it with. The signature isn't verified if no key is given.
+	SignaturePath string
+	PublicKeyPath string
 }
 
 func LogAndReturnError(errormsg string) error {
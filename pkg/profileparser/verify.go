@@ -0,0 +1,109 @@
+package profileparser
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// VerifyContent verifies the integrity of the data stream before it's
+// parsed. Depending on the parser configuration, the sha256 checksum of the
+// data stream is compared with the expected one and its detached signature
+// is verified with the given public key.
+func VerifyContent(pcfg *ParserConfig) error {
+	if pcfg.ContentSHA256 == "" && pcfg.PublicKeyPath == "" {
+		return nil
+	}
+
+	content, err := os.Open(pcfg.DataStreamPath)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, content); err != nil {
+		return fmt.Errorf("couldn't read the content file: %w", err)
+	}
+	digest := hasher.Sum(nil)
+
+	if pcfg.ContentSHA256 != "" {
+		actual := hex.EncodeToString(digest)
+		if !strings.EqualFold(actual, pcfg.ContentSHA256) {
+			return fmt.Errorf("the sha256 checksum of the content file is %s, expected %s", actual, pcfg.ContentSHA256)
+		}
+		log.Info("The checksum of the content file matches")
+	}
+
+	if pcfg.PublicKeyPath != "" {
+		if err := verifyContentSignature(digest, pcfg.SignaturePath, pcfg.PublicKeyPath); err != nil {
+			return err
+		}
+		log.Info("The signature of the content file is valid")
+	}
+	return nil
+}
+
+func verifyContentSignature(digest []byte, signaturePath, publicKeyPath string) error {
+	pub, err := readPublicKey(publicKeyPath)
+	if err != nil {
+		return err
+	}
+	signature, err := readSignature(signaturePath)
+	if err != nil {
+		return err
+	}
+
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		err = rsa.VerifyPKCS1v15(k, crypto.SHA256, digest, signature)
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, digest, signature) {
+			err = errors.New("verification failure")
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T, only RSA and ECDSA keys are supported", pub)
+	}
+	if err != nil {
+		return fmt.Errorf("the signature of the content file is invalid: %w", err)
+	}
+	return nil
+}
+
+func readPublicKey(path string) (crypto.PublicKey, error) {
+	keyPEM, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read the public key: %w", err)
+	}
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("no PEM data found in the public key")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse the public key: %w", err)
+	}
+	return pub, nil
+}
+
+// readSignature reads a detached signature, either raw as written by
+// `openssl dgst -sign` or base64-encoded as written by `cosign sign-blob`
+func readSignature(path string) ([]byte, error) {
+	signature, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read the signature of the content file: %w", err)
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature))); err == nil {
+		return decoded, nil
+	}
+	return signature, nil
+}
@@ -0,0 +1,105 @@
+package profileparser
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Testing content verification", func() {
+	var (
+		dir     string
+		content = []byte("<ds:data-stream-collection/>")
+		digest  = sha256.Sum256(content)
+		pcfg    *ParserConfig
+	)
+
+	writeFile := func(name string, data []byte) string {
+		p := filepath.Join(dir, name)
+		Expect(os.WriteFile(p, data, 0600)).To(Succeed())
+		return p
+	}
+	writePublicKey := func(pub crypto.PublicKey) string {
+		der, err := x509.MarshalPKIXPublicKey(pub)
+		Expect(err).To(BeNil())
+		return writeFile("public.pem", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "content")
+		Expect(err).To(BeNil())
+		pcfg = &ParserConfig{DataStreamPath: writeFile("ssg-ocp4-ds.xml", content)}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("doesn't verify anything by default", func() {
+		Expect(VerifyContent(pcfg)).To(Succeed())
+	})
+
+	It("verifies the checksum", func() {
+		pcfg.ContentSHA256 = hex.EncodeToString(digest[:])
+		Expect(VerifyContent(pcfg)).To(Succeed())
+
+		pcfg.ContentSHA256 = hex.EncodeToString(make([]byte, sha256.Size))
+		Expect(VerifyContent(pcfg)).To(MatchError(ContainSubstring("sha256 checksum")))
+	})
+
+	It("verifies a raw RSA signature", func() {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).To(BeNil())
+		sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		Expect(err).To(BeNil())
+
+		pcfg.PublicKeyPath = writePublicKey(&key.PublicKey)
+		pcfg.SignaturePath = writeFile("ssg-ocp4-ds.xml.sig", sig)
+		Expect(VerifyContent(pcfg)).To(Succeed())
+	})
+
+	It("verifies a base64-encoded ECDSA signature", func() {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).To(BeNil())
+		sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+		Expect(err).To(BeNil())
+
+		pcfg.PublicKeyPath = writePublicKey(&key.PublicKey)
+		pcfg.SignaturePath = writeFile("ssg-ocp4-ds.xml.sig", []byte(base64.StdEncoding.EncodeToString(sig)+"\n"))
+		Expect(VerifyContent(pcfg)).To(Succeed())
+	})
+
+	It("rejects content that doesn't match the signature", func() {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).To(BeNil())
+		otherDigest := sha256.Sum256([]byte("tampered"))
+		sig, err := ecdsa.SignASN1(rand.Reader, key, otherDigest[:])
+		Expect(err).To(BeNil())
+
+		pcfg.PublicKeyPath = writePublicKey(&key.PublicKey)
+		pcfg.SignaturePath = writeFile("ssg-ocp4-ds.xml.sig", sig)
+		Expect(VerifyContent(pcfg)).To(MatchError(ContainSubstring("signature of the content file is invalid")))
+	})
+
+	It("fails if the signature is missing", func() {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).To(BeNil())
+
+		pcfg.PublicKeyPath = writePublicKey(&key.PublicKey)
+		pcfg.SignaturePath = filepath.Join(dir, "missing.sig")
+		Expect(VerifyContent(pcfg)).ToNot(Succeed())
+	})
+})
@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"sort"
+	"strings"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+// RuleControl is a control of a compliance standard a rule maps to
+type RuleControl struct {
+	Standard string
+	ID       string
+}
+
+// GetRuleControls returns the controls a rule maps to based on its control
+// annotations, sorted by standard and ID
+func GetRuleControls(rule *compv1alpha1.Rule) []RuleControl {
+	controls := []RuleControl{}
+	for key, value := range rule.GetAnnotations() {
+		if !strings.HasPrefix(key, compv1alpha1.RuleControlAnnotationPrefix) {
+			continue
+		}
+		std := strings.TrimPrefix(key, compv1alpha1.RuleControlAnnotationPrefix)
+		for _, ctrl := range strings.Split(value, ";") {
+			ctrl = strings.TrimSpace(ctrl)
+			if ctrl != "" {
+				controls = append(controls, RuleControl{Standard: std, ID: ctrl})
+			}
+		}
+	}
+	sort.Slice(controls, func(i, j int) bool {
+		if controls[i].Standard != controls[j].Standard {
+			return controls[i].Standard < controls[j].Standard
+		}
+		return controls[i].ID < controls[j].ID
+	})
+	return controls
+}
+
+// RulesByID indexes rules by their XCCDF ID, which is what check results
+// refer to. Rules with the same ID coming from different bundles have their
+// control annotations merged.
+func RulesByID(rules []compv1alpha1.Rule) map[string]*compv1alpha1.Rule {
+	byID := map[string]*compv1alpha1.Rule{}
+	for i := range rules {
+		rule := &rules[i]
+		existing, ok := byID[rule.ID]
+		if !ok {
+			byID[rule.ID] = rule
+			continue
+		}
+		merged := existing.DeepCopy()
+		if merged.Annotations == nil {
+			merged.Annotations = map[string]string{}
+		}
+		for key, value := range rule.GetAnnotations() {
+			if !strings.HasPrefix(key, compv1alpha1.RuleControlAnnotationPrefix) {
+				continue
+			}
+			if cur, ok := merged.Annotations[key]; ok {
+				value = mergeControlIDs(cur, value)
+			}
+			merged.Annotations[key] = value
+		}
+		byID[rule.ID] = merged
+	}
+	return byID
+}
+
+// mergeControlIDs joins two lists of control IDs, leaving out the IDs found in
+// both
+func mergeControlIDs(cur, other string) string {
+	ids := []string{}
+	seen := map[string]bool{}
+	for _, ctrl := range strings.Split(cur+";"+other, ";") {
+		ctrl = strings.TrimSpace(ctrl)
+		if ctrl == "" || seen[ctrl] {
+			continue
+		}
+		seen[ctrl] = true
+		ids = append(ids, ctrl)
+	}
+	return strings.Join(ids, ";")
+}
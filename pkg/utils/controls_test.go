@@ -0,0 +1,49 @@
+package utils
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils/ruletest"
+)
+
+var _ = Describe("Rule controls", func() {
+	It("parses the controls of a rule", func() {
+		rule := ruletest.NewRule("ocp4-a", "rule_a", map[string]string{
+			compv1alpha1.RuleControlAnnotationPrefix + "NIST-800-53": "AU-2; AC-3",
+			compv1alpha1.RuleControlAnnotationPrefix + "CIS-OCP":     "1.2.22",
+			"unrelated": "x",
+		})
+		Expect(GetRuleControls(&rule)).To(Equal([]RuleControl{
+			{Standard: "CIS-OCP", ID: "1.2.22"},
+			{Standard: "NIST-800-53", ID: "AC-3"},
+			{Standard: "NIST-800-53", ID: "AU-2"},
+		}))
+	})
+
+	It("merges the controls of rules with the same ID", func() {
+		merged := RulesByID([]compv1alpha1.Rule{
+			ruletest.NewRule("ocp4-a", "rule_a", map[string]string{compv1alpha1.RuleControlAnnotationPrefix + "NIST-800-53": "AC-2"}),
+			ruletest.NewRule("rhcos4-a", "rule_a", map[string]string{compv1alpha1.RuleControlAnnotationPrefix + "NIST-800-53": "AC-3"}),
+		})
+		Expect(GetRuleControls(merged["rule_a"])).To(Equal([]RuleControl{
+			{Standard: "NIST-800-53", ID: "AC-2"},
+			{Standard: "NIST-800-53", ID: "AC-3"},
+		}))
+	})
+
+	It("doesn't repeat the controls shared by rules with the same ID", func() {
+		merged := RulesByID([]compv1alpha1.Rule{
+			ruletest.NewRule("ocp4-a", "rule_a", map[string]string{compv1alpha1.RuleControlAnnotationPrefix + "NIST-800-53": "AC-2;AC-3"}),
+			ruletest.NewRule("ocp4-b", "rule_a", map[string]string{compv1alpha1.RuleControlAnnotationPrefix + "NIST-800-53": "AC-3;AC-4"}),
+			ruletest.NewRule("ocp4-c", "rule_a", map[string]string{compv1alpha1.RuleControlAnnotationPrefix + "NIST-800-53": "AC-2"}),
+		})
+		Expect(merged["rule_a"].Annotations[compv1alpha1.RuleControlAnnotationPrefix+"NIST-800-53"]).To(Equal("AC-2;AC-3;AC-4"))
+		Expect(GetRuleControls(merged["rule_a"])).To(Equal([]RuleControl{
+			{Standard: "NIST-800-53", ID: "AC-2"},
+			{Standard: "NIST-800-53", ID: "AC-3"},
+			{Standard: "NIST-800-53", ID: "AC-4"},
+		}))
+	})
+})
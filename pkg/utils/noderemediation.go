@@ -0,0 +1,199 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	mcfgcommon "github.com/openshift/machine-config-operator/pkg/controller/common"
+)
+
+const nodeRemediationBackendEnv = "NODE_REMEDIATION_BACKEND"
+
+// NodeRemediationBackend is the mechanism the node remediations, given as
+// MachineConfigs, are applied to the nodes with
+type NodeRemediationBackend string
+
+const (
+	// NodeRemediationBackendMachineConfig applies the node remediations by
+	// creating MachineConfigs that the Machine Config Operator rolls out to
+	// the nodes of the matching MachineConfigPool
+	NodeRemediationBackendMachineConfig NodeRemediationBackend = "MachineConfig"
+	// NodeRemediationBackendDaemonSet applies the node remediations through
+	// a privileged agent running on the nodes, which doesn't require
+	// MachineConfigs nor MachineConfigPools
+	NodeRemediationBackendDaemonSet NodeRemediationBackend = "DaemonSet"
+)
+
+const (
+	// NodeRemediationAgentName is the name of the DaemonSet and the service
+	// account of the node remediation agent
+	NodeRemediationAgentName = "node-remediation-agent"
+	// NodeRemediationDataKey is the key of the node remediation ConfigMaps
+	// holding the serialized NodeRemediation
+	NodeRemediationDataKey = "remediation.json"
+	// NodeRemediationUnitDir is where the node remediation agent writes the
+	// systemd units
+	NodeRemediationUnitDir = "/etc/systemd/system"
+)
+
+// nodeRemediationAllowedPaths are the files, and the directories when ending
+// with a slash, that the node remediation agent may write to. They cover the
+// node configuration the remediations of the content set, the rest of the
+// node is off limits.
+var nodeRemediationAllowedPaths = []string{
+	"/etc/audit/",
+	"/etc/chrony.conf",
+	"/etc/crypto-policies/",
+	"/etc/issue",
+	"/etc/issue.d/",
+	"/etc/login.defs",
+	"/etc/modprobe.d/",
+	"/etc/pam.d/",
+	"/etc/profile.d/",
+	"/etc/rsyslog.d/",
+	"/etc/security/",
+	"/etc/ssh/sshd_config",
+	"/etc/ssh/sshd_config.d/",
+	"/etc/sysconfig/",
+	"/etc/sysctl.d/",
+	"/etc/systemd/",
+}
+
+// GetNodeRemediationBackend returns the backend node remediations are applied
+// with. It can be set with the NODE_REMEDIATION_BACKEND environment variable,
+// otherwise OpenShift uses MachineConfigs and the other platforms use the
+// node remediation agent.
+func GetNodeRemediationBackend() NodeRemediationBackend {
+	switch backend := NodeRemediationBackend(os.Getenv(nodeRemediationBackendEnv)); backend {
+	case NodeRemediationBackendMachineConfig, NodeRemediationBackendDaemonSet:
+		return backend
+	}
+	switch GetPlatform() {
+	case "OpenShift", "HyperShift", "OpenShiftOnPower", "OpenShiftOnZ":
+		return NodeRemediationBackendMachineConfig
+	}
+	return NodeRemediationBackendDaemonSet
+}
+
+// NodeRemediation is the node configuration of a remediation, as applied by
+// the node remediation agent to the nodes matching the node selector
+type NodeRemediation struct {
+	// NodeSelector is the label selector of the nodes to configure
+	NodeSelector string                `json:"nodeSelector"`
+	Files        []NodeRemediationFile `json:"files,omitempty"`
+	Units        []NodeRemediationUnit `json:"units,omitempty"`
+}
+
+// NodeRemediationFile is a file written to the nodes
+type NodeRemediationFile struct {
+	Path     string `json:"path"`
+	Mode     int    `json:"mode"`
+	Contents []byte `json:"contents"`
+}
+
+// NodeRemediationUnit is a systemd unit configured on the nodes. The unit
+// file is only written if it has contents.
+type NodeRemediationUnit struct {
+	Name     string `json:"name"`
+	Contents string `json:"contents,omitempty"`
+	Enabled  *bool  `json:"enabled,omitempty"`
+	Mask     bool   `json:"mask,omitempty"`
+}
+
+// NodeRemediationFromMachineConfig converts the files and the systemd units
+// of a MachineConfig to the configuration applied by the node remediation
+// agent. The other parts of the MachineConfig can't be applied by the agent.
+func NodeRemediationFromMachineConfig(mc *mcfgv1.MachineConfig, nodeSelector string) (*NodeRemediation, error) {
+	nodeRem := &NodeRemediation{NodeSelector: nodeSelector}
+	if mc.Spec.KernelArguments != nil || mc.Spec.Extensions != nil || mc.Spec.KernelType != "" || mc.Spec.FIPS {
+		return nil, fmt.Errorf("MachineConfig %s sets node options other than files and systemd units", mc.Name)
+	}
+	if len(mc.Spec.Config.Raw) == 0 {
+		return nodeRem, nil
+	}
+
+	ign, err := mcfgcommon.ParseAndConvertConfig(mc.Spec.Config.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse the Ignition config of MachineConfig %s: %w", mc.Name, err)
+	}
+	for _, file := range ign.Storage.Files {
+		if len(file.Append) > 0 {
+			return nil, fmt.Errorf("appending to file %s isn't supported", file.Path)
+		}
+		contents, err := mcfgcommon.DecodeIgnitionFileContents(file.Contents.Source, file.Contents.Compression)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode the contents of file %s: %w", file.Path, err)
+		}
+		mode := 0644
+		if file.Mode != nil {
+			mode = *file.Mode
+		}
+		nodeRem.Files = append(nodeRem.Files, NodeRemediationFile{
+			Path:     file.Path,
+			Mode:     mode,
+			Contents: contents,
+		})
+	}
+	for _, unit := range ign.Systemd.Units {
+		nodeUnit := NodeRemediationUnit{
+			Name:    unit.Name,
+			Enabled: unit.Enabled,
+		}
+		if unit.Contents != nil {
+			nodeUnit.Contents = *unit.Contents
+		}
+		if unit.Mask != nil {
+			nodeUnit.Mask = *unit.Mask
+		}
+		nodeRem.Units = append(nodeRem.Units, nodeUnit)
+		// Drop-ins are plain files in the directory of the unit
+		for _, dropin := range unit.Dropins {
+			contents := ""
+			if dropin.Contents != nil {
+				contents = *dropin.Contents
+			}
+			nodeRem.Files = append(nodeRem.Files, NodeRemediationFile{
+				Path:     path.Join(NodeRemediationUnitDir, unit.Name+".d", dropin.Name),
+				Mode:     0644,
+				Contents: []byte(contents),
+			})
+		}
+	}
+	if err := nodeRem.Validate(); err != nil {
+		return nil, err
+	}
+	return nodeRem, nil
+}
+
+// Validate checks that the node remediation agent may apply the files and
+// the units of the node remediation
+func (n *NodeRemediation) Validate() error {
+	for _, file := range n.Files {
+		if !IsNodeRemediationPathAllowed(file.Path) {
+			return fmt.Errorf("writing file %s isn't allowed", file.Path)
+		}
+	}
+	for _, unit := range n.Units {
+		if unit.Name == "" || path.Base(unit.Name) != unit.Name || strings.HasPrefix(unit.Name, ".") {
+			return fmt.Errorf("invalid systemd unit name %q", unit.Name)
+		}
+	}
+	return nil
+}
+
+// IsNodeRemediationPathAllowed tells whether the node remediation agent may
+// write to a file
+func IsNodeRemediationPathAllowed(p string) bool {
+	if !path.IsAbs(p) || path.Clean(p) != p {
+		return false
+	}
+	for _, allowed := range nodeRemediationAllowedPaths {
+		if p == allowed || (strings.HasSuffix(allowed, "/") && strings.HasPrefix(p, allowed)) {
+			return true
+		}
+	}
+	return false
+}
@@ -20,11 +20,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
 	compliancev1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
 	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -82,6 +85,32 @@ func GetScanNameFromProfile(profileName string, nodeSelector map[string]string)
 	return fmt.Sprintf("%s-%s", profileName, role)
 }
 
+var invalidScanNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// GetScanNameFromNodeLabelSelector returns the name of the scan of a profile
+// running on the nodes matched by a label selector. The name is derived from
+// the requirements of the selector, so that it stays the same as long as the
+// selector does, and is hashed if it's too long to be a DNS name.
+func GetScanNameFromNodeLabelSelector(profileName string, selector *metav1.LabelSelector) string {
+	parts := []string{}
+	keys := make([]string, 0, len(selector.MatchLabels))
+	for key := range selector.MatchLabels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		parts = append(parts, key, selector.MatchLabels[key])
+	}
+	for _, expr := range selector.MatchExpressions {
+		values := append([]string{}, expr.Values...)
+		sort.Strings(values)
+		parts = append(parts, expr.Key, string(expr.Operator))
+		parts = append(parts, values...)
+	}
+	selectorName := invalidScanNameChars.ReplaceAllString(strings.ToLower(strings.Join(parts, "-")), "-")
+	return DNSLengthName("scan-", "%s-%s", profileName, strings.Trim(selectorName, "-"))
+}
+
 func GetNodeRoles(nodeSelector map[string]string) []string {
 	roles := []string{}
 	if nodeSelector == nil {
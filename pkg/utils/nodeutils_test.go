@@ -228,6 +228,43 @@ var _ = Describe("Nodeutils", func() {
 		)
 	})
 
+	When("Testing GetScanNameFromNodeLabelSelector", func() {
+		DescribeTable("Gets expected output",
+			func(selector *metav1.LabelSelector, expectation string) {
+				Expect(utils.GetScanNameFromNodeLabelSelector("rhcos4-e8", selector)).To(Equal(expectation))
+			},
+			Entry("labels", &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"node-role.kubernetes.io/worker": "",
+					"hardware":                       "GPU",
+				},
+			}, "rhcos4-e8-hardware-gpu-node-role-kubernetes-io-worker"),
+			Entry("expressions", &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{
+						Key:      "topology.kubernetes.io/zone",
+						Operator: metav1.LabelSelectorOpNotIn,
+						Values:   []string{"zone-b", "zone-a"},
+					},
+				},
+			}, "rhcos4-e8-topology-kubernetes-io-zone-notin-zone-a-zone-b"),
+		)
+
+		It("shortens long names", func() {
+			name := utils.GetScanNameFromNodeLabelSelector("rhcos4-e8", &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{
+						Key:      "topology.kubernetes.io/zone",
+						Operator: metav1.LabelSelectorOpIn,
+						Values:   []string{"us-east-1a", "us-east-1b", "us-east-1c"},
+					},
+				},
+			})
+			Expect(name).To(HavePrefix("scan-"))
+			Expect(len(name)).To(BeNumerically("<", 64))
+		})
+	})
+
 	Context("MachineConfig Pool with no node selector", func() {
 		targetNodeSelector := map[string]string{
 			"test-node-role": "",
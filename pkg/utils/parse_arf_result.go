@@ -68,6 +68,9 @@ const (
 	dumpLocationClass        = "ocp-dump-location"
 	filterTypeClass          = "ocp-api-filter"
 	filteredEndpointClass    = "filtered"
+	labelSelectorClass       = "ocp-api-label-selector"
+	fieldSelectorClass       = "ocp-api-field-selector"
+	metadataOnlyClass        = "ocp-api-metadata-only"
 )
 
 type ParseResult struct {
@@ -81,12 +84,26 @@ type ResourcePath struct {
 	DumpPath        string
 	Filter          string
 	SuppressWarning bool
+	// Only list the objects matching these selectors
+	LabelSelector string
+	FieldSelector string
+	// Only fetch the metadata of the objects
+	MetadataOnly bool
 }
 
 // getPathsFromRuleWarning finds the API endpoint from in. The expected structure is:
 //
 //	<warning category="general" lang="en-US"><code class="ocp-api-endpoint">/apis/config.openshift.io/v1/oauths/cluster
 //	</code></warning>
+//
+// An endpoint with an id may be followed by the filter and the dump location
+// of its objects, and by the label and field selectors the objects are
+// listed with, for instance:
+//
+//	<code class="ocp-api-endpoint" id="a1b2">/api/v1/pods</code>
+//	<code class="ocp-api-label-selector" id="labelselector-a1b2">app=router</code>
+//	<code class="ocp-api-field-selector" id="fieldselector-a1b2">status.phase=Running</code>
+//	<code class="ocp-api-metadata-only" id="metadataonly-a1b2"></code>
 func GetPathFromWarningXML(in *xmlquery.Node, valuesList map[string]string) ([]ResourcePath, error) {
 	apiPaths := []ResourcePath{}
 
@@ -118,7 +135,20 @@ func GetPathFromWarningXML(in *xmlquery.Node, valuesList map[string]string) ([]R
 					dumpPath, _, err = RenderValues(XmlNodeAsMarkdown(dumpNode), valuesList)
 				}
 			}
-			apiPaths = append(apiPaths, ResourcePath{ObjPath: path, DumpPath: dumpPath, Filter: filter, SuppressWarning: warningHasSuppressTag(in)})
+			rpath := ResourcePath{ObjPath: path, DumpPath: dumpPath, Filter: filter, SuppressWarning: warningHasSuppressTag(in)}
+			if pathID != "" {
+				if err := setPathSelectors(in, pathID, valuesList, &rpath); err != nil {
+					errMsgs = append(errMsgs, err.Error())
+					continue
+				}
+			}
+			// Only a part of the objects is fetched with selectors, which
+			// mustn't be saved where the other rules expect all of them
+			if rpath.hasSelectors() && rpath.DumpPath == rpath.ObjPath {
+				errMsgs = append(errMsgs, fmt.Sprintf("the selectors of %s need a distinct dump path, ignoring them", path))
+				rpath.LabelSelector, rpath.FieldSelector, rpath.MetadataOnly = "", "", false
+			}
+			apiPaths = append(apiPaths, rpath)
 		}
 	}
 	if len(errMsgs) > 0 {
@@ -128,6 +158,32 @@ func GetPathFromWarningXML(in *xmlquery.Node, valuesList map[string]string) ([]R
 	}
 }
 
+func (rpath *ResourcePath) hasSelectors() bool {
+	return rpath.LabelSelector != "" || rpath.FieldSelector != "" || rpath.MetadataOnly
+}
+
+// setPathSelectors sets the selectors and the metadata-only flag declared
+// for the endpoint with the given id
+func setPathSelectors(in *xmlquery.Node, pathID string, valuesList map[string]string, rpath *ResourcePath) error {
+	var err error
+	if node := in.SelectElement(fmt.Sprintf(`//*[@id="labelselector-%s"]`, pathID)); node != nil && node.SelectAttr("class") == labelSelectorClass {
+		rpath.LabelSelector, _, err = RenderValues(strings.TrimSpace(XmlNodeAsMarkdown(node)), valuesList)
+		if err != nil {
+			return err
+		}
+	}
+	if node := in.SelectElement(fmt.Sprintf(`//*[@id="fieldselector-%s"]`, pathID)); node != nil && node.SelectAttr("class") == fieldSelectorClass {
+		rpath.FieldSelector, _, err = RenderValues(strings.TrimSpace(XmlNodeAsMarkdown(node)), valuesList)
+		if err != nil {
+			return err
+		}
+	}
+	if node := in.SelectElement(fmt.Sprintf(`//*[@id="metadataonly-%s"]`, pathID)); node != nil && node.SelectAttr("class") == metadataOnlyClass {
+		rpath.MetadataOnly = true
+	}
+	return nil
+}
+
 func warningHasApiObjects(in *xmlquery.Node) bool {
 	codeNodes := in.SelectElements("//html:code")
 
@@ -760,13 +816,34 @@ func mapComplianceCheckResultStatus(result *xmlquery.Node) (compv1alpha1.Complia
 func newComplianceRemediation(scheme *runtime.Scheme, scanName, namespace string, rule *xmlquery.Node, resultValues map[string]string) ([]*compv1alpha1.ComplianceRemediation, error) {
 	for _, fix := range rule.SelectElements("//xccdf-1.2:fix") {
 		if isRelevantFix(fix) {
-			return remediationFromFixElement(scheme, fix, scanName, namespace, resultValues)
+			rems, err := remediationFromFixElement(scheme, fix, scanName, namespace, resultValues)
+			if err != nil {
+				return nil, err
+			}
+			annotateRemediationImpact(rems, fix, rule)
+			return rems, nil
 		}
 	}
 
 	return nil, nil
 }
 
+// annotateRemediationImpact records the disruption of the fix and the
+// severity of the rule, which the approvals a remediation needs depend on
+func annotateRemediationImpact(rems []*compv1alpha1.ComplianceRemediation, fix, rule *xmlquery.Node) {
+	disruption := fix.SelectAttr("disruption")
+	// Rules without a severity leave it empty
+	severity, _ := mapComplianceCheckResultSeverity(rule)
+	for _, rem := range rems {
+		if disruption != "" {
+			rem.Annotations[compv1alpha1.RemediationDisruptionAnnotation] = disruption
+		}
+		if severity != "" {
+			rem.Annotations[compv1alpha1.RemediationSeverityAnnotation] = string(severity)
+		}
+	}
+}
+
 func isRelevantFix(fix *xmlquery.Node) bool {
 	if fix.SelectAttr("system") == machineConfigFixType {
 		return true
@@ -477,6 +477,26 @@ Server 3.fedora.pool.ntp.org`
 					Expect(mcFiles[0].Path).To(Equal("/etc/securetty"))
 				})
 			})
+
+			It("Should record the severity of the rule", func() {
+				Expect(rem.Annotations).To(HaveKeyWithValue(compv1alpha1.RemediationSeverityAnnotation, "medium"))
+				Expect(rem.Annotations).NotTo(HaveKey(compv1alpha1.RemediationDisruptionAnnotation))
+			})
+		})
+
+		Context("Remediation of a fix with a disruption", func() {
+			It("Should record the disruption of the fix", func() {
+				var rem *compv1alpha1.ComplianceRemediation
+				for i := range resultList {
+					for _, r := range resultList[i].Remediations {
+						if r.Name == "testScan-disable-ctrlaltdel-reboot" {
+							rem = r
+						}
+					}
+				}
+				Expect(rem).ToNot(BeNil())
+				Expect(rem.Annotations).To(HaveKeyWithValue(compv1alpha1.RemediationDisruptionAnnotation, "low"))
+			})
 		})
 	})
 
@@ -971,3 +991,50 @@ func printUniquePaths(node *xmlquery.Node, currentPath string, visitedPaths map[
 		printUniquePaths(child, path, visitedPaths)
 	}
 }
+
+var _ = Describe("Parsing the API endpoints of rule warnings", func() {
+	parseWarning := func(warning string) []ResourcePath {
+		doc, err := xmlquery.Parse(strings.NewReader(warning))
+		Expect(err).To(BeNil())
+		paths, err := GetPathFromWarningXML(doc, map[string]string{"app": "router"})
+		Expect(err).To(BeNil())
+		return paths
+	}
+
+	It("reads the selectors of an endpoint", func() {
+		paths := parseWarning(`<warning xmlns:html="http://www.w3.org/1999/xhtml">
+<html:code class="ocp-api-endpoint" id="a1b2">/api/v1/pods</html:code>
+<html:code class="ocp-api-filter" id="filter-a1b2">[.items[].metadata.name]</html:code>
+<html:code class="ocp-dump-location" id="dump-a1b2">/api/v1/pods#a1b2</html:code>
+<html:code class="ocp-api-label-selector" id="labelselector-a1b2">app={{.app}}</html:code>
+<html:code class="ocp-api-field-selector" id="fieldselector-a1b2">status.phase=Running</html:code>
+<html:code class="ocp-api-metadata-only" id="metadataonly-a1b2"></html:code>
+</warning>`)
+		Expect(paths).To(Equal([]ResourcePath{{
+			ObjPath:       "/api/v1/pods",
+			DumpPath:      "/api/v1/pods#a1b2",
+			Filter:        "[.items[].metadata.name]",
+			LabelSelector: "app=router",
+			FieldSelector: "status.phase=Running",
+			MetadataOnly:  true,
+		}}))
+	})
+
+	It("ignores the selectors of an endpoint without a dump path", func() {
+		doc, err := xmlquery.Parse(strings.NewReader(`<warning xmlns:html="http://www.w3.org/1999/xhtml">
+<html:code class="ocp-api-endpoint" id="a1b2">/api/v1/pods</html:code>
+<html:code class="ocp-api-label-selector" id="labelselector-a1b2">app=router</html:code>
+</warning>`))
+		Expect(err).To(BeNil())
+		paths, err := GetPathFromWarningXML(doc, nil)
+		Expect(err).To(MatchError(ContainSubstring("need a distinct dump path")))
+		Expect(paths).To(Equal([]ResourcePath{{ObjPath: "/api/v1/pods", DumpPath: "/api/v1/pods"}}))
+	})
+
+	It("fetches the whole objects by default", func() {
+		paths := parseWarning(`<warning xmlns:html="http://www.w3.org/1999/xhtml">
+<html:code class="ocp-api-endpoint" id="a1b2">/api/v1/pods</html:code>
+</warning>`)
+		Expect(paths).To(Equal([]ResourcePath{{ObjPath: "/api/v1/pods", DumpPath: "/api/v1/pods"}}))
+	})
+})
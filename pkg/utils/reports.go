@@ -0,0 +1,289 @@
+package utils
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/version"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	toolName     = "compliance-operator"
+	toolURI      = "https://github.com/ComplianceAsCode/compliance-operator"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool         `json:"tool"`
+	Results []sarifResult     `json:"results"`
+	Props   map[string]string `json:"properties,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifRule struct {
+	ID                   string              `json:"id"`
+	ShortDescription     sarifMessage        `json:"shortDescription"`
+	FullDescription      *sarifMessage       `json:"fullDescription,omitempty"`
+	Help                 *sarifMessage       `json:"help,omitempty"`
+	DefaultConfiguration sarifConfiguration  `json:"defaultConfiguration"`
+	Properties           map[string][]string `json:"properties,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifResult struct {
+	RuleID    string       `json:"ruleId"`
+	RuleIndex int          `json:"ruleIndex"`
+	Kind      string       `json:"kind"`
+	Level     string       `json:"level"`
+	Message   sarifMessage `json:"message"`
+}
+
+// ParseResultsToSARIF renders the check results of a scan as a SARIF 2.1.0
+// log with a single run. The source identifies what the results were
+// gathered from, typically the node name, and may be empty.
+func ParseResultsToSARIF(scanName, source string, results []*ParseResult) ([]byte, error) {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           toolName,
+				Version:        version.Version,
+				InformationURI: toolURI,
+				Rules:          []sarifRule{},
+			},
+		},
+		Results: []sarifResult{},
+		Props:   map[string]string{"scan": scanName},
+	}
+	if source != "" {
+		run.Props["source"] = source
+	}
+
+	for _, pr := range results {
+		cr := pr.CheckResult
+		if cr == nil {
+			continue
+		}
+
+		rule := sarifRule{
+			ID:               cr.ID,
+			ShortDescription: sarifMessage{Text: reportRuleTitle(cr)},
+			DefaultConfiguration: sarifConfiguration{
+				Level: sarifSeverityLevel(cr.Severity),
+			},
+			Properties: map[string][]string{"tags": {string(cr.Severity)}},
+		}
+		if cr.Description != "" {
+			rule.FullDescription = &sarifMessage{Text: cr.Description}
+		}
+		if help := reportRuleHelp(cr); help != "" {
+			rule.Help = &sarifMessage{Text: help}
+		}
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+
+		kind, level := sarifKindAndLevel(cr)
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    cr.ID,
+			RuleIndex: len(run.Tool.Driver.Rules) - 1,
+			Kind:      kind,
+			Level:     level,
+			Message:   sarifMessage{Text: reportResultMessage(cr, source)},
+		})
+	}
+
+	return json.MarshalIndent(&sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	}, "", "  ")
+}
+
+// sarifSeverityLevel maps the severity of a rule to the level a failure of
+// that rule is reported with
+func sarifSeverityLevel(s compv1alpha1.ComplianceCheckResultSeverity) string {
+	switch s {
+	case compv1alpha1.CheckResultSeverityHigh:
+		return "error"
+	case compv1alpha1.CheckResultSeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+func sarifKindAndLevel(cr *compv1alpha1.ComplianceCheckResult) (string, string) {
+	switch cr.Status {
+	case compv1alpha1.CheckResultFail:
+		return "fail", sarifSeverityLevel(cr.Severity)
+	case compv1alpha1.CheckResultError:
+		return "fail", "error"
+	case compv1alpha1.CheckResultPass:
+		return "pass", "none"
+	case compv1alpha1.CheckResultNotApplicable:
+		return "notApplicable", "none"
+	case compv1alpha1.CheckResultManual, compv1alpha1.CheckResultInconsistent:
+		return "review", "none"
+	default:
+		return "informational", "none"
+	}
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	TestCases  []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// ParseResultsToJUnit renders the check results of a scan as a JUnit XML
+// report with one test case per rule. FAIL and ERROR results are reported
+// as failures, NOT-APPLICABLE and MANUAL results as skipped.
+func ParseResultsToJUnit(scanName, source string, results []*ParseResult) ([]byte, error) {
+	suiteName := scanName
+	if source != "" {
+		suiteName = scanName + "/" + source
+	}
+	suite := junitTestSuite{
+		Name:       suiteName,
+		Properties: []junitProperty{{Name: "scan", Value: scanName}},
+		TestCases:  []junitTestCase{},
+	}
+	if source != "" {
+		suite.Properties = append(suite.Properties, junitProperty{Name: "source", Value: source})
+	}
+
+	for _, pr := range results {
+		cr := pr.CheckResult
+		if cr == nil {
+			continue
+		}
+
+		tc := junitTestCase{
+			Name:      cr.ID,
+			ClassName: scanName,
+		}
+		help := reportRuleHelp(cr)
+		switch cr.Status {
+		case compv1alpha1.CheckResultFail, compv1alpha1.CheckResultError:
+			tc.Failure = &junitFailure{
+				Message: reportResultMessage(cr, source),
+				Type:    string(cr.Status),
+				Text:    help,
+			}
+			suite.Failures++
+		case compv1alpha1.CheckResultNotApplicable, compv1alpha1.CheckResultManual:
+			tc.Skipped = &junitSkipped{Message: string(cr.Status)}
+			tc.SystemOut = help
+			suite.Skipped++
+		default:
+			tc.SystemOut = help
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+		suite.Tests++
+	}
+
+	out, err := xml.MarshalIndent(&junitTestSuites{
+		Name:     scanName,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Suites:   []junitTestSuite{suite},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+// reportRuleTitle returns the title of the rule which is the first line of
+// the check description
+func reportRuleTitle(cr *compv1alpha1.ComplianceCheckResult) string {
+	title := strings.SplitN(cr.Description, "\n", 2)[0]
+	if title == "" {
+		return cr.ID
+	}
+	return title
+}
+
+// reportRuleHelp puts the description, rationale and instructions of a rule
+// together so they can be used as the rule help in a report
+func reportRuleHelp(cr *compv1alpha1.ComplianceCheckResult) string {
+	sections := []string{}
+	if cr.Description != "" {
+		sections = append(sections, cr.Description)
+	}
+	if cr.Rationale != "" {
+		sections = append(sections, "Rationale:\n"+cr.Rationale)
+	}
+	if cr.Instructions != "" {
+		sections = append(sections, "Instructions:\n"+cr.Instructions)
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+func reportResultMessage(cr *compv1alpha1.ComplianceCheckResult, source string) string {
+	msg := reportRuleTitle(cr) + ": " + string(cr.Status)
+	if source != "" {
+		msg += " on " + source
+	}
+	return msg
+}
@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"encoding/json"
+	"encoding/xml"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+var _ = Describe("Report rendering", func() {
+	results := []*ParseResult{
+		{
+			Id: "pass",
+			CheckResult: &compv1alpha1.ComplianceCheckResult{
+				ID:          "rule_pass",
+				Status:      compv1alpha1.CheckResultPass,
+				Severity:    compv1alpha1.CheckResultSeverityLow,
+				Description: "Passing rule\nThe rule passes",
+			},
+		},
+		{
+			Id: "fail",
+			CheckResult: &compv1alpha1.ComplianceCheckResult{
+				ID:           "rule_fail",
+				Status:       compv1alpha1.CheckResultFail,
+				Severity:     compv1alpha1.CheckResultSeverityHigh,
+				Description:  "Failing rule\nThe rule fails",
+				Rationale:    "Because",
+				Instructions: "Check it",
+			},
+		},
+		{
+			Id: "error",
+			CheckResult: &compv1alpha1.ComplianceCheckResult{
+				ID:       "rule_error",
+				Status:   compv1alpha1.CheckResultError,
+				Severity: compv1alpha1.CheckResultSeverityMedium,
+			},
+		},
+		{
+			Id: "na",
+			CheckResult: &compv1alpha1.ComplianceCheckResult{
+				ID:     "rule_na",
+				Status: compv1alpha1.CheckResultNotApplicable,
+			},
+		},
+		{
+			Id: "no-check",
+		},
+	}
+
+	Context("SARIF", func() {
+		var log sarifLog
+
+		BeforeEach(func() {
+			out, err := ParseResultsToSARIF("test-scan", "node-1", results)
+			Expect(err).To(BeNil())
+			Expect(json.Unmarshal(out, &log)).To(Succeed())
+		})
+
+		It("Should produce a single run with a rule per check", func() {
+			Expect(log.Version).To(Equal("2.1.0"))
+			Expect(log.Runs).To(HaveLen(1))
+			Expect(log.Runs[0].Tool.Driver.Rules).To(HaveLen(4))
+			Expect(log.Runs[0].Results).To(HaveLen(4))
+			Expect(log.Runs[0].Props["source"]).To(Equal("node-1"))
+		})
+
+		It("Should use the description, rationale and instructions as help", func() {
+			rule := log.Runs[0].Tool.Driver.Rules[1]
+			Expect(rule.ID).To(Equal("rule_fail"))
+			Expect(rule.ShortDescription.Text).To(Equal("Failing rule"))
+			Expect(rule.Help.Text).To(Equal("Failing rule\nThe rule fails\n\nRationale:\nBecause\n\nInstructions:\nCheck it"))
+		})
+
+		It("Should map FAIL and ERROR to failures", func() {
+			res := log.Runs[0].Results
+			Expect(res[0].Kind).To(Equal("pass"))
+			Expect(res[1].Kind).To(Equal("fail"))
+			Expect(res[1].Level).To(Equal("error"))
+			Expect(res[1].RuleIndex).To(Equal(1))
+			Expect(res[2].Kind).To(Equal("fail"))
+			Expect(res[2].Level).To(Equal("error"))
+			Expect(res[3].Kind).To(Equal("notApplicable"))
+		})
+	})
+
+	Context("JUnit", func() {
+		var suites junitTestSuites
+
+		BeforeEach(func() {
+			out, err := ParseResultsToJUnit("test-scan", "node-1", results)
+			Expect(err).To(BeNil())
+			Expect(xml.Unmarshal(out, &suites)).To(Succeed())
+		})
+
+		It("Should count the tests, failures and skipped tests", func() {
+			Expect(suites.Tests).To(Equal(4))
+			Expect(suites.Failures).To(Equal(2))
+			Expect(suites.Skipped).To(Equal(1))
+			Expect(suites.Suites).To(HaveLen(1))
+			Expect(suites.Suites[0].Name).To(Equal("test-scan/node-1"))
+		})
+
+		It("Should report FAIL and ERROR as failures", func() {
+			cases := suites.Suites[0].TestCases
+			Expect(cases[0].Failure).To(BeNil())
+			Expect(cases[1].Failure).NotTo(BeNil())
+			Expect(cases[1].Failure.Type).To(Equal("FAIL"))
+			Expect(cases[1].Failure.Text).To(ContainSubstring("Rationale:\nBecause"))
+			Expect(cases[2].Failure).NotTo(BeNil())
+			Expect(cases[2].Failure.Type).To(Equal("ERROR"))
+			Expect(cases[3].Skipped).NotTo(BeNil())
+		})
+	})
+})
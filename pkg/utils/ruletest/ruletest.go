@@ -0,0 +1,24 @@
+// Package ruletest provides the rules the tests mapping check results to
+// the controls of their rule run against.
+package ruletest
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+// NewRule returns a rule with the given XCCDF ID and annotations, such as
+// its control annotations
+func NewRule(name, id string, annotations map[string]string) compv1alpha1.Rule {
+	return compv1alpha1.Rule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: annotations,
+		},
+		RulePayload: compv1alpha1.RulePayload{
+			ID:    id,
+			Title: "Title of " + name,
+		},
+	}
+}
@@ -1,44 +1,218 @@
 package utils
 
 import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
 	"time"
+)
 
-	libgocrypto "github.com/openshift/library-go/pkg/crypto"
-	"k8s.io/apimachinery/pkg/util/sets"
-	"k8s.io/apiserver/pkg/authentication/user"
+const (
+	// DefaultRSAKeySize is the size of the RSA keys when none is given,
+	// and the smallest one allowed
+	DefaultRSAKeySize = 2048
+	// DefaultECDSAKeySize is the size of the ECDSA keys when none is given
+	DefaultECDSAKeySize = 256
 )
 
-func ComplianceOperatorRootCA(certname string, expire int) ([]byte, []byte, error) {
-	config, err := libgocrypto.MakeSelfSignedCAConfig(certname, expire)
+// CertOptions configures the certificates and keys issued by the operator
+type CertOptions struct {
+	// How long the certificate is valid for
+	Validity time.Duration
+	// The algorithm of the private key, RSA or ECDSA. Defaults to RSA.
+	KeyAlgorithm string
+	// The size of the private key in bits. Defaults to DefaultRSAKeySize
+	// or DefaultECDSAKeySize.
+	KeySize int
+}
+
+// ValidateKeyOptions makes sure that a key of the given algorithm and size
+// can be generated
+func ValidateKeyOptions(algorithm string, size int) error {
+	_, err := keyGenerator(algorithm, size)
+	return err
+}
+
+func keyGenerator(algorithm string, size int) (func() (crypto.Signer, error), error) {
+	switch algorithm {
+	case "", "RSA":
+		if size == 0 {
+			size = DefaultRSAKeySize
+		}
+		if size < DefaultRSAKeySize {
+			return nil, fmt.Errorf("RSA keys must be at least %d bits long, got %d", DefaultRSAKeySize, size)
+		}
+		return func() (crypto.Signer, error) { return rsa.GenerateKey(rand.Reader, size) }, nil
+	case "ECDSA":
+		var curve elliptic.Curve
+		switch size {
+		case 0, 256:
+			curve = elliptic.P256()
+		case 384:
+			curve = elliptic.P384()
+		case 521:
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("ECDSA keys must be 256, 384 or 521 bits long, got %d", size)
+		}
+		return func() (crypto.Signer, error) { return ecdsa.GenerateKey(curve, rand.Reader) }, nil
+	}
+	return nil, fmt.Errorf("unknown key algorithm %s", algorithm)
+}
+
+// ComplianceOperatorRootCA creates a self-signed CA. It returns the PEM
+// encoded certificate and private key.
+func ComplianceOperatorRootCA(certname string, opts CertOptions) ([]byte, []byte, error) {
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: certname},
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	return issueCert(template, nil, nil, opts)
+}
+
+// NewServerCert issues a certificate for a server named certname, signed by
+// the given CA. The returned certificate is followed by the CA certificate.
+func NewServerCert(caCert, caKey []byte, certname string, opts CertOptions) ([]byte, []byte, error) {
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: certname},
+		DNSNames:    []string{certname},
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	return issueCertWithCA(template, caCert, caKey, opts)
+}
+
+// NewClientCert issues a client certificate for certname, signed by the
+// given CA. The returned certificate is followed by the CA certificate.
+func NewClientCert(caCert, caKey []byte, certname string, opts CertOptions) ([]byte, []byte, error) {
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: certname},
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	return issueCertWithCA(template, caCert, caKey, opts)
+}
+
+func issueCertWithCA(template *x509.Certificate, caCertPEM, caKeyPEM []byte, opts CertOptions) ([]byte, []byte, error) {
+	caCert, err := ParseCertificatePEM(caCertPEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot parse the CA certificate: %w", err)
+	}
+	caKey, err := parsePrivateKeyPEM(caKeyPEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot parse the CA key: %w", err)
+	}
+	// The certificate can't outlive its CA
+	if validity := time.Until(caCert.NotAfter); validity < opts.Validity {
+		opts.Validity = validity
+	}
+	cert, key, err := issueCert(template, caCert, caKey, opts)
 	if err != nil {
 		return nil, nil, err
 	}
-
-	return config.GetPEMBytes()
+	return append(cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw})...), key, nil
 }
 
-func NewServerCert(caCert, caKey []byte, certname string, expire int) ([]byte, []byte, error) {
-	ca, err := libgocrypto.GetCAFromBytes(caCert, caKey)
+// issueCert issues a certificate signed by the given CA, or a self-signed
+// one if the CA is nil
+func issueCert(template, caCert *x509.Certificate, caKey crypto.Signer, opts CertOptions) ([]byte, []byte, error) {
+	generate, err := keyGenerator(opts.KeyAlgorithm, opts.KeySize)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := generate()
 	if err != nil {
 		return nil, nil, err
 	}
-	config, err := ca.MakeServerCert(sets.New(certname), expire)
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
 	if err != nil {
 		return nil, nil, err
 	}
 
-	return config.GetPEMBytes()
-}
+	now := time.Now()
+	template.SerialNumber = serial
+	// Allow for some clock skew between the nodes
+	template.NotBefore = now.Add(-time.Minute)
+	template.NotAfter = now.Add(opts.Validity)
+	if caCert == nil {
+		caCert, caKey = template, key
+	}
 
-func NewClientCert(caCert, caKey []byte, certname string, expire int) ([]byte, []byte, error) {
-	ca, err := libgocrypto.GetCAFromBytes(caCert, caKey)
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, key.Public(), caKey)
 	if err != nil {
 		return nil, nil, err
 	}
-	config, err := ca.MakeClientCertificateForDuration(&user.DefaultInfo{Name: certname}, time.Duration(expire)*24*time.Hour)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
 	if err != nil {
 		return nil, nil, err
 	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), nil
+}
+
+// ParseCertificatePEM parses the first certificate of a PEM bundle
+func ParseCertificatePEM(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no PEM encoded certificate found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func parsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM encoded key found")
+	}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+	}
+	return nil, fmt.Errorf("unsupported PEM block %s", block.Type)
+}
 
-	return config.GetPEMBytes()
+// CertNeedsRenewal tells whether the first certificate of a PEM bundle
+// expires within renewBefore, or can't be parsed at all
+func CertNeedsRenewal(certPEM []byte, renewBefore time.Duration, now time.Time) bool {
+	cert, err := ParseCertificatePEM(certPEM)
+	if err != nil {
+		return true
+	}
+	return !now.Before(cert.NotAfter.Add(-renewBefore))
+}
+
+// CertSignedBy tells whether the first certificate of a PEM bundle was
+// signed by the first certificate of caPEM
+func CertSignedBy(certPEM, caPEM []byte) bool {
+	cert, err := ParseCertificatePEM(certPEM)
+	if err != nil {
+		return false
+	}
+	ca, err := ParseCertificatePEM(caPEM)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(cert.RawIssuer, ca.RawSubject) && cert.CheckSignatureFrom(ca) == nil
 }
@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Certificate issuing", func() {
+	opts := CertOptions{Validity: time.Hour}
+
+	var caCert, caKey []byte
+
+	BeforeEach(func() {
+		var err error
+		caCert, caKey, err = ComplianceOperatorRootCA("root-ca-test", opts)
+		Expect(err).To(BeNil())
+	})
+
+	It("issues a self-signed CA", func() {
+		ca, err := ParseCertificatePEM(caCert)
+		Expect(err).To(BeNil())
+		Expect(ca.IsCA).To(BeTrue())
+		Expect(ca.Subject.CommonName).To(Equal("root-ca-test"))
+		Expect(ca.NotAfter).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+		Expect(CertSignedBy(caCert, caCert)).To(BeTrue())
+	})
+
+	It("issues server and client certificates signed by the CA", func() {
+		serverCert, serverKey, err := NewServerCert(caCert, caKey, "test-rs", opts)
+		Expect(err).To(BeNil())
+		clientCert, clientKey, err := NewClientCert(caCert, caKey, "test-client", opts)
+		Expect(err).To(BeNil())
+
+		pool := x509.NewCertPool()
+		Expect(pool.AppendCertsFromPEM(caCert)).To(BeTrue())
+
+		server, err := tls.X509KeyPair(serverCert, serverKey)
+		Expect(err).To(BeNil())
+		// The CA follows the certificate
+		Expect(server.Certificate).To(HaveLen(2))
+		leaf, err := x509.ParseCertificate(server.Certificate[0])
+		Expect(err).To(BeNil())
+		_, err = leaf.Verify(x509.VerifyOptions{DNSName: "test-rs", Roots: pool})
+		Expect(err).To(BeNil())
+
+		client, err := tls.X509KeyPair(clientCert, clientKey)
+		Expect(err).To(BeNil())
+		leaf, err = x509.ParseCertificate(client.Certificate[0])
+		Expect(err).To(BeNil())
+		_, err = leaf.Verify(x509.VerifyOptions{Roots: pool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+		Expect(err).To(BeNil())
+		_, err = leaf.Verify(x509.VerifyOptions{Roots: pool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}})
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("doesn't issue certificates that outlive their CA", func() {
+		cert, _, err := NewServerCert(caCert, caKey, "test-rs", CertOptions{Validity: 48 * time.Hour})
+		Expect(err).To(BeNil())
+		leaf, err := ParseCertificatePEM(cert)
+		Expect(err).To(BeNil())
+		ca, err := ParseCertificatePEM(caCert)
+		Expect(err).To(BeNil())
+		Expect(leaf.NotAfter.After(ca.NotAfter)).To(BeFalse())
+	})
+
+	It("issues keys of the requested algorithm and size", func() {
+		cert, key, err := NewClientCert(caCert, caKey, "test-client", CertOptions{
+			Validity:     time.Hour,
+			KeyAlgorithm: "ECDSA",
+			KeySize:      384,
+		})
+		Expect(err).To(BeNil())
+		pair, err := tls.X509KeyPair(cert, key)
+		Expect(err).To(BeNil())
+		ecKey, ok := pair.PrivateKey.(*ecdsa.PrivateKey)
+		Expect(ok).To(BeTrue())
+		Expect(ecKey.Curve.Params().BitSize).To(Equal(384))
+
+		_, key, err = NewClientCert(caCert, caKey, "test-client", CertOptions{Validity: time.Hour, KeySize: 3072})
+		Expect(err).To(BeNil())
+		signer, err := parsePrivateKeyPEM(key)
+		Expect(err).To(BeNil())
+		Expect(signer.(*rsa.PrivateKey).N.BitLen()).To(Equal(3072))
+	})
+
+	It("rejects weak or unknown keys", func() {
+		Expect(ValidateKeyOptions("RSA", 1024)).ToNot(Succeed())
+		Expect(ValidateKeyOptions("ECDSA", 2048)).ToNot(Succeed())
+		Expect(ValidateKeyOptions("DSA", 0)).ToNot(Succeed())
+		Expect(ValidateKeyOptions("", 0)).To(Succeed())
+		Expect(ValidateKeyOptions("ECDSA", 521)).To(Succeed())
+	})
+
+	It("tells when a certificate needs to be renewed", func() {
+		now := time.Now()
+		Expect(CertNeedsRenewal(caCert, 20*time.Minute, now)).To(BeFalse())
+		Expect(CertNeedsRenewal(caCert, 20*time.Minute, now.Add(45*time.Minute))).To(BeTrue())
+		Expect(CertNeedsRenewal([]byte("garbage"), time.Minute, now)).To(BeTrue())
+	})
+
+	It("tells which CA signed a certificate", func() {
+		otherCA, _, err := ComplianceOperatorRootCA("root-ca-test", opts)
+		Expect(err).To(BeNil())
+		cert, _, err := NewServerCert(caCert, caKey, "test-rs", opts)
+		Expect(err).To(BeNil())
+
+		Expect(CertSignedBy(cert, caCert)).To(BeTrue())
+		// Same subject, different key
+		Expect(CertSignedBy(cert, otherCA)).To(BeFalse())
+	})
+})
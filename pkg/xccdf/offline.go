@@ -0,0 +1,72 @@
+package xccdf
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/antchfx/xmlquery"
+)
+
+// OCPDataRootValueID is the xccdf ID of the value telling the platform
+// checks where the API resources were saved to
+const OCPDataRootValueID = varIDPrefix + "ocp_data_root"
+
+// GetOfflineScanProfileID gets the xccdf ID of the profile evaluated by an
+// offline scan
+func GetOfflineScanProfileID(scanName string) string {
+	return fmt.Sprintf("xccdf_%s_profile_%s-offline", XCCDFNamespace, scanName)
+}
+
+func getOfflineScanTailoringID(scanName string) string {
+	return fmt.Sprintf("xccdf_%s_tailoring_%s-offline", XCCDFNamespace, scanName)
+}
+
+// OfflineScanToXML generates the tailoring used to evaluate a profile against
+// API resources saved to dataRoot instead of the default data root. The
+// profile of the tailoring extends the given profile and only overrides the
+// data root. When the profile is tailored, the tailoring is given and the
+// profile it extends, its selections and its values are carried over instead.
+func OfflineScanToXML(scanName, contentFile, profileID, tailoring, dataRoot string) (string, error) {
+	t := &TailoringElement{
+		XMLNamespaceURI: XCCDFURI,
+		ID:              getOfflineScanTailoringID(scanName),
+		Version: VersionElement{
+			Time:  time.Now().Format(time.RFC3339),
+			Value: "1",
+		},
+		Benchmark: BenchmarkElement{
+			Href: contentFile,
+		},
+		Profile: ProfileElement{
+			ID:      GetOfflineScanProfileID(scanName),
+			Extends: profileID,
+		},
+	}
+
+	if tailoring != "" {
+		profile, err := extendTailoredProfile(t, tailoring, profileID)
+		if err != nil {
+			return "", err
+		}
+		for _, sel := range xmlquery.Find(profile, xccdfElement("select")) {
+			t.Profile.Selections = append(t.Profile.Selections, SelectElement{
+				IDRef:    sel.SelectAttr("idref"),
+				Selected: sel.SelectAttr("selected") == "true",
+			})
+		}
+	}
+
+	values := []SetValueElement{}
+	for _, val := range t.Profile.Values {
+		if val.IDRef != OCPDataRootValueID {
+			values = append(values, val)
+		}
+	}
+	t.Profile.Values = append(values, SetValueElement{IDRef: OCPDataRootValueID, Value: dataRoot})
+
+	output, err := marshalTailoring(t)
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
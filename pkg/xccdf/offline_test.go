@@ -0,0 +1,88 @@
+package xccdf
+
+import (
+	"strings"
+
+	cmpv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/antchfx/xmlquery"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Testing offline scan tailorings", func() {
+	It("overrides the data root of the profile", func() {
+		tailoring, err := OfflineScanToXML("dump", "/tmp/ssg-ocp4-ds.xml", profileIDPrefix+"cis", "", "/tmp/resources")
+		Expect(err).To(BeNil())
+
+		doc, err := xmlquery.Parse(strings.NewReader(tailoring))
+		Expect(err).To(BeNil())
+		Expect(xmlquery.FindOne(doc, "//xccdf-1.2:benchmark").SelectAttr("href")).To(Equal("/tmp/ssg-ocp4-ds.xml"))
+		profile := xmlquery.FindOne(doc, "//xccdf-1.2:Profile")
+		Expect(profile.SelectAttr("id")).To(Equal(GetOfflineScanProfileID("dump")))
+		Expect(profile.SelectAttr("extends")).To(Equal(profileIDPrefix + "cis"))
+		Expect(findSelectionsInTailoring(tailoring)).To(BeEmpty())
+		vars, err := findVariablesInTailoring(tailoring)
+		Expect(err).To(BeNil())
+		Expect(vars).To(ConsistOf(tailoredValue{ID: OCPDataRootValueID, Value: "/tmp/resources"}))
+	})
+
+	It("carries over the tailoring of a tailored profile", func() {
+		tp := &cmpv1alpha1.TailoredProfile{
+			ObjectMeta: v1.ObjectMeta{Name: "tailored"},
+			Spec: cmpv1alpha1.TailoredProfileSpec{
+				EnableRules:  []cmpv1alpha1.RuleReferenceSpec{{Name: "enabled"}},
+				DisableRules: []cmpv1alpha1.RuleReferenceSpec{{Name: "disabled"}},
+			},
+		}
+		p := &cmpv1alpha1.Profile{ProfilePayload: cmpv1alpha1.ProfilePayload{ID: profileIDPrefix + "cis"}}
+		pb := &cmpv1alpha1.ProfileBundle{Spec: cmpv1alpha1.ProfileBundleSpec{ContentFile: "ssg-ocp4-ds.xml"}}
+		rules := map[string]*cmpv1alpha1.Rule{
+			"enabled":  {RulePayload: cmpv1alpha1.RulePayload{ID: ruleIDPrefix + "enabled"}},
+			"disabled": {RulePayload: cmpv1alpha1.RulePayload{ID: ruleIDPrefix + "disabled"}},
+		}
+		variables := []*cmpv1alpha1.Variable{
+			{VariablePayload: cmpv1alpha1.VariablePayload{ID: varIDPrefix + "timeout", Value: "600"}},
+			{VariablePayload: cmpv1alpha1.VariablePayload{ID: OCPDataRootValueID, Value: "/somewhere/else"}},
+		}
+		original, err := TailoredProfileToXML(tp, p, pb, rules, variables)
+		Expect(err).To(BeNil())
+
+		tailoring, err := OfflineScanToXML("dump", "/tmp/ssg-ocp4-ds.xml", GetXCCDFProfileID(tp), original, "/tmp/resources")
+		Expect(err).To(BeNil())
+
+		doc, err := xmlquery.Parse(strings.NewReader(tailoring))
+		Expect(err).To(BeNil())
+		Expect(xmlquery.FindOne(doc, "//xccdf-1.2:Profile").SelectAttr("extends")).To(Equal(profileIDPrefix + "cis"))
+		Expect(findSelectionsInTailoring(tailoring)).To(Equal(map[string]string{
+			ruleIDPrefix + "enabled":  "true",
+			ruleIDPrefix + "disabled": "false",
+		}))
+		vars, err := findVariablesInTailoring(tailoring)
+		Expect(err).To(BeNil())
+		Expect(vars).To(ConsistOf(
+			tailoredValue{ID: varIDPrefix + "timeout", Value: "600"},
+			tailoredValue{ID: OCPDataRootValueID, Value: "/tmp/resources"},
+		))
+	})
+
+	It("reads tailorings using another namespace prefix", func() {
+		original := `<xccdf:Tailoring xmlns:xccdf="http://checklists.nist.gov/xccdf/1.2" id="xccdf_compliance.openshift.io_tailoring_tailored">
+  <xccdf:benchmark href="/content/ssg-ocp4-ds.xml"></xccdf:benchmark>
+  <xccdf:Profile id="` + profileIDPrefix + `tailored" extends="` + profileIDPrefix + `cis">
+    <xccdf:select idref="` + ruleIDPrefix + `disabled" selected="false"></xccdf:select>
+  </xccdf:Profile>
+</xccdf:Tailoring>`
+
+		tailoring, err := OfflineScanToXML("dump", "/tmp/ssg-ocp4-ds.xml", profileIDPrefix+"tailored", original, "/tmp/resources")
+		Expect(err).To(BeNil())
+
+		doc, err := xmlquery.Parse(strings.NewReader(tailoring))
+		Expect(err).To(BeNil())
+		Expect(xmlquery.FindOne(doc, "//xccdf-1.2:Profile").SelectAttr("extends")).To(Equal(profileIDPrefix + "cis"))
+		Expect(findSelectionsInTailoring(tailoring)).To(Equal(map[string]string{
+			ruleIDPrefix + "disabled": "false",
+		}))
+	})
+})
@@ -0,0 +1,118 @@
+package xccdf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/antchfx/xmlquery"
+)
+
+// GetTargetedRescanProfileID gets the xccdf ID of the profile evaluated by a
+// rescan of only some of the rules of a scan
+func GetTargetedRescanProfileID(scanName string) string {
+	return fmt.Sprintf("xccdf_%s_profile_%s-rescan", XCCDFNamespace, scanName)
+}
+
+func getTargetedRescanTailoringID(scanName string) string {
+	return fmt.Sprintf("xccdf_%s_tailoring_%s-rescan", XCCDFNamespace, scanName)
+}
+
+// TargetedRescanToXML generates the tailoring used to rescan only the given
+// rules of a scan. The profile of the tailoring extends the scanned profile,
+// selects the rules to rescan and deselects the other rules of the scan.
+// When the scan is tailored, the tailoring of the scan is given and the
+// profile it extends, its selections and its values are carried over instead.
+func TargetedRescanToXML(scanName, contentFile, profileID, tailoring string, rules, otherRules []string) (string, error) {
+	t := &TailoringElement{
+		XMLNamespaceURI: XCCDFURI,
+		ID:              getTargetedRescanTailoringID(scanName),
+		Version: VersionElement{
+			Time:  time.Now().Format(time.RFC3339),
+			Value: "1",
+		},
+		Benchmark: BenchmarkElement{
+			Href: contentFile,
+		},
+		Profile: ProfileElement{
+			ID:      GetTargetedRescanProfileID(scanName),
+			Extends: profileID,
+		},
+	}
+
+	deselected := map[string]bool{}
+	for _, rule := range otherRules {
+		deselected[rule] = true
+	}
+
+	if tailoring != "" {
+		profile, err := extendTailoredProfile(t, tailoring, profileID)
+		if err != nil {
+			return "", err
+		}
+		for _, sel := range xmlquery.Find(profile, xccdfElement("select")) {
+			deselected[sel.SelectAttr("idref")] = true
+		}
+	}
+
+	for _, rule := range rules {
+		delete(deselected, rule)
+		t.Profile.Selections = append(t.Profile.Selections, SelectElement{IDRef: rule, Selected: true})
+	}
+	others := make([]string, 0, len(deselected))
+	for rule := range deselected {
+		others = append(others, rule)
+	}
+	sort.Strings(others)
+	for _, rule := range others {
+		t.Profile.Selections = append(t.Profile.Selections, SelectElement{IDRef: rule, Selected: false})
+	}
+
+	output, err := marshalTailoring(t)
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// extendTailoredProfile makes the profile of t extend the profile the given
+// tailored profile extends, with the same values, and returns the tailored
+// profile so that the caller can carry over its selections
+func extendTailoredProfile(t *TailoringElement, tailoring, profileID string) (*xmlquery.Node, error) {
+	doc, err := xmlquery.Parse(strings.NewReader(tailoring))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse the tailoring of the scan: %w", err)
+	}
+	profile := getTailoredProfile(doc, profileID)
+	if profile == nil {
+		return nil, fmt.Errorf("profile %s not found in the tailoring of the scan", profileID)
+	}
+	if benchmark := xmlquery.FindOne(doc, "//"+xccdfElement("benchmark")); benchmark != nil {
+		t.Benchmark.Href = benchmark.SelectAttr("href")
+	}
+	t.Profile.Extends = profile.SelectAttr("extends")
+	for _, val := range xmlquery.Find(profile, xccdfElement("set-value")) {
+		t.Profile.Values = append(t.Profile.Values, SetValueElement{
+			IDRef: val.SelectAttr("idref"),
+			Value: val.InnerText(),
+		})
+	}
+	return profile, nil
+}
+
+func getTailoredProfile(doc *xmlquery.Node, profileID string) *xmlquery.Node {
+	for _, profile := range xmlquery.Find(doc, "//"+xccdfElement("Profile")) {
+		if profile.SelectAttr("id") == profileID {
+			return profile
+		}
+	}
+	return nil
+}
+
+// xccdfElement returns the XPath step matching the XCCDF 1.2 elements with
+// the given local name, whichever prefix, if any, the document binds the
+// namespace to
+func xccdfElement(local string) string {
+	return fmt.Sprintf("*[local-name()='%s' and namespace-uri()='%s']", local, XCCDFURI)
+}
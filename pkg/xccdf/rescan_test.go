@@ -0,0 +1,115 @@
+package xccdf
+
+import (
+	"strings"
+
+	cmpv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/antchfx/xmlquery"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func findSelectionsInTailoring(tailoring string) map[string]string {
+	tailoringDom, err := xmlquery.Parse(strings.NewReader(tailoring))
+	Expect(err).To(BeNil())
+
+	selections := map[string]string{}
+	for _, sel := range tailoringDom.SelectElements("//xccdf-1.2:select") {
+		selections[sel.SelectAttr("idref")] = sel.SelectAttr("selected")
+	}
+	return selections
+}
+
+var _ = Describe("Testing targeted rescan tailorings", func() {
+	const (
+		failedRule = ruleIDPrefix + "failed"
+		passedRule = ruleIDPrefix + "passed"
+	)
+
+	It("extends the scanned profile", func() {
+		tailoring, err := TargetedRescanToXML("scan", "/content/ssg-ocp4-ds.xml", profileIDPrefix+"cis", "",
+			[]string{failedRule}, []string{passedRule})
+		Expect(err).To(BeNil())
+
+		doc, err := xmlquery.Parse(strings.NewReader(tailoring))
+		Expect(err).To(BeNil())
+		Expect(xmlquery.FindOne(doc, "//xccdf-1.2:benchmark").SelectAttr("href")).To(Equal("/content/ssg-ocp4-ds.xml"))
+		profile := xmlquery.FindOne(doc, "//xccdf-1.2:Profile")
+		Expect(profile.SelectAttr("id")).To(Equal(GetTargetedRescanProfileID("scan")))
+		Expect(profile.SelectAttr("extends")).To(Equal(profileIDPrefix + "cis"))
+		Expect(findSelectionsInTailoring(tailoring)).To(Equal(map[string]string{
+			failedRule: "true",
+			passedRule: "false",
+		}))
+	})
+
+	It("carries over the tailoring of a tailored scan", func() {
+		tp := &cmpv1alpha1.TailoredProfile{
+			ObjectMeta: v1.ObjectMeta{Name: "tailored"},
+			Spec: cmpv1alpha1.TailoredProfileSpec{
+				EnableRules: []cmpv1alpha1.RuleReferenceSpec{{Name: "enabled"}},
+			},
+		}
+		p := &cmpv1alpha1.Profile{ProfilePayload: cmpv1alpha1.ProfilePayload{ID: profileIDPrefix + "cis"}}
+		pb := &cmpv1alpha1.ProfileBundle{Spec: cmpv1alpha1.ProfileBundleSpec{ContentFile: "ssg-ocp4-ds.xml"}}
+		rules := map[string]*cmpv1alpha1.Rule{
+			"enabled": {RulePayload: cmpv1alpha1.RulePayload{ID: ruleIDPrefix + "enabled"}},
+		}
+		variables := []*cmpv1alpha1.Variable{
+			{VariablePayload: cmpv1alpha1.VariablePayload{ID: varIDPrefix + "timeout", Value: "600"}},
+		}
+		original, err := TailoredProfileToXML(tp, p, pb, rules, variables)
+		Expect(err).To(BeNil())
+
+		tailoring, err := TargetedRescanToXML("scan", "/content/ssg-ocp4-ds.xml", GetXCCDFProfileID(tp), original,
+			[]string{failedRule}, []string{passedRule})
+		Expect(err).To(BeNil())
+
+		doc, err := xmlquery.Parse(strings.NewReader(tailoring))
+		Expect(err).To(BeNil())
+		Expect(xmlquery.FindOne(doc, "//xccdf-1.2:benchmark").SelectAttr("href")).To(Equal("/content/ssg-ocp4-ds.xml"))
+		Expect(xmlquery.FindOne(doc, "//xccdf-1.2:Profile").SelectAttr("extends")).To(Equal(profileIDPrefix + "cis"))
+		Expect(findSelectionsInTailoring(tailoring)).To(Equal(map[string]string{
+			failedRule:               "true",
+			passedRule:               "false",
+			ruleIDPrefix + "enabled": "false",
+		}))
+		vars, err := findVariablesInTailoring(tailoring)
+		Expect(err).To(BeNil())
+		Expect(vars).To(ConsistOf(tailoredValue{ID: varIDPrefix + "timeout", Value: "600"}))
+	})
+
+	It("doesn't depend on the namespace prefix of the tailoring", func() {
+		original := `<Tailoring xmlns="http://checklists.nist.gov/xccdf/1.2" id="xccdf_compliance.openshift.io_tailoring_tailored">
+  <benchmark href="/content/ssg-ocp4-ds.xml"></benchmark>
+  <Profile id="` + profileIDPrefix + `tailored" extends="` + profileIDPrefix + `cis">
+    <select idref="` + ruleIDPrefix + `enabled" selected="true"></select>
+    <set-value idref="` + varIDPrefix + `timeout">600</set-value>
+  </Profile>
+</Tailoring>`
+
+		tailoring, err := TargetedRescanToXML("scan", "/content/ssg-ocp4-ds.xml", profileIDPrefix+"tailored", original,
+			[]string{failedRule}, nil)
+		Expect(err).To(BeNil())
+
+		doc, err := xmlquery.Parse(strings.NewReader(tailoring))
+		Expect(err).To(BeNil())
+		Expect(xmlquery.FindOne(doc, "//xccdf-1.2:Profile").SelectAttr("extends")).To(Equal(profileIDPrefix + "cis"))
+		Expect(findSelectionsInTailoring(tailoring)).To(Equal(map[string]string{
+			failedRule:               "true",
+			ruleIDPrefix + "enabled": "false",
+		}))
+		vars, err := findVariablesInTailoring(tailoring)
+		Expect(err).To(BeNil())
+		Expect(vars).To(ConsistOf(tailoredValue{ID: varIDPrefix + "timeout", Value: "600"}))
+	})
+
+	It("fails if the tailoring doesn't contain the scanned profile", func() {
+		_, err := TargetedRescanToXML("scan", "/content/ssg-ocp4-ds.xml", profileIDPrefix+"cis",
+			`<xccdf-1.2:Tailoring xmlns:xccdf-1.2="http://checklists.nist.gov/xccdf/1.2"/>`,
+			[]string{failedRule}, nil)
+		Expect(err).To(MatchError(ContainSubstring("not found in the tailoring")))
+	})
+})
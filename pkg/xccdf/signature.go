@@ -0,0 +1,116 @@
+package xccdf
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"fmt"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+)
+
+// SignatureWrapperElement is the XCCDF element holding the XML signature of
+// the tailoring. It's marshalled empty, the signature is added once the rest
+// of the document is signed.
+type SignatureWrapperElement struct {
+	XMLName xml.Name `xml:"xccdf-1.2:signature"`
+}
+
+// SigningKey is the key tailorings are signed with
+type SigningKey struct {
+	Signer crypto.Signer
+	// The certificate of the key. If set, it's embedded in the signature so
+	// that verifiers can tell which key was used.
+	Certificate *x509.Certificate
+}
+
+// ParseSigningKey parses a PEM-encoded RSA or ECDSA private key, in PKCS#1,
+// SEC 1 or PKCS#8 form, and its optional PEM-encoded certificate
+func ParseSigningKey(keyPEM, certPEM []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("no PEM data found in the private key")
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse the private key: %w", err)
+	}
+
+	signingKey := &SigningKey{}
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		signingKey.Signer = k
+	case *ecdsa.PrivateKey:
+		signingKey.Signer = k
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T, only RSA and ECDSA keys are supported", key)
+	}
+
+	if len(certPEM) > 0 {
+		certBlock, _ := pem.Decode(certPEM)
+		if certBlock == nil {
+			return nil, errors.New("no PEM data found in the certificate")
+		}
+		signingKey.Certificate, err = x509.ParseCertificate(certBlock.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse the certificate: %w", err)
+		}
+	}
+	return signingKey, nil
+}
+
+// signTailoring adds an enveloped XML signature (XML-DSig) of the whole
+// document to the tailoring and returns the signed document
+func signTailoring(tailoring *TailoringElement, key *SigningKey) ([]byte, error) {
+	var certs [][]byte
+	if key.Certificate != nil {
+		certs = append(certs, key.Certificate.Raw)
+	}
+	ctx, err := dsig.NewSigningContext(key.Signer, certs)
+	if err != nil {
+		return nil, err
+	}
+	ctx.Canonicalizer = dsig.MakeC14N10ExclusiveCanonicalizerWithPrefixList("")
+
+	// The signature element of XCCDF envelops the XML signature, so it's
+	// part of the signed document, as is the indentation
+	tailoring.Signature = &SignatureWrapperElement{}
+	output, err := marshalTailoring(tailoring)
+	if err != nil {
+		return nil, err
+	}
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(output); err != nil {
+		return nil, err
+	}
+	root := doc.Root()
+	signature, err := ctx.ConstructSignature(root, true)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't sign the tailoring: %w", err)
+	}
+	if key.Certificate == nil {
+		// The key info would be empty otherwise
+		signature.RemoveChild(signature.SelectElement(ctx.Prefix + ":" + dsig.KeyInfoTag))
+	}
+	wrapper := root.SelectElement("xccdf-1.2:signature")
+	if wrapper == nil {
+		return nil, errors.New("the tailoring has no signature element")
+	}
+	wrapper.AddChild(signature)
+	return doc.WriteToBytes()
+}
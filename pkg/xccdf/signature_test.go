@@ -0,0 +1,147 @@
+package xccdf
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"time"
+
+	cmpv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// newTestCertificate returns a self-signed certificate of the key
+func newTestCertificate(signer crypto.Signer) *x509.Certificate {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "tailoring-signer"},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, signer.Public(), signer)
+	Expect(err).To(BeNil())
+	cert, err := x509.ParseCertificate(certDER)
+	Expect(err).To(BeNil())
+	return cert
+}
+
+// verifyTailoring verifies the enveloped XML signature of a tailoring the
+// way its consumers do, with the certificate of the signing key
+func verifyTailoring(tailoring string, cert *x509.Certificate) error {
+	doc := etree.NewDocument()
+	if err := doc.ReadFromString(tailoring); err != nil {
+		return err
+	}
+	ctx := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{
+		Roots: []*x509.Certificate{cert},
+	})
+	_, err := ctx.Validate(doc.Root())
+	return err
+}
+
+var _ = Describe("Testing tailoring signatures", func() {
+	var (
+		tp *cmpv1alpha1.TailoredProfile
+		pb *cmpv1alpha1.ProfileBundle
+	)
+
+	BeforeEach(func() {
+		pb = &cmpv1alpha1.ProfileBundle{Spec: cmpv1alpha1.ProfileBundleSpec{ContentFile: "ssg-ocp4-ds.xml"}}
+		tp = &cmpv1alpha1.TailoredProfile{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      "signed",
+				Namespace: "tailoredProfileNamespace",
+			},
+			Spec: cmpv1alpha1.TailoredProfileSpec{
+				Title:       "Signed & tailored",
+				Description: `A "signed" <tailoring>`,
+			},
+		}
+	})
+
+	signAndVerify := func(signer crypto.Signer) {
+		keyDER, err := x509.MarshalPKCS8PrivateKey(signer)
+		Expect(err).To(BeNil())
+		key, err := ParseSigningKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), nil)
+		Expect(err).To(BeNil())
+
+		tailoring, err := SignedTailoredProfileToXML(tp, nil, pb, nil, nil, key)
+		Expect(err).To(BeNil())
+		Expect(tailoring).To(HavePrefix(XMLHeader))
+		Expect(tailoring).To(ContainSubstring("<xccdf-1.2:signature><ds:Signature"))
+		Expect(tailoring).ToNot(ContainSubstring("<ds:KeyInfo"))
+		cert := newTestCertificate(signer)
+		Expect(verifyTailoring(tailoring, cert)).To(Succeed())
+
+		By("detecting changes to the tailoring")
+		tampered := strings.Replace(tailoring, "Signed &amp; tailored", "Changed", 1)
+		Expect(tampered).ToNot(Equal(tailoring))
+		Expect(verifyTailoring(tampered, cert)).ToNot(Succeed())
+	}
+
+	It("signs with an RSA key", func() {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).To(BeNil())
+		signAndVerify(key)
+	})
+
+	It("signs with an ECDSA key", func() {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).To(BeNil())
+		signAndVerify(key)
+	})
+
+	It("rejects a signature made with another key", func() {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).To(BeNil())
+		other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).To(BeNil())
+
+		tailoring, err := SignedTailoredProfileToXML(tp, nil, pb, nil, nil, &SigningKey{Signer: key})
+		Expect(err).To(BeNil())
+		Expect(verifyTailoring(tailoring, newTestCertificate(other))).ToNot(Succeed())
+	})
+
+	It("embeds the certificate of the key", func() {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).To(BeNil())
+		cert := newTestCertificate(key)
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		Expect(err).To(BeNil())
+
+		signingKey, err := ParseSigningKey(
+			pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+			pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+		Expect(err).To(BeNil())
+
+		tailoring, err := SignedTailoredProfileToXML(tp, nil, pb, nil, nil, signingKey)
+		Expect(err).To(BeNil())
+		Expect(tailoring).To(ContainSubstring("<ds:X509Certificate>"))
+		Expect(verifyTailoring(tailoring, cert)).To(Succeed())
+
+		By("not trusting a certificate other than the expected one")
+		other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).To(BeNil())
+		Expect(verifyTailoring(tailoring, newTestCertificate(other))).ToNot(Succeed())
+	})
+
+	It("doesn't verify an unsigned tailoring", func() {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).To(BeNil())
+		tailoring, err := TailoredProfileToXML(tp, nil, pb, nil, nil)
+		Expect(err).To(BeNil())
+		Expect(verifyTailoring(tailoring, newTestCertificate(key))).To(MatchError(dsig.ErrMissingSignature))
+	})
+})
@@ -33,8 +33,7 @@ type TailoringElement struct {
 	Benchmark       BenchmarkElement
 	Version         VersionElement
 	Profile         ProfileElement
-	// TODO(jaosorior): Add signature capabilities
-	// Signature SignatureElement
+	Signature       *SignatureWrapperElement
 }
 
 type BenchmarkElement struct {
@@ -194,7 +193,25 @@ func getValuesFromVariables(variables []*cmpv1alpha1.Variable) []SetValueElement
 
 // TailoredProfileToXML gets an XML string from a TailoredProfile and the corresponding Profile
 func TailoredProfileToXML(tp *cmpv1alpha1.TailoredProfile, p *cmpv1alpha1.Profile, pb *cmpv1alpha1.ProfileBundle, rules map[string]*cmpv1alpha1.Rule, variables []*cmpv1alpha1.Variable) (string, error) {
-	tailoring := TailoringElement{
+	output, err := marshalTailoring(newTailoring(tp, p, pb, rules, variables))
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// SignedTailoredProfileToXML is like TailoredProfileToXML, but the tailoring
+// is signed with the given key using an enveloped XML signature
+func SignedTailoredProfileToXML(tp *cmpv1alpha1.TailoredProfile, p *cmpv1alpha1.Profile, pb *cmpv1alpha1.ProfileBundle, rules map[string]*cmpv1alpha1.Rule, variables []*cmpv1alpha1.Variable, key *SigningKey) (string, error) {
+	output, err := signTailoring(newTailoring(tp, p, pb, rules, variables), key)
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+func newTailoring(tp *cmpv1alpha1.TailoredProfile, p *cmpv1alpha1.Profile, pb *cmpv1alpha1.ProfileBundle, rules map[string]*cmpv1alpha1.Rule, variables []*cmpv1alpha1.Variable) *TailoringElement {
+	tailoring := &TailoringElement{
 		XMLNamespaceURI: XCCDFURI,
 		ID:              getTailoringID(tp),
 		Version: VersionElement{
@@ -229,9 +246,13 @@ func TailoredProfileToXML(tp *cmpv1alpha1.TailoredProfile, p *cmpv1alpha1.Profil
 		}
 	}
 
+	return tailoring
+}
+
+func marshalTailoring(tailoring *TailoringElement) ([]byte, error) {
 	output, err := xml.MarshalIndent(tailoring, "", "  ")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return XMLHeader + "\n" + string(output), nil
+	return append([]byte(XMLHeader+"\n"), output...), nil
 }